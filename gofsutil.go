@@ -3,7 +3,9 @@ package gofsutil
 import (
 	"context"
 	"errors"
+	"os"
 	"path/filepath"
+	"time"
 )
 
 var (
@@ -11,8 +13,38 @@ var (
 	// the contextual function.
 	ErrNotImplemented = errors.New("not implemented")
 
+	// ErrDeviceNotFound is returned when a device does not exist.
+	ErrDeviceNotFound = errors.New("device not found")
+
+	// ErrNotMounted is returned when a path is not a mount point.
+	ErrNotMounted = errors.New("not mounted")
+
+	// ErrAlreadyMounted is returned when a device or path is already
+	// mounted.
+	ErrAlreadyMounted = errors.New("already mounted")
+
+	// ErrShrinkUnsupported is returned when a filesystem cannot be
+	// shrunk.
+	ErrShrinkUnsupported = errors.New("shrink unsupported")
+
+	// ErrDeviceBusy is returned when a device is in use by another
+	// process.
+	ErrDeviceBusy = errors.New("device busy")
+
+	// ErrIDMapUnsupported is returned when the kernel or filesystem does
+	// not support idmapped mounts.
+	ErrIDMapUnsupported = errors.New("idmapped mounts unsupported")
+
+	// ErrBindTypeMismatch is returned when a directory is bind mounted
+	// to a target that is not a directory or when a file is bind mounted
+	// to a target that is a directory.
+	ErrBindTypeMismatch = errors.New("bind mount source and target types differ")
+
 	// fs is the default FS instance.
-	fs = &FS{ScanEntry: defaultEntryScanFunc}
+	fs = &FS{
+		ScanEntry:  defaultEntryScanFunc,
+		RunCommand: defaultCommandRunFunc,
+	}
 )
 
 // GetDiskFormat uses 'lsblk' to see if the given disk is unformatted.
@@ -20,6 +52,27 @@ func GetDiskFormat(ctx context.Context, disk string) (string, error) {
 	return fs.GetDiskFormat(ctx, disk)
 }
 
+// DetectFSType returns the type of the filesystem on the provided device.
+func DetectFSType(ctx context.Context, device string) (string, error) {
+	return fs.DetectFSType(ctx, device)
+}
+
+// FormatDevice formats the provided device as fsType without mounting it.
+func FormatDevice(
+	ctx context.Context,
+	device, fsType string,
+	force bool,
+	mkfsOpts ...string) error {
+
+	return fs.FormatDevice(ctx, device, fsType, force, mkfsOpts...)
+}
+
+// RegisterFormatter registers fn as the formatter used to format a device
+// as fsType.
+func RegisterFormatter(fsType string, fn FormatterFunc) {
+	fs.RegisterFormatter(fsType, fn)
+}
+
 // FormatAndMount uses unix utils to format and mount the given disk.
 func FormatAndMount(
 	ctx context.Context,
@@ -29,6 +82,51 @@ func FormatAndMount(
 	return fs.FormatAndMount(ctx, source, target, fsType, opts...)
 }
 
+// FormatAndMountReport behaves like FormatAndMount but also returns a flag
+// indicating whether the disk was formatted.
+func FormatAndMountReport(
+	ctx context.Context,
+	source, target, fsType string,
+	opts ...string) (bool, error) {
+
+	return fs.FormatAndMountReport(ctx, source, target, fsType, opts...)
+}
+
+// FormatAndMountWithOpts behaves like FormatAndMount but accepts options
+// that control how the disk is formatted.
+func FormatAndMountWithOpts(
+	ctx context.Context,
+	source, target, fsType string,
+	formatOpts FormatOptions,
+	opts ...string) error {
+
+	return fs.FormatAndMountWithOpts(
+		ctx, source, target, fsType, formatOpts, opts...)
+}
+
+// FormatAndMountWithProgress behaves like FormatAndMount but invokes
+// progress with each line of the output of the mkfs command as it is
+// written.
+func FormatAndMountWithProgress(
+	ctx context.Context,
+	source, target, fsType string,
+	progress func(line string),
+	options ...string) error {
+
+	return fs.FormatAndMountWithProgress(
+		ctx, source, target, fsType, progress, options...)
+}
+
+// WouldFormat reports whether FormatAndMount would format source as
+// fsType given the current state of source, and the reason for the
+// decision.
+func WouldFormat(
+	ctx context.Context,
+	source, fsType string) (format bool, reason string, err error) {
+
+	return fs.WouldFormat(ctx, source, fsType)
+}
+
 // Mount mounts source to target as fstype with given options.
 //
 // The parameters 'source' and 'fstype' must be empty strings in case they
@@ -46,6 +144,18 @@ func Mount(
 	return fs.Mount(ctx, source, target, fsType, opts...)
 }
 
+// MountFAT mounts the fat-family filesystem on device to target with the
+// files owned by uid and gid and their permissions masked by mask.
+func MountFAT(
+	ctx context.Context,
+	device, target string,
+	uid, gid int,
+	mask os.FileMode,
+	options ...string) error {
+
+	return fs.MountFAT(ctx, device, target, uid, gid, mask, options...)
+}
+
 // BindMount behaves like Mount was called with a "bind" flag set
 // in the options list.
 func BindMount(
@@ -56,11 +166,100 @@ func BindMount(
 	return fs.BindMount(ctx, source, target, opts...)
 }
 
+// BindMountFile bind mounts the regular file source to target, creating
+// target as an empty file if it does not exist.
+func BindMountFile(
+	ctx context.Context,
+	source, target string,
+	opts ...string) error {
+
+	return fs.BindMountFile(ctx, source, target, opts...)
+}
+
+// MoveMount atomically moves the mount at source to target.
+func MoveMount(ctx context.Context, source, target string) error {
+	return fs.MoveMount(ctx, source, target)
+}
+
 // Unmount unmounts the target.
 func Unmount(ctx context.Context, target string) error {
 	return fs.Unmount(ctx, target)
 }
 
+// GetMountByTarget returns the topmost mount at target.
+func GetMountByTarget(ctx context.Context, target string) (Info, error) {
+	return fs.GetMountByTarget(ctx, target)
+}
+
+// GetMountByTargetWith returns the mount at target, processing the
+// entries of the mount table with scan.
+func GetMountByTargetWith(
+	ctx context.Context, target string, scan EntryScanFunc) (Info, error) {
+
+	return fs.GetMountByTargetWith(ctx, target, scan)
+}
+
+// ResolveSource returns the canonical path of the block device that is
+// the source of the mount.
+func ResolveSource(ctx context.Context, i Info) (string, error) {
+	return fs.ResolveSource(ctx, i)
+}
+
+// DiffMount returns the differences between the mount at target and the
+// desired mount spec.
+func DiffMount(
+	ctx context.Context, target string, desired Info) (MountDiff, error) {
+
+	return fs.DiffMount(ctx, target, desired)
+}
+
+// CloneMountOptions returns the options of the mount at target so they
+// may be used to create another mount with the same options.
+func CloneMountOptions(ctx context.Context, target string) ([]string, error) {
+	return fs.CloneMountOptions(ctx, target)
+}
+
+// GetTopMount returns the visible mount at target and a flag indicating
+// whether anything is mounted at target.
+func GetTopMount(ctx context.Context, target string) (Info, bool, error) {
+	return fs.GetTopMount(ctx, target)
+}
+
+// SetAtimePolicy remounts the mount at target with the atime policy.
+func SetAtimePolicy(ctx context.Context, target, policy string) error {
+	return fs.SetAtimePolicy(ctx, target, policy)
+}
+
+// PromoteToReadWrite remounts the mount at target read-write.
+func PromoteToReadWrite(ctx context.Context, target string) error {
+	return fs.PromoteToReadWrite(ctx, target)
+}
+
+// DemoteToReadOnly remounts the mount at target read-only.
+func DemoteToReadOnly(ctx context.Context, target string) error {
+	return fs.DemoteToReadOnly(ctx, target)
+}
+
+// GetMountErrorState returns the error state of the mount at target.
+func GetMountErrorState(
+	ctx context.Context, target string) (state string, err error) {
+
+	return fs.GetMountErrorState(ctx, target)
+}
+
+// IsReadOnlyDueToErrors returns a flag indicating whether the mount at
+// target is read-only because its filesystem recorded errors.
+func IsReadOnlyDueToErrors(ctx context.Context, target string) (bool, error) {
+	return fs.IsReadOnlyDueToErrors(ctx, target)
+}
+
+// GetMountUsers returns the processes that have a file beneath target
+// open or that use a directory beneath target as their working or root
+// directory.
+func GetMountUsers(ctx context.Context, target string) ([]ProcessInfo, error) {
+	return fs.GetMountUsers(ctx, target)
+}
+
 // GetMounts returns a slice of all the mounted filesystems.
 //
 // * Linux hosts use mount_namespaces to obtain mount information.
@@ -78,11 +277,125 @@ func GetMounts(ctx context.Context) ([]Info, error) {
 	return fs.GetMounts(ctx)
 }
 
+// GetMountsWith returns a slice of all the mounted filesystems, processing
+// the entries of the mount table with scan.
+func GetMountsWith(ctx context.Context, scan EntryScanFunc) ([]Info, error) {
+	return fs.GetMountsWith(ctx, scan)
+}
+
+// GetMountTree returns the mounted filesystems as a tree.
+func GetMountTree(ctx context.Context) (*MountNode, error) {
+	return fs.GetMountTree(ctx)
+}
+
+// SnapshotMounts returns a snapshot of the mounted filesystems.
+func SnapshotMounts(ctx context.Context) (MountSnapshot, error) {
+	return fs.SnapshotMounts(ctx)
+}
+
+// WalkMounts invokes fn for each of the mounted filesystems without
+// accumulating the entire mount table in memory. The walk ends when fn
+// returns true or an error, or when the context is cancelled.
+func WalkMounts(
+	ctx context.Context, fn func(Info) (stop bool, err error)) error {
+
+	return fs.WalkMounts(ctx, fn)
+}
+
+// GetMountsForPID returns a slice of all the filesystems mounted in the
+// mount namespace of the process with the provided PID.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func GetMountsForPID(ctx context.Context, pid int) ([]Info, error) {
+	return fs.GetMountsForPID(ctx, pid)
+}
+
+// GetMountsWhere returns the mounted filesystems for which pred returns
+// true.
+func GetMountsWhere(ctx context.Context, pred func(Info) bool) ([]Info, error) {
+	return fs.GetMountsWhere(ctx, pred)
+}
+
+// GetMtabMounts returns the mounts recorded in /etc/mtab.
+func GetMtabMounts(ctx context.Context) ([]Info, error) {
+	return fs.GetMtabMounts(ctx)
+}
+
+// CompareMountSources returns the mounts found only in /etc/mtab and the
+// mounts found only in the mount table of the kernel.
+func CompareMountSources(
+	ctx context.Context) (onlyInMtab, onlyInProc []Info, err error) {
+
+	return fs.CompareMountSources(ctx)
+}
+
 // GetDevMounts returns a slice of all mounts for the provided device.
 func GetDevMounts(ctx context.Context, dev string) ([]Info, error) {
 	return fs.GetDevMounts(ctx, dev)
 }
 
+// GetFuseMounts returns the mounted FUSE filesystems.
+func GetFuseMounts(ctx context.Context) ([]Info, error) {
+	return fs.GetFuseMounts(ctx)
+}
+
+// GetCIFSMounts returns the mounted CIFS/SMB filesystems.
+func GetCIFSMounts(ctx context.Context) ([]Info, error) {
+	return fs.GetCIFSMounts(ctx)
+}
+
+// MountFUSE mounts source to target by executing the FUSE helper
+// directly.
+func MountFUSE(
+	ctx context.Context,
+	helper, source, target string,
+	opts ...string) error {
+
+	return fs.MountFUSE(ctx, helper, source, target, opts...)
+}
+
+// MountCIFS mounts the CIFS/SMB share unc to target using a temporary
+// credentials file for the provided credentials.
+func MountCIFS(
+	ctx context.Context,
+	unc, target, username, password, domain string,
+	options ...string) error {
+
+	return fs.MountCIFS(
+		ctx, unc, target, username, password, domain, options...)
+}
+
+// GetDevMountsMulti returns the mounts of each of the provided devices
+// keyed by the device as provided.
+func GetDevMountsMulti(
+	ctx context.Context, devs []string) (map[string][]Info, error) {
+
+	return fs.GetDevMountsMulti(ctx, devs)
+}
+
+// GetDevMountsWithRoot returns a slice of all mounts for the provided
+// device with a root that is equal to or beneath the provided root, ex.
+// all mounts of a btrfs subvolume.
+func GetDevMountsWithRoot(
+	ctx context.Context, dev, root string) ([]Info, error) {
+
+	return fs.GetDevMountsWithRoot(ctx, dev, root)
+}
+
+// GetDeviceForPath returns the device and root of the mount that contains
+// the provided path.
+func GetDeviceForPath(
+	ctx context.Context, path string) (device, root string, err error) {
+
+	return fs.GetDeviceForPath(ctx, path)
+}
+
+// SameFilesystem returns a flag indicating whether pathA and pathB are on
+// the same filesystem.
+func SameFilesystem(ctx context.Context, pathA, pathB string) (bool, error) {
+	return fs.SameFilesystem(ctx, pathA, pathB)
+}
+
 // EvalSymlinks evaluates the provided path and updates it to remove
 // any symlinks in its structure, replacing them with the actual path
 // components.
@@ -102,3 +415,515 @@ func EvalSymlinks(ctx context.Context, symPath *string) error {
 func ValidateDevice(ctx context.Context, source string) (string, error) {
 	return fs.ValidateDevice(ctx, source)
 }
+
+// GetSELinuxMountContext returns the SELinux context of the filesystem
+// mounted at target, parsed from the "context" mount option. An empty
+// string is returned if the filesystem is mounted without a context.
+func GetSELinuxMountContext(ctx context.Context, target string) (string, error) {
+	return fs.GetSELinuxMountContext(ctx, target)
+}
+
+// MountWithContext behaves like Mount but injects a "context" option
+// with the provided SELinux context. The context is quoted so that
+// values containing commas are passed to mount(8) intact.
+func MountWithContext(
+	ctx context.Context,
+	source, target, fsType, seLinuxContext string,
+	opts ...string) error {
+
+	return fs.MountWithContext(
+		ctx, source, target, fsType, seLinuxContext, opts...)
+}
+
+// WaitForDevice polls for the existence of the provided device until it
+// appears or the context is cancelled. The device's path is returned with
+// all symlinks evaluated.
+func WaitForDevice(
+	ctx context.Context,
+	device string,
+	pollInterval time.Duration) (string, error) {
+
+	return fs.WaitForDevice(ctx, device, pollInterval)
+}
+
+// CreateSinglePartition creates a single partition that spans the entire
+// device and returns the path to the partition's device.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func CreateSinglePartition(ctx context.Context, device string) (string, error) {
+	return fs.CreateSinglePartition(ctx, device)
+}
+
+// GetPartitionTableType returns the type of the partition table of the
+// device or an empty string if the device does not have one.
+func GetPartitionTableType(ctx context.Context, device string) (string, error) {
+	return fs.GetPartitionTableType(ctx, device)
+}
+
+// EnsureTargetPath creates the target of a mount if it does not exist.
+// The target is created as an empty file if isBlock is true and as a
+// directory otherwise. An error is returned if target exists as the
+// wrong type.
+func EnsureTargetPath(target string, isBlock bool, mode os.FileMode) error {
+	return fs.EnsureTargetPath(target, isBlock, mode)
+}
+
+// GetDiskInfo returns information about the provided device that may be
+// used to decide whether or not it is safe to format the device.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func GetDiskInfo(ctx context.Context, device string) (DiskInfo, error) {
+	return fs.GetDiskInfo(ctx, device)
+}
+
+// CreateBtrfsSubvolume creates the subvolume name beneath the btrfs
+// filesystem mounted at mountedRoot.
+func CreateBtrfsSubvolume(ctx context.Context, mountedRoot, name string) error {
+	return fs.CreateBtrfsSubvolume(ctx, mountedRoot, name)
+}
+
+// GetBtrfsDevices returns the member devices of the btrfs filesystem
+// that contains anyMemberDevice.
+func GetBtrfsDevices(
+	ctx context.Context, anyMemberDevice string) ([]string, error) {
+
+	return fs.GetBtrfsDevices(ctx, anyMemberDevice)
+}
+
+// MountBtrfsSubvolume mounts the subvolume subvolName of the btrfs
+// filesystem on device to target.
+func MountBtrfsSubvolume(
+	ctx context.Context,
+	device, target, subvolName string,
+	opts ...string) error {
+
+	return fs.MountBtrfsSubvolume(ctx, device, target, subvolName, opts...)
+}
+
+// MountZFS mounts the ZFS dataset to target using "mount -t zfs".
+func MountZFS(ctx context.Context, dataset, target string, opts ...string) error {
+	return fs.MountZFS(ctx, dataset, target, opts...)
+}
+
+// GetZFSMounts returns a slice of the mounted ZFS datasets. ErrNotImplemented
+// is returned if the zfs command is not available.
+func GetZFSMounts(ctx context.Context) ([]Info, error) {
+	return fs.GetZFSMounts(ctx)
+}
+
+// ListFormattedUnmountedDevices returns the block devices that contain a
+// filesystem but are not mounted.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func ListFormattedUnmountedDevices(ctx context.Context) ([]DiskInfo, error) {
+	return fs.ListFormattedUnmountedDevices(ctx)
+}
+
+// GetParentDevice returns the path of the disk that contains the provided
+// device.
+func GetParentDevice(ctx context.Context, device string) (string, error) {
+	return fs.GetParentDevice(ctx, device)
+}
+
+// GetDeviceAttachTime returns the approximate time the block device was
+// attached.
+func GetDeviceAttachTime(
+	ctx context.Context, device string) (time.Time, error) {
+
+	return fs.GetDeviceAttachTime(ctx, device)
+}
+
+// CanonicalizeDevice returns the kernel name of the provided device, ex.
+// /dev/sda for any of its aliases.
+func CanonicalizeDevice(ctx context.Context, device string) (string, error) {
+	return fs.CanonicalizeDevice(ctx, device)
+}
+
+// SwapOn enables swapping on the provided device. No action is taken
+// if the device is already an active swap area.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func SwapOn(ctx context.Context, device string, opts ...string) error {
+	return fs.SwapOn(ctx, device, opts...)
+}
+
+// SwapOff disables swapping on the provided device. No action is taken
+// if the device is not an active swap area.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func SwapOff(ctx context.Context, device string) error {
+	return fs.SwapOff(ctx, device)
+}
+
+// MakeSwap sets up a swap area on the provided device.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func MakeSwap(ctx context.Context, device string) error {
+	return fs.MakeSwap(ctx, device)
+}
+
+// GetSwaps returns a slice of the active swap areas.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func GetSwaps(ctx context.Context) ([]SwapInfo, error) {
+	return fs.GetSwaps(ctx)
+}
+
+// SetReservedBlocksPercent sets the percentage of the ext filesystem's
+// blocks on the provided device that are reserved for the super-user.
+func SetReservedBlocksPercent(
+	ctx context.Context, device string, percent float64) error {
+
+	return fs.SetReservedBlocksPercent(ctx, device, percent)
+}
+
+// MountImage attaches the filesystem image at imagePath to a loop device
+// and mounts the loop device to target. The path of the loop device is
+// returned so that it may be detached with UnmountImage.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func MountImage(
+	ctx context.Context,
+	imagePath, target, fsType string,
+	opts ...string) (loopDevice string, err error) {
+
+	return fs.MountImage(ctx, imagePath, target, fsType, opts...)
+}
+
+// UnmountImage unmounts target and then detaches the loop device
+// returned by MountImage.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func UnmountImage(ctx context.Context, target, loopDevice string) error {
+	return fs.UnmountImage(ctx, target, loopDevice)
+}
+
+// CreateLoopFilesystem creates a sparse file of sizeBytes at imagePath,
+// attaches it to a loop device, and formats the loop device as fsType.
+func CreateLoopFilesystem(
+	ctx context.Context,
+	imagePath string,
+	sizeBytes uint64,
+	fsType string) (loopDevice string, err error) {
+
+	return fs.CreateLoopFilesystem(ctx, imagePath, sizeBytes, fsType)
+}
+
+// DeleteLoopFilesystem detaches the loop device returned by
+// CreateLoopFilesystem and removes its backing file, which must be
+// imagePath.
+func DeleteLoopFilesystem(
+	ctx context.Context, loopDevice, imagePath string) error {
+
+	return fs.DeleteLoopFilesystem(ctx, loopDevice, imagePath)
+}
+
+// ProbeVolume checks that the filesystem of type fsType on device is
+// usable by mounting it, writing and reading back a file, and unmounting
+// it.
+func ProbeVolume(ctx context.Context, device, fsType string) error {
+	return fs.ProbeVolume(ctx, device, fsType)
+}
+
+// GetLoopBackingFile returns the path of the file backing loopDevice.
+func GetLoopBackingFile(ctx context.Context, loopDevice string) (string, error) {
+	return fs.GetLoopBackingFile(ctx, loopDevice)
+}
+
+// ListLoopDevices returns the attached loop devices.
+func ListLoopDevices(ctx context.Context) ([]LoopInfo, error) {
+	return fs.ListLoopDevices(ctx)
+}
+
+// EnableProjectQuota enables project quotas on the filesystem on the
+// provided device.
+func EnableProjectQuota(ctx context.Context, device, fsType string) error {
+	return fs.EnableProjectQuota(ctx, device, fsType)
+}
+
+// IsStaleNFSMount returns a flag indicating whether the filesystem mounted
+// at target is stale.
+func IsStaleNFSMount(ctx context.Context, target string) (bool, error) {
+	return fs.IsStaleNFSMount(ctx, target)
+}
+
+// RecoverStaleMount attempts to recover the stale filesystem mounted at
+// target by unmounting it and mounting it again.
+func RecoverStaleMount(ctx context.Context, target string) error {
+	return fs.RecoverStaleMount(ctx, target)
+}
+
+// GetNFSMountStats returns the statistics of the NFS filesystem mounted
+// at target.
+func GetNFSMountStats(ctx context.Context, target string) (NFSStats, error) {
+	return fs.GetNFSMountStats(ctx, target)
+}
+
+// GetEffectiveMountFlags returns the effective flags of the mount at
+// target.
+func GetEffectiveMountFlags(
+	ctx context.Context, target string) ([]string, error) {
+
+	return fs.GetEffectiveMountFlags(ctx, target)
+}
+
+// GetMountFSType returns the type of the filesystem of the topmost mount
+// at target.
+func GetMountFSType(ctx context.Context, target string) (string, error) {
+	return fs.GetMountFSType(ctx, target)
+}
+
+// GetDeviceByUUID returns the path of the device that contains the
+// filesystem with the provided UUID.
+func GetDeviceByUUID(ctx context.Context, uuid string) (string, error) {
+	return fs.GetDeviceByUUID(ctx, uuid)
+}
+
+// MountByUUID mounts the device that contains the filesystem with the
+// expected UUID to target after verifying the UUID of the filesystem on
+// the device.
+func MountByUUID(
+	ctx context.Context,
+	expectedUUID, target, fsType string,
+	opts ...string) error {
+
+	return fs.MountByUUID(ctx, expectedUUID, target, fsType, opts...)
+}
+
+// GetDeviceByLabel returns the path of the device that contains the
+// filesystem with the provided label.
+func GetDeviceByLabel(ctx context.Context, label string) (string, error) {
+	return fs.GetDeviceByLabel(ctx, label)
+}
+
+// GetDevicePathByPath returns the path of the device to which the
+// /dev/disk/by-path link with the provided name points.
+func GetDevicePathByPath(
+	ctx context.Context, byPathSuffix string) (string, error) {
+
+	return fs.GetDevicePathByPath(ctx, byPathSuffix)
+}
+
+// SetFSLabel sets the label of the filesystem of type fsType on device.
+func SetFSLabel(ctx context.Context, device, fsType, label string) error {
+	return fs.SetFSLabel(ctx, device, fsType, label)
+}
+
+// GetBlockDeviceSize returns the size of the provided block device in
+// bytes.
+func GetBlockDeviceSize(ctx context.Context, device string) (uint64, error) {
+	return fs.GetBlockDeviceSize(ctx, device)
+}
+
+// CheckDeviceReady reports whether the provided device is ready to be
+// provisioned.
+func CheckDeviceReady(
+	ctx context.Context,
+	device string,
+	expectedSize uint64,
+	expectedFSType string) (DeviceReadyStatus, error) {
+
+	return fs.CheckDeviceReady(ctx, device, expectedSize, expectedFSType)
+}
+
+// DiscardDevice discards all of the blocks on the provided device.
+func DiscardDevice(ctx context.Context, device string) error {
+	return fs.DiscardDevice(ctx, device)
+}
+
+// TrimMount discards the unused blocks of the filesystem mounted at
+// target.
+func TrimMount(ctx context.Context, target string) error {
+	return fs.TrimMount(ctx, target)
+}
+
+// SupportsDiscard returns a flag indicating whether the provided device
+// supports discard.
+func SupportsDiscard(ctx context.Context, device string) (bool, error) {
+	return fs.SupportsDiscard(ctx, device)
+}
+
+// GetMountsSorted returns a slice of all the mounted filesystems sorted
+// by the depth of their mount points and then by path.
+func GetMountsSorted(ctx context.Context) ([]Info, error) {
+	return fs.GetMountsSorted(ctx)
+}
+
+// UnmountAndRemoveMapping unmounts target and then removes the
+// device-mapper mapping with the provided name.
+func UnmountAndRemoveMapping(ctx context.Context, target, mapName string) error {
+	return fs.UnmountAndRemoveMapping(ctx, target, mapName)
+}
+
+// GetFSUUID returns the UUID of the filesystem on the provided device.
+func GetFSUUID(ctx context.Context, device string) (string, error) {
+	return fs.GetFSUUID(ctx, device)
+}
+
+// UnmountAll unmounts each of the provided targets.
+func UnmountAll(ctx context.Context, targets []string) error {
+	return fs.UnmountAll(ctx, targets)
+}
+
+// UnmountRecursive unmounts every mount at or beneath root.
+func UnmountRecursive(ctx context.Context, root string) error {
+	return fs.UnmountRecursive(ctx, root)
+}
+
+// UnmountDevice unmounts every mount of device.
+func UnmountDevice(ctx context.Context, device string) error {
+	return fs.UnmountDevice(ctx, device)
+}
+
+// IsFSClean returns a flag indicating whether the filesystem of type
+// fsType on the provided device is clean.
+func IsFSClean(ctx context.Context, device, fsType string) (bool, error) {
+	return fs.IsFSClean(ctx, device, fsType)
+}
+
+// MountCSV behaves like Mount but accepts the options as a single
+// comma-separated string.
+func MountCSV(ctx context.Context, source, target, fsType, optsCSV string) error {
+	return fs.MountCSV(ctx, source, target, fsType, optsCSV)
+}
+
+// BindMountCSV behaves like BindMount but accepts the options as a single
+// comma-separated string.
+func BindMountCSV(ctx context.Context, source, target, optsCSV string) error {
+	return fs.BindMountCSV(ctx, source, target, optsCSV)
+}
+
+// IsDeviceMounted returns a flag indicating whether the provided device
+// has any mounts.
+func IsDeviceMounted(ctx context.Context, dev string) (bool, error) {
+	return fs.IsDeviceMounted(ctx, dev)
+}
+
+// GetMountAge returns the approximate amount of time the filesystem has
+// been mounted at target.
+func GetMountAge(ctx context.Context, target string) (time.Duration, error) {
+	return fs.GetMountAge(ctx, target)
+}
+
+// GetMountKind returns the kind of the mount at target.
+func GetMountKind(ctx context.Context, target string) (string, error) {
+	return fs.GetMountKind(ctx, target)
+}
+
+// IsMountPoint returns a flag indicating whether a filesystem is mounted
+// at target.
+func IsMountPoint(ctx context.Context, target string) (bool, error) {
+	return fs.IsMountPoint(ctx, target)
+}
+
+// WaitForUnmount polls the mount table until nothing is mounted at target
+// or the context is cancelled.
+func WaitForUnmount(
+	ctx context.Context,
+	target string,
+	pollInterval time.Duration) error {
+
+	return fs.WaitForUnmount(ctx, target, pollInterval)
+}
+
+// GetExt4Features returns the features enabled on the ext filesystem on
+// the provided device, ex. "64bit" or "metadata_csum".
+func GetExt4Features(ctx context.Context, device string) ([]string, error) {
+	return fs.GetExt4Features(ctx, device)
+}
+
+// GetDefaultMountOptions returns the default mount options stored in the
+// superblock of the ext filesystem on the provided device.
+func GetDefaultMountOptions(
+	ctx context.Context, device string) ([]string, error) {
+
+	return fs.GetDefaultMountOptions(ctx, device)
+}
+
+// SetDefaultMountOptions edits the default mount options stored in the
+// superblock of the ext filesystem on the provided device.
+func SetDefaultMountOptions(
+	ctx context.Context, device string, opts []string) error {
+
+	return fs.SetDefaultMountOptions(ctx, device, opts)
+}
+
+// GetFSLimits returns the block size and the maximum file and volume
+// sizes of the filesystem of type fsType on the provided device.
+func GetFSLimits(
+	ctx context.Context, device, fsType string) (FSLimits, error) {
+
+	return fs.GetFSLimits(ctx, device, fsType)
+}
+
+// GetFSBlockSize returns the logical block size in bytes of the
+// filesystem of type fsType on the provided device.
+func GetFSBlockSize(
+	ctx context.Context, device, fsType string) (uint32, error) {
+
+	return fs.GetFSBlockSize(ctx, device, fsType)
+}
+
+// FSSupportsFeature returns a flag indicating whether the named feature
+// is enabled on the filesystem of type fsType on the provided device.
+func FSSupportsFeature(
+	ctx context.Context, device, fsType, feature string) (bool, error) {
+
+	return fs.FSSupportsFeature(ctx, device, fsType, feature)
+}
+
+// ShrinkFS shrinks the filesystem of type fsType on the provided device to
+// newSizeBytes.
+func ShrinkFS(
+	ctx context.Context,
+	device, fsType string,
+	newSizeBytes uint64) error {
+
+	return fs.ShrinkFS(ctx, device, fsType, newSizeBytes)
+}
+
+// GetUsableCapacity returns the capacity in bytes of the filesystem
+// mounted at path that may be used by writers other than root.
+func GetUsableCapacity(
+	ctx context.Context, path string) (usableBytes uint64, err error) {
+
+	return fs.GetUsableCapacity(ctx, path)
+}
+
+// GetPathUsage returns the disk usage in bytes of the subtree rooted at
+// path without crossing into other filesystems.
+func GetPathUsage(ctx context.Context, path string) (bytes uint64, err error) {
+	return fs.GetPathUsage(ctx, path)
+}
+
+// MountIDMapped mounts source to target with the ownership of its files
+// remapped by the provided uid and gid mappings.
+func MountIDMapped(
+	ctx context.Context,
+	source, target, fsType string,
+	uidMap, gidMap []IDMapRange,
+	options ...string) error {
+
+	return fs.MountIDMapped(
+		ctx, source, target, fsType, uidMap, gidMap, options...)
+}
+
+// MountRaw mounts source to target as fsType with the provided mount(2)
+// flags and data.
+func MountRaw(
+	source, target, fsType string, flags uintptr, data string) error {
+
+	return fs.MountRaw(source, target, fsType, flags, data)
+}
+
+// SupportedFilesystems returns the filesystem types registered with the
+// kernel.
+func SupportedFilesystems(ctx context.Context) ([]string, error) {
+	return fs.SupportedFilesystems(ctx)
+}
+
+// IsFSTypeSupported returns a flag indicating whether the kernel is able
+// to mount a filesystem of type fsType.
+func IsFSTypeSupported(ctx context.Context, fsType string) (bool, error) {
+	return fs.IsFSTypeSupported(ctx, fsType)
+}
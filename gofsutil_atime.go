@@ -0,0 +1,48 @@
+package gofsutil
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	// AtimePolicyRelatime updates the access time of a file only if it
+	// is earlier than the file's modify or change time, or if it is
+	// more than a day old.
+	AtimePolicyRelatime = "relatime"
+
+	// AtimePolicyNoatime never updates the access time of a file.
+	AtimePolicyNoatime = "noatime"
+
+	// AtimePolicyStrictatime updates the access time of a file on every
+	// access.
+	AtimePolicyStrictatime = "strictatime"
+)
+
+// atimePolicies are the atime policies accepted by SetAtimePolicy.
+var atimePolicies = []string{
+	AtimePolicyRelatime,
+	AtimePolicyNoatime,
+	AtimePolicyStrictatime,
+}
+
+// validateAtimePolicy returns an error if policy is not one of the
+// atime policies
+func validateAtimePolicy(policy string) error {
+	for _, p := range atimePolicies {
+		if policy == p {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid atime policy: %q", policy)
+}
+
+// setAtimePolicy remounts the mount at target with the atime policy
+func (fs *FS) setAtimePolicy(
+	ctx context.Context, target, policy string) error {
+
+	if err := validateAtimePolicy(policy); err != nil {
+		return err
+	}
+	return fs.remountWithOption(ctx, target, policy)
+}
@@ -0,0 +1,64 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+func TestSetAtimePolicy(t *testing.T) {
+	ctx := gofsutil.WithMountTable(context.TODO(), []gofsutil.Info{
+		{
+			Device: "/dev/sdb",
+			Path:   "/mnt/data",
+			Type:   "ext4",
+			Opts:   []string{"rw", "nosuid", "nodev", "relatime"},
+		},
+		{
+			Device: "/dev/sdc",
+			Path:   "/mnt/logs",
+			Type:   "xfs",
+			Opts:   []string{"ro", "noatime", "nodiratime"},
+		},
+	})
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	if err := fs.SetAtimePolicy(
+		ctx, "/mnt/data", gofsutil.AtimePolicyNoatime); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.SetAtimePolicy(
+		ctx, "/mnt/logs", gofsutil.AtimePolicyStrictatime); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t,
+		"mount -o remount,rw,nosuid,nodev,noatime /mnt/data",
+		"mount -o remount,ro,nodiratime,strictatime /mnt/logs")
+}
+
+func TestSetAtimePolicyErrors(t *testing.T) {
+	ctx := gofsutil.WithMountTable(context.TODO(), []gofsutil.Info{
+		{
+			Device: "/dev/sdb",
+			Path:   "/mnt/data",
+			Type:   "ext4",
+			Opts:   []string{"rw", "relatime"},
+		},
+	})
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	for _, policy := range []string{"", "atime", "lazytime", "noatime,ro"} {
+		if err := fs.SetAtimePolicy(ctx, "/mnt/data", policy); err == nil {
+			t.Errorf("expected error for policy %q", policy)
+		}
+	}
+	err := fs.SetAtimePolicy(ctx, "/mnt/other", gofsutil.AtimePolicyNoatime)
+	if !errors.Is(err, gofsutil.ErrNotMounted) {
+		t.Errorf("expected ErrNotMounted, got %v", err)
+	}
+	r.assertCommands(t)
+}
@@ -0,0 +1,13 @@
+package gofsutil
+
+import (
+	"context"
+	"time"
+)
+
+// getDeviceAttachTime returns the change time of the device node
+func (fs *FS) getDeviceAttachTime(
+	ctx context.Context, device string) (time.Time, error) {
+
+	return time.Time{}, ErrNotImplemented
+}
@@ -0,0 +1,30 @@
+package gofsutil
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// getDeviceAttachTime returns the change time of the device node
+func (fs *FS) getDeviceAttachTime(
+	ctx context.Context, device string) (time.Time, error) {
+
+	if err := EvalSymlinks(ctx, &device); err != nil {
+		if os.IsNotExist(err) {
+			return time.Time{}, fmt.Errorf("%s: %w", device, ErrDeviceNotFound)
+		}
+		return time.Time{}, err
+	}
+	var st unix.Stat_t
+	if err := unix.Stat(device, &st); err != nil {
+		return time.Time{}, fmt.Errorf("stat %s: %w", device, err)
+	}
+	if st.Mode&unix.S_IFMT != unix.S_IFBLK {
+		return time.Time{}, fmt.Errorf("invalid device: %s", device)
+	}
+	return time.Unix(st.Ctim.Unix()), nil
+}
@@ -0,0 +1,68 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+func TestGetDeviceAttachTime(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("requires root")
+	}
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The node's change time is set when it is created.
+	var (
+		blk    = path.Join(dir, "blk")
+		link   = path.Join(dir, "link")
+		before = time.Now().Add(-time.Second)
+	)
+	if err := syscall.Mknod(
+		blk, syscall.S_IFBLK|0600, int(7<<8|0)); err != nil {
+		t.Fatal(err)
+	}
+	after := time.Now().Add(time.Second)
+	if err := os.Symlink(blk, link); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, dev := range []string{blk, link} {
+		act, err := gofsutil.GetDeviceAttachTime(context.TODO(), dev)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if act.Before(before) || act.After(after) {
+			t.Errorf("%s: invalid attach time: exp=%s..%s, act=%s",
+				dev, before, after, act)
+		}
+	}
+}
+
+func TestGetDeviceAttachTimeErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx := context.TODO()
+	_, err = gofsutil.GetDeviceAttachTime(ctx, path.Join(dir, "missing"))
+	if !errors.Is(err, gofsutil.ErrDeviceNotFound) {
+		t.Errorf("expected ErrDeviceNotFound: %v", err)
+	}
+	if _, err := gofsutil.GetDeviceAttachTime(ctx, dir); err == nil {
+		t.Error("expected error for directory")
+	}
+}
@@ -0,0 +1,92 @@
+package gofsutil
+
+import (
+	"context"
+	"fmt"
+)
+
+// MountBackend selects how an FS mounts filesystems.
+type MountBackend string
+
+const (
+	// MountBackendExec mounts filesystems with the mount command, which
+	// runs the mount helper of the filesystem type, if any, ex. mount.nfs.
+	// This is the default backend. Mounting requires privileges unless
+	// the mount is permitted to users by fstab.
+	MountBackendExec MountBackend = "exec"
+
+	// MountBackendSyscall mounts filesystems with the mount(2) system
+	// call. The options are translated to mount flags, ex. "ro" to
+	// MS_RDONLY, and the remaining options are passed to the kernel as
+	// data. The options interpreted by userspace, ex. "_netdev", are
+	// dropped, and the mount helpers are not run, so filesystems that
+	// require one, ex. nfs and cifs, cannot be mounted with this
+	// backend. Nsenter does not apply to mounts made with this backend.
+	// Mounting requires CAP_SYS_ADMIN in the mount namespace's owning
+	// user namespace. Darwin hosts do not support this backend and
+	// return ErrNotImplemented.
+	MountBackendSyscall MountBackend = "syscall"
+
+	// MountBackendFusermount mounts filesystems with a FUSE helper, ex.
+	// fuse-overlayfs for an overlay filesystem, which mounts through the
+	// setuid fusermount program and so works without privileges, ex. in
+	// a rootless container. The helper of a filesystem type is read from
+	// FS.FUSEHelpers, and an error is returned for a type without a
+	// helper. The helper is run as "<helper> [-o <options>] [<source>]
+	// <target>", where the source is omitted if it is empty or the name
+	// of the filesystem type, ex. "overlay". The filesystem appears in
+	// the mount table with the type "fuse.<helper>" and is unmounted with
+	// fusermount. Bind mounts are not supported by this backend.
+	MountBackendFusermount MountBackend = "fusermount"
+)
+
+// defaultFUSEHelpers are the FUSE helpers used by MountBackendFusermount
+// for the filesystem types not in FS.FUSEHelpers.
+var defaultFUSEHelpers = map[string]string{
+	"overlay": "fuse-overlayfs",
+}
+
+// doMountBackend mounts source to target with the FS's mount backend.
+// The returned flag is false if the FS uses the exec backend, in which
+// case the caller runs the mount command.
+func (fs *FS) doMountBackend(
+	ctx context.Context,
+	source, target, fsType string,
+	opts ...string) (bool, error) {
+
+	switch fs.MountBackend {
+	case "", MountBackendExec:
+		return false, nil
+	case MountBackendSyscall:
+		return true, fs.doMountSyscall(source, target, fsType, opts...)
+	case MountBackendFusermount:
+		return true, fs.doMountFUSE(ctx, source, target, fsType, opts...)
+	}
+	return true, fmt.Errorf("invalid mount backend: %q", fs.MountBackend)
+}
+
+// doMountFUSE mounts source to target with the FUSE helper of fsType
+func (fs *FS) doMountFUSE(
+	ctx context.Context,
+	source, target, fsType string,
+	opts ...string) error {
+
+	if _, bind := fs.isBind(ctx, opts...); bind {
+		return fmt.Errorf(
+			"bind mounts not supported by mount backend %q",
+			MountBackendFusermount)
+	}
+	helper, ok := fs.FUSEHelpers[fsType]
+	if !ok {
+		helper, ok = defaultFUSEHelpers[fsType]
+	}
+	if !ok {
+		return fmt.Errorf(
+			"no fuse helper for mount backend %q: fsType=%s",
+			MountBackendFusermount, fsType)
+	}
+	if source == fsType {
+		source = ""
+	}
+	return fs.doMountCmd(ctx, helper, source, target, "", opts...)
+}
@@ -0,0 +1,8 @@
+package gofsutil
+
+// doMountSyscall mounts source to target with the mount(2) system call
+func (fs *FS) doMountSyscall(
+	source, target, fsType string, opts ...string) error {
+
+	return ErrNotImplemented
+}
@@ -0,0 +1,67 @@
+package gofsutil
+
+import (
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// mountOptionFlags maps the mount options that are mount flags to the
+// flags. An option whose flag is zero clears the flags of its opposite,
+// ex. "rw" clears MS_RDONLY.
+var mountOptionFlags = map[string]struct {
+	set   uintptr
+	clear uintptr
+}{
+	"defaults":    {},
+	"ro":          {set: unix.MS_RDONLY},
+	"rw":          {clear: unix.MS_RDONLY},
+	"nosuid":      {set: unix.MS_NOSUID},
+	"suid":        {clear: unix.MS_NOSUID},
+	"nodev":       {set: unix.MS_NODEV},
+	"dev":         {clear: unix.MS_NODEV},
+	"noexec":      {set: unix.MS_NOEXEC},
+	"exec":        {clear: unix.MS_NOEXEC},
+	"sync":        {set: unix.MS_SYNCHRONOUS},
+	"async":       {clear: unix.MS_SYNCHRONOUS},
+	"dirsync":     {set: unix.MS_DIRSYNC},
+	"noatime":     {set: unix.MS_NOATIME},
+	"atime":       {clear: unix.MS_NOATIME},
+	"nodiratime":  {set: unix.MS_NODIRATIME},
+	"diratime":    {clear: unix.MS_NODIRATIME},
+	"relatime":    {set: unix.MS_RELATIME},
+	"norelatime":  {clear: unix.MS_RELATIME},
+	"strictatime": {set: unix.MS_STRICTATIME},
+	"remount":     {set: unix.MS_REMOUNT},
+	"bind":        {set: unix.MS_BIND},
+	"rbind":       {set: unix.MS_BIND | unix.MS_REC},
+	"silent":      {set: unix.MS_SILENT},
+	"loud":        {clear: unix.MS_SILENT},
+}
+
+// makeMountFlags returns the mount flags of opts and the remaining
+// options joined as the data of a mount(2) system call
+func makeMountFlags(opts []string) (uintptr, string) {
+	var (
+		flags uintptr
+		data  []string
+	)
+	for _, o := range RemoveDuplicates(opts) {
+		if f, ok := mountOptionFlags[o]; ok {
+			flags = (flags &^ f.clear) | f.set
+			continue
+		}
+		if o != "" {
+			data = append(data, o)
+		}
+	}
+	return flags, strings.Join(data, ",")
+}
+
+// doMountSyscall mounts source to target with the mount(2) system call
+func (fs *FS) doMountSyscall(
+	source, target, fsType string, opts ...string) error {
+
+	flags, data := makeMountFlags(opts)
+	return fs.mountRaw(source, target, fsType, flags, data)
+}
@@ -0,0 +1,70 @@
+package gofsutil_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+func TestMountBackendSyscall(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("requires root")
+	}
+	target, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(target)
+	if err := gofsutil.EvalSymlinks(context.TODO(), &target); err != nil {
+		t.Fatal(err)
+	}
+
+	// The mount is made without running any command.
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{
+		RunCommand:   r.run,
+		MountBackend: gofsutil.MountBackendSyscall,
+	}
+	if err := fs.Mount(
+		context.TODO(), "gofsutil", target, "tmpfs",
+		"noexec", "nosuid", "size=1m", "_netdev"); err != nil {
+		t.Fatal(err)
+	}
+	defer unix.Unmount(target, 0)
+	r.assertCommands(t)
+
+	f, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	mounts, err := gofsutil.ParseMountInfo(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var info *gofsutil.Info
+	for i := range mounts {
+		if mounts[i].Path == target {
+			info = &mounts[i]
+		}
+	}
+	if info == nil {
+		t.Fatalf("%s not mounted", target)
+	}
+	if info.Type != "tmpfs" {
+		t.Errorf("invalid fsType: exp=tmpfs, act=%s", info.Type)
+	}
+	for _, opt := range []string{"noexec", "nosuid"} {
+		if !hasOpt(info.Opts, opt) {
+			t.Errorf("missing option %s: %v", opt, info.Opts)
+		}
+	}
+	if !hasOpt(info.SuperOpts, "size=1024k") {
+		t.Errorf("missing super option size=1024k: %v", info.SuperOpts)
+	}
+}
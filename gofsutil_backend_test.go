@@ -0,0 +1,62 @@
+package gofsutil_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+func TestMountBackendFusermount(t *testing.T) {
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{
+		RunCommand:   r.run,
+		MountBackend: gofsutil.MountBackendFusermount,
+		FUSEHelpers:  map[string]string{"sshfs": "sshfs"},
+	}
+	ctx := context.TODO()
+
+	if err := fs.Mount(
+		ctx, "overlay", "/mnt/merged", "overlay",
+		"lowerdir=/lower,upperdir=/upper,workdir=/work"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Mount(
+		ctx, "user@host:/data", "/mnt/sshfs", "sshfs", "ro"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Mount(ctx, "/dev/sdb", "/mnt/data", "ext4"); err == nil {
+		t.Error("expected error for fsType without fuse helper")
+	}
+	if err := fs.BindMount(ctx, "/src", "/mnt/bind"); err == nil {
+		t.Error("expected error for bind mount")
+	}
+	r.assertCommands(t,
+		"fuse-overlayfs -o lowerdir=/lower,upperdir=/upper,workdir=/work "+
+			"/mnt/merged",
+		"sshfs -o ro user@host:/data /mnt/sshfs")
+}
+
+func TestMountBackendExec(t *testing.T) {
+	for _, backend := range []gofsutil.MountBackend{
+		"", gofsutil.MountBackendExec,
+	} {
+		r := &testCommandRunner{}
+		fs := &gofsutil.FS{RunCommand: r.run, MountBackend: backend}
+		if err := fs.Mount(
+			context.TODO(), "/dev/sdb", "/mnt/data", "ext4"); err != nil {
+			t.Fatalf("%q: %v", backend, err)
+		}
+		r.assertCommands(t, "mount -t ext4 /dev/sdb /mnt/data")
+	}
+}
+
+func TestMountBackendInvalid(t *testing.T) {
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{RunCommand: r.run, MountBackend: "invalid"}
+	if err := fs.Mount(
+		context.TODO(), "/dev/sdb", "/mnt/data", "ext4"); err == nil {
+		t.Fatal("expected error for invalid backend")
+	}
+	r.assertCommands(t)
+}
@@ -0,0 +1,85 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+// newTestBindFileDir creates a temporary directory that contains the
+// file "config" and the directory "dir".
+func newTestBindFileDir(t *testing.T) string {
+	dir := t.TempDir()
+	if err := gofsutil.EvalSymlinks(context.TODO(), &dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(
+		path.Join(dir, "config"), []byte("key=value\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(path.Join(dir, "dir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestBindMountFile(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("requires root")
+	}
+	dir := newTestBindFileDir(t)
+
+	src := path.Join(dir, "config")
+	existing := path.Join(dir, "dir", "existing.conf")
+	if err := ioutil.WriteFile(existing, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, tgt := range []string{
+		existing,
+		// A file that is created along with its parent.
+		path.Join(dir, "etc", "app", "app.conf"),
+	} {
+		if err := gofsutil.BindMountFile(context.TODO(), src, tgt); err != nil {
+			t.Fatal(err)
+		}
+		buf, err := ioutil.ReadFile(tgt)
+		gofsutil.Unmount(context.TODO(), tgt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(buf) != "key=value\n" {
+			t.Errorf("%s: invalid contents: %q", tgt, buf)
+		}
+	}
+}
+
+func TestBindMountTypeMismatch(t *testing.T) {
+	dir := newTestBindFileDir(t)
+
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{RunCommand: r.run}
+	ctx := gofsutil.WithMountTable(context.TODO(), nil)
+	file, subdir := path.Join(dir, "config"), path.Join(dir, "dir")
+
+	err := fs.BindMount(ctx, file, subdir)
+	if !errors.Is(err, gofsutil.ErrBindTypeMismatch) {
+		t.Errorf("file to directory: expected ErrBindTypeMismatch: %v", err)
+	}
+	err = fs.BindMount(ctx, subdir, file)
+	if !errors.Is(err, gofsutil.ErrBindTypeMismatch) {
+		t.Errorf("directory to file: expected ErrBindTypeMismatch: %v", err)
+	}
+	err = fs.BindMountFile(ctx, file, subdir)
+	if !errors.Is(err, gofsutil.ErrBindTypeMismatch) {
+		t.Errorf("file to directory: expected ErrBindTypeMismatch: %v", err)
+	}
+	if err := fs.BindMountFile(ctx, subdir, file); err == nil {
+		t.Error("expected error for directory source")
+	}
+	r.assertCommands(t)
+}
@@ -0,0 +1,63 @@
+package gofsutil
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// validateBtrfsSubvolumeName returns an error if name is empty, absolute,
+// or escapes the parent of the subvolume.
+func validateBtrfsSubvolumeName(name string) error {
+	clean := path.Clean(name)
+	if name == "" || clean == "." || path.IsAbs(name) ||
+		clean == ".." || strings.HasPrefix(clean, "../") {
+		return fmt.Errorf("invalid btrfs subvolume name: %q", name)
+	}
+	return nil
+}
+
+// createBtrfsSubvolume creates the subvolume name beneath the btrfs
+// filesystem mounted at mountedRoot
+func (fs *FS) createBtrfsSubvolume(
+	ctx context.Context, mountedRoot, name string) error {
+
+	if err := validateBtrfsSubvolumeName(name); err != nil {
+		return err
+	}
+
+	subvol := path.Join(mountedRoot, name)
+	f := log.Fields{
+		"mountedRoot": mountedRoot,
+		"subvolume":   subvol,
+	}
+	log.WithFields(f).Info("creating btrfs subvolume")
+
+	buf, err := fs.exec(ctx, "btrfs", "subvolume", "create", subvol)
+	if err != nil {
+		out := string(buf)
+		log.WithFields(f).WithField("output", out).WithError(
+			err).Error("btrfs subvolume create failed")
+		return fmt.Errorf(
+			"btrfs subvolume create failed: %v\nsubvolume: %s\noutput: %s",
+			err, subvol, out)
+	}
+	return nil
+}
+
+// mountBtrfsSubvolume mounts the subvolume subvolName of the btrfs
+// filesystem on device to target
+func (fs *FS) mountBtrfsSubvolume(
+	ctx context.Context,
+	device, target, subvolName string,
+	opts ...string) error {
+
+	if err := validateBtrfsSubvolumeName(subvolName); err != nil {
+		return err
+	}
+	opts = append(opts, "subvol="+path.Clean(subvolName))
+	return fs.mount(ctx, device, target, "btrfs", opts...)
+}
@@ -0,0 +1,11 @@
+package gofsutil
+
+import "context"
+
+// getBtrfsDevices returns the member devices of the btrfs filesystem that
+// contains anyMemberDevice
+func (fs *FS) getBtrfsDevices(
+	ctx context.Context, anyMemberDevice string) ([]string, error) {
+
+	return nil, ErrNotImplemented
+}
@@ -0,0 +1,88 @@
+package gofsutil
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// btrfsShowErrors maps the output of a failed 'btrfs filesystem show'
+// command to the error that describes the failure.
+var btrfsShowErrors = []cmdError{
+	{regexp.MustCompile(`(?i)no such file or directory`),
+		ErrDeviceNotFound},
+}
+
+// getBtrfsDevices returns the member devices of the btrfs filesystem that
+// contains anyMemberDevice
+func (fs *FS) getBtrfsDevices(
+	ctx context.Context, anyMemberDevice string) ([]string, error) {
+
+	f := log.Fields{
+		"device": anyMemberDevice,
+	}
+	log.WithFields(f).Info("listing btrfs member devices")
+
+	buf, err := fs.exec(
+		ctx, "btrfs", "filesystem", "show", anyMemberDevice)
+	out := string(buf)
+	if err != nil {
+		log.WithFields(f).WithField("output", out).WithError(
+			err).Error("btrfs filesystem show failed")
+		return nil, fmt.Errorf(
+			"btrfs filesystem show failed: %w\ndevice: %s\noutput: %s",
+			wrapCmdError(err, out, btrfsShowErrors), anyMemberDevice, out)
+	}
+
+	devices := parseBtrfsShowDevices(buf)
+	if len(devices) == 0 {
+		return nil, fmt.Errorf(
+			"btrfs filesystem show: no devices found\ndevice: %s\noutput: %s",
+			anyMemberDevice, out)
+	}
+	return devices, nil
+}
+
+// parseBtrfsShowDevices returns the device paths from the "devid" lines
+// of the output of 'btrfs filesystem show', ex.
+//
+//	devid    1 size 10.00GiB used 2.02GiB path /dev/sdb
+//
+// Missing devices, whose lines do not have a path, are omitted.
+func parseBtrfsShowDevices(buf []byte) []string {
+	var devices []string
+	scan := bufio.NewScanner(bytes.NewReader(buf))
+	for scan.Scan() {
+		fields := strings.Fields(scan.Text())
+		if len(fields) == 0 || fields[0] != "devid" {
+			continue
+		}
+		for i := 1; i < len(fields)-1; i++ {
+			if fields[i] == "path" {
+				devices = append(devices, fields[i+1])
+				break
+			}
+		}
+	}
+	return devices
+}
+
+// isBtrfsMemberDevice returns a flag indicating whether the kernel has
+// registered device as a member of a mounted btrfs filesystem. The
+// kernel lists the members of each mounted btrfs filesystem beneath
+// /sys/fs/btrfs/<fsid>/devices, which also includes the members that
+// lsblk and blkid have not yet probed, ex. a device added to the
+// filesystem with 'btrfs device add'.
+func (fs *FS) isBtrfsMemberDevice(device string) bool {
+	name := path.Base(evalSymlinksOrPath(device))
+	matches, err := filepath.Glob(
+		fs.sysPath("fs", "btrfs", "*", "devices", name))
+	return err == nil && len(matches) > 0
+}
@@ -0,0 +1,125 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+// btrfsShowData is the output of 'btrfs filesystem show /dev/sdc' for a
+// two-device pool.
+const btrfsShowData = `Label: 'pool'  uuid: 5b1b2ccc-1f4e-4a43-9d5a-1f0c2b8f6e7a
+	Total devices 2 FS bytes used 144.00KiB
+	devid    1 size 10.00GiB used 2.01GiB path /dev/sdb
+	devid    2 size 10.00GiB used 2.01GiB path /dev/sdc
+
+`
+
+// btrfsShowMissingData is the output of 'btrfs filesystem show /dev/sdb'
+// for a two-device pool with a missing member.
+const btrfsShowMissingData = `Label: 'pool'  uuid: 5b1b2ccc-1f4e-4a43-9d5a-1f0c2b8f6e7a
+	Total devices 2 FS bytes used 144.00KiB
+	devid    1 size 10.00GiB used 2.01GiB path /dev/sdb
+	*** Some devices missing
+
+`
+
+func TestGetBtrfsDevices(t *testing.T) {
+	r := &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			return btrfsShowData, nil
+		},
+	}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	devices, err := fs.GetBtrfsDevices(context.TODO(), "/dev/sdc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []string{"/dev/sdb", "/dev/sdc"}
+	if !reflect.DeepEqual(devices, exp) {
+		t.Errorf("invalid devices: exp=%v, act=%v", exp, devices)
+	}
+	r.assertCommands(t, "btrfs filesystem show /dev/sdc")
+}
+
+func TestGetBtrfsDevicesMissing(t *testing.T) {
+	r := &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			return btrfsShowMissingData, nil
+		},
+	}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	devices, err := fs.GetBtrfsDevices(context.TODO(), "/dev/sdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []string{"/dev/sdb"}
+	if !reflect.DeepEqual(devices, exp) {
+		t.Errorf("invalid devices: exp=%v, act=%v", exp, devices)
+	}
+}
+
+func TestGetBtrfsDevicesErrors(t *testing.T) {
+	r := &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			return "ERROR: not a valid btrfs filesystem: /dev/sdb\n",
+				errors.New("exit status 1")
+		},
+	}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	if _, err := fs.GetBtrfsDevices(context.TODO(), "/dev/sdb"); err == nil {
+		t.Error("expected error for device that is not btrfs")
+	}
+	if _, err := fs.GetBtrfsDevices(context.TODO(), "sdb"); err == nil {
+		t.Error("expected error for relative device")
+	}
+	r.assertCommands(t, "btrfs filesystem show /dev/sdb")
+}
+
+func TestGetDiskFormatBtrfsMember(t *testing.T) {
+	sysRoot, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(sysRoot)
+	devDir := path.Join(sysRoot, "fs", "btrfs",
+		"5b1b2ccc-1f4e-4a43-9d5a-1f0c2b8f6e7a", "devices")
+	if err := os.MkdirAll(devDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"sdb", "sdc"} {
+		if err := ioutil.WriteFile(
+			path.Join(devDir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// lsblk reports neither the secondary member nor the unrelated
+	// device as formatted.
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{SysRoot: sysRoot, RunCommand: r.run}
+
+	fsType, err := fs.GetDiskFormat(context.TODO(), "/dev/sdc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fsType != "btrfs" {
+		t.Errorf("invalid fsType: exp=btrfs, act=%q", fsType)
+	}
+	fsType, err = fs.GetDiskFormat(context.TODO(), "/dev/sdd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fsType != "" {
+		t.Errorf("invalid fsType: exp=\"\", act=%q", fsType)
+	}
+}
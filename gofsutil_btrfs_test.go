@@ -0,0 +1,42 @@
+package gofsutil_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+func TestCreateBtrfsSubvolume(t *testing.T) {
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	if err := fs.CreateBtrfsSubvolume(
+		context.TODO(), "/mnt/pool", "vol1"); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"", ".", "..", "../vol1", "/vol1"} {
+		if err := fs.CreateBtrfsSubvolume(
+			context.TODO(), "/mnt/pool", name); err == nil {
+			t.Errorf("expected error for subvolume name %q", name)
+		}
+	}
+	r.assertCommands(t, "btrfs subvolume create /mnt/pool/vol1")
+}
+
+func TestMountBtrfsSubvolume(t *testing.T) {
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	if err := fs.MountBtrfsSubvolume(
+		context.TODO(), "/dev/sdb", "/mnt/vol1", "vol1",
+		"rw", "noatime"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.MountBtrfsSubvolume(
+		context.TODO(), "/dev/sdb", "/mnt/vol1", "a/../../b"); err == nil {
+		t.Error("expected error for escaping subvolume name")
+	}
+	r.assertCommands(t,
+		"mount -t btrfs -o rw,noatime,subvol=vol1 /dev/sdb /mnt/vol1")
+}
@@ -0,0 +1,36 @@
+package gofsutil
+
+import (
+	"context"
+	"fmt"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// getUsableCapacity returns the capacity of the filesystem mounted at
+// path less the blocks reserved for root
+func (fs *FS) getUsableCapacity(
+	ctx context.Context, path string) (uint64, error) {
+
+	var buf syscall.Statfs_t
+	if err := syscall.Statfs(path, &buf); err != nil {
+		return 0, fmt.Errorf("statfs: %s: %v", path, err)
+	}
+
+	// The blocks that are free but not available are reserved for root.
+	var reserved uint64
+	if buf.Bfree > buf.Bavail {
+		reserved = buf.Bfree - buf.Bavail
+	}
+	bsize := uint64(buf.Bsize)
+	usable := (buf.Blocks - reserved) * bsize
+
+	log.WithFields(log.Fields{
+		"path":     path,
+		"total":    buf.Blocks * bsize,
+		"reserved": reserved * bsize,
+		"usable":   usable,
+	}).Debug("got usable capacity")
+	return usable, nil
+}
@@ -0,0 +1,101 @@
+package gofsutil_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"syscall"
+	"testing"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+// statfsTotal returns the total size in bytes of the filesystem mounted
+// at path.
+func statfsTotal(t *testing.T, path string) uint64 {
+	var buf syscall.Statfs_t
+	if err := syscall.Statfs(path, &buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Blocks * uint64(buf.Bsize)
+}
+
+func TestGetUsableCapacityTmpfs(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("requires root")
+	}
+	target, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(target)
+	if err := unix.Mount("tmpfs", target, "tmpfs", 0, "size=4m"); err != nil {
+		t.Skipf("mount tmpfs: %v", err)
+	}
+	defer unix.Unmount(target, 0)
+
+	usable, err := gofsutil.GetUsableCapacity(context.TODO(), target)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// tmpfs does not reserve blocks for root.
+	if total := statfsTotal(t, target); usable != total {
+		t.Errorf("usable=%d, total=%d: expected equal", usable, total)
+	}
+}
+
+func TestGetUsableCapacityExt4(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("requires root")
+	}
+	if _, err := exec.LookPath("mkfs.ext4"); err != nil {
+		t.Skip(err)
+	}
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	img, target := path.Join(dir, "disk.img"), path.Join(dir, "mnt")
+	if err := os.Mkdir(target, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(img, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(img, 32<<20); err != nil {
+		t.Fatal(err)
+	}
+	if out, err := exec.Command(
+		"mkfs.ext4", "-q", "-F", "-m", "10", img).CombinedOutput(); err != nil {
+		t.Fatalf("mkfs.ext4: %v: %s", err, out)
+	}
+
+	ctx := context.TODO()
+	loopDevice, err := gofsutil.MountImage(ctx, img, target, "ext4")
+	if err != nil {
+		t.Skipf("mount image: %v", err)
+	}
+	defer gofsutil.UnmountImage(ctx, target, loopDevice)
+
+	usable, err := gofsutil.GetUsableCapacity(ctx, target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := statfsTotal(t, target)
+	if usable >= total {
+		t.Errorf("usable=%d, total=%d: expected usable < total", usable, total)
+	}
+
+	// Roughly 10% of the blocks are reserved.
+	if reserved := total - usable; reserved < total/20 {
+		t.Errorf("reserved=%d, total=%d: expected ~10%% reserved",
+			reserved, total)
+	}
+}
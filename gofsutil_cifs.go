@@ -0,0 +1,62 @@
+package gofsutil
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// cifsFSTypes are the filesystem types of CIFS/SMB mounts. Linux mounts
+// SMB shares as "cifs" or "smb3" and Darwin as "smbfs".
+var cifsFSTypes = map[string]struct{}{
+	"cifs":  {},
+	"smb3":  {},
+	"smbfs": {},
+}
+
+// getCIFSMounts returns the mounted CIFS/SMB filesystems
+func (fs *FS) getCIFSMounts(ctx context.Context) ([]Info, error) {
+	mounts, err := fs.getMounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var cifsMounts []Info
+	for _, m := range mounts {
+		if _, ok := cifsFSTypes[m.Type]; ok {
+			cifsMounts = append(cifsMounts, m)
+		}
+	}
+	return cifsMounts, nil
+}
+
+// makeCIFSSource returns the "//server/share" source of a CIFS mount
+// from unc, which may use backslashes, ex. `\\server\share\dir`, or omit
+// the leading slashes, ex. "server/share".
+func makeCIFSSource(unc string) (string, error) {
+	p := strings.TrimLeft(strings.Replace(unc, `\`, "/", -1), "/")
+	parts := strings.SplitN(p, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || strings.Trim(parts[1], "/") == "" {
+		return "", fmt.Errorf("invalid unc: %q", unc)
+	}
+	return "//" + strings.TrimRight(p, "/"), nil
+}
+
+// makeCIFSCredentials returns the contents of the credentials file read
+// by mount.cifs. The values may not contain newlines, which would allow
+// a value to inject an additional key into the file.
+func makeCIFSCredentials(username, password, domain string) (string, error) {
+	var b strings.Builder
+	for _, kv := range [][2]string{
+		{"username", username},
+		{"password", password},
+		{"domain", domain},
+	} {
+		if strings.ContainsAny(kv[1], "\r\n\x00") {
+			return "", fmt.Errorf("invalid cifs %s: contains newline", kv[0])
+		}
+		if kv[1] != "" {
+			fmt.Fprintf(&b, "%s=%s\n", kv[0], kv[1])
+		}
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,13 @@
+package gofsutil
+
+import "context"
+
+// mountCIFS mounts the share unc to target with the provided
+// credentials
+func (fs *FS) mountCIFS(
+	ctx context.Context,
+	unc, target, username, password, domain string,
+	opts ...string) error {
+
+	return ErrNotImplemented
+}
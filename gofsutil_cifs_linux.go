@@ -0,0 +1,67 @@
+package gofsutil
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// mountCIFS mounts the share unc to target with the provided
+// credentials, which are written to a temporary credentials file that is
+// removed once the mount command exits
+func (fs *FS) mountCIFS(
+	ctx context.Context,
+	unc, target, username, password, domain string,
+	opts ...string) error {
+
+	source, err := makeCIFSSource(unc)
+	if err != nil {
+		return err
+	}
+	if err := validateMountSource(source); err != nil {
+		return err
+	}
+
+	// A share without a username is mounted as the guest user.
+	if username == "" {
+		return fs.mount(ctx, source, target, "cifs", append(opts, "guest")...)
+	}
+
+	creds, err := makeCIFSCredentials(username, password, domain)
+	if err != nil {
+		return err
+	}
+
+	// ioutil.TempFile creates the file with mode 0600.
+	f, err := ioutil.TempFile("", "gofsutil-cifs-")
+	if err != nil {
+		return err
+	}
+	credsPath := f.Name()
+	defer func() {
+		if err := os.Remove(credsPath); err != nil {
+			log.WithFields(logFields(ctx, log.Fields{
+				"path": credsPath,
+			})).WithError(err).Warn(
+				"failed to remove cifs credentials file")
+		}
+	}()
+	if _, err := f.WriteString(creds); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	log.WithFields(logFields(ctx, log.Fields{
+		"source":   source,
+		"target":   target,
+		"username": username,
+		"domain":   domain,
+	})).Info("mounting cifs share")
+	opts = append(opts[:len(opts):len(opts)], "credentials="+credsPath)
+	return fs.mount(ctx, source, target, "cifs", opts...)
+}
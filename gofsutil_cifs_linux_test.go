@@ -0,0 +1,96 @@
+package gofsutil_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+func TestMountCIFS(t *testing.T) {
+	target, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(target)
+
+	// The runner inspects the credentials file while the mount command
+	// runs since the file is removed once the command exits.
+	var credsPath string
+	r := &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			for _, a := range strings.Split(args[len(args)-3], ",") {
+				if strings.HasPrefix(a, "credentials=") {
+					credsPath = strings.TrimPrefix(a, "credentials=")
+				}
+			}
+			if credsPath == "" {
+				t.Fatalf("missing credentials option: %q", args)
+			}
+			fi, err := os.Stat(credsPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if mode := fi.Mode().Perm(); mode != 0600 {
+				t.Errorf("invalid credentials mode: exp=0600, act=%o", mode)
+			}
+			buf, err := ioutil.ReadFile(credsPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			exp := "username=svc\npassword=s3cr3t\ndomain=CORP\n"
+			if string(buf) != exp {
+				t.Errorf("invalid credentials: exp=%q, act=%q", exp, buf)
+			}
+			return "", nil
+		},
+	}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	if err := fs.MountCIFS(
+		context.TODO(), `\\fs01\data`, target,
+		"svc", "s3cr3t", "CORP", "vers=3.0"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t, "mount -t cifs -o vers=3.0,credentials="+
+		credsPath+" //fs01/data "+target)
+	for _, c := range r.commands() {
+		if strings.Contains(c, "s3cr3t") {
+			t.Errorf("password in command: %s", c)
+		}
+	}
+	if _, err := os.Stat(credsPath); !os.IsNotExist(err) {
+		t.Errorf("credentials file not removed: %s: %v", credsPath, err)
+	}
+}
+
+func TestMountCIFSGuest(t *testing.T) {
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	if err := fs.MountCIFS(
+		context.TODO(), "fs01/public", "/mnt/public", "", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t, "mount -t cifs -o guest //fs01/public /mnt/public")
+}
+
+func TestMountCIFSInvalid(t *testing.T) {
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{RunCommand: r.run}
+	ctx := context.TODO()
+
+	if err := fs.MountCIFS(
+		ctx, "//fs01", "/mnt/data", "svc", "pw", ""); err == nil {
+		t.Error("expected error for unc without share")
+	}
+	if err := fs.MountCIFS(
+		ctx, "//fs01/data", "/mnt/data", "svc", "pw\nusername=root",
+		""); err == nil {
+		t.Error("expected error for password with newline")
+	}
+	r.assertCommands(t)
+}
@@ -0,0 +1,29 @@
+package gofsutil_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+func TestGetCIFSMounts(t *testing.T) {
+	ctx := gofsutil.WithMountTable(context.TODO(), []gofsutil.Info{
+		{Device: "/dev/sda1", Path: "/", Type: "ext4"},
+		{Device: "//fs01/data", Path: "/mnt/data", Type: "cifs"},
+		{Device: "host:/export", Path: "/mnt/nfs", Type: "nfs4"},
+		{Device: "//fs02/home", Path: "/mnt/home", Type: "smb3"},
+	})
+
+	mounts, err := gofsutil.GetCIFSMounts(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mounts) != 2 {
+		t.Fatalf("invalid mount count: exp=2, act=%d: %v",
+			len(mounts), mounts)
+	}
+	if mounts[0].Path != "/mnt/data" || mounts[1].Path != "/mnt/home" {
+		t.Errorf("invalid mounts: %v", mounts)
+	}
+}
@@ -0,0 +1,205 @@
+package gofsutil
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// defaultPollInterval is the interval used to poll for a change in state
+// when a non-positive interval is provided.
+const defaultPollInterval = time.Second
+
+// DiskInfo describes the state of a block device.
+type DiskInfo struct {
+	// Device is the path of the device with all symlinks evaluated.
+	Device string
+
+	// FSType is the type of the filesystem on the device. An empty
+	// value indicates the device is unformatted.
+	FSType string
+
+	// UUID is the UUID of the filesystem on the device.
+	UUID string
+
+	// Mounted is a flag indicating whether the device, or one of its
+	// partitions, is mounted.
+	Mounted bool
+
+	// Holders are the names of the devices that hold the device open,
+	// ex. device-mapper or md devices.
+	Holders []string
+
+	// PartitionTable is the type of the device's partition table, ex.
+	// "gpt" or "dos". An empty value indicates the device does not have
+	// a partition table.
+	PartitionTable string
+}
+
+// DeviceReadyStatus describes whether a device is ready to be
+// provisioned.
+type DeviceReadyStatus struct {
+	// Device is the path of the device with all symlinks evaluated.
+	Device string
+
+	// Present is a flag indicating whether the device exists.
+	Present bool
+
+	// Size is the size of the device in bytes.
+	Size uint64
+
+	// SizeMatches is a flag indicating whether the device's size is
+	// the expected size.
+	SizeMatches bool
+
+	// FSType is the type of the filesystem on the device. An empty
+	// value indicates the device is unformatted.
+	FSType string
+
+	// FormatMatches is a flag indicating whether the device is either
+	// unformatted or formatted with the expected filesystem type.
+	FormatMatches bool
+
+	// Ready is a flag indicating whether the device is present, is the
+	// expected size, and has a matching format.
+	Ready bool
+}
+
+// defaultDeviceReadyTimeout is the amount of time CheckDeviceReady waits
+// for a device to appear.
+const defaultDeviceReadyTimeout = 10 * time.Second
+
+// checkDeviceReady reports whether device is present, is expectedSize
+// bytes, and is either blank or formatted as expectedFSType
+func (fs *FS) checkDeviceReady(
+	ctx context.Context,
+	device string,
+	expectedSize uint64,
+	expectedFSType string) (DeviceReadyStatus, error) {
+
+	var status DeviceReadyStatus
+
+	waitCtx, cancel := context.WithTimeout(ctx, defaultDeviceReadyTimeout)
+	defer cancel()
+	realPath, err := fs.waitForDevice(waitCtx, device, 0)
+	if err != nil {
+		if err == context.DeadlineExceeded {
+			return status, nil
+		}
+		return status, err
+	}
+	status.Device = realPath
+	status.Present = true
+
+	if status.Size, err = fs.getBlockDeviceSize(ctx, realPath); err != nil {
+		return status, err
+	}
+	status.SizeMatches = expectedSize == 0 || status.Size == expectedSize
+
+	if status.FSType, err = fs.getDiskFormat(ctx, realPath); err != nil {
+		return status, err
+	}
+	status.FormatMatches = status.FSType == "" ||
+		status.FSType == expectedFSType
+
+	status.Ready = status.SizeMatches && status.FormatMatches
+	return status, nil
+}
+
+// waitForDevice polls for the existence of device until it appears or
+// the context is cancelled. If device is a symlink then the function
+// waits for the symlink's target to exist.
+func (fs *FS) waitForDevice(
+	ctx context.Context,
+	device string,
+	pollInterval time.Duration) (string, error) {
+
+	if pollInterval <= 0 {
+		pollInterval = defaultPollInterval
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		// os.Stat follows symlinks, so a by-id or by-path symlink
+		// is not considered present until its target exists.
+		if _, err := os.Stat(device); err == nil {
+			realPath := device
+			if err := EvalSymlinks(ctx, &realPath); err != nil {
+				return "", err
+			}
+			return realPath, nil
+		} else if !os.IsNotExist(err) {
+			return "", err
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// numericSuffixDevicePrefixes are the prefixes of the names of devices
+// whose partition numbers follow the device name directly.
+var numericSuffixDevicePrefixes = []string{"sd", "vd", "xvd", "hd"}
+
+// partitionSuffixRX matches the "p<n>" suffix of a partition of a device
+// with a name that ends in a digit.
+var partitionSuffixRX = regexp.MustCompile(`^(.*[0-9])p[0-9]+$`)
+
+// PartitionDevicePath returns the path of the partition with the provided
+// number on device. Devices with names that end in a digit, ex. nvme0n1,
+// mmcblk0, or loop0, separate the partition number with a "p", while
+// others, ex. sda, vda, or xvda, do not.
+func PartitionDevicePath(device string, partNum int) string {
+	if n := len(device); n > 0 && device[n-1] >= '0' && device[n-1] <= '9' {
+		return fmt.Sprintf("%sp%d", device, partNum)
+	}
+	return fmt.Sprintf("%s%d", device, partNum)
+}
+
+// ParentDevicePath returns the path of the device that contains the
+// provided partition, ex. /dev/nvme0n1 for /dev/nvme0n1p1 and /dev/vda
+// for /dev/vda1. The path is returned as-is if it does not name a
+// partition, including the devices whose names end in a number without
+// being partitions, ex. /dev/dm-0 or /dev/sr0. The parent is derived
+// from the name alone; use GetParentDevice to resolve it using sysfs.
+func ParentDevicePath(partition string) string {
+	if m := partitionSuffixRX.FindStringSubmatch(partition); m != nil {
+		return m[1]
+	}
+	name := filepath.Base(partition)
+	for _, p := range numericSuffixDevicePrefixes {
+		if !strings.HasPrefix(name, p) {
+			continue
+		}
+		parent := strings.TrimRight(name, "0123456789")
+		if len(parent) == len(name) || len(parent) == len(p) {
+			return partition
+		}
+		return partition[:len(partition)-len(name)+len(parent)]
+	}
+	return partition
+}
+
+// evalSymlinksOrPath returns p with all symlinks evaluated, or p cleaned
+// with filepath.Clean if the symlinks cannot be evaluated, ex. because p
+// does not exist. Either way the result may be compared to the paths in
+// the mount table, which are clean, so "/mnt/data/" and "/mnt/./data"
+// both match "/mnt/data".
+func evalSymlinksOrPath(p string) string {
+	if p == "" {
+		return p
+	}
+	if realPath, err := filepath.EvalSymlinks(p); err == nil {
+		return realPath
+	}
+	return filepath.Clean(p)
+}
@@ -0,0 +1,72 @@
+package gofsutil
+
+import "context"
+
+// createSinglePartition creates a single partition that spans the entire
+// device and returns the path to the partition's device
+func (fs *FS) createSinglePartition(
+	ctx context.Context, device string) (string, error) {
+
+	return "", ErrNotImplemented
+}
+
+// getDiskInfo returns information about the provided device
+func (fs *FS) getDiskInfo(ctx context.Context, device string) (DiskInfo, error) {
+	return DiskInfo{}, ErrNotImplemented
+}
+
+// listFormattedUnmountedDevices returns the block devices that contain
+// a filesystem but are not mounted
+func (fs *FS) listFormattedUnmountedDevices(
+	ctx context.Context) ([]DiskInfo, error) {
+
+	return nil, ErrNotImplemented
+}
+
+// getParentDevice returns the disk that contains device
+func (fs *FS) getParentDevice(
+	ctx context.Context, device string) (string, error) {
+
+	return "", ErrNotImplemented
+}
+
+// canonicalizeDevice returns the kernel name of device
+func (fs *FS) canonicalizeDevice(
+	ctx context.Context, device string) (string, error) {
+
+	return "", ErrNotImplemented
+}
+
+// getDeviceByUUID returns the device with the provided filesystem UUID
+func (fs *FS) getDeviceByUUID(
+	ctx context.Context, uuid string) (string, error) {
+
+	return "", ErrNotImplemented
+}
+
+// getDeviceByLabel returns the device with the provided filesystem label
+func (fs *FS) getDeviceByLabel(
+	ctx context.Context, label string) (string, error) {
+
+	return "", ErrNotImplemented
+}
+
+// getDevicePathByPath returns the device to which the /dev/disk/by-path
+// link with the provided name points
+func (fs *FS) getDevicePathByPath(
+	ctx context.Context, byPathSuffix string) (string, error) {
+
+	return "", ErrNotImplemented
+}
+
+// getBlockDeviceSize returns the size of device in bytes
+func (fs *FS) getBlockDeviceSize(
+	ctx context.Context, device string) (uint64, error) {
+
+	return 0, ErrNotImplemented
+}
+
+// getFSUUID returns the UUID of the filesystem on device
+func (fs *FS) getFSUUID(ctx context.Context, device string) (string, error) {
+	return "", ErrNotImplemented
+}
@@ -0,0 +1,598 @@
+package gofsutil
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// partitionWaitTimeout is the amount of time to wait for a new
+	// partition's device to appear.
+	partitionWaitTimeout = 30 * time.Second
+
+	// partitionPollInterval is the interval at which to poll for a new
+	// partition's device.
+	partitionPollInterval = 100 * time.Millisecond
+)
+
+// createSinglePartition creates a single partition that spans the entire
+// device and returns the path to the partition's device
+func (fs *FS) createSinglePartition(
+	ctx context.Context, device string) (string, error) {
+
+	// Resolve any symlinks so the partition's device path may be
+	// derived from the kernel's name for the device.
+	if err := EvalSymlinks(ctx, &device); err != nil {
+		return "", err
+	}
+
+	f := log.Fields{
+		"device": device,
+	}
+	log.WithFields(f).Info("creating partition")
+
+	if buf, err := fs.exec(ctx, "sgdisk", "-n", "1:0:0", device); err != nil {
+		out := string(buf)
+		log.WithFields(f).WithField("output", out).WithError(
+			err).Error("sgdisk failed")
+		return "", fmt.Errorf(
+			"sgdisk failed: %v\ndevice: %s\noutput: %s", err, device, out)
+	}
+
+	if buf, err := fs.exec(ctx, "partprobe", device); err != nil {
+		out := string(buf)
+		log.WithFields(f).WithField("output", out).WithError(
+			err).Error("partprobe failed")
+		return "", fmt.Errorf(
+			"partprobe failed: %v\ndevice: %s\noutput: %s", err, device, out)
+	}
+
+	part := PartitionDevicePath(device, 1)
+	f["partition"] = part
+	log.WithFields(f).Info("waiting for partition")
+
+	ctx, cancel := context.WithTimeout(ctx, partitionWaitTimeout)
+	defer cancel()
+	return fs.waitForDevice(ctx, part, partitionPollInterval)
+}
+
+// getDiskInfo returns information about the provided device
+func (fs *FS) getDiskInfo(ctx context.Context, device string) (DiskInfo, error) {
+
+	if err := EvalSymlinks(ctx, &device); err != nil {
+		return DiskInfo{}, err
+	}
+	info := DiskInfo{Device: device}
+
+	fsType, err := fs.getDiskFormat(ctx, device)
+	if err != nil {
+		return DiskInfo{}, err
+	}
+	info.FSType = fsType
+
+	mounts, err := fs.getDiskMounts(ctx, device)
+	if err != nil {
+		return DiskInfo{}, err
+	}
+	info.Mounted = len(mounts) > 0
+
+	if info.Holders, err = fs.getHolders(ctx, device); err != nil {
+		return DiskInfo{}, err
+	}
+
+	buf, err := fs.exec(ctx, "lsblk", "-n", "-d", "-o", "PTTYPE", device)
+	if err != nil {
+		return DiskInfo{}, fmt.Errorf(
+			"lsblk failed: %v\ndevice: %s\noutput: %s", err, device, buf)
+	}
+	info.PartitionTable = strings.TrimSpace(string(buf))
+
+	return info, nil
+}
+
+// getHolders returns the names of the devices that hold device open
+func (fs *FS) getHolders(ctx context.Context, device string) ([]string, error) {
+	holdersDir := fs.sysPath("class", "block", path.Base(device), "holders")
+	fis, err := ioutil.ReadDir(holdersDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var holders []string
+	for _, fi := range fis {
+		holders = append(holders, fi.Name())
+	}
+	return holders, nil
+}
+
+// getPartitions returns the paths of the partitions of device, which
+// are the children of its sys filesystem directory that have a
+// "partition" file
+func (fs *FS) getPartitions(ctx context.Context, device string) ([]string, error) {
+	name := path.Base(evalSymlinksOrPath(device))
+	sysDir := fs.sysPath("class", "block", name)
+	fis, err := ioutil.ReadDir(sysDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var parts []string
+	for _, fi := range fis {
+		_, err := os.Stat(path.Join(sysDir, fi.Name(), "partition"))
+		if err == nil {
+			parts = append(parts, fs.devPath(fi.Name()))
+		}
+	}
+	return parts, nil
+}
+
+// getDiskMounts returns the mounts of device and of its partitions
+func (fs *FS) getDiskMounts(ctx context.Context, device string) ([]Info, error) {
+	parts, err := fs.getPartitions(ctx, device)
+	if err != nil {
+		return nil, err
+	}
+	devs := append([]string{device}, parts...)
+	devMounts, err := fs.getDevMountsMulti(ctx, devs)
+	if err != nil {
+		return nil, err
+	}
+	var mounts []Info
+	for _, dev := range devs {
+		mounts = append(mounts, devMounts[dev]...)
+	}
+	return mounts, nil
+}
+
+// lsblkPairRX matches the KEY="value" pairs emitted by "lsblk -P".
+var lsblkPairRX = regexp.MustCompile(`([A-Z0-9:_-]+)="([^"]*)"`)
+
+// parseLsblkPairs parses the output of "lsblk -P" and returns a map of
+// the columns for each device.
+func parseLsblkPairs(buf []byte) []map[string]string {
+	var devs []map[string]string
+	scan := bufio.NewScanner(bytes.NewReader(buf))
+	for scan.Scan() {
+		m := lsblkPairRX.FindAllStringSubmatch(scan.Text(), -1)
+		if len(m) == 0 {
+			continue
+		}
+		dev := map[string]string{}
+		for _, kv := range m {
+			dev[kv[1]] = kv[2]
+		}
+		devs = append(devs, dev)
+	}
+	return devs
+}
+
+// lsblkJSONDevice is a device in the output of "lsblk -J -o NAME,FSTYPE".
+// The FSTYPE of a device without a filesystem is null, which is decoded
+// as an empty string.
+type lsblkJSONDevice struct {
+	Name     string            `json:"name"`
+	FSType   string            `json:"fstype"`
+	Children []lsblkJSONDevice `json:"children"`
+}
+
+// parseLsblkJSONFSTypes parses the output of "lsblk -J -o NAME,FSTYPE"
+// and returns the FSTYPE of each device in the order "lsblk -P" lists
+// them, which is each device followed by its dependent devices.
+func parseLsblkJSONFSTypes(buf []byte) ([]string, error) {
+	var out struct {
+		BlockDevices []lsblkJSONDevice `json:"blockdevices"`
+	}
+	if err := json.Unmarshal(buf, &out); err != nil {
+		return nil, fmt.Errorf("invalid lsblk json output: %v", err)
+	}
+	var (
+		fsTypes []string
+		walk    func(devs []lsblkJSONDevice)
+	)
+	walk = func(devs []lsblkJSONDevice) {
+		for _, d := range devs {
+			fsTypes = append(fsTypes, d.FSType)
+			walk(d.Children)
+		}
+	}
+	walk(out.BlockDevices)
+	return fsTypes, nil
+}
+
+// containerFSTypes are the signatures of the devices that contain other
+// block devices rather than a filesystem that may be mounted.
+var containerFSTypes = map[string]struct{}{
+	"LVM2_member":       {},
+	"crypto_LUKS":       {},
+	"linux_raid_member": {},
+	"zfs_member":        {},
+	"isw_raid_member":   {},
+	"ddf_raid_member":   {},
+	"bcache":            {},
+	"drbd":              {},
+}
+
+// listFormattedUnmountedDevices returns the block devices that contain
+// a filesystem but are not mounted, held, or containers of other devices
+func (fs *FS) listFormattedUnmountedDevices(
+	ctx context.Context) ([]DiskInfo, error) {
+
+	args := []string{"-P", "-p", "-o", "NAME,TYPE,FSTYPE,UUID,PKNAME"}
+	buf, err := fs.exec(ctx, "lsblk", args...)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"lsblk failed: %v\noutput: %s", err, buf)
+	}
+	devs := parseLsblkPairs(buf)
+
+	mounts, err := fs.getMounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mounted := map[string]struct{}{}
+	for _, m := range mounts {
+		mounted[m.Device] = struct{}{}
+	}
+
+	// A parent device with its own filesystem signature, ex. an LVM
+	// physical volume, claims the entire disk. A device that is the
+	// parent of a device other than a partition, ex. the logical volume
+	// of a physical volume or the mapping of a LUKS container, is held.
+	claimed := map[string]struct{}{}
+	held := map[string]struct{}{}
+	for _, d := range devs {
+		if d["FSTYPE"] != "" {
+			claimed[d["NAME"]] = struct{}{}
+		}
+		if pkName := d["PKNAME"]; pkName != "" && d["TYPE"] != "part" {
+			held[pkName] = struct{}{}
+		}
+	}
+
+	var disks []DiskInfo
+	for _, d := range devs {
+		name, fsType := d["NAME"], d["FSTYPE"]
+		if fsType == "" || fsType == "swap" {
+			continue
+		}
+		if _, ok := containerFSTypes[fsType]; ok {
+			continue
+		}
+		if _, ok := mounted[name]; ok {
+			continue
+		}
+		if _, ok := held[name]; ok {
+			continue
+		}
+		if pkName := d["PKNAME"]; pkName != "" {
+			if _, ok := claimed[pkName]; ok {
+				continue
+			}
+		}
+		holders, err := fs.getHolders(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if len(holders) > 0 {
+			continue
+		}
+		disks = append(disks, DiskInfo{
+			Device: name,
+			FSType: fsType,
+			UUID:   d["UUID"],
+		})
+	}
+	return disks, nil
+}
+
+// getParentDevice returns the disk that contains device. A partition is
+// resolved using the sys filesystem, and any other device, ex. a
+// device-mapper device, by following the PKNAME column of lsblk until a
+// disk or multipath device is reached.
+func (fs *FS) getParentDevice(
+	ctx context.Context, device string) (string, error) {
+
+	realPath := evalSymlinksOrPath(device)
+
+	// The sys filesystem directory of a partition is a child of the
+	// directory of the disk that contains it.
+	name := path.Base(realPath)
+	sysDir := fs.sysPath("class", "block", name)
+	if _, err := os.Stat(path.Join(sysDir, "partition")); err == nil {
+		if dir, err := filepath.EvalSymlinks(sysDir); err == nil {
+			return fs.devPath(path.Base(path.Dir(dir))), nil
+		}
+	}
+
+	args := []string{"-s", "-P", "-p", "-o", "NAME,PKNAME,TYPE", realPath}
+	buf, err := fs.exec(ctx, "lsblk", args...)
+	if err != nil {
+		out := string(buf)
+		log.WithFields(log.Fields{
+			"device": device,
+			"output": out,
+		}).WithError(err).Error("lsblk failed")
+		return "", fmt.Errorf(
+			"lsblk failed: %w\narguments: %v\noutput: %s",
+			wrapCmdError(err, out, lsblkErrors), args, out)
+	}
+
+	// The first line describes device, followed by the devices on
+	// which it depends.
+	devs := parseLsblkPairs(buf)
+	if len(devs) == 0 {
+		return "", fmt.Errorf("%s: %w", device, ErrDeviceNotFound)
+	}
+	byName := map[string]map[string]string{}
+	for _, d := range devs {
+		if _, ok := byName[d["NAME"]]; !ok {
+			byName[d["NAME"]] = d
+		}
+	}
+	dev := devs[0]
+	for range devs {
+		switch dev["TYPE"] {
+		case "disk", "mpath":
+			return dev["NAME"], nil
+		}
+		parent, ok := byName[dev["PKNAME"]]
+		if !ok {
+			break
+		}
+		dev = parent
+	}
+	return dev["NAME"], nil
+}
+
+// canonicalizeDevice returns the kernel name of device, ex. /dev/sda for
+// /dev/disk/by-id/wwn-0x5000c500a1b2c3d4. The name of a device that
+// is not a symlink to a kernel device, ex. a device node in /dev/mapper,
+// is reported by lsblk. A path member of a multipath device is replaced
+// by the dm device that holds it.
+func (fs *FS) canonicalizeDevice(
+	ctx context.Context, device string) (string, error) {
+
+	realPath, err := filepath.EvalSymlinks(device)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("%s: %w", device, ErrDeviceNotFound)
+		}
+		return "", err
+	}
+
+	name := path.Base(realPath)
+	if _, err := os.Stat(fs.sysPath("class", "block", name)); err != nil {
+		args := []string{"-d", "-n", "-o", "KNAME", realPath}
+		buf, err := fs.exec(ctx, "lsblk", args...)
+		if err != nil {
+			out := string(buf)
+			log.WithFields(log.Fields{
+				"device": device,
+				"output": out,
+			}).WithError(err).Error("lsblk failed")
+			return "", fmt.Errorf(
+				"lsblk failed: %w\narguments: %v\noutput: %s",
+				wrapCmdError(err, out, lsblkErrors), args, out)
+		}
+		kname := strings.TrimSpace(string(buf))
+		if kname == "" {
+			return "", fmt.Errorf("%s: %w", device, ErrDeviceNotFound)
+		}
+		name = path.Base(kname)
+	}
+
+	holders, err := fs.getHolders(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	for _, h := range holders {
+		if fs.isMultipathDevice(h) {
+			name = h
+			break
+		}
+	}
+
+	canonical := fs.devPath(name)
+	log.WithFields(log.Fields{
+		"device":    device,
+		"canonical": canonical,
+	}).Debug("canonicalized device")
+	return canonical, nil
+}
+
+// isMultipathDevice returns a flag indicating whether the dm device with
+// the provided kernel name is a multipath device. The uuid of a
+// multipath device's mapping has the prefix "mpath-".
+func (fs *FS) isMultipathDevice(name string) bool {
+	buf, err := ioutil.ReadFile(
+		fs.sysPath("class", "block", name, "dm", "uuid"))
+	if err != nil {
+		return false
+	}
+	return strings.HasPrefix(strings.TrimSpace(string(buf)), "mpath-")
+}
+
+// sysBlockDiskName returns the name of the directory in
+// "<SysRoot>/block" of the disk that is or contains device. A partition
+// has no directory of its own in "<SysRoot>/block", so the name of the
+// disk that contains it is returned.
+func (fs *FS) sysBlockDiskName(device string) string {
+	name := path.Base(evalSymlinksOrPath(device))
+	if _, err := os.Stat(fs.sysPath("block", name)); os.IsNotExist(err) {
+		sysDir := fs.sysPath("class", "block", name)
+		if _, err := os.Stat(path.Join(sysDir, "partition")); err == nil {
+			if dir, err := filepath.EvalSymlinks(sysDir); err == nil {
+				name = path.Base(path.Dir(dir))
+			}
+		}
+	}
+	return name
+}
+
+// getDeviceByUUID returns the device with the provided filesystem UUID
+func (fs *FS) getDeviceByUUID(
+	ctx context.Context, uuid string) (string, error) {
+
+	return fs.resolveDiskLink(ctx, "by-uuid", uuid)
+}
+
+// getDeviceByLabel returns the device with the provided filesystem label
+func (fs *FS) getDeviceByLabel(
+	ctx context.Context, label string) (string, error) {
+
+	return fs.resolveDiskLink(ctx, "by-label", encodeUdevName(label))
+}
+
+// getDevicePathByPath returns the device to which the /dev/disk/by-path
+// link with the provided name points
+func (fs *FS) getDevicePathByPath(
+	ctx context.Context, byPathSuffix string) (string, error) {
+
+	return fs.resolveDiskLink(ctx, "by-path", byPathSuffix)
+}
+
+// resolveDiskLink returns the device to which the udev-managed symlink
+// with the provided name in the /dev/disk/<kind> directory points
+func (fs *FS) resolveDiskLink(
+	ctx context.Context, kind, name string) (string, error) {
+
+	if name == "" || strings.Contains(name, "/") {
+		return "", fmt.Errorf("invalid %s name: %q", kind, name)
+	}
+	link := fs.devPath("disk", kind, name)
+	device, err := filepath.EvalSymlinks(link)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("%s: %w", link, ErrDeviceNotFound)
+		}
+		return "", err
+	}
+	log.WithFields(log.Fields{
+		"link":   link,
+		"device": device,
+	}).Debug("resolved disk link")
+	return device, nil
+}
+
+// encodeUdevName encodes s the way udev encodes the names of the links
+// it creates, ex. in /dev/disk/by-label. Bytes other than alphanumeric
+// characters, multi-byte UTF-8 sequences, and the characters "#+-.:=@_"
+// are encoded as \xNN.
+func encodeUdevName(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		r, n := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case n > 1 && r != utf8.RuneError:
+			b.WriteString(s[i : i+n])
+		case r < utf8.RuneSelf &&
+			(unicode.IsLetter(r) || unicode.IsDigit(r) ||
+				strings.ContainsRune("#+-.:=@_", r)):
+			b.WriteRune(r)
+		default:
+			fmt.Fprintf(&b, "\\x%02x", s[i])
+		}
+		i += n
+	}
+	return b.String()
+}
+
+// getBlockDeviceSize returns the size of device in bytes
+func (fs *FS) getBlockDeviceSize(
+	ctx context.Context, device string) (uint64, error) {
+
+	args := []string{"--getsize64", device}
+	buf, err := fs.exec(ctx, "blockdev", args...)
+	if err != nil {
+		out := string(buf)
+		log.WithFields(log.Fields{
+			"device": device,
+			"output": out,
+		}).WithError(err).Error("blockdev failed")
+		return 0, fmt.Errorf(
+			"blockdev failed: %v\narguments: %v\noutput: %s", err, args, out)
+	}
+	size, err := strconv.ParseUint(string(bytes.TrimSpace(buf)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid device size: %s: %v", device, err)
+	}
+	return size, nil
+}
+
+// getFSUUID returns the UUID of the filesystem on device
+func (fs *FS) getFSUUID(ctx context.Context, device string) (string, error) {
+	args := []string{"-s", "UUID", "-o", "value", device}
+	f := log.Fields{
+		"device": device,
+		"args":   args,
+	}
+
+	buf, err := fs.exec(ctx, "blkid", args...)
+	if uuid := string(bytes.TrimSpace(buf)); err == nil && uuid != "" {
+		return uuid, nil
+	}
+
+	// blkid exits with a non-zero status when the device does not
+	// contain a filesystem with a UUID, but it may also be unavailable,
+	// so fall back to the links udev maintains for each UUID.
+	log.WithFields(f).WithField("output", string(buf)).WithError(err).Debug(
+		"blkid failed, searching disk by-uuid links")
+	uuid, lookupErr := fs.lookupDiskLinkName(ctx, "by-uuid", device)
+	if lookupErr != nil {
+		return "", lookupErr
+	}
+	if uuid == "" {
+		return "", fmt.Errorf(
+			"filesystem uuid not found: device may be unformatted: %s", device)
+	}
+	return uuid, nil
+}
+
+// lookupDiskLinkName returns the name of the udev-managed symlink in the
+// /dev/disk/<kind> directory that points to device. An empty string is
+// returned if there is no such link.
+func (fs *FS) lookupDiskLinkName(
+	ctx context.Context, kind, device string) (string, error) {
+
+	device = evalSymlinksOrPath(device)
+	dir := fs.devPath("disk", kind)
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	for _, fi := range infos {
+		if fi.Mode()&os.ModeSymlink == 0 {
+			continue
+		}
+		target, err := filepath.EvalSymlinks(path.Join(dir, fi.Name()))
+		if err != nil {
+			continue
+		}
+		if target == device {
+			return fi.Name(), nil
+		}
+	}
+	return "", nil
+}
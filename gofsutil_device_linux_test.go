@@ -0,0 +1,848 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+func TestCreateSinglePartition(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := gofsutil.EvalSymlinks(context.TODO(), &dir); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		dev  string
+		part string
+	}{
+		{
+			dev:  "sdb",
+			part: "sdb1",
+		},
+		{
+			dev:  "nvme0n1",
+			part: "nvme0n1p1",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.dev, func(st *testing.T) {
+			dev := path.Join(dir, tt.dev)
+			part := path.Join(dir, tt.part)
+			if err := ioutil.WriteFile(dev, nil, 0644); err != nil {
+				st.Fatal(err)
+			}
+
+			// Create the partition's device when partprobe is run.
+			r := &testCommandRunner{
+				handler: func(args []string) (string, error) {
+					if args[0] == "partprobe" {
+						return "", ioutil.WriteFile(part, nil, 0644)
+					}
+					return "", nil
+				},
+			}
+			fs := &gofsutil.FS{RunCommand: r.run}
+
+			act, err := fs.CreateSinglePartition(context.TODO(), dev)
+			if err != nil {
+				st.Fatal(err)
+			}
+			if act != part {
+				st.Errorf("invalid partition: exp=%s, act=%s", part, act)
+			}
+			r.assertCommands(st,
+				"sgdisk -n 1:0:0 "+dev,
+				"partprobe "+dev)
+		})
+	}
+}
+
+func TestGetDiskInfo(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := gofsutil.EvalSymlinks(context.TODO(), &dir); err != nil {
+		t.Fatal(err)
+	}
+
+	devDir := path.Join(dir, "dev")
+	sysRoot := path.Join(dir, "sys")
+	for _, d := range []string{"sdb", "sdc", "sdd", "sde", "sde1"} {
+		if err := os.MkdirAll(devDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(
+			path.Join(devDir, d), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		holders := path.Join(sysRoot, "class", "block", d, "holders")
+		if err := os.MkdirAll(holders, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(path.Join(
+		sysRoot, "class", "block", "sdd", "holders", "dm-0"),
+		nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The partition sde1 is a child of the sys filesystem directory of
+	// sde, and it is mounted while sde is not.
+	sde1 := path.Join(sysRoot, "class", "block", "sde", "sde1")
+	if err := os.MkdirAll(sde1, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(
+		path.Join(sde1, "partition"), []byte("1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	procRoot := newTestProcRoot(t, fmt.Sprintf(
+		"72 60 8:32 / /mnt/sdc rw,relatime shared:28 - ext4 %s rw\n"+
+			"73 60 8:65 / /mnt/sde1 rw,relatime shared:29 - ext4 %s rw\n",
+		path.Join(devDir, "sdc"), path.Join(devDir, "sde1")), "self")
+
+	r := &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			if args[len(args)-2] == "PTTYPE" {
+				return "gpt\n", nil
+			}
+			if path.Base(args[len(args)-1]) == "sdd" {
+				return newTestLsblkJSON("LVM2_member"), nil
+			}
+			return newTestLsblkJSON("ext4"), nil
+		},
+	}
+	fs := &gofsutil.FS{
+		ProcRoot:   procRoot,
+		DevRoot:    devDir,
+		SysRoot:    sysRoot,
+		RunCommand: r.run,
+	}
+
+	tests := []struct {
+		dev     string
+		info    gofsutil.DiskInfo
+		holders []string
+	}{
+		{
+			dev:  "sdb",
+			info: gofsutil.DiskInfo{FSType: "ext4", PartitionTable: "gpt"},
+		},
+		{
+			dev: "sdc",
+			info: gofsutil.DiskInfo{
+				FSType: "ext4", PartitionTable: "gpt", Mounted: true},
+		},
+		{
+			dev:     "sdd",
+			info:    gofsutil.DiskInfo{FSType: "LVM2_member", PartitionTable: "gpt"},
+			holders: []string{"dm-0"},
+		},
+		{
+			dev: "sde",
+			info: gofsutil.DiskInfo{
+				FSType: "ext4", PartitionTable: "gpt", Mounted: true},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.dev, func(st *testing.T) {
+			dev := path.Join(devDir, tt.dev)
+			info, err := fs.GetDiskInfo(context.TODO(), dev)
+			if err != nil {
+				st.Fatal(err)
+			}
+			if info.Device != dev {
+				st.Errorf("invalid device: exp=%s, act=%s", dev, info.Device)
+			}
+			if info.FSType != tt.info.FSType {
+				st.Errorf("invalid fsType: exp=%s, act=%s",
+					tt.info.FSType, info.FSType)
+			}
+			if info.Mounted != tt.info.Mounted {
+				st.Errorf("invalid mounted: exp=%v, act=%v",
+					tt.info.Mounted, info.Mounted)
+			}
+			if info.PartitionTable != tt.info.PartitionTable {
+				st.Errorf("invalid partition table: exp=%s, act=%s",
+					tt.info.PartitionTable, info.PartitionTable)
+			}
+			if !reflect.DeepEqual(info.Holders, tt.holders) {
+				st.Errorf("invalid holders: exp=%v, act=%v",
+					tt.holders, info.Holders)
+			}
+		})
+	}
+}
+
+const lsblkPairsData = `NAME="/dev/sda" TYPE="disk" FSTYPE="" UUID="" PKNAME=""
+NAME="/dev/sda1" TYPE="part" FSTYPE="xfs" UUID="0b1c3f1e-7c38-4d5a-9a4f-8f0e7d6c5b4a" PKNAME="/dev/sda"
+NAME="/dev/sda2" TYPE="part" FSTYPE="swap" UUID="5e6f7a8b-1c2d-4e3f-8a9b-0c1d2e3f4a5b" PKNAME="/dev/sda"
+NAME="/dev/sdb" TYPE="disk" FSTYPE="ext4" UUID="9f8e7d6c-5b4a-4321-8fed-cba987654321" PKNAME=""
+NAME="/dev/sdc" TYPE="disk" FSTYPE="xfs" UUID="11111111-2222-4333-8444-555555555555" PKNAME=""
+NAME="/dev/sdd" TYPE="disk" FSTYPE="LVM2_member" UUID="aaaa-bbbb" PKNAME=""
+NAME="/dev/sdd1" TYPE="part" FSTYPE="ext4" UUID="cccccccc-dddd-4eee-8fff-000000000000" PKNAME="/dev/sdd"
+NAME="/dev/sde" TYPE="disk" FSTYPE="" UUID="" PKNAME=""
+`
+
+func TestListFormattedUnmountedDevices(t *testing.T) {
+	procRoot := newTestProcRoot(t,
+		"72 60 8:1 / /boot rw,relatime shared:28 - xfs /dev/sda1 rw\n"+
+			"73 60 8:32 / /mnt/sdc rw,relatime shared:29 - xfs /dev/sdc rw\n",
+		"self")
+
+	const (
+		sdb = `NAME="/dev/sdb" TYPE="disk" FSTYPE="ext4" ` +
+			`UUID="9f8e7d6c-5b4a-4321-8fed-cba987654321" PKNAME=""` + "\n"
+		sdf = `NAME="/dev/sdf" TYPE="disk" FSTYPE="xfs" ` +
+			`UUID="22222222-3333-4444-8555-666666666666" PKNAME=""` + "\n"
+	)
+	idle := []gofsutil.DiskInfo{
+		{
+			Device: "/dev/sdb",
+			FSType: "ext4",
+			UUID:   "9f8e7d6c-5b4a-4321-8fed-cba987654321",
+		},
+	}
+
+	tests := []struct {
+		name    string
+		lsblk   string
+		holders map[string][]string
+		exp     []gofsutil.DiskInfo
+	}{
+		{
+			name:  "mounted swap and claimed",
+			lsblk: lsblkPairsData,
+			exp:   idle,
+		},
+		{
+			name: "lvm physical volume with logical volume",
+			lsblk: sdb +
+				`NAME="/dev/sdg" TYPE="disk" FSTYPE="LVM2_member" UUID="pv" PKNAME=""` + "\n" +
+				`NAME="/dev/mapper/vg-lv" TYPE="lvm" FSTYPE="" UUID="" PKNAME="/dev/sdg"` + "\n",
+			exp: idle,
+		},
+		{
+			name: "open luks container",
+			lsblk: sdb +
+				`NAME="/dev/sdh" TYPE="disk" FSTYPE="crypto_LUKS" UUID="luks" PKNAME=""` + "\n" +
+				`NAME="/dev/mapper/data" TYPE="crypt" FSTYPE="" UUID="" PKNAME="/dev/sdh"` + "\n",
+			exp: idle,
+		},
+		{
+			name: "closed containers",
+			lsblk: sdb +
+				`NAME="/dev/sdg" TYPE="disk" FSTYPE="LVM2_member" UUID="pv" PKNAME=""` + "\n" +
+				`NAME="/dev/sdh" TYPE="disk" FSTYPE="crypto_LUKS" UUID="luks" PKNAME=""` + "\n" +
+				`NAME="/dev/sdi" TYPE="disk" FSTYPE="linux_raid_member" UUID="md" PKNAME=""` + "\n",
+			exp: idle,
+		},
+		{
+			name: "filesystem held by device-mapper",
+			lsblk: sdb + sdf +
+				`NAME="/dev/mapper/snap" TYPE="dm" FSTYPE="xfs" UUID="" PKNAME="/dev/sdf"` + "\n",
+			exp: idle,
+		},
+		{
+			name:    "filesystem with sysfs holders",
+			lsblk:   sdb + sdf,
+			holders: map[string][]string{"sdf": {"dm-3"}},
+			exp:     idle,
+		},
+		{
+			name:    "filesystem without sysfs holders",
+			lsblk:   sdb + sdf,
+			holders: map[string][]string{"sdf": nil},
+			exp: append(idle[:1:1], gofsutil.DiskInfo{
+				Device: "/dev/sdf",
+				FSType: "xfs",
+				UUID:   "22222222-3333-4444-8555-666666666666",
+			}),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(st *testing.T) {
+			sysRoot, err := ioutil.TempDir("", "")
+			if err != nil {
+				st.Fatal(err)
+			}
+			defer os.RemoveAll(sysRoot)
+			for dev, holders := range tt.holders {
+				dir := path.Join(sysRoot, "class", "block", dev, "holders")
+				if err := os.MkdirAll(dir, 0755); err != nil {
+					st.Fatal(err)
+				}
+				for _, h := range holders {
+					if err := ioutil.WriteFile(
+						path.Join(dir, h), nil, 0644); err != nil {
+						st.Fatal(err)
+					}
+				}
+			}
+
+			r := &testCommandRunner{
+				handler: func(args []string) (string, error) {
+					return tt.lsblk, nil
+				},
+			}
+			fs := &gofsutil.FS{
+				ProcRoot:   procRoot,
+				SysRoot:    sysRoot,
+				RunCommand: r.run,
+			}
+
+			disks, err := fs.ListFormattedUnmountedDevices(context.TODO())
+			if err != nil {
+				st.Fatal(err)
+			}
+			if !reflect.DeepEqual(disks, tt.exp) {
+				st.Errorf("invalid disks: exp=%+v, act=%+v", tt.exp, disks)
+			}
+		})
+	}
+}
+
+// newTestDevRoot creates a temporary dev filesystem root with a device
+// named sdb and by-uuid and by-label links to it.
+func newTestDevRoot(t *testing.T, uuid, label string) string {
+	devRoot := t.TempDir()
+	if err := gofsutil.EvalSymlinks(context.TODO(), &devRoot); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(
+		path.Join(devRoot, "sdb"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	for kind, name := range map[string]string{
+		"by-uuid":  uuid,
+		"by-label": label,
+	} {
+		dir := path.Join(devRoot, "disk", kind)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(
+			"../../sdb", path.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return devRoot
+}
+
+func TestGetParentDevice(t *testing.T) {
+	lsblk := map[string]string{
+		"/dev/sdb1": `NAME="/dev/sdb1" PKNAME="/dev/sdb" TYPE="part"
+NAME="/dev/sdb" PKNAME="" TYPE="disk"
+`,
+		"/dev/mapper/luks-1": `NAME="/dev/mapper/luks-1" PKNAME="/dev/sdb1" TYPE="crypt"
+NAME="/dev/sdb1" PKNAME="/dev/sdb" TYPE="part"
+NAME="/dev/sdb" PKNAME="" TYPE="disk"
+`,
+		"/dev/mapper/mpatha1": `NAME="/dev/mapper/mpatha1" PKNAME="/dev/mapper/mpatha" TYPE="part"
+NAME="/dev/mapper/mpatha" PKNAME="/dev/sdc" TYPE="mpath"
+NAME="/dev/sdc" PKNAME="" TYPE="disk"
+NAME="/dev/mapper/mpatha" PKNAME="/dev/sdd" TYPE="mpath"
+NAME="/dev/sdd" PKNAME="" TYPE="disk"
+`,
+		"/dev/sdc": `NAME="/dev/sdc" PKNAME="" TYPE="disk"
+`,
+	}
+	r := &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			return lsblk[args[len(args)-1]], nil
+		},
+	}
+
+	// An empty sys filesystem so that every device is resolved by lsblk.
+	sysRoot, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(sysRoot)
+	fs := &gofsutil.FS{SysRoot: sysRoot, RunCommand: r.run}
+
+	for _, tt := range []struct {
+		dev    string
+		parent string
+	}{
+		{"/dev/sdb1", "/dev/sdb"},
+		{"/dev/mapper/luks-1", "/dev/sdb"},
+		{"/dev/mapper/mpatha1", "/dev/mapper/mpatha"},
+		{"/dev/sdc", "/dev/sdc"},
+	} {
+		parent, err := fs.GetParentDevice(context.TODO(), tt.dev)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.dev, err)
+		}
+		if parent != tt.parent {
+			t.Errorf("%s: invalid parent: exp=%s, act=%s",
+				tt.dev, tt.parent, parent)
+		}
+	}
+	r.assertCommands(t,
+		"lsblk -s -P -p -o NAME,PKNAME,TYPE /dev/sdb1",
+		"lsblk -s -P -p -o NAME,PKNAME,TYPE /dev/mapper/luks-1",
+		"lsblk -s -P -p -o NAME,PKNAME,TYPE /dev/mapper/mpatha1",
+		"lsblk -s -P -p -o NAME,PKNAME,TYPE /dev/sdc")
+}
+
+func TestGetParentDeviceSysfs(t *testing.T) {
+	sysRoot, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(sysRoot)
+
+	// /sys/class/block/nvme0n1p2 links to the partition's directory
+	// beneath that of the disk.
+	part := path.Join(sysRoot, "devices", "nvme0", "nvme0n1", "nvme0n1p2")
+	if err := os.MkdirAll(part, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(
+		path.Join(part, "partition"), []byte("2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	classBlock := path.Join(sysRoot, "class", "block")
+	if err := os.MkdirAll(classBlock, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(
+		part, path.Join(classBlock, "nvme0n1p2")); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{SysRoot: sysRoot, RunCommand: r.run}
+	parent, err := fs.GetParentDevice(context.TODO(), "/dev/nvme0n1p2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parent != "/dev/nvme0n1" {
+		t.Errorf("invalid parent: exp=/dev/nvme0n1, act=%s", parent)
+	}
+	r.assertCommands(t)
+}
+
+func TestGetDeviceByUUID(t *testing.T) {
+	const uuid = "3e6be9de-8139-11d1-9106-a43f08d823a6"
+	devRoot := newTestDevRoot(t, uuid, "data")
+
+	fs := &gofsutil.FS{DevRoot: devRoot}
+	dev, err := fs.GetDeviceByUUID(context.TODO(), uuid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := path.Join(devRoot, "sdb"); dev != exp {
+		t.Errorf("invalid device: exp=%s, act=%s", exp, dev)
+	}
+
+	_, err = fs.GetDeviceByUUID(context.TODO(), "missing")
+	if !errors.Is(err, gofsutil.ErrDeviceNotFound) {
+		t.Errorf("expected ErrDeviceNotFound: %v", err)
+	}
+	if _, err := fs.GetDeviceByUUID(context.TODO(), "../sdb"); err == nil {
+		t.Error("expected error for uuid with slash")
+	}
+}
+
+func TestGetDeviceByLabel(t *testing.T) {
+	devRoot := newTestDevRoot(t, "1234", `my\x20data`)
+
+	fs := &gofsutil.FS{DevRoot: devRoot}
+	dev, err := fs.GetDeviceByLabel(context.TODO(), "my data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := path.Join(devRoot, "sdb"); dev != exp {
+		t.Errorf("invalid device: exp=%s, act=%s", exp, dev)
+	}
+}
+
+func TestGetDevicePathByPath(t *testing.T) {
+	devRoot := newTestDevRoot(t, "1234", "data")
+
+	const byPath = "pci-0000:00:10.0-scsi-0:0:1:0"
+	dir := path.Join(devRoot, "disk", "by-path")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("../../sdb", path.Join(dir, byPath)); err != nil {
+		t.Fatal(err)
+	}
+
+	fs := &gofsutil.FS{DevRoot: devRoot}
+	dev, err := fs.GetDevicePathByPath(context.TODO(), byPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := path.Join(devRoot, "sdb"); dev != exp {
+		t.Errorf("invalid device: exp=%s, act=%s", exp, dev)
+	}
+
+	_, err = fs.GetDevicePathByPath(
+		context.TODO(), "pci-0000:00:10.0-scsi-0:0:2:0")
+	if !errors.Is(err, gofsutil.ErrDeviceNotFound) {
+		t.Errorf("expected ErrDeviceNotFound: %v", err)
+	}
+}
+
+func TestCheckDeviceReady(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := gofsutil.EvalSymlinks(context.TODO(), &dir); err != nil {
+		t.Fatal(err)
+	}
+	dev := path.Join(dir, "sdb")
+	if err := ioutil.WriteFile(dev, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	newRunner := func(format string) *testCommandRunner {
+		return &testCommandRunner{
+			handler: func(args []string) (string, error) {
+				switch args[0] {
+				case "blockdev":
+					return "10737418240\n", nil
+				case "lsblk":
+					return newTestLsblkJSON(format), nil
+				}
+				return "", nil
+			},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		device string
+		format string
+		size   uint64
+		fsType string
+		status gofsutil.DeviceReadyStatus
+	}{
+		{
+			name:   "blank-ready",
+			device: dev,
+			size:   10737418240,
+			fsType: "xfs",
+			status: gofsutil.DeviceReadyStatus{
+				Device:        dev,
+				Present:       true,
+				Size:          10737418240,
+				SizeMatches:   true,
+				FormatMatches: true,
+				Ready:         true,
+			},
+		},
+		{
+			name:   "formatted-ready",
+			device: dev,
+			format: "xfs",
+			size:   10737418240,
+			fsType: "xfs",
+			status: gofsutil.DeviceReadyStatus{
+				Device:        dev,
+				Present:       true,
+				Size:          10737418240,
+				SizeMatches:   true,
+				FSType:        "xfs",
+				FormatMatches: true,
+				Ready:         true,
+			},
+		},
+		{
+			name:   "wrong-format",
+			device: dev,
+			format: "ext4",
+			size:   10737418240,
+			fsType: "xfs",
+			status: gofsutil.DeviceReadyStatus{
+				Device:      dev,
+				Present:     true,
+				Size:        10737418240,
+				SizeMatches: true,
+				FSType:      "ext4",
+			},
+		},
+		{
+			name:   "wrong-size",
+			device: dev,
+			size:   5368709120,
+			fsType: "xfs",
+			status: gofsutil.DeviceReadyStatus{
+				Device:        dev,
+				Present:       true,
+				Size:          10737418240,
+				FormatMatches: true,
+			},
+		},
+		{
+			name:   "missing-device",
+			device: path.Join(dir, "sdc"),
+			size:   10737418240,
+			fsType: "xfs",
+		},
+	}
+
+	for _, tt := range tests {
+		r := newRunner(tt.format)
+		fs := &gofsutil.FS{RunCommand: r.run}
+
+		ctx, cancel := context.WithTimeout(
+			context.Background(), 100*time.Millisecond)
+		status, err := fs.CheckDeviceReady(ctx, tt.device, tt.size, tt.fsType)
+		cancel()
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if status != tt.status {
+			t.Errorf("%s: invalid status: exp=%+v, act=%+v",
+				tt.name, tt.status, status)
+		}
+	}
+}
+
+func TestGetBlockDeviceSize(t *testing.T) {
+	r := &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			return "1073741824\n", nil
+		},
+	}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	size, err := fs.GetBlockDeviceSize(context.TODO(), "/dev/sdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != 1073741824 {
+		t.Errorf("invalid size: %d", size)
+	}
+	r.assertCommands(t, "blockdev --getsize64 /dev/sdb")
+}
+
+func TestGetFSUUID(t *testing.T) {
+	for _, tt := range []struct {
+		device string
+		out    string
+	}{
+		{"/dev/sdb", "3e6be9de-8139-11d1-9106-a43f08d823a6\n"},
+		{"/dev/sdc", "7d6f3b2b-0f7a-4a41-9c1e-0dcf9d6e4b8f\n"},
+	} {
+		out := tt.out
+		r := &testCommandRunner{
+			handler: func(args []string) (string, error) {
+				return out, nil
+			},
+		}
+		fs := &gofsutil.FS{RunCommand: r.run}
+		uuid, err := fs.GetFSUUID(context.TODO(), tt.device)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exp := tt.out[:len(tt.out)-1]; uuid != exp {
+			t.Errorf("invalid uuid: exp=%s, act=%s", exp, uuid)
+		}
+		r.assertCommands(t, "blkid -s UUID -o value "+tt.device)
+	}
+}
+
+func TestGetFSUUIDByUUIDFallback(t *testing.T) {
+	const uuid = "3e6be9de-8139-11d1-9106-a43f08d823a6"
+	devRoot := newTestDevRoot(t, uuid, "data")
+
+	r := &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			return "", &exec.Error{Name: "blkid", Err: exec.ErrNotFound}
+		},
+	}
+	fs := &gofsutil.FS{DevRoot: devRoot, RunCommand: r.run}
+	act, err := fs.GetFSUUID(context.TODO(), path.Join(devRoot, "sdb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if act != uuid {
+		t.Errorf("invalid uuid: exp=%s, act=%s", uuid, act)
+	}
+}
+
+func TestGetFSUUIDUnformatted(t *testing.T) {
+	devRoot := newTestDevRoot(t, "1234", "data")
+
+	// blkid exits with status 2 and no output for an unformatted device.
+	r := &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			return "", errors.New("exit status 2")
+		},
+	}
+	fs := &gofsutil.FS{DevRoot: devRoot, RunCommand: r.run}
+	uuid, err := fs.GetFSUUID(context.TODO(), path.Join(devRoot, "sdc"))
+	if err == nil {
+		t.Fatalf("expected error for unformatted device: uuid=%q", uuid)
+	}
+}
+
+// newTestDeviceAliases creates a temporary dev filesystem root with the
+// device-mapper device dm-0 and the aliases udev creates for it, the
+// logical volume "mapper/vg-lv" and "disk/by-id/dm-name-vg-lv".
+func newTestDeviceAliases(t *testing.T) string {
+	devRoot := t.TempDir()
+	if err := gofsutil.EvalSymlinks(context.TODO(), &devRoot); err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range []string{"mapper", "disk/by-id"} {
+		if err := os.MkdirAll(path.Join(devRoot, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ioutil.WriteFile(
+		path.Join(devRoot, "dm-0"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	for name, target := range map[string]string{
+		"mapper/vg-lv":             "../dm-0",
+		"disk/by-id/dm-name-vg-lv": "../../dm-0",
+	} {
+		if err := os.Symlink(target, path.Join(devRoot, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return devRoot
+}
+
+// newTestCanonicalRoots creates a temporary dev and sys filesystem root
+// with the disk sda, the multipath device dm-0 whose paths are sdc and
+// sdd, and the aliases udev creates for each of them. The multipath
+// device's node in the mapper directory is not a symlink.
+func newTestCanonicalRoots(t *testing.T) (string, string) {
+	root := t.TempDir()
+	if err := gofsutil.EvalSymlinks(context.TODO(), &root); err != nil {
+		t.Fatal(err)
+	}
+	devRoot, sysRoot := path.Join(root, "dev"), path.Join(root, "sys")
+
+	mkdirs := []string{
+		path.Join(devRoot, "disk", "by-id"),
+		path.Join(devRoot, "disk", "by-uuid"),
+		path.Join(devRoot, "disk", "by-path"),
+		path.Join(devRoot, "mapper"),
+		path.Join(sysRoot, "class", "block", "sda"),
+		path.Join(sysRoot, "class", "block", "sdc", "holders", "dm-0"),
+		path.Join(sysRoot, "class", "block", "sdd", "holders", "dm-0"),
+		path.Join(sysRoot, "class", "block", "dm-0", "dm"),
+	}
+	for _, d := range mkdirs {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files := map[string]string{
+		path.Join(devRoot, "sda"):                                  "",
+		path.Join(devRoot, "sdc"):                                  "",
+		path.Join(devRoot, "sdd"):                                  "",
+		path.Join(devRoot, "dm-0"):                                 "",
+		path.Join(devRoot, "mapper", "mpatha"):                     "",
+		path.Join(sysRoot, "class", "block", "dm-0", "dm", "uuid"): "mpath-3600a098038303053453f463045727a6b\n",
+	}
+	for p, data := range files {
+		if err := ioutil.WriteFile(p, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	links := map[string]string{
+		"disk/by-id/wwn-0x5000c500a1b2c3d4":                 "../../sda",
+		"disk/by-uuid/3e6be9de-8139-11d1-9106-a43f08d823a6": "../../sda",
+		"disk/by-path/pci-0000:00:1f.2-ata-1":               "../../sda",
+		"disk/by-id/dm-uuid-mpath-3600a0980383030":          "../../dm-0",
+		"disk/by-path/fc-0x500a0981-lun-0":                  "../../sdc",
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, path.Join(devRoot, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return devRoot, sysRoot
+}
+
+func TestCanonicalizeDevice(t *testing.T) {
+	devRoot, sysRoot := newTestCanonicalRoots(t)
+
+	r := &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			return "dm-0\n", nil
+		},
+	}
+	fs := &gofsutil.FS{DevRoot: devRoot, SysRoot: sysRoot, RunCommand: r.run}
+
+	for canonical, aliases := range map[string][]string{
+		"sda": {
+			"sda",
+			"disk/by-id/wwn-0x5000c500a1b2c3d4",
+			"disk/by-uuid/3e6be9de-8139-11d1-9106-a43f08d823a6",
+			"disk/by-path/pci-0000:00:1f.2-ata-1",
+		},
+		"dm-0": {
+			"dm-0",
+			"mapper/mpatha",
+			"disk/by-id/dm-uuid-mpath-3600a0980383030",
+			"sdc",
+			"sdd",
+			"disk/by-path/fc-0x500a0981-lun-0",
+		},
+	} {
+		exp := path.Join(devRoot, canonical)
+		for _, alias := range aliases {
+			act, err := fs.CanonicalizeDevice(
+				context.TODO(), path.Join(devRoot, alias))
+			if err != nil {
+				t.Errorf("%s: %v", alias, err)
+				continue
+			}
+			if act != exp {
+				t.Errorf("%s: exp=%s, act=%s", alias, exp, act)
+			}
+		}
+	}
+
+	// Only the device node that is not a symlink to a kernel device is
+	// resolved with lsblk.
+	r.assertCommands(t,
+		"lsblk -d -n -o KNAME "+path.Join(devRoot, "mapper", "mpatha"))
+}
+
+func TestCanonicalizeDeviceNotFound(t *testing.T) {
+	devRoot, sysRoot := newTestCanonicalRoots(t)
+
+	fs := &gofsutil.FS{DevRoot: devRoot, SysRoot: sysRoot}
+	_, err := fs.CanonicalizeDevice(
+		context.TODO(), path.Join(devRoot, "disk", "by-id", "missing"))
+	if !errors.Is(err, gofsutil.ErrDeviceNotFound) {
+		t.Errorf("expected ErrDeviceNotFound: %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+package gofsutil_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+func TestWaitForDevice(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := gofsutil.EvalSymlinks(context.TODO(), &dir); err != nil {
+		t.Fatal(err)
+	}
+
+	dev := path.Join(dir, "sdb")
+	byID := path.Join(dir, "by-id")
+	if err := os.Symlink(dev, byID); err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		ioutil.WriteFile(dev, nil, 0644)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for _, p := range []string{byID, dev} {
+		realPath, err := gofsutil.WaitForDevice(ctx, p, 10*time.Millisecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if realPath != dev {
+			t.Errorf("invalid device path: exp=%s, act=%s", dev, realPath)
+		}
+	}
+}
+
+func TestWaitForDeviceTimeout(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx, cancel := context.WithTimeout(
+		context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	_, err = gofsutil.WaitForDevice(
+		ctx, path.Join(dir, "sdb"), 10*time.Millisecond)
+	if err != context.DeadlineExceeded {
+		t.Errorf("expected deadline exceeded: %v", err)
+	}
+}
+
+func TestPartitionDevicePath(t *testing.T) {
+	tests := []struct {
+		device    string
+		partNum   int
+		partition string
+	}{
+		{"/dev/nvme0n1", 1, "/dev/nvme0n1p1"},
+		{"/dev/nvme10n2", 12, "/dev/nvme10n2p12"},
+		{"/dev/mmcblk0", 2, "/dev/mmcblk0p2"},
+		{"/dev/loop7", 1, "/dev/loop7p1"},
+		{"/dev/sda", 1, "/dev/sda1"},
+		{"/dev/sdab", 3, "/dev/sdab3"},
+		{"/dev/vda", 1, "/dev/vda1"},
+		{"/dev/xvdf", 2, "/dev/xvdf2"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.partition, func(st *testing.T) {
+			st.Parallel()
+			if act := gofsutil.PartitionDevicePath(
+				tt.device, tt.partNum); act != tt.partition {
+				st.Errorf("invalid partition: exp=%s, act=%s",
+					tt.partition, act)
+			}
+			if act := gofsutil.ParentDevicePath(
+				tt.partition); act != tt.device {
+				st.Errorf("invalid parent: exp=%s, act=%s", tt.device, act)
+			}
+		})
+	}
+}
+
+func TestParentDevicePathNotPartition(t *testing.T) {
+	for _, dev := range []string{
+		"/dev/nvme0n1",
+		"/dev/mmcblk0",
+		"/dev/loop0",
+		"/dev/sda",
+		"/dev/sdp",
+		"/dev/vda",
+		"/dev/xvda",
+		"/dev/dm-0",
+		"/dev/sr0",
+		"/dev/md127",
+		"/dev/mapper/vg-lv1",
+		"/dev/zd16",
+	} {
+		if act := gofsutil.ParentDevicePath(dev); act != dev {
+			t.Errorf("invalid parent: exp=%s, act=%s", dev, act)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package gofsutil
+
+import "context"
+
+// MountDiff describes the differences between a mount and a desired
+// mount spec.
+type MountDiff struct {
+	// MissingOpts are the desired options that are absent from the
+	// mount's per-mount and per super block options.
+	MissingOpts []string
+
+	// ExtraOpts are the mount's per-mount options that were not desired.
+	ExtraOpts []string
+
+	// SourceChanged is true if the mount's source differs from the
+	// desired source.
+	SourceChanged bool
+
+	// FSTypeChanged is true if the mount's filesystem type differs from
+	// the desired type.
+	FSTypeChanged bool
+}
+
+// IsEmpty returns a flag indicating whether the diff has no differences.
+func (d MountDiff) IsEmpty() bool {
+	return len(d.MissingOpts) == 0 && len(d.ExtraOpts) == 0 &&
+		!d.SourceChanged && !d.FSTypeChanged
+}
+
+// diffMount returns the differences between the mount at target and the
+// desired mount spec
+func (fs *FS) diffMount(
+	ctx context.Context, target string, desired Info) (MountDiff, error) {
+
+	m, err := fs.getTopMount(ctx, target)
+	if err != nil {
+		return MountDiff{}, err
+	}
+	return diffMountInfo(m, desired), nil
+}
+
+// diffMountInfo returns the differences between the mount m and the
+// desired mount spec.
+//
+// Filesystem specific options, ex. "discard", are often reported only
+// with the super block options, so a desired option is missing only if
+// it is absent from both the per-mount and per super block options.
+// Options interpreted by userspace, ex. "_netdev", are never reported by
+// the kernel and are ignored, as are the options in
+// KernelDefaultMountOptions.
+func diffMountInfo(m, desired Info) MountDiff {
+	opts, _ := SplitMountOptions(desired.Opts)
+	actual := append(
+		append([]string(nil), m.Opts...), m.SuperOpts...)
+
+	var d MountDiff
+	d.MissingOpts, _ = CompareMountOptions(opts, actual)
+	_, d.ExtraOpts = CompareMountOptions(opts, m.Opts)
+	d.SourceChanged = desired.Device != "" &&
+		canonicalMountDevice(desired.Device) != canonicalMountDevice(m.Device)
+	d.FSTypeChanged = desired.Type != "" && desired.Type != m.Type
+	return d
+}
@@ -0,0 +1,88 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+func TestDiffMount(t *testing.T) {
+	ctx := gofsutil.WithMountTable(context.TODO(), []gofsutil.Info{
+		{
+			Device:    "/dev/sdb",
+			Path:      "/mnt/data",
+			Type:      "ext4",
+			Opts:      []string{"rw", "nosuid", "relatime"},
+			SuperOpts: []string{"rw", "discard", "errors=remount-ro"},
+		},
+	})
+	fs := &gofsutil.FS{}
+
+	tests := []struct {
+		name    string
+		desired gofsutil.Info
+		exp     gofsutil.MountDiff
+	}{
+		{
+			name: "equal",
+			desired: gofsutil.Info{
+				Device: "/dev/sdb",
+				Type:   "ext4",
+				Opts:   []string{"defaults", "nosuid", "discard", "_netdev"},
+			},
+		},
+		{
+			name: "options",
+			desired: gofsutil.Info{
+				Device: "/dev/sdb",
+				Type:   "ext4",
+				Opts:   []string{"rw", "nodev", "relatime"},
+			},
+			exp: gofsutil.MountDiff{
+				MissingOpts: []string{"nodev"},
+				ExtraOpts:   []string{"nosuid"},
+			},
+		},
+		{
+			name: "source",
+			desired: gofsutil.Info{
+				Device: "/dev/sdc",
+				Opts:   []string{"nosuid"},
+			},
+			exp: gofsutil.MountDiff{SourceChanged: true},
+		},
+		{
+			name: "fstype",
+			desired: gofsutil.Info{
+				Device: "/dev/sdb",
+				Type:   "xfs",
+				Opts:   []string{"nosuid"},
+			},
+			exp: gofsutil.MountDiff{FSTypeChanged: true},
+		},
+	}
+	for _, tt := range tests {
+		act, err := fs.DiffMount(ctx, "/mnt/data", tt.desired)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(act, tt.exp) {
+			t.Errorf("%s: invalid diff: exp=%+v, act=%+v",
+				tt.name, tt.exp, act)
+		}
+		if act.IsEmpty() != reflect.DeepEqual(tt.exp, gofsutil.MountDiff{}) {
+			t.Errorf("%s: invalid IsEmpty: %v", tt.name, act.IsEmpty())
+		}
+	}
+}
+
+func TestDiffMountNotMounted(t *testing.T) {
+	ctx := gofsutil.WithMountTable(context.TODO(), nil)
+	_, err := (&gofsutil.FS{}).DiffMount(ctx, "/mnt/data", gofsutil.Info{})
+	if !errors.Is(err, gofsutil.ErrNotMounted) {
+		t.Errorf("expected ErrNotMounted: %v", err)
+	}
+}
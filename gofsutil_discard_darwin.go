@@ -0,0 +1,23 @@
+package gofsutil
+
+import "context"
+
+// discardDevice discards all of the blocks on device, which must not
+// be mounted
+func (fs *FS) discardDevice(ctx context.Context, device string) error {
+	return ErrNotImplemented
+}
+
+// trimMount discards the unused blocks of the filesystem mounted at
+// target
+func (fs *FS) trimMount(ctx context.Context, target string) error {
+	return ErrNotImplemented
+}
+
+// supportsDiscard returns a flag indicating whether device supports
+// discard
+func (fs *FS) supportsDiscard(
+	ctx context.Context, device string) (bool, error) {
+
+	return false, ErrNotImplemented
+}
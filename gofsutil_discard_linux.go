@@ -0,0 +1,138 @@
+package gofsutil
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// discardDevice discards all of the blocks on device. Neither device
+// nor any of its partitions may be mounted or held by another device.
+func (fs *FS) discardDevice(ctx context.Context, device string) error {
+	device = evalSymlinksOrPath(fs.hostDevPath(device))
+
+	mounts, err := fs.getDiskMounts(ctx, device)
+	if err != nil {
+		return err
+	}
+	if len(mounts) > 0 {
+		return fmt.Errorf(
+			"refusing to discard %s: %s mounted at %s: %w",
+			device, mounts[0].Device, mounts[0].Path, ErrAlreadyMounted)
+	}
+
+	parts, err := fs.getPartitions(ctx, device)
+	if err != nil {
+		return err
+	}
+	for _, dev := range append([]string{device}, parts...) {
+		holders, err := fs.getHolders(ctx, dev)
+		if err != nil {
+			return err
+		}
+		if len(holders) > 0 {
+			return fmt.Errorf(
+				"refusing to discard %s: %s held by %s: %w",
+				device, dev, strings.Join(holders, ","), ErrDeviceBusy)
+		}
+	}
+
+	return fs.execDiscardCmd(ctx, "blkdiscard", device)
+}
+
+// trimMount discards the unused blocks of the filesystem mounted at
+// target
+func (fs *FS) trimMount(ctx context.Context, target string) error {
+	return fs.execDiscardCmd(ctx, "fstrim", target)
+}
+
+func (fs *FS) execDiscardCmd(
+	ctx context.Context, name string, args ...string) error {
+
+	f := log.Fields{
+		"cmd":  name,
+		"args": args,
+	}
+	log.WithFields(f).Info("discard command")
+
+	buf, err := fs.exec(ctx, name, args...)
+	if err != nil {
+		out := string(buf)
+		log.WithFields(f).WithField("output", out).WithError(
+			err).Error("discard failed")
+		return fmt.Errorf(
+			"%s failed: %v\narguments: %v\noutput: %s", name, err, args, out)
+	}
+	return nil
+}
+
+// supportsDiscard returns a flag indicating whether device supports
+// discard. A partition supports discard if the disk that contains it
+// does, since a partition has no request queue of its own.
+func (fs *FS) supportsDiscard(
+	ctx context.Context, device string) (bool, error) {
+
+	name := fs.sysBlockDiskName(device)
+	p := fs.sysPath("block", name, "queue", "discard_max_bytes")
+	buf, err := ioutil.ReadFile(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, fmt.Errorf("%s: %w", device, ErrDeviceNotFound)
+		}
+		return false, err
+	}
+	n, err := strconv.ParseUint(strings.TrimSpace(string(buf)), 10, 64)
+	if err != nil {
+		return false, fmt.Errorf("invalid discard_max_bytes: %s: %v", p, err)
+	}
+	log.WithFields(logFields(ctx, log.Fields{
+		"device":          device,
+		"discardMaxBytes": n,
+	})).Debug("read discard support")
+	return n > 0, nil
+}
+
+// makeNoDiscardArgs returns mkfsOpts with the arguments that prevent
+// the mkfs command for fsType from discarding the blocks of the device.
+// The mkfs command is run with the FS's defaults for fsType followed by
+// mkfsOpts, and mke2fs uses only the last -E argument, so the nodiscard
+// extended option of the ext family is joined with the last extended
+// options in either, with those of the defaults carried into mkfsOpts.
+func makeNoDiscardArgs(fsType string, defaults, mkfsOpts []string) []string {
+	switch {
+	case isExtFS(fsType):
+		args := append([]string(nil), mkfsOpts...)
+		if i, _ := lastExtendedOpts(args); i >= 0 {
+			args[i] += ",nodiscard"
+			return args
+		}
+		extOpts := "nodiscard"
+		if i, v := lastExtendedOpts(defaults); i >= 0 {
+			extOpts = v + ",nodiscard"
+		}
+		return append(args, "-E", extOpts)
+	case fsType == "xfs", fsType == "btrfs":
+		return append([]string{"-K"}, mkfsOpts...)
+	}
+	return mkfsOpts
+}
+
+// lastExtendedOpts returns the index and value of the last extended
+// options of mke2fs in args, either "-E opts" or "-Eopts", or -1 if
+// args has no extended options.
+func lastExtendedOpts(args []string) (int, string) {
+	for i := len(args) - 1; i >= 0; i-- {
+		switch {
+		case args[i] == "-E" && i < len(args)-1:
+			return i + 1, args[i+1]
+		case strings.HasPrefix(args[i], "-E") && len(args[i]) > 2:
+			return i, args[i][2:]
+		}
+	}
+	return -1, ""
+}
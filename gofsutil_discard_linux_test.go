@@ -0,0 +1,238 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+const discardMountInfoData = `60 1 253:0 / / rw,relatime shared:1 - xfs /dev/mapper/cl-root rw,seclabel,attr2,inode64,noquota
+72 60 8:16 / /mnt/data rw,relatime shared:28 - ext4 /dev/sdb rw,data=ordered
+`
+
+func TestDiscardDevice(t *testing.T) {
+	procRoot := newTestProcRoot(t, discardMountInfoData, "self")
+	sysRoot := newTestDiscardSysRoot(t)
+
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{
+		ProcRoot:   procRoot,
+		SysRoot:    sysRoot,
+		RunCommand: r.run,
+	}
+
+	if err := fs.DiscardDevice(context.TODO(), "/dev/sdc"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t, "blkdiscard /dev/sdc")
+}
+
+func TestDiscardDeviceMounted(t *testing.T) {
+	procRoot := newTestProcRoot(t, discardMountInfoData, "self")
+
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{ProcRoot: procRoot, RunCommand: r.run}
+
+	err := fs.DiscardDevice(context.TODO(), "/dev/sdb")
+	if !errors.Is(err, gofsutil.ErrAlreadyMounted) {
+		t.Errorf("expected ErrAlreadyMounted: %v", err)
+	}
+	r.assertCommands(t)
+}
+
+func TestDiscardDeviceInUse(t *testing.T) {
+	devRoot := newTestDeviceAliases(t)
+	sysRoot := newTestDiscardSysRoot(t)
+
+	tests := []struct {
+		name      string
+		dev       string
+		mountInfo string
+		holder    string
+		err       error
+	}{
+		{
+			name: "mapper alias",
+			dev:  path.Join(devRoot, "dm-0"),
+			mountInfo: "72 60 253:0 / /mnt/data rw,relatime shared:28 - " +
+				"ext4 /dev/mapper/vg-lv rw\n",
+			err: gofsutil.ErrAlreadyMounted,
+		},
+		{
+			name: "mounted partition",
+			dev:  path.Join(devRoot, "sdb"),
+			mountInfo: "72 60 8:17 / /mnt/data rw,relatime shared:28 - " +
+				"ext4 /dev/sdb1 rw\n",
+			err: gofsutil.ErrAlreadyMounted,
+		},
+		{
+			name:   "held partition",
+			dev:    path.Join(devRoot, "sdb"),
+			holder: "devices/sdb/sdb1/holders/dm-1",
+			err:    gofsutil.ErrDeviceBusy,
+		},
+	}
+
+	for _, tt := range tests {
+		if tt.holder != "" {
+			holder := path.Join(sysRoot, tt.holder)
+			if err := os.MkdirAll(path.Dir(holder), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := ioutil.WriteFile(holder, nil, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		procRoot := newTestProcRoot(t, tt.mountInfo, "self")
+
+		r := &testCommandRunner{}
+		fs := &gofsutil.FS{
+			DevRoot:    devRoot,
+			ProcRoot:   procRoot,
+			SysRoot:    sysRoot,
+			RunCommand: r.run,
+		}
+		err := fs.DiscardDevice(context.TODO(), tt.dev)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: expected %v: %v", tt.name, tt.err, err)
+		}
+		r.assertCommands(t)
+	}
+}
+
+func TestTrimMount(t *testing.T) {
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	if err := fs.TrimMount(context.TODO(), "/mnt/data"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t, "fstrim /mnt/data")
+}
+
+// newTestDiscardSysRoot creates a temporary sys filesystem root in which
+// the disk sdb supports discard, the disk sdc does not, and sdb1 is a
+// partition of sdb.
+func newTestDiscardSysRoot(t *testing.T) string {
+	sysRoot := t.TempDir()
+	for dev, max := range map[string]string{"sdb": "2147450880", "sdc": "0"} {
+		queue := path.Join(sysRoot, "devices", dev, "queue")
+		if err := os.MkdirAll(queue, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(
+			path.Join(queue, "discard_max_bytes"),
+			[]byte(max+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	part := path.Join(sysRoot, "devices", "sdb", "sdb1")
+	if err := os.MkdirAll(part, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(
+		path.Join(part, "partition"), []byte("1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range []string{"block", "class/block"} {
+		if err := os.MkdirAll(path.Join(sysRoot, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	links := map[string]string{
+		"block/sdb":        "../devices/sdb",
+		"block/sdc":        "../devices/sdc",
+		"class/block/sdb":  "../../devices/sdb",
+		"class/block/sdc":  "../../devices/sdc",
+		"class/block/sdb1": "../../devices/sdb/sdb1",
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, path.Join(sysRoot, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return sysRoot
+}
+
+func TestSupportsDiscard(t *testing.T) {
+	sysRoot := newTestDiscardSysRoot(t)
+
+	fs := &gofsutil.FS{SysRoot: sysRoot}
+	for dev, exp := range map[string]bool{
+		"/dev/sdb":  true,
+		"/dev/sdb1": true,
+		"/dev/sdc":  false,
+	} {
+		act, err := fs.SupportsDiscard(context.TODO(), dev)
+		if err != nil {
+			t.Errorf("%s: %v", dev, err)
+			continue
+		}
+		if act != exp {
+			t.Errorf("%s: exp=%v, act=%v", dev, exp, act)
+		}
+	}
+
+	_, err := fs.SupportsDiscard(context.TODO(), "/dev/sdd")
+	if !errors.Is(err, gofsutil.ErrDeviceNotFound) {
+		t.Errorf("expected ErrDeviceNotFound: %v", err)
+	}
+}
+
+func TestFormatAndMountAutoNoDiscard(t *testing.T) {
+	sysRoot := newTestDiscardSysRoot(t)
+
+	for _, tt := range []struct {
+		dev      string
+		fsType   string
+		defaults []string
+		mkfsOpts []string
+		mkfsCmd  string
+	}{
+		{"/dev/sdb", "ext4", nil, nil, "mkfs.ext4 -F -E nodiscard /dev/sdb"},
+		{"/dev/sdb", "ext4", nil, []string{"-E", "lazy_itable_init=0"},
+			"mkfs.ext4 -F -E lazy_itable_init=0,nodiscard /dev/sdb"},
+
+		// mke2fs uses only the last -E argument.
+		{"/dev/sdb", "ext4", nil,
+			[]string{"-E", "stride=16", "-m", "1", "-Estripe_width=32"},
+			"mkfs.ext4 -F -E stride=16 -m 1 " +
+				"-Estripe_width=32,nodiscard /dev/sdb"},
+		{"/dev/sdb", "ext4", []string{"-E", "lazy_itable_init=0"}, nil,
+			"mkfs.ext4 -F -E lazy_itable_init=0 " +
+				"-E lazy_itable_init=0,nodiscard /dev/sdb"},
+		{"/dev/sdb", "ext4", []string{"-E", "lazy_itable_init=0"},
+			[]string{"-E", "stride=16"},
+			"mkfs.ext4 -F -E lazy_itable_init=0 " +
+				"-E stride=16,nodiscard /dev/sdb"},
+		{"/dev/sdb", "ext4", []string{"-m", "1"}, []string{"-L", "data"},
+			"mkfs.ext4 -F -m 1 -L data -E nodiscard /dev/sdb"},
+		{"/dev/sdb", "xfs", nil, nil, "mkfs.xfs -K /dev/sdb"},
+		{"/dev/sdc", "ext4", nil, nil, "mkfs.ext4 -F /dev/sdc"},
+	} {
+		r := newTestFormatRunner("")
+		fs := &gofsutil.FS{
+			ProcRoot:     newTestRootProcRoot(t),
+			SysRoot:      sysRoot,
+			RunCommand:   r.run,
+			MkfsDefaults: map[string][]string{tt.fsType: tt.defaults},
+		}
+		if err := fs.FormatAndMountWithOpts(
+			context.TODO(), tt.dev, "/mnt", tt.fsType,
+			gofsutil.FormatOptions{
+				MkfsOptions:   tt.mkfsOpts,
+				AutoNoDiscard: true,
+			}); err != nil {
+			t.Fatal(err)
+		}
+		if cmds := r.commands(); cmds[2] != tt.mkfsCmd {
+			t.Errorf("%s %s: invalid mkfs command: exp=%q, act=%q",
+				tt.dev, tt.fsType, tt.mkfsCmd, cmds[2])
+		}
+	}
+}
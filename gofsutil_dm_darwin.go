@@ -0,0 +1,11 @@
+package gofsutil
+
+import "context"
+
+// unmountAndRemoveMapping unmounts target and then removes the
+// device-mapper mapping mapName
+func (fs *FS) unmountAndRemoveMapping(
+	ctx context.Context, target, mapName string) error {
+
+	return ErrNotImplemented
+}
@@ -0,0 +1,101 @@
+package gofsutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// dmErrors maps the output of a failed dmsetup or cryptsetup command to
+// the error that describes the failure.
+var dmErrors = []cmdError{
+	{regexp.MustCompile(`(?i)no such device`), ErrDeviceNotFound},
+	{regexp.MustCompile(`(?i)does not exist`), ErrDeviceNotFound},
+	{regexp.MustCompile(`(?i)doesn't exist`), ErrDeviceNotFound},
+	{regexp.MustCompile(`(?i)is not active`), ErrDeviceNotFound},
+}
+
+// unmountAndRemoveMapping unmounts target and then removes the
+// device-mapper mapping mapName. The mapping is only removed once the
+// target is no longer mounted.
+func (fs *FS) unmountAndRemoveMapping(
+	ctx context.Context, target, mapName string) error {
+
+	if mapName == "" || strings.Contains(mapName, "/") {
+		return fmt.Errorf("invalid mapping name: %q", mapName)
+	}
+
+	if err := fs.unmount(ctx, target); err != nil &&
+		!errors.Is(err, ErrNotMounted) {
+		return err
+	}
+
+	f := log.Fields{
+		"target":  target,
+		"mapName": mapName,
+	}
+
+	// The mapping's table identifies whether it is a dm-crypt mapping,
+	// which is closed with cryptsetup so that its key is also wiped.
+	buf, err := fs.execDMCmd(ctx, "dmsetup", "table", mapName)
+	if err != nil {
+		if errors.Is(err, ErrDeviceNotFound) {
+			log.WithFields(f).Info("mapping already removed")
+			return nil
+		}
+		return err
+	}
+
+	if isCryptTable(string(buf)) {
+		_, err = fs.execDMCmd(ctx, "cryptsetup", "close", mapName)
+	} else {
+		_, err = fs.execDMCmd(ctx, "dmsetup", "remove", mapName)
+	}
+	if err != nil {
+		if errors.Is(err, ErrDeviceNotFound) {
+			log.WithFields(f).Info("mapping already removed")
+			return nil
+		}
+		return err
+	}
+	return nil
+}
+
+// isCryptTable returns a flag indicating whether the output of
+// 'dmsetup table' describes a dm-crypt mapping. Each line of the output
+// is "<start> <length> <target> <args...>".
+func isCryptTable(table string) bool {
+	for _, line := range strings.Split(table, "\n") {
+		if fields := strings.Fields(line); len(fields) > 2 &&
+			fields[2] == "crypt" {
+			return true
+		}
+	}
+	return false
+}
+
+// execDMCmd runs one of the device-mapper commands
+func (fs *FS) execDMCmd(
+	ctx context.Context, name string, args ...string) ([]byte, error) {
+
+	f := log.Fields{
+		"cmd":  name,
+		"args": args,
+	}
+	log.WithFields(f).Info("device-mapper command")
+
+	buf, err := fs.exec(ctx, name, args...)
+	if err != nil {
+		out := string(buf)
+		log.WithFields(f).WithField("output", out).WithError(
+			err).Error("device-mapper command failed")
+		return nil, fmt.Errorf(
+			"%s failed: %w\narguments: %v\noutput: %s",
+			name, wrapCmdError(err, out, dmErrors), args, out)
+	}
+	return buf, nil
+}
@@ -0,0 +1,100 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+// newTestDMRunner returns a command runner for a device-mapper mapping
+// with the provided table. An empty table indicates the mapping does
+// not exist.
+func newTestDMRunner(table string, mounted bool) *testCommandRunner {
+	return &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			switch {
+			case args[0] == "umount" && !mounted:
+				return "umount: /mnt: not mounted.",
+					errors.New("exit status 32")
+			case args[0] == "umount":
+				mounted = false
+			case args[0] == "dmsetup" && table == "":
+				return "Device does not exist.\nCommand failed.",
+					errors.New("exit status 1")
+			case args[0] == "dmsetup" && args[1] == "table":
+				return table, nil
+			case args[0] == "dmsetup", args[0] == "cryptsetup":
+				if mounted {
+					return "Device or resource busy",
+						errors.New("exit status 1")
+				}
+				table = ""
+			}
+			return "", nil
+		},
+	}
+}
+
+func TestUnmountAndRemoveMappingLinear(t *testing.T) {
+	r := newTestDMRunner("0 2097152 linear 8:16 0\n", true)
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	if err := fs.UnmountAndRemoveMapping(
+		context.TODO(), "/mnt", "data"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t,
+		"umount /mnt",
+		"dmsetup table data",
+		"dmsetup remove data")
+}
+
+func TestUnmountAndRemoveMappingCrypt(t *testing.T) {
+	r := newTestDMRunner(
+		"0 2093056 crypt aes-xts-plain64 :64:logon:cryptsetup:1 0 8:16 4096\n",
+		true)
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	if err := fs.UnmountAndRemoveMapping(
+		context.TODO(), "/mnt", "luks-data"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t,
+		"umount /mnt",
+		"dmsetup table luks-data",
+		"cryptsetup close luks-data")
+}
+
+func TestUnmountAndRemoveMappingIdempotent(t *testing.T) {
+	r := newTestDMRunner("", false)
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	if err := fs.UnmountAndRemoveMapping(
+		context.TODO(), "/mnt", "data"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t,
+		"umount /mnt",
+		"dmsetup table data")
+}
+
+func TestUnmountAndRemoveMappingUnmountFailed(t *testing.T) {
+	r := &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			if args[0] == "umount" {
+				return "umount: /mnt: target is busy.",
+					errors.New("exit status 32")
+			}
+			return "", nil
+		},
+	}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	if err := fs.UnmountAndRemoveMapping(
+		context.TODO(), "/mnt", "data"); err == nil {
+		t.Fatal("expected unmount error")
+	}
+	r.assertCommands(t, "umount /mnt")
+}
@@ -0,0 +1,70 @@
+package gofsutil
+
+import (
+	"path"
+	"strings"
+)
+
+// Equal returns a flag indicating whether i and other describe the same
+// mount. The Device fields are compared after their symlinks are
+// evaluated, so a mount of "/dev/disk/by-uuid/<uuid>" equals a mount of
+// the device to which the link points. The Path and Root fields are
+// compared after they are cleaned, and an empty Root equals "/". The
+// Opts fields are compared without regard to order or duplicates, and if
+// ignoreKernelDefaults is true then the options in
+// KernelDefaultMountOptions are ignored, ex. a desired spec without
+// "relatime" equals a mount with it. The other fields are not compared.
+func (i Info) Equal(other Info, ignoreKernelDefaults bool) bool {
+	if i.Type != other.Type {
+		return false
+	}
+	if path.Clean(i.Path) != path.Clean(other.Path) {
+		return false
+	}
+	if normalizeMountRoot(i.Root) != normalizeMountRoot(other.Root) {
+		return false
+	}
+	if canonicalMountDevice(i.Device) != canonicalMountDevice(other.Device) {
+		return false
+	}
+	return equalMountOptions(i.Opts, other.Opts, ignoreKernelDefaults)
+}
+
+// normalizeMountRoot returns the cleaned root of a mount, or "/" if
+// root is empty
+func normalizeMountRoot(root string) string {
+	if root == "" {
+		return "/"
+	}
+	return path.Clean(root)
+}
+
+// canonicalMountDevice returns device with its symlinks evaluated if it
+// is a path. Other devices, ex. "host:/export", are returned as-is.
+func canonicalMountDevice(device string) string {
+	if !strings.HasPrefix(device, "/") {
+		return device
+	}
+	return evalSymlinksOrPath(path.Clean(device))
+}
+
+// equalMountOptions returns a flag indicating whether a and b contain the
+// same options
+func equalMountOptions(a, b []string, ignoreKernelDefaults bool) bool {
+	if ignoreKernelDefaults {
+		missing, extra := CompareMountOptions(a, b)
+		return len(missing) == 0 && len(extra) == 0
+	}
+	setA, setB := toStringSet(a), toStringSet(b)
+	delete(setA, "")
+	delete(setB, "")
+	if len(setA) != len(setB) {
+		return false
+	}
+	for o := range setA {
+		if _, ok := setB[o]; !ok {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,100 @@
+package gofsutil_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+func TestInfoEqual(t *testing.T) {
+	base := gofsutil.Info{
+		Device: "/dev/sdb",
+		Path:   "/mnt/data",
+		Type:   "ext4",
+		Root:   "/",
+		Opts:   []string{"rw", "relatime", "nosuid", "nodev"},
+	}
+	with := func(f func(i *gofsutil.Info)) gofsutil.Info {
+		i := base
+		i.Opts = append([]string(nil), base.Opts...)
+		f(&i)
+		return i
+	}
+
+	for _, tt := range []struct {
+		name                 string
+		other                gofsutil.Info
+		ignoreKernelDefaults bool
+		equal                bool
+	}{
+		{"identical", base, false, true},
+		{"reordered options", with(func(i *gofsutil.Info) {
+			i.Opts = []string{"nodev", "nosuid", "relatime", "rw"}
+		}), false, true},
+		{"duplicate options", with(func(i *gofsutil.Info) {
+			i.Opts = append(i.Opts, "nodev")
+		}), false, true},
+		{"kernel default ignored", with(func(i *gofsutil.Info) {
+			i.Opts = []string{"nosuid", "nodev"}
+		}), true, true},
+		{"kernel default not ignored", with(func(i *gofsutil.Info) {
+			i.Opts = []string{"nosuid", "nodev"}
+		}), false, false},
+		{"different option", with(func(i *gofsutil.Info) {
+			i.Opts = []string{"rw", "relatime", "nosuid", "noexec"}
+		}), true, false},
+		{"different fstype", with(func(i *gofsutil.Info) {
+			i.Type = "xfs"
+		}), true, false},
+		{"different path", with(func(i *gofsutil.Info) {
+			i.Path = "/mnt/other"
+		}), true, false},
+		{"uncleaned path", with(func(i *gofsutil.Info) {
+			i.Path = "/mnt/data/"
+		}), false, true},
+		{"empty root", with(func(i *gofsutil.Info) {
+			i.Root = ""
+		}), false, true},
+		{"different root", with(func(i *gofsutil.Info) {
+			i.Root = "/sub"
+		}), false, false},
+		{"different device", with(func(i *gofsutil.Info) {
+			i.Device = "/dev/sdc"
+		}), false, false},
+	} {
+		if act := base.Equal(tt.other, tt.ignoreKernelDefaults); act != tt.equal {
+			t.Errorf("%s: exp=%v, act=%v", tt.name, tt.equal, act)
+		}
+		if act := tt.other.Equal(base, tt.ignoreKernelDefaults); act != tt.equal {
+			t.Errorf("%s: reversed: exp=%v, act=%v", tt.name, tt.equal, act)
+		}
+	}
+}
+
+func TestInfoEqualDeviceSymlink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := gofsutil.EvalSymlinks(context.TODO(), &dir); err != nil {
+		t.Fatal(err)
+	}
+	dev, link := path.Join(dir, "sdb"), path.Join(dir, "by-uuid")
+	if err := ioutil.WriteFile(dev, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(dev, link); err != nil {
+		t.Fatal(err)
+	}
+
+	a := gofsutil.Info{Device: dev, Path: "/mnt/data", Type: "ext4"}
+	b := gofsutil.Info{Device: link, Path: "/mnt/data", Type: "ext4"}
+	if !a.Equal(b, false) {
+		t.Errorf("%s and %s: expected equal", dev, link)
+	}
+}
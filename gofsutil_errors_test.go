@@ -0,0 +1,79 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+// newTestErrorRunner returns a command runner that fails every command
+// with the provided output.
+func newTestErrorRunner(out string) *testCommandRunner {
+	return &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			return out, errors.New("exit status 32")
+		},
+	}
+}
+
+func TestErrNotMounted(t *testing.T) {
+	r := newTestErrorRunner("umount: /mnt: not mounted.\n")
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	err := fs.Unmount(context.TODO(), "/mnt")
+	if !errors.Is(err, gofsutil.ErrNotMounted) {
+		t.Errorf("expected ErrNotMounted: %v", err)
+	}
+}
+
+func TestErrAlreadyMounted(t *testing.T) {
+	r := newTestErrorRunner(
+		"mount: /mnt: /dev/sdb already mounted on /mnt.\n")
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	err := fs.Mount(context.TODO(), "/dev/sdb", "/mnt", "ext4")
+	if !errors.Is(err, gofsutil.ErrAlreadyMounted) {
+		t.Errorf("expected ErrAlreadyMounted: %v", err)
+	}
+}
+
+func TestErrDeviceNotFoundMount(t *testing.T) {
+	r := newTestErrorRunner(
+		"mount: /mnt: special device /dev/sdz does not exist.\n")
+	fs := &gofsutil.FS{
+		RunCommand: r.run,
+		ProcRoot:   newTestRootProcRoot(t),
+	}
+
+	err := fs.Mount(context.TODO(), "/dev/sdz", "/mnt", "ext4")
+	if !errors.Is(err, gofsutil.ErrDeviceNotFound) {
+		t.Errorf("expected ErrDeviceNotFound: %v", err)
+	}
+	if errors.Is(err, gofsutil.ErrAlreadyMounted) {
+		t.Errorf("unexpected ErrAlreadyMounted: %v", err)
+	}
+}
+
+func TestErrMountUnknown(t *testing.T) {
+	r := newTestErrorRunner("mount: /mnt: wrong fs type.\n")
+	fs := &gofsutil.FS{
+		RunCommand: r.run,
+		ProcRoot:   newTestRootProcRoot(t),
+	}
+
+	err := fs.Mount(context.TODO(), "/dev/sdb", "/mnt", "ext4")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for _, e := range []error{
+		gofsutil.ErrAlreadyMounted,
+		gofsutil.ErrDeviceNotFound,
+		gofsutil.ErrNotMounted,
+	} {
+		if errors.Is(err, e) {
+			t.Errorf("unexpected error match: %v: %v", e, err)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+func TestFormatAndMountExclusiveFormat(t *testing.T) {
+	img, err := ioutil.TempFile("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(img.Name())
+	if err := img.Truncate(8 << 20); err != nil {
+		t.Fatal(err)
+	}
+	img.Close()
+
+	out, err := exec.Command(
+		"losetup", "-f", "--show", img.Name()).CombinedOutput()
+	if err != nil {
+		t.Fatalf("losetup failed: %v: %s", err, out)
+	}
+	loopDevice := strings.TrimSpace(string(out))
+	defer exec.Command("losetup", "-d", loopDevice).Run()
+
+	r := newTestFormatRunner("")
+	fs := &gofsutil.FS{
+		RunCommand:      r.run,
+		ExclusiveFormat: true,
+		ProcRoot:        newTestRootProcRoot(t),
+	}
+
+	// Hold the device open exclusively, as mkfs or a mount would.
+	f, err := os.OpenFile(loopDevice, os.O_RDONLY|syscall.O_EXCL, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = fs.FormatAndMount(context.TODO(), loopDevice, "/mnt", "ext4")
+	f.Close()
+	if !errors.Is(err, gofsutil.ErrDeviceBusy) {
+		t.Fatalf("expected ErrDeviceBusy: %v", err)
+	}
+	r.assertCommands(t,
+		"mount -t ext4 -o defaults "+loopDevice+" /mnt",
+		"lsblk -J -o NAME,FSTYPE "+loopDevice)
+
+	// The device is formatted once it is no longer in use.
+	r = newTestFormatRunner("")
+	fs.RunCommand = r.run
+	if err := fs.FormatAndMount(
+		context.TODO(), loopDevice, "/mnt", "ext4"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t,
+		"mount -t ext4 -o defaults "+loopDevice+" /mnt",
+		"lsblk -J -o NAME,FSTYPE "+loopDevice,
+		"mkfs.ext4 -F "+loopDevice,
+		"mount -t ext4 -o defaults "+loopDevice+" /mnt")
+}
@@ -0,0 +1,194 @@
+package gofsutil
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"regexp"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// CommandRunFunc defines the signature of the function used to run the
+// commands executed by the functions in this package. The function must
+// run the provided command and wait for it to complete, writing the
+// command's output to the command's Stdout and Stderr writers.
+type CommandRunFunc func(ctx context.Context, cmd *exec.Cmd) error
+
+// DefaultCommandRunFunc returns the default command run function.
+func DefaultCommandRunFunc() CommandRunFunc {
+	return defaultCommandRunFunc
+}
+
+func defaultCommandRunFunc(ctx context.Context, cmd *exec.Cmd) error {
+	return cmd.Run()
+}
+
+// NsenterConfig configures the execution of commands in the mount
+// namespace of the host, ex. from a container that has the host's proc
+// filesystem mounted.
+type NsenterConfig struct {
+	// Enabled is a flag indicating whether commands are executed in the
+	// mount namespace of the host with nsenter.
+	Enabled bool
+
+	// HostProcPath is the path at which the host's proc filesystem is
+	// mounted. If empty then "/proc" is used.
+	HostProcPath string
+}
+
+// mountNamespacePath returns the path of the mount namespace of the
+// host's init process
+func (c NsenterConfig) mountNamespacePath() string {
+	procPath := c.HostProcPath
+	if procPath == "" {
+		procPath = defaultProcRoot
+	}
+	return path.Join(procPath, "1", "ns", "mnt")
+}
+
+// defaultCommandLocale is the locale in which the commands executed by
+// this package are run.
+const defaultCommandLocale = "LC_ALL=C"
+
+// exec runs the named program with the provided arguments using the
+// FS's command run function and returns the program's combined output.
+func (fs *FS) exec(
+	ctx context.Context, name string, args ...string) ([]byte, error) {
+
+	return fs.execProgress(ctx, nil, name, args...)
+}
+
+// execProgress behaves like exec but also invokes progress, if not nil,
+// with each line of the program's combined output as it is written.
+func (fs *FS) execProgress(
+	ctx context.Context,
+	progress func(line string),
+	name string, args ...string) ([]byte, error) {
+
+	cmdName := name
+	if fs.Nsenter.Enabled {
+		args = append(
+			[]string{"--mount=" + fs.Nsenter.mountNamespacePath(), "--", name},
+			args...)
+		name = "nsenter"
+	}
+
+	var (
+		buf bytes.Buffer
+		cmd = exec.CommandContext(ctx, name, args...)
+	)
+	cmd.Stdout = &buf
+	cmd.Stderr = &buf
+	if progress != nil {
+		w := &lineWriter{w: &buf, fn: progress}
+		defer w.flush()
+		cmd.Stdout = w
+		cmd.Stderr = w
+	}
+
+	// The C locale keeps the output of the commands stable for parsing.
+	// The environment is processed in order with later values taking
+	// precedence, so fs.Env may override the locale.
+	cmd.Env = append(os.Environ(), defaultCommandLocale)
+	cmd.Env = append(cmd.Env, fs.Env...)
+
+	log.WithFields(logFields(ctx, log.Fields{
+		"cmd":  name,
+		"args": args,
+	})).Debug("executing command")
+
+	runCommand := fs.RunCommand
+	if runCommand == nil {
+		runCommand = defaultCommandRunFunc
+	}
+	err := runCommand(ctx, cmd)
+
+	// nsenter exits with status 127 if the command is not installed in
+	// the host's mount namespace.
+	if fs.Nsenter.Enabled && exitCode(err) == nsenterExitNotFound {
+		err = &exec.Error{Name: cmdName, Err: exec.ErrNotFound}
+	}
+	return buf.Bytes(), err
+}
+
+// nsenterExitNotFound is the exit status of nsenter when the command it
+// was asked to run cannot be found.
+const nsenterExitNotFound = 127
+
+// exitCode returns the exit status of the command that failed with err,
+// or -1 if err does not describe a command that exited.
+func exitCode(err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
+
+// isCommandNotFound returns a flag indicating whether err indicates the
+// command could not be found.
+func isCommandNotFound(err error) bool {
+	if err == exec.ErrNotFound {
+		return true
+	}
+	if e, ok := err.(*exec.Error); ok {
+		return e.Err == exec.ErrNotFound
+	}
+	return false
+}
+
+// cmdError associates the output of a failed command with the error
+// that describes the failure.
+type cmdError struct {
+	rx  *regexp.Regexp
+	err error
+}
+
+// wrapCmdError returns err wrapped with the first of the cmdErrors that
+// matches the command's output. If none match then err is returned.
+func wrapCmdError(err error, out string, cmdErrors []cmdError) error {
+	for _, e := range cmdErrors {
+		if e.rx.MatchString(out) {
+			return fmt.Errorf("%w: %v", e.err, err)
+		}
+	}
+	return err
+}
+
+// lineWriter writes to w and invokes fn with each complete line written
+// to it. The trailing newline, and the carriage return that precedes it,
+// are removed from each line.
+type lineWriter struct {
+	w       io.Writer
+	fn      func(line string)
+	partial []byte
+}
+
+func (lw *lineWriter) Write(p []byte) (int, error) {
+	n, err := lw.w.Write(p)
+	lw.partial = append(lw.partial, p[:n]...)
+	for {
+		i := bytes.IndexByte(lw.partial, '\n')
+		if i < 0 {
+			break
+		}
+		lw.fn(string(bytes.TrimSuffix(lw.partial[:i], []byte{'\r'})))
+		lw.partial = lw.partial[i+1:]
+	}
+	return n, err
+}
+
+// flush invokes fn with the final line if it is not terminated by a
+// newline.
+func (lw *lineWriter) flush() {
+	if len(lw.partial) > 0 {
+		lw.fn(string(lw.partial))
+		lw.partial = nil
+	}
+}
@@ -0,0 +1,177 @@
+package gofsutil_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+// testCommandRunner is a gofsutil.CommandRunFunc that records the
+// commands it is asked to run instead of running them.
+type testCommandRunner struct {
+	sync.Mutex
+	cmds [][]string
+
+	// handler, if set, is invoked for each command and returns the
+	// command's output and error.
+	handler func(args []string) (string, error)
+}
+
+func (r *testCommandRunner) run(ctx context.Context, cmd *exec.Cmd) error {
+	r.Lock()
+	r.cmds = append(r.cmds, cmd.Args)
+	r.Unlock()
+	if r.handler == nil {
+		return nil
+	}
+	out, err := r.handler(cmd.Args)
+	io.WriteString(cmd.Stdout, out)
+	return err
+}
+
+// newTestExitError returns the error of a command that exited with the
+// provided status.
+func newTestExitError(t *testing.T, status int) error {
+	err := exec.Command("sh", "-c", fmt.Sprintf("exit %d", status)).Run()
+	if _, ok := err.(*exec.ExitError); !ok {
+		t.Fatalf("expected exit error: %v", err)
+	}
+	return err
+}
+
+// commands returns the recorded commands as space-separated strings.
+func (r *testCommandRunner) commands() []string {
+	r.Lock()
+	defer r.Unlock()
+	cmds := make([]string, len(r.cmds))
+	for i, c := range r.cmds {
+		cmds[i] = strings.Join(c, " ")
+	}
+	return cmds
+}
+
+// testRootMountInfoData is a mount table with only the root filesystem.
+const testRootMountInfoData = "1 0 8:1 / / rw,relatime shared:1 - ext4 /dev/sda1 rw\n"
+
+// newTestRootProcRoot returns the path of a proc filesystem root whose
+// mount table has only the root filesystem, so the mounts the tests fake
+// with a testCommandRunner do not depend on the host's mount table.
+func newTestRootProcRoot(t *testing.T) string {
+	procRoot := t.TempDir()
+	dir := path.Join(procRoot, "self")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path.Join(dir, "mountinfo"),
+		[]byte(testRootMountInfoData), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return procRoot
+}
+
+// assertCommands asserts the runner recorded the expected commands.
+func (r *testCommandRunner) assertCommands(t *testing.T, exp ...string) {
+	act := r.commands()
+	if len(act) != len(exp) {
+		t.Fatalf("invalid command count: exp=%d, act=%d: %q",
+			len(exp), len(act), act)
+	}
+	for i := range exp {
+		if act[i] != exp[i] {
+			t.Errorf("invalid command: i=%d, exp=%s, act=%s",
+				i, exp[i], act[i])
+		}
+	}
+}
+
+func TestCommandEnvLocale(t *testing.T) {
+	t.Setenv("LC_ALL", "de_DE.UTF-8")
+	t.Setenv("LANG", "de_DE.UTF-8")
+
+	var envs [][]string
+	fs := &gofsutil.FS{
+		RunCommand: func(ctx context.Context, cmd *exec.Cmd) error {
+			envs = append(envs, cmd.Env)
+			fmt.Fprintln(cmd.Stdout,
+				`{"blockdevices": [{"name":"sdb", "fstype":"ext4"}]}`)
+			return nil
+		},
+	}
+	if _, err := fs.GetDiskFormat(context.TODO(), "/dev/sdb"); err != nil {
+		t.Fatal(err)
+	}
+
+	fs.Env = []string{"LC_ALL=en_US.UTF-8", "CRYPTSETUP_DEBUG=1"}
+	if _, err := fs.GetDiskFormat(context.TODO(), "/dev/sdb"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(envs) != 2 {
+		t.Fatalf("invalid command count: %d", len(envs))
+	}
+	if v := lookupEnv(envs[0], "LC_ALL"); v != "C" {
+		t.Errorf("invalid LC_ALL: exp=C, act=%s", v)
+	}
+	if v := lookupEnv(envs[1], "LC_ALL"); v != "en_US.UTF-8" {
+		t.Errorf("invalid LC_ALL: exp=en_US.UTF-8, act=%s", v)
+	}
+	if v := lookupEnv(envs[1], "CRYPTSETUP_DEBUG"); v != "1" {
+		t.Errorf("invalid CRYPTSETUP_DEBUG: exp=1, act=%s", v)
+	}
+}
+
+// lookupEnv returns the value of key in env. As with exec.Cmd, the last
+// value for a key takes precedence.
+func lookupEnv(env []string, key string) string {
+	var v string
+	for _, kv := range env {
+		if strings.HasPrefix(kv, key+"=") {
+			v = strings.TrimPrefix(kv, key+"=")
+		}
+	}
+	return v
+}
+
+func TestCommandNsenter(t *testing.T) {
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{
+		ProcRoot:   newTestRootProcRoot(t),
+		RunCommand: r.run,
+		Nsenter: gofsutil.NsenterConfig{
+			Enabled:      true,
+			HostProcPath: "/host/proc",
+		},
+	}
+	if err := fs.Mount(
+		context.TODO(), "/dev/sdb", "/mnt", "ext4", "ro"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Unmount(context.TODO(), "/mnt"); err != nil {
+		t.Fatal(err)
+	}
+
+	fs.Nsenter.HostProcPath = ""
+	if err := fs.Unmount(context.TODO(), "/mnt"); err != nil {
+		t.Fatal(err)
+	}
+
+	fs.Nsenter.Enabled = false
+	if err := fs.Unmount(context.TODO(), "/mnt"); err != nil {
+		t.Fatal(err)
+	}
+
+	r.assertCommands(t,
+		"nsenter --mount=/host/proc/1/ns/mnt -- mount -t ext4 -o ro /dev/sdb /mnt",
+		"nsenter --mount=/host/proc/1/ns/mnt -- umount /mnt",
+		"nsenter --mount=/proc/1/ns/mnt -- umount /mnt",
+		"umount /mnt")
+}
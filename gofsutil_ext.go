@@ -0,0 +1,120 @@
+package gofsutil
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// minReservedBlocksPercent is the minimum percentage of an ext
+	// filesystem's blocks that may be reserved for the super-user.
+	minReservedBlocksPercent = 0
+
+	// maxReservedBlocksPercent is the maximum percentage of an ext
+	// filesystem's blocks that may be reserved for the super-user.
+	maxReservedBlocksPercent = 50
+)
+
+// validateReservedBlocksPercent returns an error if percent is not
+// within the range accepted by tune2fs.
+func validateReservedBlocksPercent(percent float64) error {
+	if percent < minReservedBlocksPercent ||
+		percent > maxReservedBlocksPercent {
+		return fmt.Errorf(
+			"invalid reserved blocks percent: %v: must be between %d and %d",
+			percent, minReservedBlocksPercent, maxReservedBlocksPercent)
+	}
+	return nil
+}
+
+// setReservedBlocksPercent sets the percentage of the ext filesystem's
+// blocks on device that are reserved for the super-user
+func (fs *FS) setReservedBlocksPercent(
+	ctx context.Context, device string, percent float64) error {
+
+	if err := validateReservedBlocksPercent(percent); err != nil {
+		return err
+	}
+	return fs.tune2fs(
+		ctx, device, "-m", strconv.FormatFloat(percent, 'f', -1, 64))
+}
+
+// tune2fs runs tune2fs on device with the provided arguments
+func (fs *FS) tune2fs(
+	ctx context.Context, device string, args ...string) error {
+
+	args = append(args, device)
+	f := log.Fields{
+		"device": device,
+		"args":   args,
+	}
+	log.WithFields(f).Info("tune2fs command")
+
+	buf, err := fs.exec(ctx, "tune2fs", args...)
+	if err != nil {
+		out := string(buf)
+		log.WithFields(f).WithField("output", out).WithError(
+			err).Error("tune2fs failed")
+		return fmt.Errorf(
+			"tune2fs failed: %v\narguments: %v\noutput: %s", err, args, out)
+	}
+	return nil
+}
+
+// isExtFS returns a flag indicating whether fsType is a member of the
+// ext filesystem family.
+func isExtFS(fsType string) bool {
+	switch fsType {
+	case "ext2", "ext3", "ext4":
+		return true
+	}
+	return false
+}
+
+// ext4OnlyFeatures are the incompatible and read-only compatible features
+// of an ext filesystem that ext2 and ext3 do not support. A filesystem
+// with any of these features cannot be mounted as ext2 or ext3.
+var ext4OnlyFeatures = map[string]struct{}{
+	"64bit":         {},
+	"bigalloc":      {},
+	"casefold":      {},
+	"csum_seed":     {},
+	"dir_nlink":     {},
+	"ea_inode":      {},
+	"encrypt":       {},
+	"extent":        {},
+	"extra_isize":   {},
+	"flex_bg":       {},
+	"huge_file":     {},
+	"inline_data":   {},
+	"journal_dev":   {},
+	"large_dir":     {},
+	"metadata_csum": {},
+	"mmp":           {},
+	"orphan_file":   {},
+	"project":       {},
+	"quota":         {},
+	"uninit_bg":     {},
+	"verity":        {},
+}
+
+// extFeaturesUnsupportedBy returns the features that prevent a filesystem
+// with the provided features from being mounted as fsType, a member of
+// the ext family. ext2 also cannot mount a filesystem with a journal
+// that needs recovery.
+func extFeaturesUnsupportedBy(fsType string, features []string) []string {
+	if fsType == "ext4" {
+		return nil
+	}
+	var unsupported []string
+	for _, f := range features {
+		_, ok := ext4OnlyFeatures[f]
+		if ok || (fsType == "ext2" && f == "needs_recovery") {
+			unsupported = append(unsupported, f)
+		}
+	}
+	return unsupported
+}
@@ -0,0 +1,27 @@
+package gofsutil
+
+import "context"
+
+// getExt4Features returns the features enabled on the ext filesystem on
+// device
+func (fs *FS) getExt4Features(
+	ctx context.Context, device string) ([]string, error) {
+
+	return nil, ErrNotImplemented
+}
+
+// getDefaultMountOptions returns the default mount options stored in the
+// superblock of the ext filesystem on device
+func (fs *FS) getDefaultMountOptions(
+	ctx context.Context, device string) ([]string, error) {
+
+	return nil, ErrNotImplemented
+}
+
+// setDefaultMountOptions sets and clears the default mount options stored
+// in the superblock of the ext filesystem on device
+func (fs *FS) setDefaultMountOptions(
+	ctx context.Context, device string, opts []string) error {
+
+	return ErrNotImplemented
+}
@@ -0,0 +1,149 @@
+package gofsutil
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// dumpe2fsErrors maps the output of a failed dumpe2fs command to the
+// error that describes the failure.
+var dumpe2fsErrors = []cmdError{
+	{regexp.MustCompile(`(?i)no such file or directory`),
+		ErrDeviceNotFound},
+	{regexp.MustCompile(`(?i)bad magic number in super-block`),
+		ErrNotImplemented},
+}
+
+// getExt4Features returns the features enabled on the ext filesystem on
+// device
+func (fs *FS) getExt4Features(
+	ctx context.Context, device string) ([]string, error) {
+
+	buf, err := fs.dumpe2fs(ctx, device)
+	if err != nil {
+		return nil, err
+	}
+
+	features, err := parseDumpe2fsField(buf, "Filesystem features")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", device, err)
+	}
+	if features == "(none)" {
+		return nil, nil
+	}
+	return strings.Fields(features), nil
+}
+
+// extMismatchError returns the error of a failure to mount the ext
+// filesystem on device, whose type is existingFormat, as fsType, another
+// member of the ext family. The features that prevent the filesystem
+// from being mounted as fsType are reported, and if there are none the
+// filesystem itself is suspect and should be checked.
+func (fs *FS) extMismatchError(
+	ctx context.Context,
+	device, fsType, existingFormat string,
+	mountErr error) error {
+
+	features, err := fs.getExt4Features(ctx, device)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"device": device,
+		}).WithError(err).Warn("failed to read ext filesystem features")
+	}
+	if unsupported := extFeaturesUnsupportedBy(fsType, features); len(
+		unsupported) > 0 {
+
+		return fmt.Errorf(
+			"failed to mount volume as %q; already contains %s with "+
+				"features not supported by %s: %s; mount it as %q "+
+				"instead: error: %v",
+			fsType, existingFormat, fsType,
+			strings.Join(unsupported, ","), existingFormat, mountErr)
+	}
+	return fmt.Errorf(
+		"failed to mount volume as %q; already contains %s, which %s "+
+			"should be able to mount; check the filesystem with "+
+			"'e2fsck -n %s': error: %v",
+		fsType, existingFormat, fsType, device, mountErr)
+}
+
+// getDefaultMountOptions returns the default mount options stored in the
+// superblock of the ext filesystem on device
+func (fs *FS) getDefaultMountOptions(
+	ctx context.Context, device string) ([]string, error) {
+
+	buf, err := fs.dumpe2fs(ctx, device)
+	if err != nil {
+		return nil, err
+	}
+
+	opts, err := parseDumpe2fsField(buf, "Default mount options")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", device, err)
+	}
+	if opts == "(none)" {
+		return nil, nil
+	}
+	return strings.Fields(opts), nil
+}
+
+// setDefaultMountOptions sets and clears the default mount options stored
+// in the superblock of the ext filesystem on device
+func (fs *FS) setDefaultMountOptions(
+	ctx context.Context, device string, opts []string) error {
+
+	arg, err := makeTune2fsMountOpts(opts)
+	if err != nil {
+		return err
+	}
+	return fs.tune2fs(ctx, device, "-o", arg)
+}
+
+// makeTune2fsMountOpts returns the argument of 'tune2fs -o' that sets
+// the options without a prefix or with a "+" prefix and clears the
+// options with a "^" or "-" prefix, ex. "+journal_data_writeback,^acl".
+func makeTune2fsMountOpts(opts []string) (string, error) {
+	if len(opts) == 0 {
+		return "", fmt.Errorf("invalid default mount options: none provided")
+	}
+	args := make([]string, len(opts))
+	for i, o := range opts {
+		prefix := "+"
+		switch {
+		case strings.HasPrefix(o, "^"), strings.HasPrefix(o, "-"):
+			prefix = "^"
+			o = o[1:]
+		case strings.HasPrefix(o, "+"):
+			o = o[1:]
+		}
+		if o == "" || strings.ContainsAny(o, ", \t\n+^") {
+			return "", fmt.Errorf(
+				"invalid default mount option: %q", opts[i])
+		}
+		args[i] = prefix + o
+	}
+	return strings.Join(args, ","), nil
+}
+
+// dumpe2fs returns the output of 'dumpe2fs -h' for device
+func (fs *FS) dumpe2fs(ctx context.Context, device string) ([]byte, error) {
+	f := log.Fields{
+		"device": device,
+	}
+	log.WithFields(f).Info("reading ext filesystem superblock")
+
+	buf, err := fs.exec(ctx, "dumpe2fs", "-h", device)
+	if err != nil {
+		out := string(buf)
+		log.WithFields(f).WithField("output", out).WithError(
+			err).Error("dumpe2fs failed")
+		return nil, fmt.Errorf(
+			"dumpe2fs failed: %w\ndevice: %s\noutput: %s",
+			wrapCmdError(err, out, dumpe2fsErrors), device, out)
+	}
+	return buf, nil
+}
@@ -0,0 +1,116 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+func TestGetExt4Features(t *testing.T) {
+	r := &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			return dumpe2fsCleanData, nil
+		},
+	}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	features, err := fs.GetExt4Features(context.TODO(), "/dev/sdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []string{
+		"has_journal", "ext_attr", "resize_inode", "dir_index",
+		"filetype", "extent", "64bit", "flex_bg", "sparse_super",
+		"large_file", "huge_file", "dir_nlink", "extra_isize",
+		"metadata_csum",
+	}
+	if !reflect.DeepEqual(features, exp) {
+		t.Errorf("invalid features: exp=%v, act=%v", exp, features)
+	}
+	r.assertCommands(t, "dumpe2fs -h /dev/sdb")
+}
+
+func TestGetExt4FeaturesNotExt(t *testing.T) {
+	r := newTestErrorRunner("dumpe2fs 1.46.5 (30-Dec-2021)\n" +
+		"dumpe2fs: Bad magic number in super-block while trying to " +
+		"open /dev/sdb\n" +
+		"Couldn't find valid filesystem superblock.\n" +
+		"/dev/sdb contains a xfs file system")
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	_, err := fs.GetExt4Features(context.TODO(), "/dev/sdb")
+	if !errors.Is(err, gofsutil.ErrNotImplemented) {
+		t.Errorf("expected ErrNotImplemented: %v", err)
+	}
+}
+
+func TestGetDefaultMountOptions(t *testing.T) {
+	r := &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			return dumpe2fsCleanData, nil
+		},
+	}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	opts, err := fs.GetDefaultMountOptions(context.TODO(), "/dev/sdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := []string{"user_xattr", "acl"}; !reflect.DeepEqual(opts, exp) {
+		t.Errorf("invalid options: exp=%v, act=%v", exp, opts)
+	}
+	r.assertCommands(t, "dumpe2fs -h /dev/sdb")
+}
+
+func TestGetDefaultMountOptionsNone(t *testing.T) {
+	r := &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			return "Default mount options:    (none)\n", nil
+		},
+	}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	opts, err := fs.GetDefaultMountOptions(context.TODO(), "/dev/sdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(opts) != 0 {
+		t.Errorf("unexpected options: %v", opts)
+	}
+}
+
+func TestSetDefaultMountOptions(t *testing.T) {
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	if err := fs.SetDefaultMountOptions(
+		context.TODO(), "/dev/sdb",
+		[]string{"journal_data_writeback", "^acl", "+user_xattr"},
+	); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t,
+		"tune2fs -o +journal_data_writeback,^acl,+user_xattr /dev/sdb")
+}
+
+func TestSetDefaultMountOptionsInvalid(t *testing.T) {
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	for _, opts := range [][]string{
+		nil,
+		{""},
+		{"^"},
+		{"acl,user_xattr"},
+		{"acl user_xattr"},
+	} {
+		if err := fs.SetDefaultMountOptions(
+			context.TODO(), "/dev/sdb", opts); err == nil {
+			t.Errorf("expected error: %q", opts)
+		}
+	}
+	r.assertCommands(t)
+}
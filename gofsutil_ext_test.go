@@ -0,0 +1,30 @@
+package gofsutil_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+func TestSetReservedBlocksPercent(t *testing.T) {
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	for _, p := range []float64{0, 0.5, 50} {
+		if err := fs.SetReservedBlocksPercent(
+			context.TODO(), "/dev/sdb", p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, p := range []float64{-1, 50.1, 100} {
+		if err := fs.SetReservedBlocksPercent(
+			context.TODO(), "/dev/sdb", p); err == nil {
+			t.Errorf("expected error for percent %v", p)
+		}
+	}
+	r.assertCommands(t,
+		"tune2fs -m 0 /dev/sdb",
+		"tune2fs -m 0.5 /dev/sdb",
+		"tune2fs -m 50 /dev/sdb")
+}
@@ -0,0 +1,50 @@
+package gofsutil
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// fatFSTypes are the filesystem types that do not store ownership or
+// permissions and are mounted with uid, gid, and umask options instead.
+var fatFSTypes = map[string]bool{
+	"exfat": true,
+	"fat":   true,
+	"msdos": true,
+	"vfat":  true,
+}
+
+// fatMountOptions returns the options that mount a fat filesystem with
+// files owned by uid and gid and permissions masked by mask.
+func fatMountOptions(uid, gid int, mask os.FileMode) []string {
+	return []string{
+		fmt.Sprintf("uid=%d", uid),
+		fmt.Sprintf("gid=%d", gid),
+		fmt.Sprintf("umask=%03o", mask.Perm()),
+	}
+}
+
+func (fs *FS) mountFAT(
+	ctx context.Context,
+	device, target string,
+	uid, gid int,
+	mask os.FileMode,
+	options ...string) error {
+
+	if uid < 0 || gid < 0 {
+		return fmt.Errorf("mountFAT: invalid uid=%d gid=%d", uid, gid)
+	}
+	fsType, err := fs.getDiskFormat(ctx, device)
+	if err != nil {
+		return err
+	}
+	if !fatFSTypes[fsType] {
+		return fmt.Errorf(
+			"mountFAT: unsupported fs type: device=%s, fsType=%q",
+			device, fsType)
+	}
+	opts := append(
+		append([]string{}, options...), fatMountOptions(uid, gid, mask)...)
+	return fs.Mount(ctx, device, target, fsType, opts...)
+}
@@ -0,0 +1,52 @@
+package gofsutil_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+func TestMountFAT(t *testing.T) {
+	r := newTestFormatRunner("vfat")
+	fs := &gofsutil.FS{
+		RunCommand: r.run,
+		ProcRoot:   newTestRootProcRoot(t),
+	}
+
+	if err := fs.MountFAT(
+		context.TODO(), "/dev/sdb", "/mnt", 1000, 2000, 0027,
+		"noexec"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t,
+		"lsblk -J -o NAME,FSTYPE /dev/sdb",
+		"mount -t vfat -o noexec,uid=1000,gid=2000,umask=027 /dev/sdb /mnt")
+}
+
+func TestMountFATExfat(t *testing.T) {
+	r := newTestFormatRunner("exfat")
+	fs := &gofsutil.FS{
+		RunCommand: r.run,
+		ProcRoot:   newTestRootProcRoot(t),
+	}
+
+	if err := fs.MountFAT(
+		context.TODO(), "/dev/sdb", "/mnt", 0, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t,
+		"lsblk -J -o NAME,FSTYPE /dev/sdb",
+		"mount -t exfat -o uid=0,gid=0,umask=000 /dev/sdb /mnt")
+}
+
+func TestMountFATWrongFSType(t *testing.T) {
+	r := newTestFormatRunner("ext4")
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	if err := fs.MountFAT(
+		context.TODO(), "/dev/sdb", "/mnt", 1000, 1000, 0022); err == nil {
+		t.Fatal("expected error for ext4 device")
+	}
+	r.assertCommands(t, "lsblk -J -o NAME,FSTYPE /dev/sdb")
+}
@@ -0,0 +1,11 @@
+package gofsutil
+
+import "context"
+
+// fsSupportsFeature returns a flag indicating whether the feature is
+// enabled on the filesystem of type fsType on device
+func (fs *FS) fsSupportsFeature(
+	ctx context.Context, device, fsType, feature string) (bool, error) {
+
+	return false, ErrNotImplemented
+}
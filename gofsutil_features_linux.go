@@ -0,0 +1,142 @@
+package gofsutil
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+)
+
+// extFeatureAliases maps the normalized names of features to the names
+// of the features of an ext filesystem.
+var extFeatureAliases = map[string]string{
+	"crc": "metadata_csum",
+}
+
+// xfsFeatureAliases maps the normalized names of features to the names
+// of the features of an xfs filesystem.
+var xfsFeatureAliases = map[string]string{
+	"metadata_csum": "crc",
+}
+
+// btrfsBuiltinFeatures are the features every btrfs filesystem supports
+// and which are therefore not recorded in its superblock flags.
+var btrfsBuiltinFeatures = map[string]struct{}{
+	"reflink":       {},
+	"crc":           {},
+	"metadata_csum": {},
+}
+
+// fsSupportsFeature returns a flag indicating whether the feature is
+// enabled on the filesystem of type fsType on device
+func (fs *FS) fsSupportsFeature(
+	ctx context.Context, device, fsType, feature string) (bool, error) {
+
+	feature = strings.ToLower(feature)
+
+	var (
+		features []string
+		err      error
+	)
+	switch {
+	case isExtFS(fsType):
+		if f, ok := extFeatureAliases[feature]; ok {
+			feature = f
+		}
+		features, err = fs.getExt4Features(ctx, device)
+	case fsType == "xfs":
+		if f, ok := xfsFeatureAliases[feature]; ok {
+			feature = f
+		}
+		var buf []byte
+		if buf, err = fs.xfsInfo(ctx, device); err == nil {
+			features, err = parseXFSInfoFeatures(buf)
+		}
+	case fsType == "btrfs":
+		if _, ok := btrfsBuiltinFeatures[feature]; ok {
+			return true, nil
+		}
+		var buf []byte
+		if buf, err = fs.btrfsDumpSuper(ctx, device); err == nil {
+			features, err = parseBtrfsFeatures(buf)
+		}
+	default:
+		return false, fmt.Errorf("%w: fsType=%s", ErrNotImplemented, fsType)
+	}
+	if err != nil {
+		return false, err
+	}
+	for _, f := range features {
+		if f == feature {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// parseXFSInfoFeatures returns the features enabled in the output of
+// 'xfs_info', which are the fields set to 1, ex. "reflink=1". The fields
+// with other values, ex. "bsize=4096", are not features.
+func parseXFSInfoFeatures(buf []byte) ([]string, error) {
+	var features []string
+	scan := bufio.NewScanner(bytes.NewReader(buf))
+	for scan.Scan() {
+		for _, field := range strings.Fields(scan.Text()) {
+			field = strings.TrimSuffix(field, ",")
+			if strings.HasSuffix(field, "=1") {
+				features = append(features, strings.TrimSuffix(field, "=1"))
+			}
+		}
+	}
+	if err := scan.Err(); err != nil {
+		return nil, err
+	}
+	return features, nil
+}
+
+// parseBtrfsFeatures returns the lower-cased names of the compat,
+// compat_ro, and incompat flags in the output of
+// 'btrfs inspect-internal dump-super', ex.
+//
+//	compat_ro_flags		0x3
+//				( FREE_SPACE_TREE |
+//				  FREE_SPACE_TREE_VALID )
+func parseBtrfsFeatures(buf []byte) ([]string, error) {
+	var (
+		features []string
+		header   bool
+		inList   bool
+	)
+	scan := bufio.NewScanner(bytes.NewReader(buf))
+	for scan.Scan() {
+		line := strings.TrimSpace(scan.Text())
+		fields := strings.Fields(line)
+
+		// A flags field without flags, ex. "compat_flags 0x0", is not
+		// followed by a list.
+		if header && strings.HasPrefix(line, "(") {
+			inList = true
+		}
+		header = false
+		if !inList {
+			if len(fields) > 0 {
+				switch fields[0] {
+				case "compat_flags", "compat_ro_flags", "incompat_flags":
+					header = true
+				}
+			}
+			continue
+		}
+		for _, f := range fields {
+			if f = strings.Trim(f, "()|"); f != "" {
+				features = append(features, strings.ToLower(f))
+			}
+		}
+		inList = !strings.HasSuffix(line, ")")
+	}
+	if err := scan.Err(); err != nil {
+		return nil, err
+	}
+	return features, nil
+}
@@ -0,0 +1,96 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+const btrfsDumpSuperFeaturesData = `superblock: bytenr=65536, device=/dev/sdb
+---------------------------------------------------------
+csum_type		0 (crc32c)
+flags			0x1
+			( WRITTEN )
+magic			_BHRfS_M [match]
+compat_flags		0x0
+compat_ro_flags		0x3
+			( FREE_SPACE_TREE |
+			  FREE_SPACE_TREE_VALID )
+incompat_flags		0x361
+			( MIXED_BACKREF |
+			  BIG_METADATA |
+			  EXTENDED_IREF |
+			  SKINNY_METADATA |
+			  NO_HOLES )
+sectorsize		4096
+`
+
+func TestFSSupportsFeature(t *testing.T) {
+	tests := []struct {
+		fsType  string
+		out     string
+		feature string
+		cmds    []string
+		exp     bool
+	}{
+		{"ext4", dumpe2fsExt4LimitsData, "64bit",
+			[]string{"dumpe2fs -h /dev/sdb"}, true},
+		{"ext4", dumpe2fsExt4LimitsData, "crc",
+			[]string{"dumpe2fs -h /dev/sdb"}, true},
+		{"ext4", dumpe2fsExt4LimitsData, "reflink",
+			[]string{"dumpe2fs -h /dev/sdb"}, false},
+		{"ext3", dumpe2fsExt3LimitsData, "metadata_csum",
+			[]string{"dumpe2fs -h /dev/sdb"}, false},
+		{"xfs", xfsInfoData, "reflink",
+			[]string{"xfs_info /dev/sdb"}, true},
+		{"xfs", xfsInfoData, "metadata_csum",
+			[]string{"xfs_info /dev/sdb"}, true},
+		{"xfs", xfsInfoData, "bigtime",
+			[]string{"xfs_info /dev/sdb"}, false},
+		{"xfs", xfsInfoData, "bsize",
+			[]string{"xfs_info /dev/sdb"}, false},
+		{"btrfs", btrfsDumpSuperFeaturesData, "NO_HOLES",
+			[]string{"btrfs inspect-internal dump-super /dev/sdb"}, true},
+		{"btrfs", btrfsDumpSuperFeaturesData, "free_space_tree",
+			[]string{"btrfs inspect-internal dump-super /dev/sdb"}, true},
+		{"btrfs", btrfsDumpSuperFeaturesData, "zoned",
+			[]string{"btrfs inspect-internal dump-super /dev/sdb"}, false},
+		{"btrfs", btrfsDumpSuperFeaturesData, "written",
+			[]string{"btrfs inspect-internal dump-super /dev/sdb"}, false},
+		{"btrfs", btrfsDumpSuperFeaturesData, "reflink", nil, true},
+	}
+	for _, tt := range tests {
+		out := tt.out
+		r := &testCommandRunner{
+			handler: func(args []string) (string, error) {
+				return out, nil
+			},
+		}
+		fs := &gofsutil.FS{RunCommand: r.run}
+
+		ok, err := fs.FSSupportsFeature(
+			context.TODO(), "/dev/sdb", tt.fsType, tt.feature)
+		if err != nil {
+			t.Fatalf("%s: %s: %v", tt.fsType, tt.feature, err)
+		}
+		if ok != tt.exp {
+			t.Errorf("%s: %s: exp=%v, act=%v",
+				tt.fsType, tt.feature, tt.exp, ok)
+		}
+		r.assertCommands(t, tt.cmds...)
+	}
+}
+
+func TestFSSupportsFeatureNotImplemented(t *testing.T) {
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	_, err := fs.FSSupportsFeature(
+		context.TODO(), "/dev/sdb", "vfat", "reflink")
+	if !errors.Is(err, gofsutil.ErrNotImplemented) {
+		t.Fatalf("expected ErrNotImplemented: %v", err)
+	}
+	r.assertCommands(t)
+}
@@ -0,0 +1,17 @@
+package gofsutil
+
+import "context"
+
+// supportedFilesystems returns the filesystem types registered with the
+// kernel
+func (fs *FS) supportedFilesystems(ctx context.Context) ([]string, error) {
+	return nil, ErrNotImplemented
+}
+
+// isFSTypeSupported returns a flag indicating whether the kernel is
+// able to mount the filesystem type
+func (fs *FS) isFSTypeSupported(
+	ctx context.Context, fsType string) (bool, error) {
+
+	return false, ErrNotImplemented
+}
@@ -0,0 +1,106 @@
+package gofsutil
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// supportedFilesystems returns the filesystem types registered with the
+// kernel as listed by /proc/filesystems
+func (fs *FS) supportedFilesystems(ctx context.Context) ([]string, error) {
+	buf, err := readFileContext(ctx, fs.procPath("filesystems"))
+	if err != nil {
+		return nil, err
+	}
+	return parseProcFilesystems(buf), nil
+}
+
+// parseProcFilesystems parses the contents of /proc/filesystems. Each
+// line is a filesystem type, ex. "\text4", optionally preceded by the
+// "nodev" flag of a filesystem that does not require a block device,
+// ex. "nodev\ttmpfs".
+func parseProcFilesystems(buf []byte) []string {
+	var (
+		types []string
+		seen  = map[string]struct{}{}
+	)
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		fsType := fields[len(fields)-1]
+		if _, ok := seen[fsType]; ok {
+			continue
+		}
+		seen[fsType] = struct{}{}
+		types = append(types, fsType)
+	}
+	return types
+}
+
+// isFSTypeSupported returns a flag indicating whether the kernel is
+// able to mount the filesystem type, either because the type is
+// registered or because its module may be autoloaded
+func (fs *FS) isFSTypeSupported(
+	ctx context.Context, fsType string) (bool, error) {
+
+	types, err := fs.supportedFilesystems(ctx)
+	if err != nil {
+		return false, err
+	}
+	for _, t := range types {
+		if t == fsType {
+			return true, nil
+		}
+	}
+	ok, err := fs.isFSModuleAvailable(ctx, fsType)
+	if err != nil {
+		return false, err
+	}
+	log.WithFields(logFields(ctx, log.Fields{
+		"fsType":    fsType,
+		"available": ok,
+	})).Debug("filesystem type not registered, checked module aliases")
+	return ok, nil
+}
+
+// isFSModuleAvailable returns a flag indicating whether the modules of
+// the running kernel include one that the kernel autoloads to register
+// the filesystem type, i.e. one with the alias "fs-<fsType>". A kernel
+// without a modules.alias file has no such modules.
+func (fs *FS) isFSModuleAvailable(
+	ctx context.Context, fsType string) (bool, error) {
+
+	release, err := readFileContext(
+		ctx, fs.procPath("sys", "kernel", "osrelease"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	buf, err := readFileContext(ctx, fs.modulesPath(
+		strings.TrimSpace(string(release)), "modules.alias"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	alias := "fs-" + fsType
+	scanner := bufio.NewScanner(bytes.NewReader(buf))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 3 && fields[0] == "alias" && fields[1] == alias {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
@@ -0,0 +1,121 @@
+package gofsutil_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+const procFilesystemsData = `nodev	sysfs
+nodev	tmpfs
+nodev	proc
+nodev	overlay
+	ext3
+	ext2
+	ext4
+	xfs
+nodev	fuse
+	fuseblk
+`
+
+const modulesAliasData = `# Aliases extracted from modules themselves.
+alias fs-btrfs btrfs
+alias fs-nfs4 nfsv4
+alias fs-nfs nfs
+alias net-pf-38 af_alg
+`
+
+func newTestFilesystemsRoot(t *testing.T) (procRoot, modulesRoot string) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"proc/filesystems":                   procFilesystemsData,
+		"proc/sys/kernel/osrelease":          "5.15.0-test\n",
+		"modules/5.15.0-test/modules.alias":  modulesAliasData,
+		"modules/4.18.0-other/modules.alias": "alias fs-zfs zfs\n",
+	}
+	for name, data := range files {
+		p := path.Join(dir, name)
+		if err := os.MkdirAll(path.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return path.Join(dir, "proc"), path.Join(dir, "modules")
+}
+
+func TestSupportedFilesystems(t *testing.T) {
+	procRoot, modulesRoot := newTestFilesystemsRoot(t)
+	fs := &gofsutil.FS{ProcRoot: procRoot, ModulesRoot: modulesRoot}
+
+	types, err := fs.SupportedFilesystems(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []string{
+		"sysfs", "tmpfs", "proc", "overlay",
+		"ext3", "ext2", "ext4", "xfs", "fuse", "fuseblk",
+	}
+	if !reflect.DeepEqual(types, exp) {
+		t.Errorf("types=%v, exp=%v", types, exp)
+	}
+}
+
+func TestIsFSTypeSupported(t *testing.T) {
+	procRoot, modulesRoot := newTestFilesystemsRoot(t)
+	fs := &gofsutil.FS{ProcRoot: procRoot, ModulesRoot: modulesRoot}
+
+	tests := []struct {
+		fsType    string
+		supported bool
+	}{
+		// Registered nodev and block device filesystems.
+		{"tmpfs", true},
+		{"overlay", true},
+		{"ext4", true},
+		{"xfs", true},
+		// Modules that are autoloaded on the first mount.
+		{"btrfs", true},
+		{"nfs4", true},
+		// A module of another kernel release.
+		{"zfs", false},
+		{"nodev", false},
+		{"vfat", false},
+		{"af_alg", false},
+	}
+	for _, tt := range tests {
+		ok, err := fs.IsFSTypeSupported(context.TODO(), tt.fsType)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.fsType, err)
+		}
+		if ok != tt.supported {
+			t.Errorf("%s: supported=%v, exp=%v", tt.fsType, ok, tt.supported)
+		}
+	}
+}
+
+func TestIsFSTypeSupportedNoModules(t *testing.T) {
+	procRoot, modulesRoot := newTestFilesystemsRoot(t)
+	fs := &gofsutil.FS{
+		ProcRoot:    procRoot,
+		ModulesRoot: path.Join(modulesRoot, "missing"),
+	}
+
+	ok, err := fs.IsFSTypeSupported(context.TODO(), "btrfs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Error("btrfs should not be supported without modules")
+	}
+	fs.ProcRoot = modulesRoot
+	if _, err := fs.IsFSTypeSupported(context.TODO(), "ext4"); err == nil {
+		t.Error("expected error without /proc/filesystems")
+	}
+}
@@ -0,0 +1,294 @@
+package gofsutil
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// darwinFSTypes maps the filesystem types and aliases accepted by
+// formatAndMount to the type reported by diskutil and used by mount(8).
+var darwinFSTypes = map[string]string{
+	"apfs":    "apfs",
+	"hfs":     "hfs",
+	"hfs+":    "hfs",
+	"hfsplus": "hfs",
+	"jhfs+":   "hfs",
+	"exfat":   "exfat",
+}
+
+// darwinDefaultFSType is the filesystem type used to format a disk when
+// formatAndMount is not provided a type.
+const darwinDefaultFSType = "apfs"
+
+// diskutilErrors maps the output of a failed diskutil command to the
+// error that describes the failure.
+var diskutilErrors = []cmdError{
+	{regexp.MustCompile(`(?i)could not find disk`), ErrDeviceNotFound},
+}
+
+// getDiskFormat uses 'diskutil info' to see if the given disk is
+// unformatted
+func (fs *FS) getDiskFormat(ctx context.Context, disk string) (string, error) {
+	args := []string{"info", "-plist", disk}
+
+	f := logFields(ctx, log.Fields{
+		"disk": disk,
+		"args": args,
+	})
+	log.WithFields(f).Info("checking if disk is formatted using diskutil")
+
+	buf, err := fs.exec(ctx, "diskutil", args...)
+	if err != nil {
+		out := string(buf)
+		log.WithFields(f).WithField("output", out).WithError(
+			err).Error("failed to determine if disk is formatted")
+		return "", fmt.Errorf(
+			"diskutil failed: %w\narguments: %v\noutput: %s",
+			wrapCmdError(err, out, diskutilErrors), args, out)
+	}
+
+	info, err := parseDiskutilInfo(bytes.NewReader(buf))
+	if err != nil {
+		return "", err
+	}
+
+	// A whole disk with a partition map, ex. "GUID_partition_scheme",
+	// has content but no filesystem.
+	fsType := info["FilesystemType"]
+	if fsType == "" && strings.HasSuffix(info["Content"], "_partition_scheme") {
+		return diskFormatPartitions, nil
+	}
+	return fsType, nil
+}
+
+// parseDiskutilInfo returns the string values of the top-level keys of
+// the property list emitted by 'diskutil info -plist'. Values that are
+// not strings, ex. integers or nested dictionaries, are ignored.
+func parseDiskutilInfo(r io.Reader) (map[string]string, error) {
+	var (
+		dec   = xml.NewDecoder(r)
+		info  = map[string]string{}
+		depth int
+		key   string
+		elem  string
+	)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return info, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid diskutil output: %v", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+			elem = t.Name.Local
+			if depth == 3 && elem != "key" && elem != "string" {
+				key = ""
+			}
+		case xml.EndElement:
+			depth--
+			elem = ""
+		case xml.CharData:
+			// The top-level dictionary is at depth 2, beneath the
+			// plist element, so its keys and values are at depth 3.
+			if depth != 3 {
+				continue
+			}
+			switch elem {
+			case "key":
+				key = string(t)
+			case "string":
+				if key != "" {
+					info[key] = string(t)
+					key = ""
+				}
+			}
+		}
+	}
+}
+
+// formatAndMount uses unix utils to format and mount the given disk and
+// returns a flag indicating whether the disk was formatted
+func (fs *FS) formatAndMount(
+	ctx context.Context,
+	source, target, fsType string,
+	formatOpts FormatOptions,
+	opts ...string) (bool, error) {
+
+	// The options are checked by mount, but also before the device may
+	// be formatted.
+	if err := fs.checkOptions(fsType, opts); err != nil {
+		return false, err
+	}
+	if fsType == "" {
+		fsType = darwinDefaultFSType
+	}
+	t, ok := darwinFSTypes[strings.ToLower(fsType)]
+	if !ok {
+		return false, fmt.Errorf("format not supported: fsType=%s", fsType)
+	}
+	fsType = t
+
+	if formatOpts.ReservedBlocksPercent != nil {
+		return false, fmt.Errorf(
+			"reserved blocks percent not supported: fsType=%s", fsType)
+	}
+	if formatOpts.ProjectQuota {
+		return false, fmt.Errorf(
+			"project quotas not supported: fsType=%s", fsType)
+	}
+
+	f := logFields(ctx, log.Fields{
+		"source":  source,
+		"target":  target,
+		"fsType":  fsType,
+		"options": opts,
+	})
+
+	// Try to mount the disk
+	log.WithFields(f).Info("attempting to mount disk")
+	mountErr := fs.mount(ctx, source, target, fsType, opts...)
+	if mountErr == nil {
+		return false, fs.verifyMounted(ctx, target)
+	}
+
+	// A disk is never formatted when the target is in use or the mount
+	// was aborted by the PreMountHook.
+	if errors.Is(mountErr, ErrAlreadyMounted) || isMountVetoed(mountErr) {
+		return false, mountErr
+	}
+
+	// Mount failed. This indicates either that the disk is unformatted or
+	// it contains an unexpected filesystem.
+	existingFormat, err := fs.getDiskFormat(ctx, source)
+	if err != nil {
+		return false, err
+	}
+	if existingFormat != "" {
+		if existingFormat == fsType {
+			return false, mountErr
+		}
+		return false, fmt.Errorf(
+			"failed to mount volume as %q; already contains %s: error: %v",
+			fsType, existingFormat, mountErr)
+	}
+
+	log.WithFields(f).Info("disk appears unformatted, attempting format")
+
+	if err := fs.newfs(ctx, fsType, source, formatOpts, f); err != nil {
+		return false, err
+	}
+
+	log.WithFields(f).Info("disk successfully formatted")
+
+	if err := fs.mount(ctx, source, target, fsType, opts...); err != nil {
+		return true, err
+	}
+	return true, fs.verifyMounted(ctx, target)
+}
+
+// newfs formats source as fsType with the newfs command. The command's
+// output is streamed to formatOpts.Progress if it is not nil.
+func (fs *FS) newfs(
+	ctx context.Context,
+	fsType, source string,
+	formatOpts FormatOptions,
+	f log.Fields) error {
+
+	newfsCmd := fmt.Sprintf("newfs_%s", fsType)
+	args, err := fs.makeNewfsArgs(fsType, source, formatOpts)
+	if err != nil {
+		return err
+	}
+	buf, err := fs.execProgress(ctx, formatOpts.Progress, newfsCmd, args...)
+	if err != nil {
+		out := string(buf)
+		log.WithFields(f).WithField("output", out).WithError(
+			err).Error("format of disk failed")
+		return fmt.Errorf(
+			"format failed: %v\nformat command: %s\noutput: %s",
+			err, newfsCmd, out)
+	}
+	return nil
+}
+
+// formatDevice formats the given disk without mounting it
+func (fs *FS) formatDevice(
+	ctx context.Context,
+	device, fsType string,
+	force bool,
+	mkfsOpts ...string) error {
+
+	if fsType == "" {
+		fsType = darwinDefaultFSType
+	}
+	t, ok := darwinFSTypes[strings.ToLower(fsType)]
+	if !ok {
+		return fmt.Errorf("format not supported: fsType=%s", fsType)
+	}
+	fsType = t
+
+	f := logFields(ctx, log.Fields{
+		"device": device,
+		"fsType": fsType,
+		"force":  force,
+	})
+
+	existingFormat, err := fs.getDiskFormat(ctx, device)
+	if err != nil {
+		return err
+	}
+	if existingFormat != "" && !force {
+		if existingFormat == fsType {
+			log.WithFields(f).Info("disk already formatted")
+			return nil
+		}
+		return fmt.Errorf(
+			"failed to format volume as %q; already contains %s",
+			fsType, existingFormat)
+	}
+
+	log.WithFields(f).Info("attempting format")
+	if err := fs.newfs(
+		ctx, fsType, device,
+		FormatOptions{MkfsOptions: mkfsOpts}, f); err != nil {
+		return err
+	}
+	log.WithFields(f).Info("disk successfully formatted")
+	return nil
+}
+
+// makeNewfsArgs returns the arguments used to format source with the
+// newfs command for fsType. The newfs commands, unlike
+// 'diskutil eraseVolume', do not mount the volume once it is created.
+func (fs *FS) makeNewfsArgs(
+	fsType, source string, formatOpts FormatOptions) ([]string, error) {
+
+	var args []string
+
+	// Create journaled HFS+ volumes as 'diskutil eraseVolume JHFS+' does.
+	if fsType == "hfs" {
+		args = append(args, "-J")
+	}
+	if formatOpts.Label != "" {
+		if strings.Contains(formatOpts.Label, "/") {
+			return nil, fmt.Errorf(
+				"invalid %s label: %q: contains a slash",
+				fsType, formatOpts.Label)
+		}
+		args = append(args, "-v", formatOpts.Label)
+	}
+	args = append(args, fs.MkfsDefaults[fsType]...)
+	args = append(args, formatOpts.MkfsOptions...)
+	return append(args, source), nil
+}
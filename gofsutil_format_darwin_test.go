@@ -0,0 +1,310 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+const diskutilInfoAPFSData = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>APFSContainerReference</key>
+	<string>disk3</string>
+	<key>APFSPhysicalStores</key>
+	<array>
+		<dict>
+			<key>APFSPhysicalStore</key>
+			<string>disk2s2</string>
+		</dict>
+	</array>
+	<key>Bootable</key>
+	<true/>
+	<key>DeviceIdentifier</key>
+	<string>disk3s1</string>
+	<key>DeviceNode</key>
+	<string>/dev/disk3s1</string>
+	<key>FilesystemName</key>
+	<string>APFS</string>
+	<key>FilesystemType</key>
+	<string>apfs</string>
+	<key>Size</key>
+	<integer>499963174912</integer>
+	<key>VolumeName</key>
+	<string>Data</string>
+</dict>
+</plist>
+`
+
+const diskutilInfoBlankData = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Content</key>
+	<string></string>
+	<key>DeviceIdentifier</key>
+	<string>disk4</string>
+	<key>DeviceNode</key>
+	<string>/dev/disk4</string>
+	<key>Size</key>
+	<integer>1073741824</integer>
+</dict>
+</plist>
+`
+
+const diskutilInfoGPTData = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Content</key>
+	<string>GUID_partition_scheme</string>
+	<key>DeviceIdentifier</key>
+	<string>disk4</string>
+	<key>DeviceNode</key>
+	<string>/dev/disk4</string>
+	<key>Size</key>
+	<integer>1073741824</integer>
+	<key>WholeDisk</key>
+	<true/>
+</dict>
+</plist>
+`
+
+// newTestDiskutilRunner returns a command runner for a disk described by
+// the provided 'diskutil info -plist' output. The first mount fails
+// unless the disk is formatted.
+func newTestDiskutilRunner(info string) *testCommandRunner {
+	formatted := strings.Contains(info, "FilesystemType")
+	return &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			switch {
+			case args[0] == "diskutil":
+				return info, nil
+			case args[0] == "mount" && len(args) == 1:
+				// The mount table is empty.
+				return "", nil
+			case strings.HasPrefix(args[0], "newfs_"):
+				formatted = true
+			case args[0] == "mount" && !formatted:
+				return "mount: wrong fs type", errors.New("exit status 1")
+			}
+			return "", nil
+		},
+	}
+}
+
+func TestGetDiskFormatDarwin(t *testing.T) {
+	for _, tt := range []struct {
+		info   string
+		fsType string
+	}{
+		{diskutilInfoAPFSData, "apfs"},
+		{diskutilInfoBlankData, ""},
+		{diskutilInfoGPTData, "unknown data, probably partitions"},
+	} {
+		r := newTestDiskutilRunner(tt.info)
+		fs := &gofsutil.FS{RunCommand: r.run}
+		fsType, err := fs.GetDiskFormat(context.TODO(), "/dev/disk3s1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fsType != tt.fsType {
+			t.Errorf("invalid fsType: exp=%s, act=%s", tt.fsType, fsType)
+		}
+		r.assertCommands(t, "diskutil info -plist /dev/disk3s1")
+	}
+}
+
+func TestGetDiskFormatDarwinNotFound(t *testing.T) {
+	r := &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			return "Could not find disk: /dev/disk9\n",
+				errors.New("exit status 1")
+		},
+	}
+	fs := &gofsutil.FS{RunCommand: r.run}
+	_, err := fs.GetDiskFormat(context.TODO(), "/dev/disk9")
+	if !errors.Is(err, gofsutil.ErrDeviceNotFound) {
+		t.Errorf("expected ErrDeviceNotFound: %v", err)
+	}
+}
+
+func TestFormatAndMountDarwin(t *testing.T) {
+	for _, tt := range []struct {
+		fsType string
+		newfs  string
+		mount  string
+	}{
+		{"", "newfs_apfs /dev/disk4", "mount -t apfs /dev/disk4 /mnt"},
+		{"apfs", "newfs_apfs /dev/disk4", "mount -t apfs /dev/disk4 /mnt"},
+		{"hfs", "newfs_hfs -J /dev/disk4", "mount -t hfs /dev/disk4 /mnt"},
+		{"jhfs+", "newfs_hfs -J /dev/disk4", "mount -t hfs /dev/disk4 /mnt"},
+		{"exfat", "newfs_exfat /dev/disk4", "mount -t exfat /dev/disk4 /mnt"},
+	} {
+		r := newTestDiskutilRunner(diskutilInfoBlankData)
+		fs := &gofsutil.FS{RunCommand: r.run}
+		if err := fs.FormatAndMount(
+			context.TODO(), "/dev/disk4", "/mnt", tt.fsType); err != nil {
+			t.Fatal(err)
+		}
+		r.assertCommands(t,
+			"mount",
+			tt.mount,
+			"diskutil info -plist /dev/disk4",
+			tt.newfs,
+			"mount",
+			tt.mount)
+	}
+}
+
+func TestFormatAndMountDarwinLabel(t *testing.T) {
+	r := newTestDiskutilRunner(diskutilInfoBlankData)
+	fs := &gofsutil.FS{RunCommand: r.run}
+	if err := fs.FormatAndMountWithOpts(
+		context.TODO(), "/dev/disk4", "/mnt", "apfs",
+		gofsutil.FormatOptions{Label: "Data"}); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t,
+		"mount",
+		"mount -t apfs /dev/disk4 /mnt",
+		"diskutil info -plist /dev/disk4",
+		"newfs_apfs -v Data /dev/disk4",
+		"mount",
+		"mount -t apfs /dev/disk4 /mnt")
+}
+
+func TestFormatAndMountDarwinPartitions(t *testing.T) {
+	// A disk with a partition map is never formatted.
+	r := newTestDiskutilRunner(diskutilInfoGPTData)
+	fs := &gofsutil.FS{RunCommand: r.run}
+	err := fs.FormatAndMount(context.TODO(), "/dev/disk4", "/mnt", "apfs")
+	if err == nil || !strings.Contains(err.Error(), "probably partitions") {
+		t.Fatalf("expected partitions error: %v", err)
+	}
+	r.assertCommands(t,
+		"mount",
+		"mount -t apfs /dev/disk4 /mnt",
+		"diskutil info -plist /dev/disk4")
+}
+
+func TestFormatAndMountDarwinUnsupported(t *testing.T) {
+	r := newTestDiskutilRunner(diskutilInfoBlankData)
+	fs := &gofsutil.FS{RunCommand: r.run}
+	if err := fs.FormatAndMount(
+		context.TODO(), "/dev/disk4", "/mnt", "ext4"); err == nil {
+		t.Fatal("expected error for ext4")
+	}
+	r.assertCommands(t)
+}
+
+const darwinMountData = `/dev/disk3s1s1 on / (apfs, sealed, local, read-only, journaled)
+devfs on /dev (devfs, local, nobrowse)
+/dev/disk3s6 on /System/Volumes/VM (apfs, local, noexec, journaled, noatime, nobrowse)
+/dev/disk3s5 on /System/Volumes/Data (apfs, local, journaled, nobrowse, protect)
+map auto_home on /System/Volumes/Data/home (autofs, automounted, nobrowse)
+/dev/disk4s1 on /Volumes/USB (msdos, local, nodev, nosuid, noowners)
+/dev/disk6s1 on /Volumes/External (apfs, local, nodev, nosuid, journaled, noowners)
+`
+
+// newTestDarwinMountRunner returns a command runner that emits the
+// captured mount table and reports the APFS containers disk3 and disk6
+// as synthesized from the physical stores disk0s2 and disk5s2.
+func newTestDarwinMountRunner() *testCommandRunner {
+	return &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			switch {
+			case args[0] == "mount":
+				return darwinMountData, nil
+			case args[0] == "diskutil" &&
+				strings.HasPrefix(args[len(args)-1], "disk3"):
+				return strings.Replace(
+					diskutilInfoAPFSData, "disk2s2", "disk0s2", 1), nil
+			case args[0] == "diskutil" &&
+				strings.HasPrefix(args[len(args)-1], "disk6"):
+				return strings.Replace(
+					diskutilInfoAPFSData, "disk2s2", "disk5s2", 1), nil
+			}
+			return "", errors.New("exit status 1")
+		},
+	}
+}
+
+func TestGetDevMountsDarwin(t *testing.T) {
+	for _, tt := range []struct {
+		dev   string
+		paths []string
+	}{
+		{"/dev/disk0", []string{
+			"/", "/System/Volumes/VM", "/System/Volumes/Data"}},
+		{"/dev/disk0s2", []string{
+			"/", "/System/Volumes/VM", "/System/Volumes/Data"}},
+		{"/dev/disk3s5", []string{"/System/Volumes/Data"}},
+		{"/dev/disk3s1", []string{"/"}},
+		{"/dev/rdisk4", []string{"/Volumes/USB"}},
+		{"disk4s1", []string{"/Volumes/USB"}},
+		{"/dev/disk5", []string{"/Volumes/External"}},
+		{"/dev/disk1", nil},
+	} {
+		r := newTestDarwinMountRunner()
+		fs := &gofsutil.FS{RunCommand: r.run}
+
+		mounts, err := fs.GetDevMounts(context.TODO(), tt.dev)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.dev, err)
+		}
+		var paths []string
+		for _, m := range mounts {
+			paths = append(paths, m.Path)
+		}
+		if strings.Join(paths, ",") != strings.Join(tt.paths, ",") {
+			t.Errorf("%s: invalid mounts: exp=%v, act=%v",
+				tt.dev, tt.paths, paths)
+		}
+	}
+}
+
+func TestGetDevMountsDarwinCachesContainer(t *testing.T) {
+	r := newTestDarwinMountRunner()
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	if _, err := fs.GetDevMounts(context.TODO(), "/dev/disk5"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t,
+		"mount",
+		"diskutil info -plist disk3s1s1",
+		"diskutil info -plist disk6s1")
+}
+
+func TestGetDevMountsDarwinSkipsContainers(t *testing.T) {
+	r := newTestDarwinMountRunner()
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	// A Time Machine snapshot has no disk identifier, and the container
+	// disk7 cannot be read, so neither fails the lookup.
+	ctx := gofsutil.WithMountTable(context.TODO(), []gofsutil.Info{
+		{Device: "/dev/disk3s5", Path: "/System/Volumes/Data", Type: "apfs"},
+		{
+			Device: "com.apple.TimeMachine.2023-05-01-101010.local@/dev/disk3s5",
+			Path:   "/Volumes/.timemachine/snapshot",
+			Type:   "apfs",
+		},
+		{Device: "/dev/disk7s1", Path: "/Volumes/Broken", Type: "apfs"},
+		{Device: "/dev/disk7s2", Path: "/Volumes/Broken2", Type: "apfs"},
+	})
+	mounts, err := fs.GetDevMounts(ctx, "/dev/disk0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mounts) != 1 || mounts[0].Path != "/System/Volumes/Data" {
+		t.Errorf("invalid mounts: %+v", mounts)
+	}
+	r.assertCommands(t,
+		"diskutil info -plist disk3s5",
+		"diskutil info -plist disk7s1")
+}
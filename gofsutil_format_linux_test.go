@@ -0,0 +1,731 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+// newTestLsblkJSON returns the output of "lsblk -J -o NAME,FSTYPE" for
+// a device that contains a filesystem of type fsType, or no filesystem
+// if fsType is empty.
+func newTestLsblkJSON(fsType string) string {
+	v := "null"
+	if fsType != "" {
+		v = `"` + fsType + `"`
+	}
+	return `{
+   "blockdevices": [
+      {"name":"sdb", "fstype":` + v + `}
+   ]
+}
+`
+}
+
+// newTestFormatRunner returns a command runner for a device that
+// initially contains a filesystem of type existingFormat. The first
+// mount fails unless the requested fsType matches the existing format.
+func newTestFormatRunner(existingFormat string) *testCommandRunner {
+	return &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			switch {
+			case args[0] == "lsblk":
+				return newTestLsblkJSON(existingFormat), nil
+			case strings.HasPrefix(args[0], "mkfs."):
+				existingFormat = strings.TrimPrefix(args[0], "mkfs.")
+				return "", nil
+			case args[0] == "mount":
+				if len(args) > 2 && args[1] == "-t" &&
+					args[2] != existingFormat {
+					return "wrong fs type", errors.New("exit status 32")
+				}
+			case args[0] == "dumpe2fs":
+				return testExtFeatures[existingFormat], nil
+			}
+			return "", nil
+		},
+	}
+}
+
+// testExtFeatures is the output of "dumpe2fs -h" for the filesystems
+// created by each of the ext family's mkfs commands.
+var testExtFeatures = map[string]string{
+	"ext3": "Filesystem features:      has_journal ext_attr resize_inode " +
+		"dir_index filetype sparse_super large_file\n",
+	"ext4": "Filesystem features:      has_journal ext_attr resize_inode " +
+		"dir_index filetype extent 64bit flex_bg sparse_super " +
+		"large_file huge_file dir_nlink extra_isize metadata_csum\n",
+}
+
+func TestFormatAndMountBlankExt3(t *testing.T) {
+	r := newTestFormatRunner("")
+	fs := &gofsutil.FS{
+		RunCommand: r.run,
+		ProcRoot:   newTestRootProcRoot(t),
+	}
+
+	if err := fs.FormatAndMount(
+		context.TODO(), "/dev/sdb", "/mnt", "ext3"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t,
+		"mount -t ext3 -o defaults /dev/sdb /mnt",
+		"lsblk -J -o NAME,FSTYPE /dev/sdb",
+		"mkfs.ext3 -F /dev/sdb",
+		"mount -t ext3 -o defaults /dev/sdb /mnt")
+}
+
+func TestFormatAndMountPreMountHookVeto(t *testing.T) {
+	errVeto := errors.New("nosuid required")
+	r := newTestFormatRunner("")
+	fs := &gofsutil.FS{
+		RunCommand: r.run,
+		PreMountHook: func(
+			ctx context.Context,
+			source, target, fsType string,
+			opts []string) error {
+
+			return errVeto
+		},
+	}
+
+	err := fs.FormatAndMount(context.TODO(), "/dev/sdb", "/mnt", "ext4")
+	if !errors.Is(err, errVeto) {
+		t.Fatalf("expected veto: %v", err)
+	}
+	r.assertCommands(t)
+}
+
+func TestFormatAndMountExt4AsExt3(t *testing.T) {
+	r := newTestFormatRunner("ext4")
+	fs := &gofsutil.FS{
+		RunCommand: r.run,
+		ProcRoot:   newTestRootProcRoot(t),
+	}
+
+	err := fs.FormatAndMount(context.TODO(), "/dev/sdb", "/mnt", "ext3")
+	if err == nil {
+		t.Fatal("expected error mounting ext4 as ext3")
+	}
+	if !strings.Contains(err.Error(), "already contains ext4") {
+		t.Errorf("error does not include existing format: %v", err)
+	}
+	if !strings.Contains(err.Error(),
+		"extent,64bit,flex_bg,huge_file,dir_nlink,extra_isize,metadata_csum") {
+		t.Errorf("error does not include unsupported features: %v", err)
+	}
+	if !strings.Contains(err.Error(), `mount it as "ext4"`) {
+		t.Errorf("error does not include existing format hint: %v", err)
+	}
+	r.assertCommands(t,
+		"mount -t ext3 -o defaults /dev/sdb /mnt",
+		"lsblk -J -o NAME,FSTYPE /dev/sdb",
+		"dumpe2fs -h /dev/sdb")
+}
+
+func TestFormatAndMountExt3AsExt4(t *testing.T) {
+	// ext4 mounts ext3, so the failure is the filesystem's.
+	r := newTestFormatRunner("ext3")
+	fs := &gofsutil.FS{
+		RunCommand: r.run,
+		ProcRoot:   newTestRootProcRoot(t),
+	}
+
+	err := fs.FormatAndMount(context.TODO(), "/dev/sdb", "/mnt", "ext4")
+	if err == nil {
+		t.Fatal("expected error mounting ext3 as ext4")
+	}
+	if !strings.Contains(err.Error(), "already contains ext3") {
+		t.Errorf("error does not include existing format: %v", err)
+	}
+	if !strings.Contains(err.Error(), "'e2fsck -n /dev/sdb'") {
+		t.Errorf("error does not include e2fsck hint: %v", err)
+	}
+	r.assertCommands(t,
+		"mount -t ext4 -o defaults /dev/sdb /mnt",
+		"lsblk -J -o NAME,FSTYPE /dev/sdb",
+		"dumpe2fs -h /dev/sdb")
+}
+
+func TestFormatAndMountDeniedOptions(t *testing.T) {
+	r := newTestFormatRunner("")
+	fs := &gofsutil.FS{
+		RunCommand:    r.run,
+		DeniedOptions: map[string]struct{}{"suid": {}},
+	}
+
+	err := fs.FormatAndMount(
+		context.TODO(), "/dev/sdb", "/mnt", "ext4", "suid")
+	var optErr *gofsutil.MountOptionError
+	if !errors.As(err, &optErr) || optErr.Option != "suid" {
+		t.Fatalf("expected MountOptionError: %v", err)
+	}
+
+	// The device is never formatted.
+	r.assertCommands(t)
+}
+
+func TestFormatAndMountFormatFailed(t *testing.T) {
+	r := newTestFormatRunner("")
+	h := r.handler
+	r.handler = func(args []string) (string, error) {
+		if strings.HasPrefix(args[0], "mkfs.") {
+			return "bad device", errors.New("exit status 1")
+		}
+		return h(args)
+	}
+	fs := &gofsutil.FS{
+		RunCommand: r.run,
+		ProcRoot:   newTestRootProcRoot(t),
+	}
+
+	if err := fs.FormatAndMount(
+		context.TODO(), "/dev/sdb", "/mnt", "xfs"); err == nil {
+		t.Fatal("expected format error")
+	}
+	r.assertCommands(t,
+		"mount -t xfs -o defaults /dev/sdb /mnt",
+		"lsblk -J -o NAME,FSTYPE /dev/sdb",
+		"mkfs.xfs /dev/sdb")
+}
+
+func TestFormatAndMountWithOptsReservedBlocks(t *testing.T) {
+	r := newTestFormatRunner("")
+	fs := &gofsutil.FS{
+		RunCommand: r.run,
+		ProcRoot:   newTestRootProcRoot(t),
+	}
+
+	percent := 1.0
+	if err := fs.FormatAndMountWithOpts(
+		context.TODO(), "/dev/sdb", "/mnt", "ext4",
+		gofsutil.FormatOptions{
+			MkfsOptions:           []string{"-E", "lazy_itable_init=0"},
+			ReservedBlocksPercent: &percent,
+		}); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t,
+		"mount -t ext4 -o defaults /dev/sdb /mnt",
+		"lsblk -J -o NAME,FSTYPE /dev/sdb",
+		"mkfs.ext4 -F -E lazy_itable_init=0 /dev/sdb",
+		"tune2fs -m 1 /dev/sdb",
+		"mount -t ext4 -o defaults /dev/sdb /mnt")
+}
+
+func TestFormatAndMountWithOptsReservedBlocksInvalid(t *testing.T) {
+	r := newTestFormatRunner("")
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	percent := 51.0
+	if err := fs.FormatAndMountWithOpts(
+		context.TODO(), "/dev/sdb", "/mnt", "ext4",
+		gofsutil.FormatOptions{ReservedBlocksPercent: &percent}); err == nil {
+		t.Fatal("expected error for invalid percent")
+	}
+	percent = 1.0
+	if err := fs.FormatAndMountWithOpts(
+		context.TODO(), "/dev/sdb", "/mnt", "xfs",
+		gofsutil.FormatOptions{ReservedBlocksPercent: &percent}); err == nil {
+		t.Fatal("expected error for xfs")
+	}
+	r.assertCommands(t)
+}
+
+func TestGetDiskFormatErrDeviceNotFound(t *testing.T) {
+	r := &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			return "lsblk: /dev/sdz: not a block device\n",
+				errors.New("exit status 32")
+		},
+	}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	_, err := fs.GetDiskFormat(context.TODO(), "/dev/sdz")
+	if !errors.Is(err, gofsutil.ErrDeviceNotFound) {
+		t.Errorf("expected ErrDeviceNotFound: %v", err)
+	}
+}
+
+func TestFormatAndMountMkfsDefaults(t *testing.T) {
+	r := newTestFormatRunner("")
+	fs := &gofsutil.FS{
+		ProcRoot:   newTestRootProcRoot(t),
+		RunCommand: r.run,
+		MkfsDefaults: map[string][]string{
+			"ext4": {"-E", "lazy_itable_init=1"},
+			"xfs":  {"-f"},
+		},
+	}
+
+	if err := fs.FormatAndMount(
+		context.TODO(), "/dev/sdb", "/mnt", "ext4"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t,
+		"mount -t ext4 -o defaults /dev/sdb /mnt",
+		"lsblk -J -o NAME,FSTYPE /dev/sdb",
+		"mkfs.ext4 -F -E lazy_itable_init=1 /dev/sdb",
+		"mount -t ext4 -o defaults /dev/sdb /mnt")
+}
+
+func TestFormatAndMountMkfsDefaultsOverride(t *testing.T) {
+	r := newTestFormatRunner("")
+	fs := &gofsutil.FS{
+		ProcRoot:   newTestRootProcRoot(t),
+		RunCommand: r.run,
+		MkfsDefaults: map[string][]string{
+			"ext4": {"-E", "lazy_itable_init=1"},
+			"xfs":  {"-f"},
+		},
+	}
+
+	if err := fs.FormatAndMountWithOpts(
+		context.TODO(), "/dev/sdb", "/mnt", "xfs",
+		gofsutil.FormatOptions{
+			MkfsOptions: []string{"-m", "crc=0"},
+		}); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t,
+		"mount -t xfs -o defaults /dev/sdb /mnt",
+		"lsblk -J -o NAME,FSTYPE /dev/sdb",
+		"mkfs.xfs -f -m crc=0 /dev/sdb",
+		"mount -t xfs -o defaults /dev/sdb /mnt")
+
+	r = newTestFormatRunner("")
+	fs.RunCommand = r.run
+	if err := fs.FormatAndMountWithOpts(
+		context.TODO(), "/dev/sdb", "/mnt", "ext4",
+		gofsutil.FormatOptions{
+			MkfsOptions: []string{"-E", "lazy_itable_init=0"},
+		}); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t,
+		"mount -t ext4 -o defaults /dev/sdb /mnt",
+		"lsblk -J -o NAME,FSTYPE /dev/sdb",
+		"mkfs.ext4 -F -E lazy_itable_init=1 -E lazy_itable_init=0 /dev/sdb",
+		"mount -t ext4 -o defaults /dev/sdb /mnt")
+}
+
+func TestFormatAndMountVerifyMount(t *testing.T) {
+	procRoot := newTestProcRoot(t, procMountInfoData, "self")
+
+	r := newTestFormatRunner("ext4")
+	fs := &gofsutil.FS{ProcRoot: procRoot, RunCommand: r.run}
+
+	// The mount command succeeds but the mount table does not change.
+	if err := fs.FormatAndMount(
+		context.TODO(), "/dev/sdb", "/mnt/none", "ext4"); err != nil {
+		t.Fatal(err)
+	}
+
+	fs.VerifyMount = true
+	err := fs.FormatAndMount(context.TODO(), "/dev/sdb", "/mnt/none", "ext4")
+	if !errors.Is(err, gofsutil.ErrNotMounted) {
+		t.Errorf("expected ErrNotMounted: %v", err)
+	}
+
+	r = newTestFormatRunner("")
+	fs.RunCommand = r.run
+	err = fs.FormatAndMount(context.TODO(), "/dev/sdb", "/mnt/none", "ext4")
+	if !errors.Is(err, gofsutil.ErrNotMounted) {
+		t.Errorf("expected ErrNotMounted after format: %v", err)
+	}
+}
+
+func TestFormatAndMountVerifyMountSuccess(t *testing.T) {
+	data := "72 60 8:16 / /mnt/data rw,relatime shared:28 - " +
+		"ext4 /dev/sdb rw,data=ordered\n"
+	procRoot := newTestProcRoot(t, data, "self")
+
+	// The static mount table already includes the mount that is
+	// verified, so it must not be mistaken for an overmount.
+	r := newTestFormatRunner("ext4")
+	fs := &gofsutil.FS{
+		ProcRoot:       procRoot,
+		RunCommand:     r.run,
+		VerifyMount:    true,
+		AllowOvermount: true,
+	}
+	if err := fs.FormatAndMount(
+		context.TODO(), "/dev/sdb", "/mnt/data", "ext4"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newTestNoLsblkRunner returns a command runner for a host without lsblk
+// on which blkid reports the provided output.
+func newTestNoLsblkRunner(blkidOut string, blkidErr error) *testCommandRunner {
+	return &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			if args[0] == "lsblk" {
+				return "", &exec.Error{Name: "lsblk", Err: exec.ErrNotFound}
+			}
+			return blkidOut, blkidErr
+		},
+	}
+}
+
+func TestGetDiskFormatBlkidFallback(t *testing.T) {
+	tests := []struct {
+		name   string
+		out    string
+		err    error
+		fsType string
+	}{
+		{
+			name: "xfs",
+			out: "DEVNAME=/dev/sdb\nUUID=3e6be9de-8139-11d1-9106-a43f08d823a6\n" +
+				"BLOCK_SIZE=512\nTYPE=xfs\nUSAGE=filesystem\n",
+			fsType: "xfs",
+		},
+		{
+			name:   "partitions",
+			out:    "DEVNAME=/dev/sdb\nPTUUID=8c0b7f1e\nPTTYPE=gpt\n",
+			fsType: "unknown data, probably partitions",
+		},
+		{
+			name: "unformatted",
+			err:  newTestExitError(t, 2),
+		},
+	}
+
+	for _, tt := range tests {
+		r := newTestNoLsblkRunner(tt.out, tt.err)
+		fs := &gofsutil.FS{RunCommand: r.run}
+		fsType, err := fs.GetDiskFormat(context.TODO(), "/dev/sdb")
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if fsType != tt.fsType {
+			t.Errorf("%s: invalid fsType: exp=%q, act=%q",
+				tt.name, tt.fsType, fsType)
+		}
+		r.assertCommands(t,
+			"lsblk -J -o NAME,FSTYPE /dev/sdb",
+			"blkid -p -o export /dev/sdb")
+	}
+}
+
+func TestGetDiskFormatBlkidError(t *testing.T) {
+	// Only exit status 2 means the device has no signature, so a failure
+	// without output, ex. an I/O error, is not an unformatted device.
+	for _, err := range []error{
+		newTestExitError(t, 4),
+		newTestExitError(t, 8),
+		errors.New("signal: killed"),
+	} {
+		r := newTestNoLsblkRunner("", err)
+		fs := &gofsutil.FS{RunCommand: r.run}
+		if fsType, err := fs.GetDiskFormat(
+			context.TODO(), "/dev/sdb"); err == nil {
+			t.Errorf("expected error: fsType=%q", fsType)
+		}
+	}
+}
+
+func TestGetDiskFormatBlkidFallbackNsenter(t *testing.T) {
+	// nsenter exits with status 127 if lsblk is not installed on the host.
+	exitNotFound := newTestExitError(t, 127)
+	r := &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			if args[3] == "lsblk" {
+				return "nsenter: failed to execute lsblk: " +
+					"No such file or directory", exitNotFound
+			}
+			return "TYPE=xfs\n", nil
+		},
+	}
+	fs := &gofsutil.FS{
+		RunCommand: r.run,
+		Nsenter:    gofsutil.NsenterConfig{Enabled: true},
+	}
+	fsType, err := fs.GetDiskFormat(context.TODO(), "/dev/sdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fsType != "xfs" {
+		t.Errorf("invalid fsType: exp=xfs, act=%s", fsType)
+	}
+	r.assertCommands(t,
+		"nsenter --mount=/proc/1/ns/mnt -- lsblk -J -o NAME,FSTYPE /dev/sdb",
+		"nsenter --mount=/proc/1/ns/mnt -- blkid -p -o export /dev/sdb")
+}
+
+func TestGetDiskFormatDiskFormatTools(t *testing.T) {
+	r := newTestNoLsblkRunner("TYPE=ext4\n", nil)
+	fs := &gofsutil.FS{
+		RunCommand:      r.run,
+		DiskFormatTools: []string{"blkid", "lsblk"},
+	}
+	fsType, err := fs.GetDiskFormat(context.TODO(), "/dev/sdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fsType != "ext4" {
+		t.Errorf("invalid fsType: exp=ext4, act=%s", fsType)
+	}
+	r.assertCommands(t, "blkid -p -o export /dev/sdb")
+
+	fs.DiskFormatTools = []string{"lsblk"}
+	if _, err := fs.GetDiskFormat(context.TODO(), "/dev/sdb"); err == nil {
+		t.Error("expected error when no tool is installed")
+	}
+}
+
+// newTestSysRoot creates a temporary sys filesystem root in which the
+// block device sdb has the provided read-only flag.
+func newTestSysRoot(t *testing.T, ro string) string {
+	sysRoot := t.TempDir()
+	dir := path.Join(sysRoot, "class", "block", "sdb")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(
+		path.Join(dir, "ro"), []byte(ro+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return sysRoot
+}
+
+func TestFormatAndMountAutoReadOnly(t *testing.T) {
+	sysRoot := newTestSysRoot(t, "1")
+
+	r := newTestFormatRunner("")
+	fs := &gofsutil.FS{
+		ProcRoot:     newTestRootProcRoot(t),
+		SysRoot:      sysRoot,
+		RunCommand:   r.run,
+		AutoReadOnly: true,
+	}
+
+	// The device is unformatted, but it is write-protected and so must
+	// not be formatted.
+	if err := fs.FormatAndMount(
+		context.TODO(), "/dev/sdb", "/mnt", "ext4"); err == nil {
+		t.Fatal("expected mount error")
+	}
+	r.assertCommands(t, "mount -t ext4 -o defaults,ro /dev/sdb /mnt")
+
+	r = newTestFormatRunner("ext4")
+	fs.RunCommand = r.run
+	if err := fs.FormatAndMount(
+		context.TODO(), "/dev/sdb", "/mnt", "ext4"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Mount(
+		context.TODO(), "/dev/sdb", "/mnt", "ext4", "noatime"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t,
+		"mount -t ext4 -o defaults,ro /dev/sdb /mnt",
+		"mount -t ext4 -o noatime,ro /dev/sdb /mnt")
+}
+
+func TestFormatAndMountAutoReadOnlyWritable(t *testing.T) {
+	sysRoot := newTestSysRoot(t, "0")
+
+	r := newTestFormatRunner("")
+	fs := &gofsutil.FS{
+		ProcRoot:     newTestRootProcRoot(t),
+		SysRoot:      sysRoot,
+		RunCommand:   r.run,
+		AutoReadOnly: true,
+	}
+	if err := fs.FormatAndMount(
+		context.TODO(), "/dev/sdb", "/mnt", "ext4"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t,
+		"mount -t ext4 -o defaults /dev/sdb /mnt",
+		"lsblk -J -o NAME,FSTYPE /dev/sdb",
+		"mkfs.ext4 -F /dev/sdb",
+		"mount -t ext4 -o defaults /dev/sdb /mnt")
+}
+
+func TestFormatAndMountReport(t *testing.T) {
+	for _, tt := range []struct {
+		existing  string
+		formatted bool
+	}{
+		{"", true},
+		{"xfs", false},
+	} {
+		r := newTestFormatRunner(tt.existing)
+		fs := &gofsutil.FS{
+			RunCommand: r.run,
+			ProcRoot:   newTestRootProcRoot(t),
+		}
+		formatted, err := fs.FormatAndMountReport(
+			context.TODO(), "/dev/sdb", "/mnt", "xfs")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if formatted != tt.formatted {
+			t.Errorf("existing=%q: invalid formatted: exp=%v, act=%v",
+				tt.existing, tt.formatted, formatted)
+		}
+	}
+}
+
+func TestDetectFSType(t *testing.T) {
+	r := newTestFormatRunner("xfs")
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	fsType, err := fs.DetectFSType(context.TODO(), "/dev/sdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fsType != "xfs" {
+		t.Errorf("invalid fs type: %q", fsType)
+	}
+	r.assertCommands(t, "lsblk -J -o NAME,FSTYPE /dev/sdb")
+}
+
+func TestDetectFSTypeBlank(t *testing.T) {
+	r := newTestFormatRunner("")
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	fsType, err := fs.DetectFSType(context.TODO(), "/dev/sdb")
+	if err != nil {
+		t.Fatalf("unexpected error for blank device: %v", err)
+	}
+	if fsType != "" {
+		t.Errorf("invalid fs type for blank device: %q", fsType)
+	}
+}
+
+func TestDetectFSTypeMissing(t *testing.T) {
+	r := newTestErrorRunner("lsblk: /dev/sdz: not a block device\n")
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	fsType, err := fs.DetectFSType(context.TODO(), "/dev/sdz")
+	if !errors.Is(err, gofsutil.ErrDeviceNotFound) {
+		t.Fatalf("expected ErrDeviceNotFound: %v", err)
+	}
+	if fsType != "" {
+		t.Errorf("invalid fs type for missing device: %q", fsType)
+	}
+}
+
+func TestFormatDeviceBlank(t *testing.T) {
+	r := newTestFormatRunner("")
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	if err := fs.FormatDevice(
+		context.TODO(), "/dev/sdb", "xfs", false, "-K"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t,
+		"lsblk -J -o NAME,FSTYPE /dev/sdb",
+		"mkfs.xfs -K /dev/sdb")
+}
+
+func TestFormatDeviceFormatted(t *testing.T) {
+	r := newTestFormatRunner("ext4")
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	if err := fs.FormatDevice(
+		context.TODO(), "/dev/sdb", "ext4", false); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t, "lsblk -J -o NAME,FSTYPE /dev/sdb")
+}
+
+func TestFormatDeviceFormattedOther(t *testing.T) {
+	r := newTestFormatRunner("ext4")
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	if err := fs.FormatDevice(
+		context.TODO(), "/dev/sdb", "xfs", false); err == nil {
+		t.Fatal("expected error for device formatted as ext4")
+	}
+	r.assertCommands(t, "lsblk -J -o NAME,FSTYPE /dev/sdb")
+}
+
+func TestFormatDeviceForce(t *testing.T) {
+	r := newTestFormatRunner("ext4")
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	if err := fs.FormatDevice(
+		context.TODO(), "/dev/sdb", "xfs", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.FormatDevice(
+		context.TODO(), "/dev/sdb", "ext4", true); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t,
+		"lsblk -J -o NAME,FSTYPE /dev/sdb",
+		"mkfs.xfs -f /dev/sdb",
+		"lsblk -J -o NAME,FSTYPE /dev/sdb",
+		"mkfs.ext4 -F /dev/sdb")
+}
+
+func TestFormatAndMountWithProgress(t *testing.T) {
+	mkfsOut := []string{
+		"Creating filesystem with 262144 4k blocks\n",
+		"Allocating group tables: done\r\n",
+		"Writing inode tables: ",
+		"done\n",
+		"Writing superblocks: done",
+	}
+	exp := []string{
+		"Creating filesystem with 262144 4k blocks",
+		"Allocating group tables: done",
+		"Writing inode tables: done",
+		"Writing superblocks: done",
+	}
+
+	var lines []string
+	progress := func(line string) { lines = append(lines, line) }
+
+	// The runner writes the mkfs output in pieces and asserts each
+	// complete line was delivered before the next piece is written.
+	r := newTestFormatRunner("")
+	runMkfs := func(ctx context.Context, cmd *exec.Cmd) error {
+		if !strings.HasPrefix(cmd.Args[0], "mkfs.") {
+			return r.run(ctx, cmd)
+		}
+		complete := 0
+		for _, s := range mkfsOut {
+			io.WriteString(cmd.Stdout, s)
+			complete += strings.Count(s, "\n")
+			if len(lines) != complete {
+				t.Errorf("lines not streamed: exp=%d, act=%d: %q",
+					complete, len(lines), lines)
+			}
+			time.Sleep(time.Millisecond)
+		}
+		return r.run(ctx, cmd)
+	}
+	fs := &gofsutil.FS{
+		RunCommand: runMkfs,
+		ProcRoot:   newTestRootProcRoot(t),
+	}
+
+	if err := fs.FormatAndMountWithProgress(
+		context.TODO(), "/dev/sdb", "/mnt", "ext4", progress); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(lines, exp) {
+		t.Errorf("invalid progress lines: exp=%q, act=%q", exp, lines)
+	}
+	r.assertCommands(t,
+		"mount -t ext4 -o defaults /dev/sdb /mnt",
+		"lsblk -J -o NAME,FSTYPE /dev/sdb",
+		"mkfs.ext4 -F /dev/sdb",
+		"mount -t ext4 -o defaults /dev/sdb /mnt")
+}
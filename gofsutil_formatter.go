@@ -0,0 +1,35 @@
+package gofsutil
+
+import "context"
+
+// Exec defines the signature of the function a formatter uses to run a
+// command, ex. "mkfs.f2fs". The command is run like the commands of this
+// package and its combined output is returned.
+type Exec func(ctx context.Context, name string, args ...string) ([]byte, error)
+
+// FormatterFunc defines the signature of the function registered with
+// RegisterFormatter to format a device with the provided mkfs options.
+type FormatterFunc func(
+	ctx context.Context, exec Exec, device string, opts []string) error
+
+// registerFormatter registers the formatter of the filesystem type
+func (fs *FS) registerFormatter(fsType string, fn FormatterFunc) {
+	fs.formattersMu.Lock()
+	defer fs.formattersMu.Unlock()
+	if fn == nil {
+		delete(fs.formatters, fsType)
+		return
+	}
+	if fs.formatters == nil {
+		fs.formatters = map[string]FormatterFunc{}
+	}
+	fs.formatters[fsType] = fn
+}
+
+// getFormatter returns the formatter registered for the filesystem type
+// or nil if there is none
+func (fs *FS) getFormatter(fsType string) FormatterFunc {
+	fs.formattersMu.RLock()
+	defer fs.formattersMu.RUnlock()
+	return fs.formatters[fsType]
+}
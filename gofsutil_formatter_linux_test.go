@@ -0,0 +1,113 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+// testFormatter is a formatter that records the device and options with
+// which it is invoked and formats the device with mkfs.<fsType>.
+type testFormatter struct {
+	fsType string
+	device string
+	opts   []string
+	err    error
+}
+
+func (f *testFormatter) format(
+	ctx context.Context,
+	exec gofsutil.Exec,
+	device string,
+	opts []string) error {
+
+	f.device, f.opts = device, opts
+	if f.err != nil {
+		return f.err
+	}
+	_, err := exec(ctx, "mkfs."+f.fsType, append(opts, device)...)
+	return err
+}
+
+func TestRegisterFormatterFormatAndMount(t *testing.T) {
+	r := newTestFormatRunner("")
+	fs := &gofsutil.FS{
+		ProcRoot:     newTestRootProcRoot(t),
+		RunCommand:   r.run,
+		MkfsDefaults: map[string][]string{"fakefs": {"-O", "compression"}},
+	}
+	f := &testFormatter{fsType: "fakefs"}
+	fs.RegisterFormatter("fakefs", f.format)
+
+	if err := fs.FormatAndMountWithOpts(
+		context.TODO(), "/dev/sdb", "/mnt", "fakefs",
+		gofsutil.FormatOptions{MkfsOptions: []string{"-q"}}); err != nil {
+		t.Fatal(err)
+	}
+	if f.device != "/dev/sdb" {
+		t.Errorf("device=%s, exp=/dev/sdb", f.device)
+	}
+	if exp := []string{"-O", "compression", "-q"}; !reflect.DeepEqual(
+		f.opts, exp) {
+		t.Errorf("opts=%v, exp=%v", f.opts, exp)
+	}
+	r.assertCommands(t,
+		"mount -t fakefs -o defaults /dev/sdb /mnt",
+		"lsblk -J -o NAME,FSTYPE /dev/sdb",
+		"mkfs.fakefs -O compression -q /dev/sdb",
+		"mount -t fakefs -o defaults /dev/sdb /mnt")
+}
+
+func TestRegisterFormatterFormatDevice(t *testing.T) {
+	r := newTestFormatRunner("")
+	fs := &gofsutil.FS{RunCommand: r.run}
+	f := &testFormatter{fsType: "ext4"}
+	fs.RegisterFormatter("ext4", f.format)
+
+	if err := fs.FormatDevice(
+		context.TODO(), "/dev/sdb", "ext4", false, "-m", "0"); err != nil {
+		t.Fatal(err)
+	}
+	if f.device != "/dev/sdb" {
+		t.Errorf("device=%s, exp=/dev/sdb", f.device)
+	}
+	// The registered formatter replaces the built-in support, so the
+	// "-F" flag of the ext family is not added.
+	r.assertCommands(t,
+		"lsblk -J -o NAME,FSTYPE /dev/sdb",
+		"mkfs.ext4 -m 0 /dev/sdb")
+
+	// A nil formatter restores the built-in support.
+	fs.RegisterFormatter("ext4", nil)
+	r = newTestFormatRunner("")
+	fs.RunCommand = r.run
+	if err := fs.FormatDevice(
+		context.TODO(), "/dev/sdb", "ext4", false); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t,
+		"lsblk -J -o NAME,FSTYPE /dev/sdb",
+		"mkfs.ext4 -F /dev/sdb")
+}
+
+func TestRegisterFormatterError(t *testing.T) {
+	r := newTestFormatRunner("")
+	fs := &gofsutil.FS{
+		RunCommand: r.run,
+		ProcRoot:   newTestRootProcRoot(t),
+	}
+	errFormat := errors.New("fakefs: device too small")
+	f := &testFormatter{fsType: "fakefs", err: errFormat}
+	fs.RegisterFormatter("fakefs", f.format)
+
+	err := fs.FormatAndMount(context.TODO(), "/dev/sdb", "/mnt", "fakefs")
+	if !errors.Is(err, errFormat) {
+		t.Fatalf("expected formatter error, got %v", err)
+	}
+	r.assertCommands(t,
+		"mount -t fakefs -o defaults /dev/sdb /mnt",
+		"lsblk -J -o NAME,FSTYPE /dev/sdb")
+}
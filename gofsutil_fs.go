@@ -1,52 +1,455 @@
 package gofsutil
 
-import "context"
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+)
 
 // FS provides many filesystem-specific functions, such as mount, format, etc.
 type FS struct {
 
 	// ScanEntry is the function used to process mount table entries.
 	ScanEntry EntryScanFunc
+
+	// ProcRoot is the path to the root of the proc filesystem. If empty
+	// then "/proc" is used.
+	ProcRoot string
+
+	// SysRoot is the path to the root of the sys filesystem. If empty
+	// then "/sys" is used.
+	SysRoot string
+
+	// DevRoot is the path to the root of the dev filesystem. If empty
+	// then "/dev" is used.
+	DevRoot string
+
+	// EtcRoot is the path to the host configuration directory, ex. the
+	// directory that contains mtab. If empty then "/etc" is used.
+	EtcRoot string
+
+	// ModulesRoot is the path to the kernel modules directory, ex. the
+	// directory that contains a modules.alias file for each kernel
+	// release. If empty then "/lib/modules" is used.
+	ModulesRoot string
+
+	// RunCommand is the function used to run the commands executed
+	// by this package, ex. mount, lsblk, mkfs. If nil then the function
+	// returned by DefaultCommandRunFunc is used.
+	RunCommand CommandRunFunc
+
+	// Env is a list of environment variables, in the form "key=value",
+	// added to the environment of the commands executed by this package.
+	// The commands inherit the process environment with LC_ALL=C so that
+	// their output may be parsed regardless of the process locale. Env
+	// is applied last and so may override any of these values.
+	Env []string
+
+	// Nsenter configures the execution of the commands run by this
+	// package, ex. mount, umount, and mkfs, in the mount namespace of the
+	// host with "nsenter --mount=<HostProcPath>/1/ns/mnt --". Only the
+	// commands are affected. The functions that read files or make system
+	// calls directly, ex. GetMounts reading the mount table from ProcRoot
+	// or IsStaleNFSMount, continue to do so in the namespace of the
+	// current process, so ProcRoot, SysRoot, and DevRoot should also
+	// refer to the host's filesystems.
+	Nsenter NsenterConfig
+
+	// Stat is the function used by IsStaleNFSMount to stat a mount
+	// target. If nil then the function returned by DefaultStatFunc
+	// is used.
+	Stat StatFunc
+
+	// StatTimeout is the amount of time the stat of IsStaleNFSMount may
+	// take before the mount is considered stale. If zero then a timeout
+	// of five seconds is used.
+	StatTimeout time.Duration
+
+	// MkfsDefaults are the options passed to the mkfs command for each
+	// filesystem type whenever a device is formatted, ex.
+	// {"ext4": {"-E", "lazy_itable_init=0"}}. The defaults precede the
+	// MkfsOptions from FormatOptions on the command line, so options
+	// provided per call override the defaults, since the mkfs commands
+	// honor the last occurrence of an option.
+	MkfsDefaults map[string][]string
+
+	// VerifyMount causes FormatAndMount to scan the mount table after
+	// mounting a disk and return an error if the mount is not present,
+	// rather than trusting the exit status of the mount command.
+	VerifyMount bool
+
+	// AllowedOptions is the set of options accepted by Mount, BindMount,
+	// FormatAndMount, and every other function that mounts a filesystem,
+	// except MountRaw. If non-nil then an option not in the set is
+	// rejected with a *MountOptionError before anything is mounted. An
+	// option with a value, ex. "uid=1000", may be allowed by its name,
+	// ex. "uid". The options a function adds itself, ex. "subvol=" for
+	// MountBtrfsSubvolume, must be allowed too, except for "defaults",
+	// "ro", "bind", and "remount".
+	AllowedOptions map[string]struct{}
+
+	// DeniedOptions is the set of options rejected with a
+	// *MountOptionError by the functions that check AllowedOptions, ex.
+	// "dev", "suid", or "exec". The DeniedOptions take precedence over
+	// the AllowedOptions.
+	DeniedOptions map[string]struct{}
+
+	// StrictOptions causes Mount, and every other function that mounts a
+	// filesystem except MountRaw, to reject options that are not
+	// supported by the filesystem type, as reported by
+	// ValidateOptionsForFSType, with an *UnsupportedMountOptionsError
+	// before anything is mounted. The options of unknown filesystem types
+	// are not checked.
+	StrictOptions bool
+
+	// SkipAutofs omits autofs mounts, including the placeholders of
+	// unconfigured automounts, from the mounts returned by GetMounts and
+	// the other functions that read the mount table. Touching the path
+	// of an automount placeholder may trigger the mount or hang if its
+	// source is unavailable.
+	SkipAutofs bool
+
+	// XFSAutoNoUUID causes Mount to retry the mount of an xfs filesystem
+	// with the "nouuid" option when the mount fails because a mounted
+	// filesystem has the same UUID, ex. a clone of a mounted volume.
+	XFSAutoNoUUID bool
+
+	// AutoModprobe causes Mount to load the kernel module of the
+	// filesystem type with "modprobe <fsType>" and retry the mount once
+	// when the mount fails because the type is unknown to the kernel,
+	// ex. an xfs or btrfs filesystem when the module is not loaded and
+	// the kernel does not autoload it. Darwin hosts ignore this field.
+	AutoModprobe bool
+
+	// VerifyOptions causes Mount, BindMount, and FormatAndMount to read
+	// the mount table once the filesystem is mounted and return a
+	// *MountOptionsDroppedError if any of the requested options are
+	// absent from the mount's options, since the kernel may silently
+	// ignore an option it does not support. The filesystem remains
+	// mounted. The options the kernel never reports, ex. "bind" or
+	// "defaults", and the options interpreted by userspace are not
+	// verified, and an option with a value, ex. "size=1m", is verified
+	// by its name since the kernel may report a normalized value.
+	VerifyOptions bool
+
+	// DefaultMountOpts are the options added to the options of a mount
+	// of each filesystem type, ex. {"xfs": {"noatime"}}. The options
+	// provided by the caller always win: a default is omitted if it
+	// conflicts with one of the caller's options, ex. the same option
+	// with a different value, its "no" form, or another atime option.
+	DefaultMountOpts map[string][]string
+
+	// DiskFormatTools are the tools GetDiskFormat uses, in order of
+	// preference, to determine the format of a disk. A tool is skipped
+	// if it is not installed. The supported tools are "lsblk" and
+	// "blkid". If empty then {"lsblk", "blkid"} is used. Darwin hosts
+	// always use diskutil.
+	DiskFormatTools []string
+
+	// AutoReadOnly causes Mount and FormatAndMount to mount a block
+	// device that is write-protected, ex. a snapshot, with the "ro"
+	// option, as a read-write mount of the device would fail. A
+	// write-protected device is never formatted. The write protection
+	// of a device is read from SysRoot. Darwin hosts ignore this field.
+	AutoReadOnly bool
+
+	// ExclusiveFormat causes FormatAndMount to open a device exclusively
+	// before it is formatted and return an error wrapping ErrDeviceBusy
+	// if the device is in use, ex. mounted elsewhere, held by a device
+	// mapper target, or opened exclusively by another process. Darwin
+	// hosts ignore this field.
+	ExclusiveFormat bool
+
+	// AutoCreateTarget causes Mount, BindMount, and FormatAndMount to
+	// create a target that does not exist, along with any missing
+	// parents, rather than fail. The target of a bind mount of a file,
+	// ex. a block device for a raw block volume, is created as an empty
+	// file, and any other target as a directory with mode 0750.
+	//
+	// A missing target is often a sign of a caller error, ex. a typo in
+	// the path or a volume staged to the wrong directory. With this
+	// option such errors are no longer reported, and the filesystem is
+	// mounted at the unintended path instead, so it should only be
+	// enabled if the caller cannot otherwise ensure the target exists.
+	AutoCreateTarget bool
+
+	// DedupeMounts collapses the entries of the mount table that have the
+	// same Device, Path, Type, Root, and Opts into the first of them, ex.
+	// when the same filesystem is mounted repeatedly at the same path.
+	// Mounts stacked on the same path that differ in any of these fields
+	// are retained. The functions that only search the mount table, ex.
+	// IsMountPoint, are not affected.
+	DedupeMounts bool
+
+	// Retry is the policy used to retry a mount that fails because the
+	// source device does not exist, ex. when udev has not yet created
+	// the device node of a recently attached volume. The symlinks in the
+	// source are evaluated before each attempt so a mount of a udev
+	// symlink, ex. one in /dev/disk/by-id, uses the device to which the
+	// symlink currently refers.
+	Retry RetryPolicy
+
+	// AllowOvermount causes Mount, BindMount, and FormatAndMount to mount
+	// a filesystem at a target at which a filesystem is already mounted,
+	// stacking the new mount on top of the existing one. By default such
+	// a mount fails with an *AlreadyMountedError, which wraps
+	// ErrAlreadyMounted, since an accidental overmount hides the existing
+	// filesystem. A remount is always permitted, and a mount of the
+	// source that is already mounted at the target succeeds without
+	// mounting anything. The latter also applies when AllowOvermount is
+	// true, since the kernel refuses to mount a source at a target at
+	// which it is already mounted, so such a mount returns nil instead of
+	// stacking a second mount of the source.
+	AllowOvermount bool
+
+	// PreMountHook is invoked before each filesystem is mounted by
+	// Mount, BindMount, FormatAndMount, or any other function that mounts
+	// a filesystem except MountRaw, ex. to enforce a policy. The mount is
+	// aborted if the hook returns an error, which is returned to the
+	// caller, and FormatAndMount does not format a device whose mount was
+	// aborted. The hook receives the source, type, and options passed to
+	// the mount command, ex. with the "defaults" option FormatAndMount
+	// adds, and may be invoked more than once by a single call, ex. when
+	// FormatAndMount mounts the device again once it is formatted.
+	PreMountHook PreMountHookFunc
+
+	// PostMountHook is invoked by the same functions as PreMountHook with
+	// the result of each mount that was attempted, ex. to record an audit
+	// log. The hook is not invoked if PreMountHook aborted the mount.
+	PostMountHook PostMountHookFunc
+
+	// MountBackend selects how filesystems are mounted. If empty then
+	// MountBackendExec is used. Please see the MountBackend constants
+	// for the limitations of each backend.
+	MountBackend MountBackend
+
+	// FUSEHelpers maps a filesystem type to the FUSE helper used to mount
+	// it with MountBackendFusermount, ex. "overlay" to "fuse-overlayfs",
+	// which is the default helper for overlay filesystems.
+	FUSEHelpers map[string]string
+
+	// ApparentPathUsage causes GetPathUsage to sum the apparent sizes of
+	// the files, ex. the length of a file in bytes, rather than the
+	// space allocated to them. The apparent size of a sparse file may
+	// exceed its allocated size, and the apparent size of a small file
+	// is usually less than its allocated size.
+	ApparentPathUsage bool
+
+	// LsblkMode selects the output format GetDiskFormat requests from
+	// lsblk. If empty then LsblkModeJSON is tried first and
+	// LsblkModePairs is used if lsblk does not support JSON output. The
+	// mode used is logged with the "lsblkMode" field.
+	LsblkMode LsblkMode
+
+	// formatters are the formatters registered with RegisterFormatter,
+	// keyed by filesystem type.
+	formatters   map[string]FormatterFunc
+	formattersMu sync.RWMutex
 }
 
 // GetDiskFormat uses 'lsblk' to see if the given disk is unformatted.
+// On Linux a member of a mounted multi-device btrfs filesystem is
+// reported as "btrfs" even if the tool does not recognize it.
 func (fs *FS) GetDiskFormat(ctx context.Context, disk string) (string, error) {
 	return fs.getDiskFormat(ctx, disk)
 }
 
+// DetectFSType returns the type of the filesystem on the provided device
+// without formatting or mounting it. An empty string and a nil error are
+// returned if the device does not contain a filesystem. An error that
+// wraps ErrDeviceNotFound is returned if the device does not exist.
+func (fs *FS) DetectFSType(
+	ctx context.Context, device string) (string, error) {
+
+	if err := ValidateDevicePath(device); err != nil {
+		return "", err
+	}
+	return fs.getDiskFormat(ctx, device)
+}
+
+// FormatDevice formats the provided device as fsType without mounting
+// it. The mkfsOpts are passed to the mkfs command before the device. If
+// the device is already formatted as fsType then no action is taken
+// unless force is true, in which case the device is reformatted. An
+// error is returned if the device contains a different filesystem and
+// force is false.
+func (fs *FS) FormatDevice(
+	ctx context.Context,
+	device, fsType string,
+	force bool,
+	mkfsOpts ...string) error {
+
+	if err := ValidateDevicePath(device); err != nil {
+		return err
+	}
+	return fs.formatDevice(ctx, device, fsType, force, mkfsOpts...)
+}
+
+// RegisterFormatter registers fn as the formatter FormatAndMount and
+// FormatDevice use to format a device as fsType, ex. "f2fs" or a
+// site-specific type, in place of running "mkfs.<fsType>". A formatter
+// registered for a type with built-in support, ex. "ext4", replaces the
+// built-in support. The formatter is invoked with the device and the
+// mkfs options, i.e. the MkfsDefaults of fsType followed by the
+// MkfsOptions of the call, and should format the device with exec so
+// that RunCommand, Env, and Nsenter are honored. A nil fn removes the
+// formatter of fsType.
+//
+// Darwin hosts ignore the registered formatters.
+func (fs *FS) RegisterFormatter(fsType string, fn FormatterFunc) {
+	fs.registerFormatter(fsType, fn)
+}
+
 // FormatAndMount uses unix utils to format and mount the given disk.
 func (fs *FS) FormatAndMount(
 	ctx context.Context,
 	source, target, fsType string,
 	options ...string) error {
 
-	return fs.formatAndMount(ctx, source, target, fsType, options...)
+	if err := validateFormatAndMount(source, target); err != nil {
+		return err
+	}
+	_, err := fs.formatAndMount(
+		ctx, source, target, fsType, FormatOptions{}, options...)
+	return err
+}
+
+// FormatAndMountReport behaves like FormatAndMount but also returns a flag
+// indicating whether the disk was formatted, ex. to distinguish the first
+// attachment of a volume from a reattachment. The flag is true only if
+// the mkfs command was executed successfully, even if a later step, ex.
+// the mount, failed.
+func (fs *FS) FormatAndMountReport(
+	ctx context.Context,
+	source, target, fsType string,
+	options ...string) (bool, error) {
+
+	if err := validateFormatAndMount(source, target); err != nil {
+		return false, err
+	}
+	return fs.formatAndMount(
+		ctx, source, target, fsType, FormatOptions{}, options...)
+}
+
+// FormatAndMountWithOpts behaves like FormatAndMount but accepts options
+// that control how the disk is formatted.
+func (fs *FS) FormatAndMountWithOpts(
+	ctx context.Context,
+	source, target, fsType string,
+	formatOpts FormatOptions,
+	options ...string) error {
+
+	if err := validateFormatAndMount(source, target); err != nil {
+		return err
+	}
+	_, err := fs.formatAndMount(
+		ctx, source, target, fsType, formatOpts, options...)
+	return err
+}
+
+// FormatAndMountWithProgress behaves like FormatAndMount but invokes
+// progress with each line of the output of the mkfs command as it is
+// written rather than once the command completes. Please see
+// FormatOptions.Progress.
+func (fs *FS) FormatAndMountWithProgress(
+	ctx context.Context,
+	source, target, fsType string,
+	progress func(line string),
+	options ...string) error {
+
+	return fs.FormatAndMountWithOpts(
+		ctx, source, target, fsType,
+		FormatOptions{Progress: progress}, options...)
+}
+
+// WouldFormat reports whether FormatAndMount would format source as
+// fsType, which destroys any data on source, given the current state of
+// source, and the reason for the decision, ex. "blank device",
+// "already ext4", or "fstype mismatch: device contains xfs". Nothing is
+// formatted or mounted. FormatAndMount only formats a device that does
+// not contain a filesystem or partitions. If fsType is empty then "ext4"
+// is assumed.
+//
+// A device that contains a filesystem of another type is reported as
+// false with a "fstype mismatch" reason rather than true, because
+// FormatAndMount fails instead of reformatting the device. A caller
+// that intends to reformat the device must check the reason.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) WouldFormat(
+	ctx context.Context,
+	source, fsType string) (format bool, reason string, err error) {
+
+	if err := ValidateDevicePath(source); err != nil {
+		return false, "", err
+	}
+	return fs.wouldFormat(ctx, source, fsType)
 }
 
 // Mount mounts source to target as fstype with given options.
 //
 // The parameters 'source' and 'fstype' must be empty strings in case they
 // are not required, e.g. for remount, or for an auto filesystem type where
-// the kernel handles fstype automatically.
+// the kernel handles fstype automatically. A fstype with a subtype, ex.
+// "fuse.sshfs", is passed to the mount command as-is.
 //
 // The 'options' parameter is a list of options. Please see mount(8) for
 // more information. If no options are required then please invoke Mount
-// with an empty or nil argument.
+// with an empty or nil argument. The options are checked against the
+// FS's AllowedOptions and DeniedOptions before anything is mounted.
+//
+// Mount is idempotent: nil is returned if source is already mounted at
+// target as fstype, regardless of the options of the existing mount. An
+// *AlreadyMountedError that describes the existing mount is returned if
+// a different filesystem is mounted at target.
 func (fs *FS) Mount(
 	ctx context.Context,
 	source, target, fsType string,
 	options ...string) error {
 
+	if err := validateMountSource(source); err != nil {
+		return err
+	}
+	if err := ValidateMountTarget(target); err != nil {
+		return err
+	}
 	return fs.mount(ctx, source, target, fsType, options...)
 }
 
+// MountFAT mounts the fat-family filesystem (vfat, msdos, or exfat) on
+// device to target. Since these filesystems do not store ownership or
+// permissions, the files are owned by uid and gid and their permissions
+// are masked by mask. An error is returned if the device is not formatted
+// with a fat-family filesystem.
+func (fs *FS) MountFAT(
+	ctx context.Context,
+	device, target string,
+	uid, gid int,
+	mask os.FileMode,
+	options ...string) error {
+
+	if err := ValidateDevicePath(device); err != nil {
+		return err
+	}
+	return fs.mountFAT(ctx, device, target, uid, gid, mask, options...)
+}
+
 // BindMount behaves like Mount was called with a "bind" flag set
-// in the options list.
+// in the options list. An error wrapping ErrBindTypeMismatch is returned
+// if source is a directory and target exists but is not, or if target
+// is a directory and source is not.
 func (fs *FS) BindMount(
 	ctx context.Context,
 	source, target string,
 	options ...string) error {
 
+	if err := validateMountSource(source); err != nil {
+		return err
+	}
+	if err := ValidateMountTarget(target); err != nil {
+		return err
+	}
 	if options == nil {
 		options = []string{"bind"}
 	} else {
@@ -55,11 +458,257 @@ func (fs *FS) BindMount(
 	return fs.mount(ctx, source, target, "", options...)
 }
 
-// Unmount unmounts the target.
+// BindMountFile bind mounts the regular file source to target, ex. to
+// inject a configuration file into a container's filesystem. The target
+// is created as an empty file, along with any missing parents, if it
+// does not exist, regardless of AutoCreateTarget. An error is returned if
+// source is not a regular file, and an error wrapping
+// ErrBindTypeMismatch is returned if target is a directory.
+func (fs *FS) BindMountFile(
+	ctx context.Context,
+	source, target string,
+	options ...string) error {
+
+	if err := validateMountSource(source); err != nil {
+		return err
+	}
+	if err := ValidateMountTarget(target); err != nil {
+		return err
+	}
+	// The options are checked by mount, but also before the target is
+	// created.
+	if err := fs.checkMountOptions(options); err != nil {
+		return err
+	}
+	options = append(options[:len(options):len(options)], "bind")
+	if err := createBindFileTarget(ctx, source, target); err != nil {
+		return err
+	}
+	return fs.mount(ctx, source, target, "", options...)
+}
+
+// MoveMount atomically moves the mount at source, which must be a mount
+// point returned by GetMounts, to target, which must exist. The mount is
+// not remounted, so its options and any mounts beneath it are retained.
+// The move fails if the parent mount of source has shared propagation.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) MoveMount(ctx context.Context, source, target string) error {
+	if err := ValidateMountTarget(source); err != nil {
+		return err
+	}
+	if err := ValidateMountTarget(target); err != nil {
+		return err
+	}
+	return fs.moveMount(ctx, source, target)
+}
+
+// MountCSV behaves like Mount but accepts the options as a single
+// comma-separated string, ex. "rw,nodev,noexec". Commas inside of a
+// double-quoted value, ex. an SELinux context, do not separate options.
+// An empty string indicates no options.
+func (fs *FS) MountCSV(
+	ctx context.Context,
+	source, target, fsType, optsCSV string) error {
+
+	return fs.Mount(ctx, source, target, fsType, splitMountOptsCSV(optsCSV)...)
+}
+
+// BindMountCSV behaves like BindMount but accepts the options as a single
+// comma-separated string. Please see MountCSV for more information.
+func (fs *FS) BindMountCSV(
+	ctx context.Context,
+	source, target, optsCSV string) error {
+
+	return fs.BindMount(ctx, source, target, splitMountOptsCSV(optsCSV)...)
+}
+
+// Unmount unmounts the target. The type of the filesystem mounted at
+// target is read from the mount table with GetMountByTarget to select
+// the command that unmounts it:
+//
+// A FUSE filesystem is unmounted with "fusermount3 -u", or with
+// "fusermount -u" if fusermount3 is not installed, so that a filesystem
+// mounted by an unprivileged user may be unmounted by that user.
+//
+// An NFS filesystem is unmounted with umount.nfs or umount.nfs4, which
+// notify the server of the unmount.
+//
+// Any other filesystem, a filesystem whose helpers are not installed,
+// and a target that is not in the mount table are unmounted with umount.
+// Darwin hosts always unmount with umount.
 func (fs *FS) Unmount(ctx context.Context, target string) error {
 	return fs.unmount(ctx, target)
 }
 
+// GetMountByTarget returns the mount at target. If several filesystems
+// are mounted at target then the topmost, which is the last in the mount
+// table, is returned. Symlinks in target are evaluated first, or target
+// is cleaned if it does not exist, so "/mnt/data/" and "/mnt/./data" are
+// the same target as "/mnt/data". An error wrapping ErrNotMounted is
+// returned if nothing is mounted at target.
+func (fs *FS) GetMountByTarget(
+	ctx context.Context, target string) (Info, error) {
+
+	return fs.getTopMount(ctx, target)
+}
+
+// GetMountByTargetWith returns the mount at target like
+// GetMountByTarget, but processes the entries of the mount table with
+// scan instead of the FS's ScanEntry.
+func (fs *FS) GetMountByTargetWith(
+	ctx context.Context,
+	target string,
+	scan EntryScanFunc) (Info, error) {
+
+	return fs.getTopMount(WithEntryScanFunc(ctx, scan), target)
+}
+
+// ResolveSource returns the canonical path of the block device that is
+// the source of the mount, ex. "/dev/dm-0" for a mount of
+// "/dev/mapper/vg-data", so the source of a mount returned by GetMounts
+// may be compared with a device provided by a caller, ex.
+// "/dev/disk/by-id/dm-name-vg-data", that is also resolved. A source the
+// kernel reports that does not exist, ex. "/dev/root", is resolved by
+// the mount's MajorMinor and "<SysRoot>/dev/block/<major>:<minor>". A
+// source in /dev is resolved in DevRoot, so the kernel's source and its
+// fallback are both returned in DevRoot. The source is returned as-is if
+// it is not a block device, ex. the source of an NFS mount or a bind
+// mount of a directory. An error wrapping ErrDeviceNotFound is returned
+// if the device does not exist.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) ResolveSource(ctx context.Context, i Info) (string, error) {
+	return fs.resolveSource(ctx, i)
+}
+
+// DiffMount returns the differences between the mount at target and the
+// desired mount spec. The desired Device and Type are compared only if
+// they are not empty, and the Device fields are compared after their
+// symlinks are evaluated. The options in KernelDefaultMountOptions and
+// the options interpreted by userspace, ex. "_netdev", are ignored.
+// ErrNotMounted is returned if nothing is mounted at target.
+func (fs *FS) DiffMount(
+	ctx context.Context, target string, desired Info) (MountDiff, error) {
+
+	return fs.diffMount(ctx, target, desired)
+}
+
+// CloneMountOptions returns the options of the mount at target so they
+// may be used to create another mount with the same options, ex. a
+// sibling bind mount. The options are the mount's per super block
+// options, ex. "discard", and its per-mount options, ex. "nosuid", which
+// take precedence, without the options in KernelDefaultMountOptions.
+// ErrNotMounted is returned if nothing is mounted at target.
+func (fs *FS) CloneMountOptions(
+	ctx context.Context, target string) ([]string, error) {
+
+	return fs.cloneMountOptions(ctx, target)
+}
+
+// GetTopMount returns the mount at target that is visible, which is the
+// last of the mounts stacked at target in the mount table, and a flag
+// indicating whether anything is mounted at target. Symlinks in target
+// are evaluated first. Only the mounts returned by the FS's ScanEntry
+// function are considered, so the default function, which omits mounts
+// whose source is not a path, ex. tmpfs, hides such mounts at target.
+func (fs *FS) GetTopMount(
+	ctx context.Context, target string) (Info, bool, error) {
+
+	return fs.lookupTopMount(ctx, target)
+}
+
+// SetAtimePolicy remounts the mount at target with the atime policy,
+// which must be AtimePolicyRelatime, AtimePolicyNoatime, or
+// AtimePolicyStrictatime. The atime options of the mount are replaced by
+// policy and its other options, as returned by GetMountByTarget, are
+// preserved. An error wrapping ErrNotMounted is returned if nothing is
+// mounted at target.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) SetAtimePolicy(
+	ctx context.Context, target, policy string) error {
+
+	if err := ValidateMountTarget(target); err != nil {
+		return err
+	}
+	return fs.setAtimePolicy(ctx, target, policy)
+}
+
+// PromoteToReadWrite remounts the mount at target read-write, ex. after
+// a read-only mount of a volume has been validated. The "ro" option of
+// the mount is replaced by "rw" and its other options, as returned by
+// GetMountByTarget, are preserved. An error wrapping ErrNotMounted is
+// returned if nothing is mounted at target.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) PromoteToReadWrite(ctx context.Context, target string) error {
+	if err := ValidateMountTarget(target); err != nil {
+		return err
+	}
+	return fs.remountWithOption(ctx, target, "rw")
+}
+
+// DemoteToReadOnly remounts the mount at target read-only. The "rw"
+// option of the mount is replaced by "ro" and its other options, as
+// returned by GetMountByTarget, are preserved. An error wrapping
+// ErrNotMounted is returned if nothing is mounted at target.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) DemoteToReadOnly(ctx context.Context, target string) error {
+	if err := ValidateMountTarget(target); err != nil {
+		return err
+	}
+	return fs.remountWithOption(ctx, target, "ro")
+}
+
+// GetMountErrorState returns the error state of the mount at target:
+// MountErrorStateOffline if the state of the mount's device in
+// "<SysRoot>/block/<dev>/device/state" is offline,
+// MountErrorStateErrors if the mount is of an ext filesystem whose
+// superblock records a non-zero error count, as reported by
+// 'dumpe2fs -h', and MountErrorStateHealthy otherwise. Only the device
+// state is checked for other filesystem types. An error wrapping
+// ErrNotMounted is returned if nothing is mounted at target.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) GetMountErrorState(
+	ctx context.Context, target string) (state string, err error) {
+
+	return fs.getMountErrorState(ctx, target)
+}
+
+// IsReadOnlyDueToErrors returns a flag indicating whether the mount at
+// target is read-only because the kernel remounted its filesystem
+// read-only after detecting errors, ex. an ext4 filesystem mounted with
+// "errors=remount-ro", rather than because it was mounted read-only. A
+// mount with the "ro" per-mount option was mounted read-only. A mount
+// with only the "ro" per-superblock option is read-only due to errors if
+// its superblock, as reported by 'dumpe2fs -h', records errors. Only ext
+// filesystems are remounted read-only on errors, so false is returned
+// for other filesystem types. An error wrapping ErrNotMounted is
+// returned if nothing is mounted at target.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) IsReadOnlyDueToErrors(
+	ctx context.Context, target string) (bool, error) {
+
+	return fs.isReadOnlyDueToErrors(ctx, target)
+}
+
+// GetMountUsers returns the processes that have a file beneath target
+// open or that use a directory beneath target as their working or root
+// directory, ex. to report why an unmount of target failed because it
+// is busy. The processes are read from ProcRoot. Only the processes
+// whose links in ProcRoot the caller may read are reported.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) GetMountUsers(
+	ctx context.Context, target string) ([]ProcessInfo, error) {
+
+	return fs.getMountUsers(ctx, target)
+}
+
 // GetMounts returns a slice of all the mounted filesystems.
 //
 // * Linux hosts use mount_namespaces to obtain mount information.
@@ -77,11 +726,225 @@ func (fs *FS) GetMounts(ctx context.Context) ([]Info, error) {
 	return fs.getMounts(ctx)
 }
 
+// GetMountsWith returns a slice of all the mounted filesystems like
+// GetMounts, but processes the entries of the mount table with scan
+// instead of the FS's ScanEntry. The FS is not modified, so a shared FS
+// may be used concurrently. Please see WithEntryScanFunc to override
+// the scan function of other functions.
+func (fs *FS) GetMountsWith(
+	ctx context.Context, scan EntryScanFunc) ([]Info, error) {
+
+	return fs.getMounts(WithEntryScanFunc(ctx, scan))
+}
+
+// GetMountTree returns the mounted filesystems as a tree built from the
+// ID and ParentID of each mount, ex. for diagnostics. The node at the top
+// of the tree does not describe a mount. Its children are the roots of
+// the tree: the root mount of the process and any mount whose parent is
+// not among the mounts returned by GetMounts, ex. because ScanEntry
+// omitted the parent.
+//
+// Darwin hosts do not report the ID of a mount, so every mount is a
+// child of the node at the top of the tree.
+func (fs *FS) GetMountTree(ctx context.Context) (*MountNode, error) {
+	return fs.getMountTree(ctx)
+}
+
+// SnapshotMounts returns a snapshot of the mounted filesystems, as
+// returned by GetMounts, ex. for a monitoring agent that detects mounts
+// that are never cleaned up by comparing snapshots with DiffMounts.
+func (fs *FS) SnapshotMounts(ctx context.Context) (MountSnapshot, error) {
+	return fs.snapshotMounts(ctx)
+}
+
+// WalkMounts invokes fn for each of the mounted filesystems without
+// accumulating the entire mount table in memory. The walk ends when fn
+// returns true or an error, or when the context is cancelled, in which
+// case the context's error is returned.
+//
+// Unlike GetMounts, WalkMounts reads the mount table once and does not
+// verify it was read consistently.
+func (fs *FS) WalkMounts(
+	ctx context.Context, fn func(Info) (stop bool, err error)) error {
+
+	return fs.walkMounts(ctx, fn)
+}
+
+// GetMountsWhere returns the mounted filesystems for which pred returns
+// true. Like WalkMounts, the mount table is not accumulated in memory;
+// only the matching entries are retained. A nil pred matches every mount.
+func (fs *FS) GetMountsWhere(
+	ctx context.Context, pred func(Info) bool) ([]Info, error) {
+
+	return fs.getMountsWhere(ctx, pred)
+}
+
+// GetMountsForPID returns a slice of all the filesystems mounted in the
+// mount namespace of the process with the provided PID. The mount table
+// is read from "<ProcRoot>/<pid>/mountinfo".
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) GetMountsForPID(ctx context.Context, pid int) ([]Info, error) {
+	return fs.getMountsForPID(ctx, pid)
+}
+
+// GetMtabMounts returns the mounts recorded in the mtab file in EtcRoot.
+// On most hosts mtab is a symlink to /proc/self/mounts, but on some it is
+// a file maintained by mount(8) that may diverge from the mount table of
+// the kernel. The entries are processed with ScanEntry and filtered like
+// those returned by GetMounts. The Root of each mount is "/" since mtab
+// does not record it.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) GetMtabMounts(ctx context.Context) ([]Info, error) {
+	return fs.getMtabMounts(ctx)
+}
+
+// CompareMountSources compares the mounts recorded in the mtab file with
+// the mount table of the kernel, as returned by GetMounts, and returns
+// the mounts found only in mtab and the mounts found only in the kernel's
+// mount table. Mounts are matched by their Device, Path, and Type. This
+// helps diagnose a host on which the mounts tracked by userspace are
+// stale.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) CompareMountSources(
+	ctx context.Context) (onlyInMtab, onlyInProc []Info, err error) {
+
+	return fs.compareMountSources(ctx)
+}
+
 // GetDevMounts returns a slice of all mounts for the provided device.
+// On Linux the symlinks in the device, and in the devices of the mount
+// table, are evaluated so a device and any of its aliases, ex. in
+// /dev/disk/by-id or /dev/mapper, have the same mounts.
 func (fs *FS) GetDevMounts(ctx context.Context, dev string) ([]Info, error) {
 	return fs.getDevMounts(ctx, dev)
 }
 
+// GetDevMountsMulti returns the mounts of each of the provided devices
+// keyed by the device as provided. The symlinks in the devices, and in
+// the devices of the mount table, are evaluated so a device and a symlink
+// to it, ex. in /dev/disk/by-id, have the same mounts. Unlike calling
+// GetDevMounts for each device, the mount table is read only once. A
+// device without any mounts is not present in the returned map.
+func (fs *FS) GetDevMountsMulti(
+	ctx context.Context, devs []string) (map[string][]Info, error) {
+
+	return fs.getDevMountsMulti(ctx, devs)
+}
+
+// GetDevMountsWithRoot returns a slice of all mounts for the provided
+// device with a root that is equal to or beneath the provided root, ex.
+// all mounts of a btrfs subvolume.
+func (fs *FS) GetDevMountsWithRoot(
+	ctx context.Context, dev, root string) ([]Info, error) {
+
+	return fs.getDevMountsWithRoot(ctx, dev, root)
+}
+
+// GetDeviceForPath returns the device and root of the mount that contains
+// the provided path, which may be any file or directory, ex.
+// "/var/lib/data/file.db". The mount is the one with the longest mount
+// point that is the path or one of its parents. Symlinks in the path are
+// evaluated first. An error wrapping ErrNotMounted is returned if no
+// mount contains the path.
+func (fs *FS) GetDeviceForPath(
+	ctx context.Context, path string) (device, root string, err error) {
+
+	return fs.getDeviceForPath(ctx, path)
+}
+
+// SameFilesystem returns a flag indicating whether pathA and pathB are on
+// the same filesystem, ex. to decide whether a file may be renamed from
+// one path to the other. Symlinks in the paths are evaluated first, and
+// both paths must exist. The paths are on different filesystems if their
+// st_dev differs. Paths with the same st_dev are also on different
+// filesystems if the mounts that contain them, as returned by GetMounts,
+// have different roots, ex. bind mounts of two directories of the same
+// filesystem.
+func (fs *FS) SameFilesystem(
+	ctx context.Context, pathA, pathB string) (bool, error) {
+
+	return fs.sameFilesystem(ctx, pathA, pathB)
+}
+
+// GetFuseMounts returns the mounted FUSE filesystems, ex. sshfs, s3fs, or
+// gocryptfs, which are the mounts with a Type that begins with "fuse".
+// The Source field of each mount is set to the mount source, ex.
+// "user@host:/data", rather than the path of a mount of the same source,
+// and the Device field is prefixed with the FUSE subtype, ex.
+// "sshfs#user@host:/data", so the source is not mistaken for a device.
+// The Device field of a "fuseblk" mount, ex. ntfs-3g, is the block device
+// and is not changed.
+//
+// GetDevMounts never returns a FUSE filesystem whose source is not a
+// block device.
+func (fs *FS) GetFuseMounts(ctx context.Context) ([]Info, error) {
+	return fs.getFuseMounts(ctx)
+}
+
+// MountFUSE mounts source to target by executing the FUSE helper, ex.
+// "sshfs" or "/usr/bin/s3fs", directly rather than the mount command,
+// with the arguments "-o <options> <source> <target>". The source is
+// omitted if it is empty. The filesystem is mounted with the type
+// "fuse.<helper>", ex. "fuse.sshfs", which is the type passed to the
+// PreMountHook and PostMountHook and usually the type reported by
+// GetMounts.
+func (fs *FS) MountFUSE(
+	ctx context.Context,
+	helper, source, target string,
+	options ...string) error {
+
+	if err := validateFUSEHelper(helper); err != nil {
+		return err
+	}
+	if err := validateMountSource(source); err != nil {
+		return err
+	}
+	if err := ValidateMountTarget(target); err != nil {
+		return err
+	}
+	if err := fs.checkMountOptions(options); err != nil {
+		return err
+	}
+	return fs.runMountHooks(
+		ctx, source, target, fuseHelperType(helper), options,
+		func() error {
+			return fs.mountFUSE(ctx, helper, source, target, options...)
+		})
+}
+
+// GetCIFSMounts returns the mounted CIFS/SMB filesystems, which are the
+// mounts with a Type of "cifs" or "smb3", or "smbfs" on Darwin hosts.
+func (fs *FS) GetCIFSMounts(ctx context.Context) ([]Info, error) {
+	return fs.getCIFSMounts(ctx)
+}
+
+// MountCIFS mounts the CIFS/SMB share unc, ex. "//server/share" or
+// `\\server\share`, to target with the provided options. The username,
+// password, and domain are written to a temporary credentials file that
+// is readable only by its owner and passed to mount.cifs with the
+// "credentials=" option, so the password does not appear in the
+// arguments of any process. The file is removed once the mount command
+// exits. If username is empty then the share is mounted with the "guest"
+// option and the password and domain are ignored. The credentials file
+// is created in os.TempDir, which must be visible to mount.cifs, ex.
+// when Nsenter is enabled.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) MountCIFS(
+	ctx context.Context,
+	unc, target, username, password, domain string,
+	options ...string) error {
+
+	if err := ValidateMountTarget(target); err != nil {
+		return err
+	}
+	return fs.mountCIFS(
+		ctx, unc, target, username, password, domain, options...)
+}
+
 // ValidateDevice evalutes the specified path and determines whether
 // or not it is a valid device. If true then the provided path is
 // evaluated and returned as an absolute path without any symlinks.
@@ -89,5 +952,931 @@ func (fs *FS) GetDevMounts(ctx context.Context, dev string) ([]Info, error) {
 func (fs *FS) ValidateDevice(
 	ctx context.Context, source string) (string, error) {
 
+	if err := ValidateDevicePath(source); err != nil {
+		return "", err
+	}
 	return fs.validateDevice(ctx, source)
 }
+
+// GetSELinuxMountContext returns the SELinux context of the filesystem
+// mounted at target, parsed from the "context" mount option. An empty
+// string is returned if the filesystem is mounted without a context.
+func (fs *FS) GetSELinuxMountContext(
+	ctx context.Context, target string) (string, error) {
+
+	return fs.getSELinuxMountContext(ctx, target)
+}
+
+// MountWithContext behaves like Mount but injects a "context" option
+// with the provided SELinux context. The context is quoted so that
+// values containing commas are passed to mount(8) intact.
+func (fs *FS) MountWithContext(
+	ctx context.Context,
+	source, target, fsType, seLinuxContext string,
+	options ...string) error {
+
+	return fs.mountWithContext(
+		ctx, source, target, fsType, seLinuxContext, options...)
+}
+
+// WaitForDevice polls for the existence of the provided device until it
+// appears or the context is cancelled. The device may be a symlink, such
+// as a "/dev/disk/by-id" entry, in which case the function waits for the
+// symlink's target to appear. The device's path is returned with all
+// symlinks evaluated.
+func (fs *FS) WaitForDevice(
+	ctx context.Context,
+	device string,
+	pollInterval time.Duration) (string, error) {
+
+	return fs.waitForDevice(ctx, device, pollInterval)
+}
+
+// CreateSinglePartition creates a single partition that spans the entire
+// device using sgdisk, triggers a reread of the device's partition table,
+// and waits for the partition's device to appear. The path to the
+// partition's device is returned, ex. "/dev/sdb1" or "/dev/nvme0n1p1".
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) CreateSinglePartition(
+	ctx context.Context, device string) (string, error) {
+
+	return fs.createSinglePartition(ctx, device)
+}
+
+// GetPartitionTableType returns the type of the partition table of the
+// device, ex. PartitionTableGPT or PartitionTableDOS, or an empty string
+// if the device does not have a partition table, ex. a blank device or
+// one with a filesystem on the whole device. The type is determined with
+// blkid, or with sgdisk or parted if blkid is not installed. Other types
+// are returned as reported by the tool, ex. "sun".
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) GetPartitionTableType(
+	ctx context.Context, device string) (string, error) {
+
+	if err := ValidateDevicePath(device); err != nil {
+		return "", err
+	}
+	return fs.getPartitionTableType(ctx, device)
+}
+
+// EnsureTargetPath creates the target of a mount if it does not exist.
+// Filesystem mounts require a directory target, while raw block device
+// bind mounts require a file target, so target is created as an empty
+// file if isBlock is true and as a directory otherwise. Missing parent
+// directories of a file target are created with a mode of 0750.
+//
+// No action is taken if target already exists as the expected type, and
+// an error is returned if target exists as the wrong type.
+func (fs *FS) EnsureTargetPath(
+	target string, isBlock bool, mode os.FileMode) error {
+
+	return fs.ensureTargetPath(target, isBlock, mode)
+}
+
+// GetDiskInfo returns information about the provided device that may be
+// used to decide whether or not it is safe to format the device: its
+// filesystem type, whether it or one of its partitions is mounted, by
+// any of its aliases, the devices that hold it open
+// (from "<SysRoot>/class/block/<dev>/holders"), and its partition table.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) GetDiskInfo(ctx context.Context, device string) (DiskInfo, error) {
+	if err := ValidateDevicePath(device); err != nil {
+		return DiskInfo{}, err
+	}
+	return fs.getDiskInfo(ctx, device)
+}
+
+// CreateBtrfsSubvolume creates the subvolume name beneath the btrfs
+// filesystem mounted at mountedRoot. The name must be relative and may
+// not escape mountedRoot.
+func (fs *FS) CreateBtrfsSubvolume(
+	ctx context.Context, mountedRoot, name string) error {
+
+	return fs.createBtrfsSubvolume(ctx, mountedRoot, name)
+}
+
+// GetBtrfsDevices returns the member devices of the multi-device btrfs
+// filesystem that contains anyMemberDevice, as reported by
+// 'btrfs filesystem show'. Since the mount table lists only one member
+// of a mounted btrfs filesystem, the result may be used to find the
+// mounts of the other members. Missing member devices are omitted.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) GetBtrfsDevices(
+	ctx context.Context, anyMemberDevice string) ([]string, error) {
+
+	if err := ValidateDevicePath(anyMemberDevice); err != nil {
+		return nil, err
+	}
+	return fs.getBtrfsDevices(ctx, anyMemberDevice)
+}
+
+// MountBtrfsSubvolume mounts the subvolume subvolName of the btrfs
+// filesystem on device to target. The "subvol" option is appended to
+// the provided options.
+func (fs *FS) MountBtrfsSubvolume(
+	ctx context.Context,
+	device, target, subvolName string,
+	options ...string) error {
+
+	return fs.mountBtrfsSubvolume(ctx, device, target, subvolName, options...)
+}
+
+// MountZFS mounts the ZFS dataset to target using "mount -t zfs".
+// ErrNotImplemented is returned if the host does not support ZFS.
+func (fs *FS) MountZFS(
+	ctx context.Context,
+	dataset, target string,
+	options ...string) error {
+
+	return fs.mountZFS(ctx, dataset, target, options...)
+}
+
+// GetZFSMounts returns a slice of the mounted ZFS datasets as reported
+// by "zfs list". ErrNotImplemented is returned if the zfs command is not
+// available.
+func (fs *FS) GetZFSMounts(ctx context.Context) ([]Info, error) {
+	return fs.getZFSMounts(ctx)
+}
+
+// ListFormattedUnmountedDevices returns the block devices that contain a
+// filesystem but are not mounted. Swap devices are excluded, as are the
+// partitions of a disk that is claimed in its entirety by a filesystem
+// signature of its own. Containers of other devices, ex. LVM physical
+// volumes and LUKS containers, and devices held by other devices, as
+// listed by lsblk or in "<SysRoot>/class/block/<dev>/holders", are not
+// free and are also excluded.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) ListFormattedUnmountedDevices(
+	ctx context.Context) ([]DiskInfo, error) {
+
+	return fs.listFormattedUnmountedDevices(ctx)
+}
+
+// GetParentDevice returns the path of the disk that contains the provided
+// device, ex. /dev/sda for the partition /dev/sda1, the disk that
+// contains the partition beneath a dm-crypt mapping, or the multipath
+// device /dev/mapper/mpatha for its partition /dev/mapper/mpatha1. A
+// disk, including a path member of a multipath device, is its own
+// parent. Partitions are resolved using SysRoot and other devices with
+// lsblk.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) GetParentDevice(
+	ctx context.Context, device string) (string, error) {
+
+	if err := ValidateDevicePath(device); err != nil {
+		return "", err
+	}
+	return fs.getParentDevice(ctx, device)
+}
+
+// GetDeviceAttachTime returns the approximate time the block device was
+// attached, which is the change time of its device node after symlinks
+// are evaluated. The node is created when the device is attached, but
+// its change time is updated when the node's owner or permissions
+// change, ex. by udev rules shortly after the device is attached, so the
+// time is suitable for ordering attachments but not as an exact
+// timestamp. An error wrapping ErrDeviceNotFound is returned if the
+// device does not exist.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) GetDeviceAttachTime(
+	ctx context.Context, device string) (time.Time, error) {
+
+	if err := ValidateDevicePath(device); err != nil {
+		return time.Time{}, err
+	}
+	return fs.getDeviceAttachTime(ctx, device)
+}
+
+// CanonicalizeDevice returns the kernel name of the provided device so
+// that the aliases of a device may be compared, ex. /dev/sda for
+// /dev/sda, /dev/disk/by-uuid/<uuid>, and /dev/disk/by-id/<id>. Symlinks
+// are evaluated, and the name of a device node that is not a symlink,
+// ex. /dev/mapper/mpatha on hosts that create device nodes in
+// /dev/mapper, is reported by lsblk. A path member of a multipath device,
+// and any alias of the multipath device itself, canonicalizes to the dm
+// device, ex. /dev/dm-0. The returned path is relative to DevRoot.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) CanonicalizeDevice(
+	ctx context.Context, device string) (string, error) {
+
+	if err := ValidateDevicePath(device); err != nil {
+		return "", err
+	}
+	return fs.canonicalizeDevice(ctx, device)
+}
+
+// SwapOn enables swapping on the provided device using swapon. The
+// options are passed to swapon before the device. No action is taken
+// if the device is already an active swap area.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) SwapOn(
+	ctx context.Context, device string, options ...string) error {
+
+	return fs.swapOn(ctx, device, options...)
+}
+
+// SwapOff disables swapping on the provided device using swapoff. No
+// action is taken if the device is not an active swap area.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) SwapOff(ctx context.Context, device string) error {
+	return fs.swapOff(ctx, device)
+}
+
+// MakeSwap sets up a swap area on the provided device using mkswap.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) MakeSwap(ctx context.Context, device string) error {
+	return fs.makeSwap(ctx, device)
+}
+
+// GetSwaps returns a slice of the active swap areas read from
+// "<ProcRoot>/swaps".
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) GetSwaps(ctx context.Context) ([]SwapInfo, error) {
+	return fs.getSwaps(ctx)
+}
+
+// SetReservedBlocksPercent sets the percentage of the ext filesystem's
+// blocks on the provided device that are reserved for the super-user
+// using "tune2fs -m". The percentage must be between 0 and 50.
+func (fs *FS) SetReservedBlocksPercent(
+	ctx context.Context, device string, percent float64) error {
+
+	return fs.setReservedBlocksPercent(ctx, device, percent)
+}
+
+// MountImage attaches the filesystem image at imagePath to the first
+// unused loop device with "losetup" and mounts the loop device to target.
+// The loop device is attached read-only if the options include "ro".
+// The path of the loop device is returned so that it may be detached
+// with UnmountImage. The loop device is detached if the mount fails.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) MountImage(
+	ctx context.Context,
+	imagePath, target, fsType string,
+	options ...string) (loopDevice string, err error) {
+
+	return fs.mountImage(ctx, imagePath, target, fsType, options...)
+}
+
+// UnmountImage unmounts target and then detaches the loop device
+// returned by MountImage.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) UnmountImage(
+	ctx context.Context, target, loopDevice string) error {
+
+	return fs.unmountImage(ctx, target, loopDevice)
+}
+
+// CreateLoopFilesystem creates a sparse file of sizeBytes at imagePath,
+// which must not exist, attaches it to the first unused loop device with
+// "losetup", and formats the loop device as fsType, ex. for an ephemeral
+// volume or a test. The path of the loop device is returned so that it
+// may be mounted, and deleted with DeleteLoopFilesystem. If fsType is
+// empty then the loop device is formatted as ext4. The loop device is
+// detached and the file removed if the loop filesystem cannot be
+// created.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) CreateLoopFilesystem(
+	ctx context.Context,
+	imagePath string,
+	sizeBytes uint64,
+	fsType string) (loopDevice string, err error) {
+
+	return fs.createLoopFilesystem(ctx, imagePath, sizeBytes, fsType)
+}
+
+// DeleteLoopFilesystem detaches the loop device returned by
+// CreateLoopFilesystem and removes its backing file, which must be the
+// imagePath provided to CreateLoopFilesystem so that a file the caller
+// did not create is never removed. An error is returned, and nothing is
+// detached or removed, if the loop device is backed by another file. An
+// error wrapping ErrAlreadyMounted is returned if the loop device or one
+// of its partitions is mounted.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) DeleteLoopFilesystem(
+	ctx context.Context, loopDevice, imagePath string) error {
+
+	if err := ValidateDevicePath(loopDevice); err != nil {
+		return err
+	}
+	return fs.deleteLoopFilesystem(ctx, loopDevice, imagePath)
+}
+
+// ProbeVolume checks that the filesystem of type fsType on device is
+// usable, ex. for the readiness check of a storage backend. The device is
+// mounted at a temporary directory, the file ProbeFileName is written to
+// the root of the filesystem, flushed, and read back, and its contents
+// are verified. The mount is subject to the FS's mount hooks. The
+// temporary directory is always unmounted, along with anything mounted
+// beneath it, and removed, even if the probe fails or the context is
+// cancelled; an error is returned if the cleanup fails.
+func (fs *FS) ProbeVolume(ctx context.Context, device, fsType string) error {
+	if err := validateMountSource(device); err != nil {
+		return err
+	}
+	return fs.probeVolume(ctx, device, fsType)
+}
+
+// GetLoopBackingFile returns the path of the file backing loopDevice, as
+// reported by "<SysRoot>/block/<loop>/loop/backing_file". The
+// " (deleted)" marker the kernel appends to the path of a deleted
+// backing file is removed. An error wrapping ErrDeviceNotFound is
+// returned if loopDevice does not exist.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) GetLoopBackingFile(
+	ctx context.Context, loopDevice string) (string, error) {
+
+	if err := ValidateDevicePath(loopDevice); err != nil {
+		return "", err
+	}
+	return fs.getLoopBackingFile(ctx, loopDevice)
+}
+
+// ListLoopDevices returns the attached loop devices, as reported by
+// 'losetup -J'.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) ListLoopDevices(ctx context.Context) ([]LoopInfo, error) {
+	return fs.listLoopDevices(ctx)
+}
+
+// EnableProjectQuota enables project quotas on the filesystem on the
+// provided device.
+//
+// Quotas cannot be enabled on an xfs filesystem once it is mounted, so
+// for xfs an error is returned unless the device is already mounted with
+// the "prjquota" option. For the ext family the project and quota
+// features are enabled with tune2fs, which requires the filesystem to be
+// unmounted.
+func (fs *FS) EnableProjectQuota(
+	ctx context.Context, device, fsType string) error {
+
+	return fs.enableProjectQuota(ctx, device, fsType)
+}
+
+// IsStaleNFSMount returns a flag indicating whether the filesystem mounted
+// at target is stale, ex. an NFS mount whose server is no longer
+// reachable. A mount is considered stale if a stat of the target fails
+// with ESTALE or does not complete within StatTimeout. The stat runs
+// in its own goroutine so that a hung call never blocks the caller. If
+// the context is cancelled or its deadline passes first then the
+// context's error is returned and the mount is not reported as stale.
+func (fs *FS) IsStaleNFSMount(
+	ctx context.Context, target string) (bool, error) {
+
+	return fs.isStaleNFSMount(ctx, target)
+}
+
+// RecoverStaleMount attempts to recover the filesystem mounted at target
+// if it is stale, as reported by IsStaleNFSMount, by unmounting it and
+// mounting it again with the source, type, and options of the mount as
+// returned by GetMountByTarget. A busy mount is unmounted lazily. Nothing
+// is done if the mount is not stale.
+//
+// The recovery is best-effort: the mount fails if the server is
+// unreachable or no longer exports the source, in which case the stale
+// mount has already been unmounted and an error is returned.
+//
+// Darwin hosts do not support lazy unmounts, so a busy mount cannot be
+// recovered.
+func (fs *FS) RecoverStaleMount(ctx context.Context, target string) error {
+	if err := ValidateMountTarget(target); err != nil {
+		return err
+	}
+	return fs.recoverStaleMount(ctx, target)
+}
+
+// GetNFSMountStats returns the statistics of the NFS filesystem mounted
+// at target, ex. its byte counters and the number of RPC requests that
+// were retransmitted, as reported by "/proc/self/mountstats" in
+// ProcRoot. Symlinks in target are evaluated first. An error wrapping
+// ErrNotMounted is returned if nothing is mounted at target, and a
+// *NotNFSMountError if the filesystem mounted at target is not an NFS
+// filesystem.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) GetNFSMountStats(
+	ctx context.Context, target string) (NFSStats, error) {
+
+	return fs.getNFSMountStats(ctx, target)
+}
+
+// GetEffectiveMountFlags returns the effective flags of the mount at
+// target. The flags are the mount's own options merged with the options
+// of the super block of the filesystem from which the mount originates,
+// which every bind mount of the filesystem shares, so a bind mount that
+// is read-only, or that is of a read-only filesystem, reports "ro".
+func (fs *FS) GetEffectiveMountFlags(
+	ctx context.Context, target string) ([]string, error) {
+
+	return fs.getEffectiveMountFlags(ctx, target)
+}
+
+// GetMountFSType returns the type of the filesystem of the topmost mount
+// at target. Symlinks in target are evaluated first. ErrNotMounted is
+// returned if nothing is mounted at target.
+func (fs *FS) GetMountFSType(
+	ctx context.Context, target string) (string, error) {
+
+	return fs.getMountFSType(ctx, target)
+}
+
+// GetDeviceByUUID returns the path of the device that contains the
+// filesystem with the provided UUID. The device is resolved using the
+// udev-managed symlinks in /dev/disk/by-uuid.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) GetDeviceByUUID(
+	ctx context.Context, uuid string) (string, error) {
+
+	return fs.getDeviceByUUID(ctx, uuid)
+}
+
+// MountByUUID mounts the device that contains the filesystem with the
+// expected UUID to target as fsType with the provided options. The device
+// is resolved as it is by GetDeviceByUUID and the UUID of the filesystem
+// on the device is then read with GetFSUUID, so a device whose
+// /dev/disk/by-uuid link is stale, ex. after a reattach changed the
+// names the kernel assigned to devices, is not mounted. A
+// *UUIDMismatchError is returned if the UUIDs differ. Since GetFSUUID
+// falls back to the by-uuid links if blkid fails, a stale link is only
+// detected if blkid is available.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) MountByUUID(
+	ctx context.Context,
+	expectedUUID, target, fsType string,
+	options ...string) error {
+
+	if err := ValidateMountTarget(target); err != nil {
+		return err
+	}
+	device, err := fs.resolveDeviceByUUID(ctx, expectedUUID)
+	if err != nil {
+		return err
+	}
+	return fs.mount(ctx, device, target, fsType, options...)
+}
+
+// GetDeviceByLabel returns the path of the device that contains the
+// filesystem with the provided label. The device is resolved using the
+// udev-managed symlinks in /dev/disk/by-label.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) GetDeviceByLabel(
+	ctx context.Context, label string) (string, error) {
+
+	return fs.getDeviceByLabel(ctx, label)
+}
+
+// GetDevicePathByPath returns the path of the device to which the
+// udev-managed symlink /dev/disk/by-path/<byPathSuffix> points, ex.
+// "pci-0000:00:1f.2-ata-1" or "pci-0000:00:10.0-scsi-0:0:1:0", with all
+// symlinks evaluated. An error wrapping ErrDeviceNotFound is returned if
+// there is no such link or it is dangling.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) GetDevicePathByPath(
+	ctx context.Context, byPathSuffix string) (string, error) {
+
+	return fs.getDevicePathByPath(ctx, byPathSuffix)
+}
+
+// SetFSLabel sets the label of the filesystem of type fsType on device.
+// The label is validated with ValidateFSLabel before it is applied.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) SetFSLabel(
+	ctx context.Context, device, fsType, label string) error {
+
+	return fs.setFSLabel(ctx, device, fsType, label)
+}
+
+// GetBlockDeviceSize returns the size of the provided block device in
+// bytes.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) GetBlockDeviceSize(
+	ctx context.Context, device string) (uint64, error) {
+
+	return fs.getBlockDeviceSize(ctx, device)
+}
+
+// CheckDeviceReady reports whether the provided device is ready to be
+// provisioned: the device is present, its size is expectedSize bytes,
+// and it is either unformatted or formatted as expectedFSType. An
+// expectedSize of zero matches a device of any size. The function waits
+// a short time for the device to appear, and a device that does not
+// appear is reported as not present rather than as an error.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) CheckDeviceReady(
+	ctx context.Context,
+	device string,
+	expectedSize uint64,
+	expectedFSType string) (DeviceReadyStatus, error) {
+
+	return fs.checkDeviceReady(ctx, device, expectedSize, expectedFSType)
+}
+
+// DiscardDevice discards all of the blocks on the provided device using
+// blkdiscard. All data on the device is lost. An error wrapping
+// ErrAlreadyMounted is returned if the device or one of its partitions
+// is mounted, by any of its aliases, and an error wrapping ErrDeviceBusy
+// if the device or one of its partitions is held by another device, ex.
+// a device-mapper device.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) DiscardDevice(ctx context.Context, device string) error {
+	return fs.discardDevice(ctx, device)
+}
+
+// TrimMount discards the unused blocks of the filesystem mounted at
+// target using fstrim.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) TrimMount(ctx context.Context, target string) error {
+	return fs.trimMount(ctx, target)
+}
+
+// SupportsDiscard returns a flag indicating whether the provided device
+// supports discard, ex. a thin-provisioned volume or an SSD, which is
+// the case if "<SysRoot>/block/<dev>/queue/discard_max_bytes" is not
+// zero. A partition supports discard if its disk does. An error
+// wrapping ErrDeviceNotFound is returned if the device has no request
+// queue in SysRoot.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) SupportsDiscard(
+	ctx context.Context, device string) (bool, error) {
+
+	if err := ValidateDevicePath(device); err != nil {
+		return false, err
+	}
+	return fs.supportsDiscard(ctx, device)
+}
+
+// GetMountsSorted returns a slice of all the mounted filesystems sorted
+// by the depth of their mount points and then by path, so that a mount
+// always appears after the mounts of its parent directories. Mounts
+// stacked on the same path retain their order from the mount table.
+func (fs *FS) GetMountsSorted(ctx context.Context) ([]Info, error) {
+	return fs.getMountsSorted(ctx)
+}
+
+// UnmountAndRemoveMapping unmounts target and then removes the
+// device-mapper mapping with the provided name, ex. the mapping of a
+// LUKS or linear device. A dm-crypt mapping is closed with
+// 'cryptsetup close' and any other mapping is removed with
+// 'dmsetup remove'. The mapping is not removed unless the unmount
+// succeeds or target is not mounted, and a mapping that has already been
+// removed is not an error.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) UnmountAndRemoveMapping(
+	ctx context.Context, target, mapName string) error {
+
+	return fs.unmountAndRemoveMapping(ctx, target, mapName)
+}
+
+// GetFSUUID returns the UUID of the filesystem on the provided device
+// using blkid. If blkid fails then the UUID is resolved from the links
+// in /dev/disk/by-uuid. An error is returned if the device does not
+// contain a filesystem with a UUID, ex. an unformatted device.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) GetFSUUID(ctx context.Context, device string) (string, error) {
+	return fs.getFSUUID(ctx, device)
+}
+
+// UnmountAll unmounts each of the provided targets. Targets are unmounted
+// in order of decreasing depth so that nested mounts are unmounted before
+// their parents. A failed unmount does not prevent the remaining targets
+// from being unmounted, and the returned error joins the errors of every
+// target that could not be unmounted.
+func (fs *FS) UnmountAll(ctx context.Context, targets []string) error {
+	return fs.unmountAll(ctx, targets)
+}
+
+// UnmountRecursive unmounts every mount at or beneath root, ex. to tear
+// down a directory that contains nested mounts. The mounts are unmounted
+// in order of decreasing depth so that nested mounts are unmounted before
+// their parents. A mount that is busy is unmounted lazily instead, which
+// detaches it immediately and cleans it up once it is no longer in use.
+// A failed unmount does not prevent the remaining mounts from being
+// unmounted, but no more mounts are unmounted once the context is
+// cancelled. The returned error joins the errors of every mount that
+// could not be unmounted.
+//
+// Darwin hosts do not support lazy unmounts, so a busy mount cannot be
+// unmounted.
+func (fs *FS) UnmountRecursive(ctx context.Context, root string) error {
+	return fs.unmountRecursive(ctx, root)
+}
+
+// UnmountDevice unmounts every mount of device, as returned by
+// GetDevMounts, ex. to clean up a device that is bind mounted to several
+// paths. The mounts are unmounted in order of decreasing depth, and a
+// mount that is busy is unmounted lazily instead. A failed unmount does
+// not prevent the remaining mounts from being unmounted, and the
+// returned error joins the errors of every mount that could not be
+// unmounted. Nothing is unmounted if the device is not mounted.
+//
+// Darwin hosts do not support lazy unmounts, so a busy mount cannot be
+// unmounted.
+func (fs *FS) UnmountDevice(ctx context.Context, device string) error {
+	if err := ValidateDevicePath(device); err != nil {
+		return err
+	}
+	return fs.unmountDevice(ctx, device)
+}
+
+// IsFSClean returns a flag indicating whether the filesystem of type
+// fsType on the provided device is clean, ex. before the filesystem is
+// mounted read-write. The state of an ext filesystem is read with
+// 'dumpe2fs -h', an xfs filesystem is checked with 'xfs_repair -n', and
+// a btrfs filesystem is checked with 'btrfs check --readonly'. None of
+// the checks modify the filesystem, which should not be mounted.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) IsFSClean(
+	ctx context.Context, device, fsType string) (bool, error) {
+
+	return fs.isFSClean(ctx, device, fsType)
+}
+
+// IsDeviceMounted returns a flag indicating whether the provided device
+// has any mounts. Symlinks in both dev and the devices in the mount table
+// are evaluated, so dev may be a path such as "/dev/disk/by-id/...". The
+// scan of the mount table ends as soon as a mount of the device is
+// found.
+func (fs *FS) IsDeviceMounted(ctx context.Context, dev string) (bool, error) {
+	return fs.isDeviceMounted(ctx, dev)
+}
+
+// GetMountAge returns the approximate amount of time the filesystem has
+// been mounted at target. An error wrapping ErrNotMounted is returned if
+// nothing is mounted at target.
+//
+// The kernel does not record when a filesystem is mounted, so the age is
+// derived from the time the root directory of the mounted filesystem
+// last changed. The age is accurate for a filesystem created when it is
+// mounted, ex. tmpfs, or for a bind mount of a newly created directory,
+// but is too short if the root directory changed after the filesystem
+// was mounted and too long if the root directory has not changed since
+// before it was mounted.
+func (fs *FS) GetMountAge(
+	ctx context.Context, target string) (time.Duration, error) {
+
+	return fs.getMountAge(ctx, target)
+}
+
+// GetMountKind returns the kind of the mount at target: MountKindBlock if
+// target is a block device, ex. a device bind mounted to a file for a
+// CSI block volume, MountKindBind if target is any other file or is a
+// bind mount of a directory, and MountKindFilesystem otherwise. An error
+// wrapping ErrNotMounted is returned if nothing is mounted at target.
+//
+// Bind mounts of directories are detected on a best-effort basis; please
+// see Info.LooksLikeBindMount for more information.
+func (fs *FS) GetMountKind(ctx context.Context, target string) (string, error) {
+	return fs.getMountKind(ctx, target)
+}
+
+// IsMountPoint returns a flag indicating whether a filesystem is mounted
+// at target. Symlinks in target are evaluated, and the scan of the mount
+// table ends as soon as a mount at target is found.
+func (fs *FS) IsMountPoint(ctx context.Context, target string) (bool, error) {
+	return fs.isMountPoint(ctx, target)
+}
+
+// WaitForUnmount polls the mount table until nothing is mounted at target
+// or the context is cancelled, ex. to wait for a lazy unmount to complete
+// before the path is reused. If pollInterval is not positive then the
+// mount table is polled once a second.
+func (fs *FS) WaitForUnmount(
+	ctx context.Context,
+	target string,
+	pollInterval time.Duration) error {
+
+	return fs.waitForUnmount(ctx, target, pollInterval)
+}
+
+// GetExt4Features returns the features enabled on the ext filesystem on
+// the provided device, ex. "64bit" or "metadata_csum", as reported by
+// 'dumpe2fs -h'. The features may be inspected before a filesystem is
+// resized, since resize2fs cannot grow a filesystem without the "64bit"
+// feature beyond 16TiB. An error wrapping ErrNotImplemented is returned
+// if the device does not contain an ext filesystem.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) GetExt4Features(
+	ctx context.Context, device string) ([]string, error) {
+
+	return fs.getExt4Features(ctx, device)
+}
+
+// GetDefaultMountOptions returns the default mount options stored in the
+// superblock of the ext filesystem on the provided device, ex.
+// "user_xattr" and "acl". The options are parsed from the output of
+// 'dumpe2fs -h'.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) GetDefaultMountOptions(
+	ctx context.Context, device string) ([]string, error) {
+
+	return fs.getDefaultMountOptions(ctx, device)
+}
+
+// SetDefaultMountOptions edits the default mount options stored in the
+// superblock of the ext filesystem on the provided device with
+// 'tune2fs -o'. An option without a prefix or with a "+" prefix is set,
+// ex. "journal_data_writeback", and an option with a "^" prefix is
+// cleared, ex. "^acl". The options not provided are left unchanged.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) SetDefaultMountOptions(
+	ctx context.Context, device string, opts []string) error {
+
+	return fs.setDefaultMountOptions(ctx, device, opts)
+}
+
+// GetFSLimits returns the block size and the maximum file and volume
+// sizes of the filesystem of type fsType on the provided device. The
+// limits are computed from the geometry reported by 'dumpe2fs -h' for
+// an ext filesystem and by 'xfs_info' for an xfs filesystem. An error
+// wrapping ErrNotImplemented is returned for other filesystem types.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) GetFSLimits(
+	ctx context.Context, device, fsType string) (FSLimits, error) {
+
+	return fs.getFSLimits(ctx, device, fsType)
+}
+
+// GetFSBlockSize returns the logical block size in bytes of the
+// filesystem of type fsType on the provided device, as reported by
+// 'dumpe2fs -h' for an ext filesystem, by 'xfs_info' for an xfs
+// filesystem, and by 'btrfs inspect-internal dump-super' for a btrfs
+// filesystem, whose block size is its sector size. An error wrapping
+// ErrNotImplemented is returned for other filesystem types.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) GetFSBlockSize(
+	ctx context.Context, device, fsType string) (uint32, error) {
+
+	return fs.getFSBlockSize(ctx, device, fsType)
+}
+
+// FSSupportsFeature returns a flag indicating whether the named feature
+// is enabled on the filesystem of type fsType on the provided device.
+// The features of an ext filesystem are read with 'dumpe2fs -h', ex.
+// "metadata_csum", those of an xfs filesystem are the flags set to 1 by
+// 'xfs_info', ex. "reflink" for "reflink=1", and those of a btrfs
+// filesystem are the lower-cased superblock flags reported by
+// 'btrfs inspect-internal dump-super', ex. "no_holes".
+//
+// Feature names are not case-sensitive, and the following names are
+// normalized across types: "reflink", which every btrfs filesystem
+// supports and no ext filesystem does, and "crc" or "metadata_csum", the
+// checksumming of metadata. An error wrapping ErrNotImplemented is
+// returned for other filesystem types.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) FSSupportsFeature(
+	ctx context.Context, device, fsType, feature string) (bool, error) {
+
+	if err := ValidateDevicePath(device); err != nil {
+		return false, err
+	}
+	return fs.fsSupportsFeature(ctx, device, fsType, feature)
+}
+
+// ShrinkFS shrinks the filesystem of type fsType on the provided device to
+// newSizeBytes, which must be less than the current size of the
+// filesystem and a multiple of its block size. Only the ext filesystems
+// may be shrunk, and an error wrapping ErrShrinkUnsupported is returned
+// for any other type, ex. xfs, which cannot be shrunk at all. The
+// filesystem must not be mounted and is checked with 'e2fsck -f' before
+// it is shrunk with resize2fs. The device itself is not resized.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) ShrinkFS(
+	ctx context.Context,
+	device, fsType string,
+	newSizeBytes uint64) error {
+
+	return fs.shrinkFS(ctx, device, fsType, newSizeBytes)
+}
+
+// GetUsableCapacity returns the capacity in bytes of the filesystem
+// mounted at path that may be used by writers other than root. A
+// filesystem may reserve blocks for root, ex. the 5% reserved by default
+// by mkfs.ext4, so statfs(2) reports both the free blocks, which root may
+// write, and the available blocks, which everyone else may write. The
+// difference between the two is the reserved blocks, which are
+// subtracted from the total capacity of the filesystem.
+func (fs *FS) GetUsableCapacity(
+	ctx context.Context, path string) (usableBytes uint64, err error) {
+
+	return fs.getUsableCapacity(ctx, path)
+}
+
+// GetPathUsage returns the disk usage in bytes of the subtree rooted at
+// path, which is the sum of the space allocated to each of its files and
+// directories, or the sum of their apparent sizes if the FS's
+// ApparentPathUsage flag is set. The walk does not cross into the
+// filesystems mounted beneath path, and a file with several hard links
+// is counted once. The walk stops with the context's error if the
+// context is canceled.
+func (fs *FS) GetPathUsage(
+	ctx context.Context, path string) (bytes uint64, err error) {
+
+	return fs.getPathUsage(ctx, path)
+}
+
+// MountIDMapped mounts source to target with the ownership of its files
+// remapped by uidMap and gidMap, ex. a mapping with a ContainerID of
+// 1000, a HostID of 0, and a Size of 1 causes the files owned by uid 1000
+// to appear owned by root through target. The mapped tree is cloned with
+// open_tree(2), mapped with mount_setattr(2), and attached to target with
+// move_mount(2). If fsType is empty then source is a directory whose tree
+// is mapped, and the options are limited to "ro", "nosuid", "nodev", and
+// "noexec"; otherwise source is mounted to target as fsType with the
+// provided options and the new mount is replaced by its mapped clone.
+// An error wrapping ErrIDMapUnsupported is returned if the kernel or the
+// filesystem does not support idmapped mounts.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) MountIDMapped(
+	ctx context.Context,
+	source, target, fsType string,
+	uidMap, gidMap []IDMapRange,
+	options ...string) error {
+
+	if err := validateMountSource(source); err != nil {
+		return err
+	}
+	if err := ValidateMountTarget(target); err != nil {
+		return err
+	}
+	return fs.mountIDMapped(
+		ctx, source, target, fsType, uidMap, gidMap, options...)
+}
+
+// MountRaw mounts source to target as fsType by calling the mount(2)
+// system call directly with the provided flags, ex.
+// unix.MS_NOEXEC|unix.MS_NOSUID, and data. Unlike Mount, the options are
+// not validated against AllowedOptions and DeniedOptions or passed
+// through the mount command, so data reaches the kernel as provided,
+// except that the options interpreted by userspace, ex. "_netdev", are
+// removed since the kernel rejects them. Please see SplitMountOptions.
+// Nsenter does not apply to this function.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) MountRaw(
+	source, target, fsType string, flags uintptr, data string) error {
+
+	return fs.mountRaw(source, target, fsType, flags, data)
+}
+
+// SupportedFilesystems returns the filesystem types registered with the
+// kernel as listed by /proc/filesystems in ProcRoot, ex. "ext4" or
+// "tmpfs". The list includes the types built into the kernel and those
+// of the modules that are loaded, but not those of the modules the
+// kernel would load on demand. Please see IsFSTypeSupported.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) SupportedFilesystems(ctx context.Context) ([]string, error) {
+	return fs.supportedFilesystems(ctx)
+}
+
+// IsFSTypeSupported returns a flag indicating whether the kernel is able
+// to mount a filesystem of type fsType. A type is supported if it is
+// listed by SupportedFilesystems or if a module of the running kernel in
+// ModulesRoot has the alias "fs-<fsType>", since the kernel loads such a
+// module on the first mount of the type.
+//
+// Darwin hosts do not support this function and return ErrNotImplemented.
+func (fs *FS) IsFSTypeSupported(
+	ctx context.Context, fsType string) (bool, error) {
+
+	return fs.isFSTypeSupported(ctx, fsType)
+}
@@ -0,0 +1,11 @@
+package gofsutil
+
+import "context"
+
+// isFSClean returns a flag indicating whether the filesystem on device
+// is clean
+func (fs *FS) isFSClean(
+	ctx context.Context, device, fsType string) (bool, error) {
+
+	return false, ErrNotImplemented
+}
@@ -0,0 +1,118 @@
+package gofsutil
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// errCommandNotFound is returned when a filesystem check command
+	// could not be found.
+	errCommandNotFound = errors.New("command not found")
+
+	// fsckErrors maps the output of a failed filesystem check to the
+	// error that describes the failure.
+	fsckErrors = []cmdError{
+		{regexp.MustCompile(`(?i)no such file or directory`),
+			ErrDeviceNotFound},
+	}
+)
+
+// isFSClean returns a flag indicating whether the filesystem on device
+// is clean. The filesystem is never modified.
+func (fs *FS) isFSClean(
+	ctx context.Context, device, fsType string) (bool, error) {
+
+	switch {
+	case isExtFS(fsType):
+		buf, err := fs.execFsckCmd(ctx, "dumpe2fs", "-h", device)
+		if err != nil {
+			return false, err
+		}
+		state, err := parseExtFSState(buf)
+		if err != nil {
+			return false, fmt.Errorf("%s: %v", device, err)
+		}
+		return state == "clean", nil
+	case fsType == "xfs":
+		return fs.checkExitStatus(ctx, "xfs_repair", "-n", device)
+	case fsType == "btrfs":
+		return fs.checkExitStatus(
+			ctx, "btrfs", "check", "--readonly", device)
+	}
+	return false, fmt.Errorf(
+		"filesystem check not supported: fsType=%s", fsType)
+}
+
+// parseExtFSState returns the value of the "Filesystem state" field of
+// the output of 'dumpe2fs -h', ex. "clean" or "not clean".
+func parseExtFSState(buf []byte) (string, error) {
+	return parseDumpe2fsField(buf, "Filesystem state")
+}
+
+// parseDumpe2fsField returns the value of the named field of the output
+// of 'dumpe2fs -h'.
+func parseDumpe2fsField(buf []byte, name string) (string, error) {
+	prefix := name + ":"
+	scan := bufio.NewScanner(bytes.NewReader(buf))
+	for scan.Scan() {
+		line := scan.Text()
+		if !strings.HasPrefix(line, prefix) {
+			continue
+		}
+		return strings.TrimSpace(strings.TrimPrefix(line, prefix)), nil
+	}
+	if err := scan.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("%s not found", strings.ToLower(name))
+}
+
+// checkExitStatus runs a read-only filesystem check and returns a flag
+// indicating whether it succeeded. A check that fails because it found
+// a problem with the filesystem is not an error, but a check that
+// could not be run, ex. because the device does not exist, is.
+func (fs *FS) checkExitStatus(
+	ctx context.Context, name string, args ...string) (bool, error) {
+
+	_, err := fs.execFsckCmd(ctx, name, args...)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, ErrDeviceNotFound) || errors.Is(err, errCommandNotFound) {
+		return false, err
+	}
+	return false, nil
+}
+
+// execFsckCmd runs one of the filesystem check commands
+func (fs *FS) execFsckCmd(
+	ctx context.Context, name string, args ...string) ([]byte, error) {
+
+	f := log.Fields{
+		"cmd":  name,
+		"args": args,
+	}
+	log.WithFields(f).Info("filesystem check command")
+
+	buf, err := fs.exec(ctx, name, args...)
+	if err != nil {
+		out := string(buf)
+		log.WithFields(f).WithField("output", out).WithError(
+			err).Error("filesystem check failed")
+		if isCommandNotFound(err) {
+			return buf, fmt.Errorf("%s: %w: %v", name, errCommandNotFound, err)
+		}
+		return buf, fmt.Errorf(
+			"%s failed: %w\narguments: %v\noutput: %s",
+			name, wrapCmdError(err, out, fsckErrors), args, out)
+	}
+	return buf, nil
+}
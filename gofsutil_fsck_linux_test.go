@@ -0,0 +1,120 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+const dumpe2fsCleanData = `dumpe2fs 1.46.5 (30-Dec-2021)
+Filesystem volume name:   <none>
+Last mounted on:          /mnt/data
+Filesystem UUID:          3e6be9de-8139-11d1-9106-a43f08d823a6
+Filesystem magic number:  0xEF53
+Filesystem revision #:    1 (dynamic)
+Filesystem features:      has_journal ext_attr resize_inode dir_index filetype extent 64bit flex_bg sparse_super large_file huge_file dir_nlink extra_isize metadata_csum
+Default mount options:    user_xattr acl
+Filesystem state:         clean
+Errors behavior:          Continue
+Filesystem OS type:       Linux
+Inode count:              65536
+Block count:              262144
+`
+
+const dumpe2fsNotCleanData = `dumpe2fs 1.46.5 (30-Dec-2021)
+Filesystem volume name:   <none>
+Last mounted on:          /mnt/data
+Filesystem UUID:          3e6be9de-8139-11d1-9106-a43f08d823a6
+Filesystem magic number:  0xEF53
+Filesystem revision #:    1 (dynamic)
+Filesystem state:         not clean with errors
+Errors behavior:          Continue
+Filesystem OS type:       Linux
+`
+
+func TestIsFSCleanExt4(t *testing.T) {
+	for _, tt := range []struct {
+		out   string
+		clean bool
+	}{
+		{dumpe2fsCleanData, true},
+		{dumpe2fsNotCleanData, false},
+	} {
+		out := tt.out
+		r := &testCommandRunner{
+			handler: func(args []string) (string, error) {
+				return out, nil
+			},
+		}
+		fs := &gofsutil.FS{RunCommand: r.run}
+
+		clean, err := fs.IsFSClean(context.TODO(), "/dev/sdb", "ext4")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if clean != tt.clean {
+			t.Errorf("invalid clean state: exp=%v, act=%v", tt.clean, clean)
+		}
+
+		// Only the read-only header dump may be run.
+		r.assertCommands(t, "dumpe2fs -h /dev/sdb")
+	}
+}
+
+func TestIsFSCleanXFS(t *testing.T) {
+	r := &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			if args[len(args)-1] == "/dev/sdc" {
+				return "ERROR: The filesystem has valuable metadata " +
+					"changes in a log which needs to be replayed.",
+					errors.New("exit status 1")
+			}
+			return "", nil
+		},
+	}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	clean, err := fs.IsFSClean(context.TODO(), "/dev/sdb", "xfs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !clean {
+		t.Error("clean xfs reported dirty")
+	}
+	clean, err = fs.IsFSClean(context.TODO(), "/dev/sdc", "xfs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if clean {
+		t.Error("dirty xfs reported clean")
+	}
+	clean, err = fs.IsFSClean(context.TODO(), "/dev/sdb", "btrfs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !clean {
+		t.Error("clean btrfs reported dirty")
+	}
+	r.assertCommands(t,
+		"xfs_repair -n /dev/sdb",
+		"xfs_repair -n /dev/sdc",
+		"btrfs check --readonly /dev/sdb")
+}
+
+func TestIsFSCleanDeviceNotFound(t *testing.T) {
+	r := newTestErrorRunner(
+		"/dev/sdz: No such file or directory\n" +
+			"could not initialize XFS library")
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	_, err := fs.IsFSClean(context.TODO(), "/dev/sdz", "xfs")
+	if !errors.Is(err, gofsutil.ErrDeviceNotFound) {
+		t.Errorf("expected ErrDeviceNotFound: %v", err)
+	}
+	if _, err := fs.IsFSClean(
+		context.TODO(), "/dev/sdz", "zfs"); err == nil {
+		t.Error("expected error for zfs")
+	}
+}
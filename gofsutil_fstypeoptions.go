@@ -0,0 +1,141 @@
+package gofsutil
+
+import (
+	"fmt"
+	"strings"
+)
+
+// UnsupportedMountOptionsError is returned by Mount when the FS's
+// StrictOptions field is true and options are not supported by the
+// filesystem type.
+type UnsupportedMountOptionsError struct {
+	// FSType is the filesystem type.
+	FSType string
+
+	// Options are the unsupported options.
+	Options []string
+}
+
+// Error returns the error message.
+func (e *UnsupportedMountOptionsError) Error() string {
+	return fmt.Sprintf("mount options unsupported by %s: %s",
+		e.FSType, strings.Join(e.Options, ","))
+}
+
+// vfsMountOptions are the names of the options that are supported by
+// every filesystem type because they are interpreted by the kernel's VFS
+// layer or by mount(8).
+var vfsMountOptions = toStringSet([]string{
+	"defaults", "ro", "rw", "suid", "nosuid", "dev", "nodev", "exec",
+	"noexec", "sync", "async", "dirsync", "atime", "noatime", "diratime",
+	"nodiratime", "relatime", "norelatime", "strictatime",
+	"nostrictatime", "lazytime", "nolazytime", "mand", "nomand", "silent",
+	"loud", "iversion", "noiversion", "symfollow", "nosymfollow",
+	"remount", "bind", "rbind", "move", "loop", "offset", "sizelimit",
+	"owner", "group", "user", "nouser", "users", "private", "rprivate",
+	"shared", "rshared", "slave", "rslave", "unbindable", "runbindable",
+	"context", "fscontext", "defcontext", "rootcontext",
+})
+
+// extMountOptions are the names of the options supported by the ext
+// filesystems.
+var extMountOptions = toStringSet([]string{
+	"acl", "noacl", "auto_da_alloc", "noauto_da_alloc", "barrier",
+	"nobarrier", "block_validity", "noblock_validity", "bsddf", "minixdf",
+	"commit", "data", "data_err", "dax", "debug", "delalloc",
+	"nodelalloc", "dioread_lock", "dioread_nolock", "discard",
+	"nodiscard", "errors", "grpid", "bsdgroups", "nogrpid", "sysvgroups",
+	"grpjquota", "usrjquota", "jqfmt", "i_version", "init_itable",
+	"noinit_itable", "inode_readahead_blks", "journal_async_commit",
+	"journal_checksum", "nojournal_checksum", "journal_dev",
+	"journal_path", "journal_ioprio", "max_batch_time", "min_batch_time",
+	"nombcache", "noload", "norecovery", "oldalloc", "orlov", "quota",
+	"noquota", "usrquota", "grpquota", "prjquota", "resgid", "resuid",
+	"sb", "stripe", "user_xattr", "nouser_xattr",
+})
+
+// fsTypeMountOptions maps the known filesystem types to the names of
+// the options they support in addition to the vfsMountOptions.
+var fsTypeMountOptions = map[string]map[string]struct{}{
+	"ext2": extMountOptions,
+	"ext3": extMountOptions,
+	"ext4": extMountOptions,
+	"xfs": toStringSet([]string{
+		"allocsize", "attr2", "noattr2", "dax", "discard", "nodiscard",
+		"filestreams", "grpid", "bsdgroups", "nogrpid", "sysvgroups",
+		"ikeep", "noikeep", "inode32", "inode64", "largeio", "nolargeio",
+		"logbufs", "logbsize", "logdev", "noalign", "norecovery",
+		"nouuid", "noquota", "uquota", "usrquota", "quota", "uqnoenforce",
+		"qnoenforce", "gquota", "grpquota", "gqnoenforce", "pquota",
+		"prjquota", "pqnoenforce", "rtdev", "sunit", "swidth", "swalloc",
+		"wsync",
+	}),
+	"btrfs": toStringSet([]string{
+		"acl", "noacl", "autodefrag", "noautodefrag", "barrier",
+		"nobarrier", "check_int", "check_int_data", "clear_cache",
+		"commit", "compress", "compress-force", "datacow", "nodatacow",
+		"datasum", "nodatasum", "degraded", "device", "discard",
+		"nodiscard", "enospc_debug", "noenospc_debug", "fatal_errors",
+		"flushoncommit", "noflushoncommit", "max_inline",
+		"metadata_ratio", "norecovery", "rescan_uuid_tree", "rescue",
+		"skip_balance", "space_cache", "nospace_cache", "ssd", "nossd",
+		"ssd_spread", "nossd_spread", "subvol", "subvolid",
+		"thread_pool", "treelog", "notreelog", "usebackuproot",
+		"user_subvol_rm_allowed",
+	}),
+	"tmpfs": toStringSet([]string{
+		"size", "nr_blocks", "nr_inodes", "mode", "uid", "gid", "mpol",
+		"huge", "inode32", "inode64", "noswap",
+	}),
+}
+
+// ValidateOptionsForFSType returns the options that are not supported by
+// the filesystem type, ex. "size=" for ext4, which only tmpfs supports,
+// or "discard" for tmpfs, which has no backing device to discard. An
+// option with a value is validated by its name. The options interpreted
+// by userspace and the options interpreted by the VFS layer, ex. "ro"
+// and "nosuid", are supported by every type. An error wrapping
+// ErrNotImplemented is returned if the type is not one of ext2, ext3,
+// ext4, xfs, btrfs, or tmpfs.
+func ValidateOptionsForFSType(
+	fsType string, opts []string) (unsupported []string, err error) {
+
+	supported, ok := fsTypeMountOptions[fsType]
+	if !ok {
+		return nil, fmt.Errorf("%w: fsType=%s", ErrNotImplemented, fsType)
+	}
+	for _, o := range opts {
+		if o == "" || IsUserspaceMountOption(o) {
+			continue
+		}
+		name := o
+		if i := strings.IndexByte(o, '='); i >= 0 {
+			name = o[:i]
+		}
+		if _, ok := vfsMountOptions[name]; ok {
+			continue
+		}
+		if _, ok := supported[name]; ok {
+			continue
+		}
+		unsupported = append(unsupported, o)
+	}
+	return unsupported, nil
+}
+
+// checkFSTypeOptions returns an *UnsupportedMountOptionsError if the FS's
+// StrictOptions field is true and any of opts are not supported by
+// fsType. Options of unknown filesystem types are not checked.
+func (fs *FS) checkFSTypeOptions(fsType string, opts []string) error {
+	if !fs.StrictOptions {
+		return nil
+	}
+	unsupported, err := ValidateOptionsForFSType(fsType, opts)
+	if err != nil || len(unsupported) == 0 {
+		return nil
+	}
+	return &UnsupportedMountOptionsError{
+		FSType:  fsType,
+		Options: unsupported,
+	}
+}
@@ -0,0 +1,78 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+func TestValidateOptionsForFSType(t *testing.T) {
+	tests := []struct {
+		fsType      string
+		opts        []string
+		unsupported []string
+	}{
+		{"ext4", []string{"size=1m"}, []string{"size=1m"}},
+		{"tmpfs", []string{"size=1m", "mode=0755"}, nil},
+		{"ext4", []string{"discard", "errors=remount-ro"}, nil},
+		{"xfs", []string{"discard", "nouuid"}, nil},
+		{"tmpfs", []string{"discard"}, []string{"discard"}},
+		{"btrfs", []string{"subvol=/data", "compress=zstd:3"}, nil},
+		{"xfs", []string{"subvol=/data", "data=ordered"},
+			[]string{"subvol=/data", "data=ordered"}},
+		{"ext4", []string{
+			"ro", "nosuid", "noatime", "_netdev", "x-systemd.automount",
+			"context=system_u:object_r:container_file_t:s0"}, nil},
+	}
+	for _, tt := range tests {
+		unsupported, err := gofsutil.ValidateOptionsForFSType(
+			tt.fsType, tt.opts)
+		if err != nil {
+			t.Fatalf("%s: %v: %v", tt.fsType, tt.opts, err)
+		}
+		if !reflect.DeepEqual(unsupported, tt.unsupported) {
+			t.Errorf("%s: %v: invalid unsupported options: exp=%v, act=%v",
+				tt.fsType, tt.opts, tt.unsupported, unsupported)
+		}
+	}
+
+	_, err := gofsutil.ValidateOptionsForFSType("zfs", []string{"ro"})
+	if !errors.Is(err, gofsutil.ErrNotImplemented) {
+		t.Errorf("expected ErrNotImplemented: %v", err)
+	}
+}
+
+func TestMountStrictOptions(t *testing.T) {
+	ctx := gofsutil.WithMountTable(context.TODO(), nil)
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{
+		RunCommand:    r.run,
+		StrictOptions: true,
+		ProcRoot:      newTestRootProcRoot(t),
+	}
+
+	err := fs.Mount(ctx, "/dev/sdb", "/mnt", "ext4", "discard", "size=1m")
+	var uErr *gofsutil.UnsupportedMountOptionsError
+	if !errors.As(err, &uErr) {
+		t.Fatalf("expected UnsupportedMountOptionsError: %v", err)
+	}
+	if exp := []string{"size=1m"}; !reflect.DeepEqual(uErr.Options, exp) {
+		t.Errorf("invalid unsupported options: exp=%v, act=%v",
+			exp, uErr.Options)
+	}
+	r.assertCommands(t)
+
+	// The options of unknown filesystem types are not checked.
+	if err := fs.Mount(ctx, "pool/data", "/mnt", "zfs", "zfsutil"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Mount(ctx, "/dev/sdb", "/mnt", "ext4", "discard"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t,
+		"mount -t zfs -o zfsutil pool/data /mnt",
+		"mount -t ext4 -o discard /dev/sdb /mnt")
+}
@@ -0,0 +1,91 @@
+package gofsutil
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// isFuseMount returns a flag indicating whether the mount is a FUSE
+// filesystem. The FUSE control filesystem, "fusectl", is not.
+func isFuseMount(m Info) bool {
+	return strings.HasPrefix(m.Type, "fuse") && m.Type != "fusectl"
+}
+
+// isFuseNonBlockMount returns a flag indicating whether the mount is a
+// FUSE filesystem whose source is not a block device. The source of a
+// "fuseblk" mount, ex. ntfs-3g, is a block device.
+func isFuseNonBlockMount(m Info) bool {
+	return isFuseMount(m) && m.Type != "fuseblk"
+}
+
+// getFuseMounts returns the FUSE filesystems with their Device fields
+// normalized by normalizeFuseMount
+func (fs *FS) getFuseMounts(ctx context.Context) ([]Info, error) {
+	mounts, err := fs.getMounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var fuseMounts []Info
+	for _, m := range mounts {
+		if isFuseMount(m) {
+			fuseMounts = append(fuseMounts, normalizeFuseMount(m))
+		}
+	}
+	return fuseMounts, nil
+}
+
+// normalizeFuseMount sets the Source field of a FUSE mount to its mount
+// source, ex. "user@host:/data", and the Device field to the source
+// prefixed with the FUSE subtype, ex. "sshfs#user@host:/data", the form
+// used for FUSE filesystems in fstab. The Device field of a mount whose
+// source is a block device is not changed.
+func normalizeFuseMount(m Info) Info {
+	if !isFuseNonBlockMount(m) {
+		return m
+	}
+	m.Source = m.Device
+	if subtype := strings.TrimPrefix(m.Type, "fuse."); subtype != m.Type {
+		m.Device = subtype + "#" + m.Device
+	}
+	return m
+}
+
+// fuseHelperType returns the type of the mounts created by the FUSE
+// helper, ex. "fuse.sshfs" for "/usr/bin/sshfs"
+func fuseHelperType(helper string) string {
+	return "fuse." + path.Base(helper)
+}
+
+// validateFUSEHelper returns an error if helper is empty or contains
+// characters that are not valid in the name or path of a program
+func validateFUSEHelper(helper string) error {
+	if helper == "" {
+		return fmt.Errorf("invalid fuse helper: empty helper")
+	}
+	if err := validatePathChars(helper); err != nil {
+		return fmt.Errorf("invalid fuse helper: %v", err)
+	}
+	if strings.HasPrefix(helper, "-") {
+		return fmt.Errorf("invalid fuse helper: %s", helper)
+	}
+	return nil
+}
+
+// mountFUSE mounts source to target by executing the FUSE helper
+func (fs *FS) mountFUSE(
+	ctx context.Context,
+	helper, source, target string,
+	opts ...string) error {
+
+	if err := fs.checkOvermount(ctx, target, opts); err != nil {
+		return err
+	}
+	if fs.AutoCreateTarget {
+		if err := createMountTarget(ctx, source, target, false); err != nil {
+			return err
+		}
+	}
+	return fs.doMountCmd(ctx, helper, source, target, "", opts...)
+}
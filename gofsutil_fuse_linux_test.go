@@ -0,0 +1,190 @@
+package gofsutil_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+const fuseMountInfoData = `60 1 253:0 / / rw,relatime shared:1 - xfs /dev/sda1 rw
+61 60 0:40 / /sys/fs/fuse/connections rw,relatime shared:20 - fusectl fusectl rw
+72 60 0:50 / /mnt/remote rw,nosuid,nodev,relatime shared:28 - fuse.sshfs user@host:/data rw,user_id=0,group_id=0
+73 60 0:50 / /mnt/remote2 rw,nosuid,nodev,relatime shared:29 - fuse.sshfs user@host:/data rw,user_id=0,group_id=0
+74 60 0:51 / /mnt/plain rw,nosuid,nodev,relatime shared:30 - fuse.gocryptfs /home/u/.cipher rw,user_id=0,group_id=0
+75 60 8:17 / /mnt/win rw,relatime shared:31 - fuseblk /dev/sdb1 rw,user_id=0,group_id=0
+`
+
+func TestGetFuseMounts(t *testing.T) {
+	procRoot := newTestProcRoot(t, fuseMountInfoData, "self")
+
+	fs := &gofsutil.FS{ProcRoot: procRoot}
+	mounts, err := fs.GetFuseMounts(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []struct {
+		path   string
+		device string
+		source string
+	}{
+		{"/mnt/remote", "sshfs#user@host:/data", "user@host:/data"},
+		{"/mnt/remote2", "sshfs#user@host:/data", "user@host:/data"},
+		{"/mnt/plain", "gocryptfs#/home/u/.cipher", "/home/u/.cipher"},
+		{"/mnt/win", "/dev/sdb1", "/dev/sdb1"},
+	}
+	if len(mounts) != len(exp) {
+		t.Fatalf("invalid mount count: exp=%d, act=%d: %+v",
+			len(exp), len(mounts), mounts)
+	}
+	for i, e := range exp {
+		m := mounts[i]
+		if m.Path != e.path || m.Device != e.device || m.Source != e.source {
+			t.Errorf("invalid mount: exp=%+v, act=%+v", e, m)
+		}
+	}
+
+	for _, dev := range []string{"user@host:/data", "/home/u/.cipher"} {
+		devMounts, err := fs.GetDevMounts(context.TODO(), dev)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(devMounts) != 0 {
+			t.Errorf("%s: unexpected device mounts: %+v", dev, devMounts)
+		}
+	}
+	devMounts, err := fs.GetDevMounts(context.TODO(), "/dev/sdb1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(devMounts) != 1 {
+		t.Errorf("/dev/sdb1: invalid mount count: exp=1, act=%d",
+			len(devMounts))
+	}
+}
+
+// newTestFuseMountRunner returns a command runner that records each
+// mount in the mountinfo file of the "self" process beneath procRoot.
+// The mounts created by the mount command have the type passed with -t
+// and the mounts created by a FUSE helper have the type
+// "fuse.<helper>".
+func newTestFuseMountRunner(t *testing.T, procRoot string) *testCommandRunner {
+	return &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			fsType := "fuse." + path.Base(args[0])
+			if args[0] == "mount" && len(args) > 2 && args[1] == "-t" {
+				fsType = args[2]
+			}
+			source, target := args[len(args)-2], args[len(args)-1]
+			line := fmt.Sprintf(
+				"80 60 0:52 / %s rw,nosuid,nodev,relatime shared:40 - "+
+					"%s %s rw,user_id=0,group_id=0\n",
+				target, fsType, source)
+			f, err := os.OpenFile(path.Join(procRoot, "self", "mountinfo"),
+				os.O_APPEND|os.O_WRONLY, 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			_, err = f.WriteString(line)
+			return "", err
+		},
+	}
+}
+
+func TestMountSubtype(t *testing.T) {
+	procRoot := newTestProcRoot(t, fuseMountInfoData, "self")
+
+	r := newTestFuseMountRunner(t, procRoot)
+	fs := &gofsutil.FS{ProcRoot: procRoot, RunCommand: r.run}
+
+	if err := fs.Mount(context.TODO(),
+		"user@host:/srv", "/mnt/srv", "fuse.sshfs", "ro"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t, "mount -t fuse.sshfs -o ro user@host:/srv /mnt/srv")
+
+	mounts, err := fs.GetMounts(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, m := range mounts {
+		if m.Path != "/mnt/srv" {
+			continue
+		}
+		found = true
+		if m.Type != "fuse.sshfs" {
+			t.Errorf("invalid type: exp=fuse.sshfs, act=%s", m.Type)
+		}
+		if m.Device != "user@host:/srv" {
+			t.Errorf("invalid device: exp=user@host:/srv, act=%s", m.Device)
+		}
+	}
+	if !found {
+		t.Fatalf("mount not found: %+v", mounts)
+	}
+
+	fsType, err := fs.GetMountFSType(context.TODO(), "/mnt/srv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fsType != "fuse.sshfs" {
+		t.Errorf("invalid fsType: exp=fuse.sshfs, act=%s", fsType)
+	}
+}
+
+func TestMountFUSE(t *testing.T) {
+	procRoot := newTestProcRoot(t, fuseMountInfoData, "self")
+
+	var hookFSType string
+	r := newTestFuseMountRunner(t, procRoot)
+	fs := &gofsutil.FS{
+		ProcRoot:   procRoot,
+		RunCommand: r.run,
+		PreMountHook: func(
+			ctx context.Context,
+			source, target, fsType string,
+			opts []string) error {
+
+			hookFSType = fsType
+			return nil
+		},
+	}
+
+	if err := fs.MountFUSE(context.TODO(), "sshfs",
+		"user@host:/srv", "/mnt/srv", "reconnect", "ro"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t, "sshfs -o reconnect,ro user@host:/srv /mnt/srv")
+	if hookFSType != "fuse.sshfs" {
+		t.Errorf("invalid hook fsType: exp=fuse.sshfs, act=%s", hookFSType)
+	}
+
+	mounts, err := fs.GetFuseMounts(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := mounts[len(mounts)-1]
+	if m.Path != "/mnt/srv" || m.Type != "fuse.sshfs" ||
+		m.Device != "sshfs#user@host:/srv" {
+		t.Errorf("invalid mount: %+v", m)
+	}
+
+	// The target is already mounted.
+	if err := fs.MountFUSE(context.TODO(),
+		"sshfs", "user@host:/srv", "/mnt/srv"); err == nil {
+		t.Error("expected error for mounted target")
+	}
+	for _, helper := range []string{"", "sshfs;reboot", "-o"} {
+		if err := fs.MountFUSE(context.TODO(),
+			helper, "user@host:/srv", "/mnt/other"); err == nil {
+			t.Errorf("expected error for helper %q", helper)
+		}
+	}
+	r.assertCommands(t, "sshfs -o reconnect,ro user@host:/srv /mnt/srv")
+}
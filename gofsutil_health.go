@@ -0,0 +1,24 @@
+package gofsutil
+
+const (
+	// MountErrorStateHealthy is the state of a mount whose device is
+	// online and whose filesystem has not recorded any errors.
+	MountErrorStateHealthy = "healthy"
+
+	// MountErrorStateErrors is the state of a mount whose filesystem has
+	// recorded errors, ex. I/O errors or corruption detected by the
+	// kernel.
+	MountErrorStateErrors = "errors"
+
+	// MountErrorStateOffline is the state of a mount whose device has
+	// been taken offline, ex. by the SCSI error handler.
+	MountErrorStateOffline = "offline"
+)
+
+// offlineDeviceStates are the values of "<SysRoot>/block/<dev>/device/state"
+// of a device that is offline.
+var offlineDeviceStates = map[string]struct{}{
+	"offline":           {},
+	"transport-offline": {},
+	"dead":              {},
+}
@@ -0,0 +1,18 @@
+package gofsutil
+
+import "context"
+
+// getMountErrorState returns the error state of the mount at target
+func (fs *FS) getMountErrorState(
+	ctx context.Context, target string) (string, error) {
+
+	return "", ErrNotImplemented
+}
+
+// isReadOnlyDueToErrors returns a flag indicating whether the mount at
+// target is read-only because its filesystem recorded errors
+func (fs *FS) isReadOnlyDueToErrors(
+	ctx context.Context, target string) (bool, error) {
+
+	return false, ErrNotImplemented
+}
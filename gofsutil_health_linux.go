@@ -0,0 +1,136 @@
+package gofsutil
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// getMountErrorState returns the error state of the mount at target
+func (fs *FS) getMountErrorState(
+	ctx context.Context, target string) (string, error) {
+
+	m, err := fs.getTopMount(ctx, target)
+	if err != nil {
+		return "", err
+	}
+	f := log.Fields{
+		"target": target,
+		"device": m.Device,
+		"fsType": m.Type,
+	}
+
+	// The superblock of a filesystem on an offline device cannot be read.
+	offline, err := fs.isDeviceOffline(m.Device)
+	if err != nil {
+		return "", err
+	}
+	if offline {
+		log.WithFields(f).Warn("mount device is offline")
+		return MountErrorStateOffline, nil
+	}
+
+	if isExtFS(m.Type) {
+		n, err := fs.getExtErrorCount(ctx, m.Device)
+		if err != nil {
+			return "", err
+		}
+		if n > 0 {
+			log.WithFields(f).WithField("errorCount", n).Warn(
+				"mount filesystem has errors")
+			return MountErrorStateErrors, nil
+		}
+	}
+	return MountErrorStateHealthy, nil
+}
+
+// isReadOnlyDueToErrors returns a flag indicating whether the mount at
+// target is read-only because its filesystem recorded errors
+func (fs *FS) isReadOnlyDueToErrors(
+	ctx context.Context, target string) (bool, error) {
+
+	m, err := fs.getTopMount(ctx, target)
+	if err != nil {
+		return false, err
+	}
+
+	// A read-only mount has the "ro" per-mount option, which the kernel
+	// does not set when it remounts a filesystem read-only on errors. It
+	// sets the "ro" per-superblock option instead.
+	if hasMountOpt(m.Opts, "ro") || !hasMountOpt(m.SuperOpts, "ro") ||
+		!isExtFS(m.Type) {
+		return false, nil
+	}
+	buf, err := fs.dumpe2fs(ctx, m.Device)
+	if err != nil {
+		return false, err
+	}
+	errs := extFSHasErrors(buf)
+	if errs {
+		log.WithFields(logFields(ctx, log.Fields{
+			"target": target,
+			"device": m.Device,
+			"fsType": m.Type,
+		})).Warn("mount is read-only due to filesystem errors")
+	}
+	return errs, nil
+}
+
+// extFSHasErrors returns a flag indicating whether the superblock of an
+// ext filesystem, as reported by 'dumpe2fs -h', records errors, either
+// in its state, ex. "clean with errors", or its error count. dumpe2fs
+// omits the error count if it is zero.
+func extFSHasErrors(buf []byte) bool {
+	if state, err := parseExtFSState(buf); err == nil &&
+		strings.HasSuffix(state, "with errors") {
+		return true
+	}
+	v, err := parseDumpe2fsField(buf, "FS Error count")
+	if err != nil {
+		return false
+	}
+	n, err := strconv.Atoi(v)
+	return err == nil && n > 0
+}
+
+// isDeviceOffline returns a flag indicating whether the state of device
+// in SysRoot is offline. A device without a state, ex. the source of an
+// NFS mount, is not offline.
+func (fs *FS) isDeviceOffline(device string) (bool, error) {
+	name := fs.sysBlockDiskName(device)
+	buf, err := ioutil.ReadFile(fs.sysPath("block", name, "device", "state"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	_, ok := offlineDeviceStates[strings.TrimSpace(string(buf))]
+	return ok, nil
+}
+
+// getExtErrorCount returns the number of errors recorded in the
+// superblock of the ext filesystem on device. dumpe2fs omits the error
+// count if it is zero.
+func (fs *FS) getExtErrorCount(
+	ctx context.Context, device string) (int, error) {
+
+	buf, err := fs.dumpe2fs(ctx, device)
+	if err != nil {
+		return 0, err
+	}
+	v, err := parseDumpe2fsField(buf, "FS Error count")
+	if err != nil {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("invalid error count: %s: %q", device, v)
+	}
+	return n, nil
+}
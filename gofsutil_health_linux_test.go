@@ -0,0 +1,175 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+// dumpe2fsErrorsData is the output of 'dumpe2fs -h' for a filesystem
+// that has recorded errors.
+const dumpe2fsErrorsData = `dumpe2fs 1.46.5 (30-Dec-2021)
+Filesystem volume name:   <none>
+Last mounted on:          /mnt/logs
+Filesystem UUID:          5c1b7a4e-2f0d-4d8a-9f8e-3a6f0b1c2d3e
+Filesystem magic number:  0xEF53
+Filesystem state:         clean
+Errors behavior:          Continue
+FS Error count:           3
+First error time:         Mon Mar  4 10:12:31 2024
+First error function:     ext4_lookup
+First error line #:       1785
+Last error time:          Mon Mar  4 10:14:02 2024
+`
+
+// newTestDeviceStateSysRoot creates a temporary sys filesystem root in
+// which each of the provided disks has the provided device state.
+func newTestDeviceStateSysRoot(
+	t *testing.T, states map[string]string) string {
+
+	sysRoot := t.TempDir()
+	for dev, state := range states {
+		dir := path.Join(sysRoot, "block", dev, "device")
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(
+			path.Join(dir, "state"), []byte(state+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return sysRoot
+}
+
+func TestGetMountErrorState(t *testing.T) {
+	sysRoot := newTestDeviceStateSysRoot(t, map[string]string{
+		"sdb": "running",
+		"sdc": "running",
+		"sdd": "offline",
+	})
+
+	ctx := gofsutil.WithMountTable(context.TODO(), []gofsutil.Info{
+		{Device: "/dev/sdb", Path: "/mnt/data", Type: "ext4"},
+		{Device: "/dev/sdc", Path: "/mnt/logs", Type: "ext4"},
+		{Device: "/dev/sdd", Path: "/mnt/old", Type: "ext4"},
+		{Device: "host:/export", Path: "/mnt/nfs", Type: "nfs4"},
+	})
+	r := &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			if strings.HasSuffix(args[len(args)-1], "sdc") {
+				return dumpe2fsErrorsData, nil
+			}
+			return dumpe2fsCleanData, nil
+		},
+	}
+	fs := &gofsutil.FS{SysRoot: sysRoot, RunCommand: r.run}
+
+	for target, exp := range map[string]string{
+		"/mnt/data": gofsutil.MountErrorStateHealthy,
+		"/mnt/logs": gofsutil.MountErrorStateErrors,
+		"/mnt/old":  gofsutil.MountErrorStateOffline,
+		"/mnt/nfs":  gofsutil.MountErrorStateHealthy,
+	} {
+		state, err := fs.GetMountErrorState(ctx, target)
+		if err != nil {
+			t.Errorf("%s: %v", target, err)
+			continue
+		}
+		if state != exp {
+			t.Errorf("%s: invalid state: exp=%s, act=%s", target, exp, state)
+		}
+	}
+
+	_, err := fs.GetMountErrorState(ctx, "/mnt/none")
+	if !errors.Is(err, gofsutil.ErrNotMounted) {
+		t.Errorf("expected ErrNotMounted: %v", err)
+	}
+}
+
+func TestIsReadOnlyDueToErrors(t *testing.T) {
+	ctx := gofsutil.WithMountTable(context.TODO(), []gofsutil.Info{
+		{
+			Device:    "/dev/sdb",
+			Path:      "/mnt/data",
+			Type:      "ext4",
+			Opts:      []string{"rw", "relatime"},
+			SuperOpts: []string{"rw"},
+		},
+		{
+			Device:    "/dev/sdc",
+			Path:      "/mnt/logs",
+			Type:      "ext4",
+			Opts:      []string{"rw", "relatime"},
+			SuperOpts: []string{"ro", "errors=remount-ro"},
+		},
+		{
+			Device:    "/dev/sdd",
+			Path:      "/mnt/snap",
+			Type:      "ext4",
+			Opts:      []string{"ro", "relatime"},
+			SuperOpts: []string{"ro"},
+		},
+		{
+			Device:    "/dev/sde",
+			Path:      "/mnt/bind",
+			Type:      "ext4",
+			Opts:      []string{"rw", "relatime"},
+			SuperOpts: []string{"ro"},
+		},
+		{
+			Device:    "/dev/sdf",
+			Path:      "/mnt/xfs",
+			Type:      "xfs",
+			Opts:      []string{"rw"},
+			SuperOpts: []string{"ro"},
+		},
+	})
+	r := &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			if strings.HasSuffix(args[len(args)-1], "sdc") {
+				return dumpe2fsErrorsData, nil
+			}
+			return dumpe2fsCleanData, nil
+		},
+	}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	tests := []struct {
+		target string
+		exp    bool
+	}{
+		// The filesystem is writable.
+		{"/mnt/data", false},
+		// The kernel remounted the filesystem read-only on errors.
+		{"/mnt/logs", true},
+		// The filesystem was mounted read-only.
+		{"/mnt/snap", false},
+		// Another mount of the filesystem was remounted read-only.
+		{"/mnt/bind", false},
+		// Only ext filesystems are remounted read-only on errors.
+		{"/mnt/xfs", false},
+	}
+	for _, tt := range tests {
+		ro, err := fs.IsReadOnlyDueToErrors(ctx, tt.target)
+		if err != nil {
+			t.Errorf("%s: %v", tt.target, err)
+			continue
+		}
+		if ro != tt.exp {
+			t.Errorf("%s: readOnlyDueToErrors=%v, exp=%v",
+				tt.target, ro, tt.exp)
+		}
+	}
+	r.assertCommands(t, "dumpe2fs -h /dev/sdc", "dumpe2fs -h /dev/sde")
+
+	_, err := fs.IsReadOnlyDueToErrors(ctx, "/mnt/none")
+	if !errors.Is(err, gofsutil.ErrNotMounted) {
+		t.Errorf("expected ErrNotMounted: %v", err)
+	}
+}
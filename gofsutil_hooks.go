@@ -0,0 +1,68 @@
+package gofsutil
+
+import (
+	"context"
+	"errors"
+)
+
+// PreMountHookFunc defines the signature of the function that is invoked
+// before a filesystem is mounted. The mount is aborted if the function
+// returns an error.
+type PreMountHookFunc func(
+	ctx context.Context,
+	source, target, fsType string,
+	opts []string) error
+
+// PostMountHookFunc defines the signature of the function that is invoked
+// after a filesystem is mounted. The err parameter is the result of the
+// mount.
+type PostMountHookFunc func(
+	ctx context.Context,
+	source, target, fsType string,
+	opts []string,
+	err error)
+
+// runMountHooks invokes mount between the FS's PreMountHook and
+// PostMountHook
+func (fs *FS) runMountHooks(
+	ctx context.Context,
+	source, target, fsType string,
+	opts []string,
+	mount func() error) error {
+
+	if fs.PreMountHook != nil {
+		if err := fs.PreMountHook(
+			ctx, source, target, fsType, opts); err != nil {
+			return &mountVetoError{err: err}
+		}
+	}
+	err := mount()
+	if fs.PostMountHook != nil {
+		fs.PostMountHook(ctx, source, target, fsType, opts, err)
+	}
+	return err
+}
+
+// mountVetoError wraps the error returned by a PreMountHook that aborted
+// a mount, so the mount is not mistaken for one that failed, ex. because
+// the device is not formatted.
+type mountVetoError struct {
+	err error
+}
+
+// Error returns the error message of the hook's error.
+func (e *mountVetoError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the hook's error.
+func (e *mountVetoError) Unwrap() error {
+	return e.err
+}
+
+// isMountVetoed returns a flag indicating whether err is the result of a
+// mount that was aborted by a PreMountHook.
+func isMountVetoed(err error) bool {
+	var vErr *mountVetoError
+	return errors.As(err, &vErr)
+}
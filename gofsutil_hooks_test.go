@@ -0,0 +1,103 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+func TestPreMountHookVeto(t *testing.T) {
+	errVeto := errors.New("nosuid required")
+	var posts int
+
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{
+		ProcRoot:   newTestRootProcRoot(t),
+		RunCommand: r.run,
+		PreMountHook: func(
+			ctx context.Context,
+			source, target, fsType string,
+			opts []string) error {
+
+			for _, o := range opts {
+				if o == "nosuid" {
+					return nil
+				}
+			}
+			return errVeto
+		},
+		PostMountHook: func(
+			ctx context.Context,
+			source, target, fsType string,
+			opts []string,
+			err error) {
+
+			posts++
+		},
+	}
+
+	err := fs.Mount(context.TODO(), "/dev/sdb", "/mnt", "ext4")
+	if !errors.Is(err, errVeto) {
+		t.Fatalf("expected veto: %v", err)
+	}
+	r.assertCommands(t)
+	if posts != 0 {
+		t.Errorf("post hook invoked for vetoed mount: %d", posts)
+	}
+
+	if err := fs.Mount(
+		context.TODO(), "/dev/sdb", "/mnt", "ext4", "nosuid"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t, "mount -t ext4 -o nosuid /dev/sdb /mnt")
+	if posts != 1 {
+		t.Errorf("invalid post hook count: %d", posts)
+	}
+}
+
+func TestPostMountHook(t *testing.T) {
+	type result struct {
+		source, target, fsType string
+		err                    error
+	}
+	var results []result
+
+	fs := &gofsutil.FS{
+		ProcRoot: newTestRootProcRoot(t),
+		PostMountHook: func(
+			ctx context.Context,
+			source, target, fsType string,
+			opts []string,
+			err error) {
+
+			results = append(results, result{source, target, fsType, err})
+		},
+	}
+
+	r := &testCommandRunner{}
+	fs.RunCommand = r.run
+	if err := fs.Mount(
+		context.TODO(), "/dev/sdb", "/mnt", "ext4"); err != nil {
+		t.Fatal(err)
+	}
+
+	fs.RunCommand = newTestErrorRunner("mount: /mnt: wrong fs type.\n").run
+	mountErr := fs.Mount(context.TODO(), "/dev/sdc", "/mnt", "xfs")
+	if mountErr == nil {
+		t.Fatal("expected error")
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("invalid post hook count: %d", len(results))
+	}
+	if r := results[0]; r.source != "/dev/sdb" || r.target != "/mnt" ||
+		r.fsType != "ext4" || r.err != nil {
+		t.Errorf("invalid success result: %+v", r)
+	}
+	if r := results[1]; r.source != "/dev/sdc" || r.fsType != "xfs" ||
+		r.err != mountErr {
+		t.Errorf("invalid failure result: %+v", r)
+	}
+}
@@ -0,0 +1,36 @@
+package gofsutil
+
+import "fmt"
+
+// IDMapRange maps a range of user or group IDs for an idmapped mount. The
+// ranges are those of the user namespace whose mapping is applied to the
+// mount, so a file owned by an ID in the range
+// [ContainerID, ContainerID+Size) on the filesystem appears to be owned by
+// the corresponding ID in the range [HostID, HostID+Size) through the
+// mount.
+type IDMapRange struct {
+	// ContainerID is the first ID of the range as stored on the
+	// filesystem.
+	ContainerID int
+
+	// HostID is the first ID of the range as seen through the mount.
+	HostID int
+
+	// Size is the number of IDs in the range.
+	Size int
+}
+
+// validateIDMap returns an error if any of the ranges of the ID mapping
+// are invalid. An empty mapping is invalid since the kernel requires at
+// least one range.
+func validateIDMap(name string, m []IDMapRange) error {
+	if len(m) == 0 {
+		return fmt.Errorf("invalid %s map: no ranges", name)
+	}
+	for _, r := range m {
+		if r.ContainerID < 0 || r.HostID < 0 || r.Size <= 0 {
+			return fmt.Errorf("invalid %s map range: %+v", name, r)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+package gofsutil
+
+import "context"
+
+// mountIDMapped mounts source to target with the ownership of its files
+// remapped by uidMap and gidMap
+func (fs *FS) mountIDMapped(
+	ctx context.Context,
+	source, target, fsType string,
+	uidMap, gidMap []IDMapRange,
+	opts ...string) error {
+
+	return ErrNotImplemented
+}
@@ -0,0 +1,243 @@
+package gofsutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"syscall"
+	"unsafe"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+)
+
+// The numbers of the system calls of the mount API added in Linux 5.2
+// and 5.12, sysOpenTree, sysMoveMount, and sysMountSetattr, are defined
+// for each architecture in the gofsutil_idmap_sysnum_linux*.go files.
+const (
+	openTreeClone       = 0x1
+	atEmptyPath         = 0x1000
+	atRecursive         = 0x8000
+	moveMountFEmptyPath = 0x4
+	mountAttrRdonly     = 0x1
+	mountAttrNosuid     = 0x2
+	mountAttrNodev      = 0x4
+	mountAttrNoexec     = 0x8
+	mountAttrIDMap      = 0x100000
+	mountAttrSize       = 32
+)
+
+// atFDCWD is a variable so that it may be converted to a uintptr.
+var atFDCWD = unix.AT_FDCWD
+
+// mountAttr is struct mount_attr from linux/mount.h.
+type mountAttr struct {
+	attrSet     uint64
+	attrClr     uint64
+	propagation uint64
+	usernsFD    uint64
+}
+
+// idMapMountAttrs maps the options that may be applied to the clone of
+// a directory tree to the mount attributes that set them.
+var idMapMountAttrs = map[string]uint64{
+	"ro":     mountAttrRdonly,
+	"nosuid": mountAttrNosuid,
+	"nodev":  mountAttrNodev,
+	"noexec": mountAttrNoexec,
+}
+
+// mountIDMapped mounts source to target with the ownership of its files
+// remapped by uidMap and gidMap
+func (fs *FS) mountIDMapped(
+	ctx context.Context,
+	source, target, fsType string,
+	uidMap, gidMap []IDMapRange,
+	opts ...string) error {
+
+	if err := validateIDMap("uid", uidMap); err != nil {
+		return err
+	}
+	if err := validateIDMap("gid", gidMap); err != nil {
+		return err
+	}
+
+	f := logFields(ctx, log.Fields{
+		"source":  source,
+		"target":  target,
+		"fsType":  fsType,
+		"uidMap":  uidMap,
+		"gidMap":  gidMap,
+		"options": opts,
+	})
+
+	// The options of a filesystem are applied when it is mounted, but
+	// only the mount attributes may be applied to a directory tree.
+	var attrs uint64
+	tree := source
+	if fsType == "" {
+		for _, o := range opts {
+			a, ok := idMapMountAttrs[o]
+			if !ok {
+				return fmt.Errorf(
+					"invalid idmapped mount option: %q: fsType required", o)
+			}
+			attrs |= a
+		}
+	} else {
+		if err := fs.mount(ctx, source, target, fsType, opts...); err != nil {
+			return err
+		}
+		tree = target
+	}
+	log.WithFields(f).Info("idmapped mount")
+
+	err := mountIDMappedTree(tree, target, attrs, uidMap, gidMap)
+	if err != nil {
+		log.WithFields(f).WithError(err).Error("idmapped mount failed")
+		if fsType != "" {
+			if uerr := unix.Unmount(target, 0); uerr != nil {
+				log.WithFields(f).WithError(uerr).Warn(
+					"failed to unmount filesystem after idmapped mount failed")
+			}
+		}
+	}
+	return err
+}
+
+// mountIDMappedTree clones the directory tree at tree, applies the id
+// mapping and attributes to the clone, and attaches it at target. If tree
+// is target then the clone replaces the mount at target.
+func mountIDMappedTree(
+	tree, target string,
+	attrs uint64,
+	uidMap, gidMap []IDMapRange) error {
+
+	usernsFD, err := openIDMappedUserns(uidMap, gidMap)
+	if err != nil {
+		return err
+	}
+	defer usernsFD.Close()
+
+	treeFD, err := openTree(tree)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(treeFD)
+
+	attr := mountAttr{
+		attrSet:  attrs | mountAttrIDMap,
+		usernsFD: uint64(usernsFD.Fd()),
+	}
+	if _, _, errno := unix.Syscall6(
+		sysMountSetattr,
+		uintptr(treeFD), uintptr(unsafe.Pointer(&emptyPath[0])),
+		atEmptyPath|atRecursive,
+		uintptr(unsafe.Pointer(&attr)), mountAttrSize, 0); errno != 0 {
+
+		// The kernel rejects the id mapping of a filesystem that does
+		// not support idmapped mounts.
+		if errno == unix.ENOSYS || errno == unix.EINVAL {
+			return fmt.Errorf("mount_setattr: %s: %w: %v",
+				tree, ErrIDMapUnsupported, errno)
+		}
+		return fmt.Errorf("mount_setattr: %s: %v", tree, errno)
+	}
+
+	// The clone replaces the mount from which it was cloned.
+	if tree == target {
+		if err := unix.Unmount(target, unix.MNT_DETACH); err != nil {
+			return fmt.Errorf("unmount: %s: %v", target, err)
+		}
+	}
+
+	targetPtr, err := unix.BytePtrFromString(target)
+	if err != nil {
+		return err
+	}
+	if _, _, errno := unix.Syscall6(
+		sysMoveMount,
+		uintptr(treeFD), uintptr(unsafe.Pointer(&emptyPath[0])),
+		uintptr(atFDCWD), uintptr(unsafe.Pointer(targetPtr)),
+		moveMountFEmptyPath, 0); errno != 0 {
+
+		return fmt.Errorf("move_mount: %s: %v", target, errno)
+	}
+	return nil
+}
+
+// emptyPath is the empty, NUL-terminated path passed to the system calls
+// that operate on a file descriptor with AT_EMPTY_PATH.
+var emptyPath = []byte{0}
+
+// openTree returns a file descriptor for a detached clone of the
+// directory tree at path
+func openTree(path string) (int, error) {
+	p, err := unix.BytePtrFromString(path)
+	if err != nil {
+		return -1, err
+	}
+	fd, _, errno := unix.Syscall(
+		sysOpenTree,
+		uintptr(atFDCWD), uintptr(unsafe.Pointer(p)),
+		openTreeClone|unix.O_CLOEXEC|atRecursive)
+	if errno != 0 {
+		if errno == unix.ENOSYS {
+			return -1, fmt.Errorf(
+				"open_tree: %s: %w: %v", path, ErrIDMapUnsupported, errno)
+		}
+		return -1, fmt.Errorf("open_tree: %s: %v", path, errno)
+	}
+	return int(fd), nil
+}
+
+// openIDMappedUserns returns the user namespace of a process with the
+// provided id mappings. The process is started in a new user namespace
+// and traced so that it stops before it runs, and it is killed once its
+// user namespace is opened.
+func openIDMappedUserns(uidMap, gidMap []IDMapRange) (*os.File, error) {
+	cmd := exec.Command("/proc/self/exe")
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags:  syscall.CLONE_NEWUSER,
+		UidMappings: sysProcIDMap(uidMap),
+		GidMappings: sysProcIDMap(gidMap),
+		Ptrace:      true,
+	}
+	if err := cmd.Start(); err != nil {
+		// The kernel was built without user namespaces. EPERM is not
+		// mapped since it is also returned when the caller lacks a
+		// privilege or user namespaces are disabled by a policy.
+		if errors.Is(err, unix.EINVAL) {
+			return nil, fmt.Errorf(
+				"create user namespace: %w: %v", ErrIDMapUnsupported, err)
+		}
+		return nil, fmt.Errorf("create user namespace: %w", err)
+	}
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+
+	ns, err := os.Open(
+		"/proc/" + strconv.Itoa(cmd.Process.Pid) + "/ns/user")
+	if err != nil {
+		return nil, fmt.Errorf("open user namespace: %v", err)
+	}
+	return ns, nil
+}
+
+// sysProcIDMap converts an id mapping to the type used by SysProcAttr
+func sysProcIDMap(m []IDMapRange) []syscall.SysProcIDMap {
+	ids := make([]syscall.SysProcIDMap, len(m))
+	for i, r := range m {
+		ids[i] = syscall.SysProcIDMap{
+			ContainerID: r.ContainerID,
+			HostID:      r.HostID,
+			Size:        r.Size,
+		}
+	}
+	return ids
+}
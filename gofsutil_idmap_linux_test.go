@@ -0,0 +1,127 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+// kernelAtLeast returns a flag indicating whether the running kernel's
+// version is at least major.minor.
+func kernelAtLeast(t *testing.T, major, minor int) bool {
+	var u unix.Utsname
+	if err := unix.Uname(&u); err != nil {
+		t.Fatal(err)
+	}
+	b := make([]byte, 0, len(u.Release))
+	for _, c := range u.Release {
+		if c == 0 {
+			break
+		}
+		b = append(b, byte(c))
+	}
+	rel := string(b)
+	if i := strings.IndexAny(rel, "-+"); i >= 0 {
+		rel = rel[:i]
+	}
+	v := strings.SplitN(rel, ".", 3)
+	if len(v) < 2 {
+		t.Fatalf("invalid kernel release: %q", rel)
+	}
+	maj, _ := strconv.Atoi(v[0])
+	min, _ := strconv.Atoi(v[1])
+	return maj > major || (maj == major && min >= minor)
+}
+
+func TestMountIDMapped(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("requires root")
+	}
+	// Idmapped mounts of tmpfs were added in Linux 6.3.
+	if !kernelAtLeast(t, 6, 3) {
+		t.Skip("requires Linux 6.3 or later")
+	}
+
+	dir, err := ioutil.TempDir("", "gofsutil")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	src, dst := path.Join(dir, "src"), path.Join(dir, "dst")
+	for _, p := range []string{src, dst} {
+		if err := os.Mkdir(p, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := unix.Mount("tmpfs", src, "tmpfs", 0, ""); err != nil {
+		t.Skipf("mount tmpfs: %v", err)
+	}
+	defer unix.Unmount(src, unix.MNT_DETACH)
+
+	file := path.Join(src, "file")
+	if err := ioutil.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chown(file, 1000, 1000); err != nil {
+		t.Fatal(err)
+	}
+
+	idMap := []gofsutil.IDMapRange{{ContainerID: 1000, HostID: 0, Size: 1}}
+	err = gofsutil.MountIDMapped(
+		context.TODO(), src, dst, "", idMap, idMap, "nosuid")
+	if errors.Is(err, gofsutil.ErrIDMapUnsupported) ||
+		errors.Is(err, syscall.EPERM) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unix.Unmount(dst, unix.MNT_DETACH)
+
+	st, err := os.Stat(path.Join(dst, "file"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sys := st.Sys().(*syscall.Stat_t)
+	if sys.Uid != 0 || sys.Gid != 0 {
+		t.Errorf("mapped owner: %d:%d: expected 0:0", sys.Uid, sys.Gid)
+	}
+
+	st, err = os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sys = st.Sys().(*syscall.Stat_t)
+	if sys.Uid != 1000 || sys.Gid != 1000 {
+		t.Errorf("source owner: %d:%d: expected 1000:1000", sys.Uid, sys.Gid)
+	}
+}
+
+func TestMountIDMappedInvalid(t *testing.T) {
+	ctx := context.TODO()
+	idMap := []gofsutil.IDMapRange{{ContainerID: 1000, HostID: 0, Size: 1}}
+	if err := gofsutil.MountIDMapped(
+		ctx, "/src", "/dst", "", nil, idMap); err == nil {
+		t.Error("expected error for empty uid map")
+	}
+	if err := gofsutil.MountIDMapped(
+		ctx, "/src", "/dst", "", idMap,
+		[]gofsutil.IDMapRange{{Size: 0}}); err == nil {
+		t.Error("expected error for empty gid range")
+	}
+	if err := gofsutil.MountIDMapped(
+		ctx, "/src", "/dst", "", idMap, idMap, "size=1m"); err == nil {
+		t.Error("expected error for filesystem option without fsType")
+	}
+}
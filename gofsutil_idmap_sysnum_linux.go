@@ -0,0 +1,12 @@
+// +build linux,!mips,!mipsle,!mips64,!mips64le
+
+package gofsutil
+
+// The system calls of the mount API have the same numbers on all of the
+// architectures that use the generic system call table, which includes
+// every Linux architecture Go supports except the mips family.
+const (
+	sysOpenTree     = 428
+	sysMoveMount    = 429
+	sysMountSetattr = 442
+)
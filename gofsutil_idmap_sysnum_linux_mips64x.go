@@ -0,0 +1,11 @@
+// +build linux
+// +build mips64 mips64le
+
+package gofsutil
+
+// The system calls of the n64 ABI are offset by 5000.
+const (
+	sysOpenTree     = 5428
+	sysMoveMount    = 5429
+	sysMountSetattr = 5442
+)
@@ -0,0 +1,11 @@
+// +build linux
+// +build mips mipsle
+
+package gofsutil
+
+// The system calls of the o32 ABI are offset by 4000.
+const (
+	sysOpenTree     = 4428
+	sysMoveMount    = 4429
+	sysMountSetattr = 4442
+)
@@ -0,0 +1,68 @@
+package gofsutil
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// maxFSLabelBytes is the maximum length, in bytes, of the label of each
+// supported filesystem type.
+var maxFSLabelBytes = map[string]int{
+	"ext2":  16,
+	"ext3":  16,
+	"ext4":  16,
+	"xfs":   12,
+	"vfat":  11,
+	"fat":   11,
+	"msdos": 11,
+	"btrfs": 255,
+}
+
+// isFATFS returns a flag indicating whether fsType is a member of the
+// FAT filesystem family.
+func isFATFS(fsType string) bool {
+	switch fsType {
+	case "vfat", "fat", "msdos":
+		return true
+	}
+	return false
+}
+
+// ValidateFSLabel returns an error if label is not a valid label for a
+// filesystem of type fsType, ex. a label that would be truncated when
+// the filesystem is formatted. Labels may not contain a slash, and their
+// length is limited in bytes rather than characters: 16 for the ext
+// family, 12 for xfs, 11 for the FAT family, and 255 for btrfs. FAT
+// labels must also be uppercase.
+func ValidateFSLabel(fsType, label string) error {
+	max, ok := maxFSLabelBytes[fsType]
+	if !ok {
+		return fmt.Errorf("filesystem labels not supported: fsType=%s", fsType)
+	}
+	if strings.Contains(label, "/") {
+		return fmt.Errorf("invalid %s label: %q: contains a slash", fsType, label)
+	}
+	if len(label) > max {
+		return fmt.Errorf(
+			"invalid %s label: %q: length is %d bytes, max is %d",
+			fsType, label, len(label), max)
+	}
+	if isFATFS(fsType) && strings.IndexFunc(label, unicode.IsLower) >= 0 {
+		return fmt.Errorf(
+			"invalid %s label: %q: must be uppercase", fsType, label)
+	}
+	return nil
+}
+
+// makeMkfsLabelArgs returns the mkfs arguments that set the label of the
+// filesystem being created
+func makeMkfsLabelArgs(fsType, label string) []string {
+	if label == "" {
+		return nil
+	}
+	if isFATFS(fsType) {
+		return []string{"-n", label}
+	}
+	return []string{"-L", label}
+}
@@ -0,0 +1,10 @@
+package gofsutil
+
+import "context"
+
+// setFSLabel sets the label of the filesystem of type fsType on device
+func (fs *FS) setFSLabel(
+	ctx context.Context, device, fsType, label string) error {
+
+	return ErrNotImplemented
+}
@@ -0,0 +1,52 @@
+package gofsutil
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// setFSLabel sets the label of the filesystem of type fsType on device
+func (fs *FS) setFSLabel(
+	ctx context.Context, device, fsType, label string) error {
+
+	if err := ValidateFSLabel(fsType, label); err != nil {
+		return err
+	}
+
+	var (
+		name string
+		args []string
+	)
+	switch {
+	case isExtFS(fsType):
+		name, args = "e2label", []string{device, label}
+	case fsType == "xfs":
+		// xfs_admin clears the label when provided "--".
+		if label == "" {
+			label = "--"
+		}
+		name, args = "xfs_admin", []string{"-L", label, device}
+	case fsType == "btrfs":
+		name, args = "btrfs", []string{"filesystem", "label", device, label}
+	case isFATFS(fsType):
+		name, args = "fatlabel", []string{device, label}
+	}
+
+	f := log.Fields{
+		"cmd":  name,
+		"args": args,
+	}
+	log.WithFields(f).Info("label command")
+
+	buf, err := fs.exec(ctx, name, args...)
+	if err != nil {
+		out := string(buf)
+		log.WithFields(f).WithField("output", out).WithError(
+			err).Error("label failed")
+		return fmt.Errorf(
+			"%s failed: %v\narguments: %v\noutput: %s", name, err, args, out)
+	}
+	return nil
+}
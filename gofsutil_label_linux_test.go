@@ -0,0 +1,67 @@
+package gofsutil_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+func TestSetFSLabel(t *testing.T) {
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	for _, tt := range []struct {
+		fsType string
+		label  string
+	}{
+		{"ext4", "data"},
+		{"xfs", "data"},
+		{"xfs", ""},
+		{"btrfs", "data"},
+		{"vfat", "BOOT"},
+	} {
+		if err := fs.SetFSLabel(
+			context.TODO(), "/dev/sdb", tt.fsType, tt.label); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := fs.SetFSLabel(
+		context.TODO(), "/dev/sdb", "xfs", "toolongforxfs"); err == nil {
+		t.Error("expected error for invalid label")
+	}
+	r.assertCommands(t,
+		"e2label /dev/sdb data",
+		"xfs_admin -L data /dev/sdb",
+		"xfs_admin -L -- /dev/sdb",
+		"btrfs filesystem label /dev/sdb data",
+		"fatlabel /dev/sdb BOOT")
+}
+
+func TestFormatAndMountWithOptsLabel(t *testing.T) {
+	r := newTestFormatRunner("")
+	fs := &gofsutil.FS{
+		RunCommand: r.run,
+		ProcRoot:   newTestRootProcRoot(t),
+	}
+
+	if err := fs.FormatAndMountWithOpts(
+		context.TODO(), "/dev/sdb", "/mnt", "xfs",
+		gofsutil.FormatOptions{Label: "data"}); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t,
+		"mount -t xfs -o defaults /dev/sdb /mnt",
+		"lsblk -J -o NAME,FSTYPE /dev/sdb",
+		"mkfs.xfs -L data /dev/sdb",
+		"mount -t xfs -o defaults /dev/sdb /mnt")
+
+	r = newTestFormatRunner("")
+	fs.RunCommand = r.run
+	if err := fs.FormatAndMountWithOpts(
+		context.TODO(), "/dev/sdb", "/mnt", "",
+		gofsutil.FormatOptions{Label: "a-label-too-long-for-ext4"}); err == nil {
+		t.Fatal("expected error for invalid label")
+	}
+	r.assertCommands(t)
+}
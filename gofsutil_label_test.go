@@ -0,0 +1,52 @@
+package gofsutil_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+func TestValidateFSLabel(t *testing.T) {
+	tests := []struct {
+		fsType string
+		label  string
+		err    bool
+	}{
+		{fsType: "ext4", label: "data"},
+		{fsType: "ext4", label: strings.Repeat("a", 16)},
+		{fsType: "ext4", label: strings.Repeat("a", 17), err: true},
+		{fsType: "ext3", label: strings.Repeat("a", 17), err: true},
+		// Six characters but 18 bytes.
+		{fsType: "ext4", label: "データデータ", err: true},
+		{fsType: "ext4", label: "データ"},
+		{fsType: "ext4", label: "a/b", err: true},
+		{fsType: "xfs", label: strings.Repeat("a", 12)},
+		{fsType: "xfs", label: strings.Repeat("a", 13), err: true},
+		{fsType: "xfs", label: "ラベルラベル", err: true},
+		{fsType: "vfat", label: "BOOT"},
+		{fsType: "vfat", label: strings.Repeat("A", 11)},
+		{fsType: "vfat", label: strings.Repeat("A", 12), err: true},
+		{fsType: "vfat", label: "boot", err: true},
+		{fsType: "msdos", label: "BOOT"},
+		{fsType: "btrfs", label: strings.Repeat("a", 255)},
+		{fsType: "btrfs", label: strings.Repeat("a", 256), err: true},
+		{fsType: "btrfs", label: strings.Repeat("é", 128), err: true},
+		{fsType: "btrfs", label: "a/b", err: true},
+		{fsType: "zfs", label: "data", err: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.fsType, func(st *testing.T) {
+			st.Parallel()
+			err := gofsutil.ValidateFSLabel(tt.fsType, tt.label)
+			if tt.err && err == nil {
+				st.Errorf("expected error: fsType=%s, label=%q",
+					tt.fsType, tt.label)
+			} else if !tt.err && err != nil {
+				st.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package gofsutil
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+// FSLimits describes the size limits of a filesystem as determined by
+// its type and geometry.
+type FSLimits struct {
+	// BlockSize is the size of a block of the filesystem in bytes.
+	BlockSize uint64
+
+	// MaxFileSize is the size in bytes of the largest file that may be
+	// stored on the filesystem.
+	MaxFileSize uint64
+
+	// MaxVolumeSize is the size in bytes of the largest volume to which
+	// the filesystem may be grown.
+	MaxVolumeSize uint64
+}
+
+// maxLFSFileSize is the largest file size supported by the kernel's
+// page cache on 64-bit hosts.
+const maxLFSFileSize = math.MaxInt64
+
+// extLimits returns the limits of an ext filesystem with the provided
+// block size and features, ex. "extent", "huge_file", and "64bit". The
+// maximum file size is computed as the kernel does in ext4_max_size and
+// ext4_max_bitmap_size.
+func extLimits(blockSize uint64, features []string) (FSLimits, error) {
+	if blockSize < 1024 || blockSize&(blockSize-1) != 0 {
+		return FSLimits{}, fmt.Errorf("invalid block size: %d", blockSize)
+	}
+	var extent, hugeFile, is64bit bool
+	for _, f := range features {
+		switch f {
+		case "extent", "extents":
+			extent = true
+		case "huge_file":
+			hugeFile = true
+		case "64bit":
+			is64bit = true
+		}
+	}
+
+	blkBits := uint(bits.TrailingZeros64(blockSize))
+	var maxFileSize uint64
+	if extent {
+		maxFileSize = extExtentMaxSize(blkBits, hugeFile)
+	} else {
+		maxFileSize = extBitmapMaxSize(blkBits, hugeFile)
+	}
+
+	// The block numbers of a filesystem with the 64bit feature are 48
+	// bits wide, otherwise 32 bits.
+	maxBlocks := uint64(1) << 32
+	if is64bit {
+		maxBlocks = uint64(1) << 48
+	}
+
+	return FSLimits{
+		BlockSize:     blockSize,
+		MaxFileSize:   maxFileSize,
+		MaxVolumeSize: mulSaturate(maxBlocks, blockSize),
+	}, nil
+}
+
+// extExtentMaxSize returns the maximum size of a file mapped by extents
+func extExtentMaxSize(blkBits uint, hugeFile bool) uint64 {
+	// The logical block number of an extent is 32 bits wide.
+	res := uint64(1)<<32 - 1
+	res <<= blkBits
+
+	// Without the huge_file feature the i_blocks field of an inode
+	// counts 512-byte sectors in 32 bits.
+	upper := uint64(maxLFSFileSize)
+	if !hugeFile {
+		upper = (uint64(1)<<32 - 1) >> (blkBits - 9) << blkBits
+	}
+	if res > upper {
+		res = upper
+	}
+	if res > maxLFSFileSize {
+		res = maxLFSFileSize
+	}
+	return res
+}
+
+// extBitmapMaxSize returns the maximum size of a file mapped by direct
+// and indirect blocks
+func extBitmapMaxSize(blkBits uint, hugeFile bool) uint64 {
+	// The i_blocks field of an inode counts 512-byte sectors in 32 bits
+	// or, with the huge_file feature, filesystem blocks in 48 bits.
+	upper := (uint64(1)<<32 - 1) >> (blkBits - 9)
+	if hugeFile {
+		upper = uint64(1)<<48 - 1
+	}
+
+	// The indirect blocks are counted by i_blocks.
+	ptrBits := blkBits - 2
+	meta := uint64(1)
+	meta += 1 + uint64(1)<<ptrBits
+	meta += 1 + uint64(1)<<ptrBits + uint64(1)<<(2*ptrBits)
+	upper = (upper - meta) << blkBits
+
+	// There are 12 direct blocks and single, double, and triple
+	// indirect blocks.
+	res := uint64(12)
+	res += uint64(1) << ptrBits
+	res += uint64(1) << (2 * ptrBits)
+	res += uint64(1) << (3 * ptrBits)
+	res <<= blkBits
+
+	if res > upper {
+		res = upper
+	}
+	if res > maxLFSFileSize {
+		res = maxLFSFileSize
+	}
+	return res
+}
+
+// xfsLimits returns the limits of an xfs filesystem with the provided
+// block size. The file and volume sizes of xfs are limited only by the
+// kernel on 64-bit hosts.
+func xfsLimits(blockSize uint64) (FSLimits, error) {
+	if blockSize == 0 {
+		return FSLimits{}, fmt.Errorf("invalid block size: %d", blockSize)
+	}
+	return FSLimits{
+		BlockSize:     blockSize,
+		MaxFileSize:   maxLFSFileSize,
+		MaxVolumeSize: maxLFSFileSize,
+	}, nil
+}
+
+// mulSaturate returns a*b or math.MaxUint64 if the product overflows.
+func mulSaturate(a, b uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	if hi != 0 {
+		return math.MaxUint64
+	}
+	return lo
+}
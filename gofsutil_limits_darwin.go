@@ -0,0 +1,19 @@
+package gofsutil
+
+import "context"
+
+// getFSLimits returns the limits of the filesystem of type fsType on
+// device
+func (fs *FS) getFSLimits(
+	ctx context.Context, device, fsType string) (FSLimits, error) {
+
+	return FSLimits{}, ErrNotImplemented
+}
+
+// getFSBlockSize returns the block size of the filesystem of type fsType
+// on device
+func (fs *FS) getFSBlockSize(
+	ctx context.Context, device, fsType string) (uint32, error) {
+
+	return 0, ErrNotImplemented
+}
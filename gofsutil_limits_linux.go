@@ -0,0 +1,194 @@
+package gofsutil
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// xfsInfoErrors maps the output of a failed xfs_info command to the
+// error that describes the failure.
+var xfsInfoErrors = []cmdError{
+	{regexp.MustCompile(`(?i)no such file or directory`),
+		ErrDeviceNotFound},
+}
+
+// btrfsDumpSuperErrors maps the output of a failed
+// 'btrfs inspect-internal dump-super' command to the error that
+// describes the failure.
+var btrfsDumpSuperErrors = []cmdError{
+	{regexp.MustCompile(`(?i)no such file or directory`),
+		ErrDeviceNotFound},
+}
+
+// getFSLimits returns the limits of the filesystem of type fsType on
+// device
+func (fs *FS) getFSLimits(
+	ctx context.Context, device, fsType string) (FSLimits, error) {
+
+	switch {
+	case isExtFS(fsType):
+		buf, err := fs.dumpe2fs(ctx, device)
+		if err != nil {
+			return FSLimits{}, err
+		}
+		blockSize, err := parseDumpe2fsUint(buf, "Block size")
+		if err != nil {
+			return FSLimits{}, fmt.Errorf("%s: %v", device, err)
+		}
+		features, err := parseDumpe2fsField(buf, "Filesystem features")
+		if err != nil {
+			return FSLimits{}, fmt.Errorf("%s: %v", device, err)
+		}
+		return extLimits(blockSize, strings.Fields(features))
+	case fsType == "xfs":
+		buf, err := fs.xfsInfo(ctx, device)
+		if err != nil {
+			return FSLimits{}, err
+		}
+		blockSize, err := parseXFSInfoBlockSize(buf)
+		if err != nil {
+			return FSLimits{}, fmt.Errorf("%s: %v", device, err)
+		}
+		return xfsLimits(blockSize)
+	}
+	return FSLimits{}, fmt.Errorf("%w: fsType=%s", ErrNotImplemented, fsType)
+}
+
+// getFSBlockSize returns the block size of the filesystem of type fsType
+// on device
+func (fs *FS) getFSBlockSize(
+	ctx context.Context, device, fsType string) (uint32, error) {
+
+	var (
+		blockSize uint64
+		buf       []byte
+		err       error
+	)
+	switch {
+	case isExtFS(fsType):
+		if buf, err = fs.dumpe2fs(ctx, device); err != nil {
+			return 0, err
+		}
+		blockSize, err = parseDumpe2fsUint(buf, "Block size")
+	case fsType == "xfs":
+		if buf, err = fs.xfsInfo(ctx, device); err != nil {
+			return 0, err
+		}
+		blockSize, err = parseXFSInfoBlockSize(buf)
+	case fsType == "btrfs":
+		if buf, err = fs.btrfsDumpSuper(ctx, device); err != nil {
+			return 0, err
+		}
+		blockSize, err = parseBtrfsSectorSize(buf)
+	default:
+		return 0, fmt.Errorf("%w: fsType=%s", ErrNotImplemented, fsType)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%s: %v", device, err)
+	}
+	if blockSize == 0 || blockSize > math.MaxUint32 {
+		return 0, fmt.Errorf("%s: invalid block size: %d", device, blockSize)
+	}
+	return uint32(blockSize), nil
+}
+
+// xfsInfo returns the output of 'xfs_info' for device
+func (fs *FS) xfsInfo(ctx context.Context, device string) ([]byte, error) {
+	f := log.Fields{
+		"device": device,
+	}
+	log.WithFields(f).Info("reading xfs filesystem geometry")
+
+	buf, err := fs.exec(ctx, "xfs_info", device)
+	if err != nil {
+		out := string(buf)
+		log.WithFields(f).WithField("output", out).WithError(
+			err).Error("xfs_info failed")
+		return nil, fmt.Errorf(
+			"xfs_info failed: %w\ndevice: %s\noutput: %s",
+			wrapCmdError(err, out, xfsInfoErrors), device, out)
+	}
+	return buf, nil
+}
+
+// parseXFSInfoBlockSize returns the block size of the data section of
+// the output of 'xfs_info', ex.
+//
+//	data     =                       bsize=4096   blocks=262144, imaxpct=25
+func parseXFSInfoBlockSize(buf []byte) (uint64, error) {
+	scan := bufio.NewScanner(bytes.NewReader(buf))
+	for scan.Scan() {
+		line := scan.Text()
+		if !strings.HasPrefix(line, "data") {
+			continue
+		}
+		for _, field := range strings.Fields(line) {
+			if !strings.HasPrefix(field, "bsize=") {
+				continue
+			}
+			v := strings.TrimSuffix(strings.TrimPrefix(field, "bsize="), ",")
+			n, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid block size: %q", v)
+			}
+			return n, nil
+		}
+	}
+	if err := scan.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("block size not found")
+}
+
+// btrfsDumpSuper returns the output of
+// 'btrfs inspect-internal dump-super' for device
+func (fs *FS) btrfsDumpSuper(
+	ctx context.Context, device string) ([]byte, error) {
+
+	f := log.Fields{
+		"device": device,
+	}
+	log.WithFields(f).Info("reading btrfs superblock")
+
+	args := []string{"inspect-internal", "dump-super", device}
+	buf, err := fs.exec(ctx, "btrfs", args...)
+	if err != nil {
+		out := string(buf)
+		log.WithFields(f).WithField("output", out).WithError(
+			err).Error("btrfs inspect-internal dump-super failed")
+		return nil, fmt.Errorf(
+			"btrfs inspect-internal dump-super failed: %w\n"+
+				"device: %s\noutput: %s",
+			wrapCmdError(err, out, btrfsDumpSuperErrors), device, out)
+	}
+	return buf, nil
+}
+
+// parseBtrfsSectorSize returns the sector size, the unit in which btrfs
+// allocates data, from the output of 'btrfs inspect-internal dump-super'
+func parseBtrfsSectorSize(buf []byte) (uint64, error) {
+	scan := bufio.NewScanner(bytes.NewReader(buf))
+	for scan.Scan() {
+		fields := strings.Fields(scan.Text())
+		if len(fields) != 2 || fields[0] != "sectorsize" {
+			continue
+		}
+		n, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid sector size: %q", fields[1])
+		}
+		return n, nil
+	}
+	if err := scan.Err(); err != nil {
+		return 0, err
+	}
+	return 0, fmt.Errorf("sector size not found")
+}
@@ -0,0 +1,155 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+const dumpe2fsExt4LimitsData = `dumpe2fs 1.46.5 (30-Dec-2021)
+Filesystem volume name:   <none>
+Filesystem features:      has_journal ext_attr resize_inode dir_index filetype extent 64bit flex_bg sparse_super large_file huge_file dir_nlink extra_isize metadata_csum
+Block count:              262144
+Block size:               4096
+`
+
+const dumpe2fsExt3LimitsData = `dumpe2fs 1.46.5 (30-Dec-2021)
+Filesystem volume name:   <none>
+Filesystem features:      has_journal ext_attr resize_inode dir_index filetype sparse_super large_file
+Block count:              262144
+Block size:               4096
+`
+
+const xfsInfoData = `meta-data=/dev/sdb               isize=512    agcount=4, agsize=65536 blks
+         =                       sectsz=512   attr=2, projid32bit=1
+         =                       crc=1        finobt=1, sparse=1, rmapbt=0
+         =                       reflink=1    bigtime=0 inobtcount=0
+data     =                       bsize=4096   blocks=262144, imaxpct=25
+         =                       sunit=0      swidth=0 blks
+naming   =version 2              bsize=4096   ascii-ci=0, ftype=1
+log      =internal log           bsize=4096   blocks=2560, version=2
+         =                       sectsz=512   sunit=0 blks, lazy-count=1
+realtime =none                   extsz=4096   blocks=0, rtextents=0
+`
+
+func TestGetFSLimits(t *testing.T) {
+	for _, tt := range []struct {
+		fsType string
+		out    string
+		cmd    string
+		exp    gofsutil.FSLimits
+	}{
+		{"ext4", dumpe2fsExt4LimitsData, "dumpe2fs -h /dev/sdb",
+			gofsutil.FSLimits{
+				BlockSize:     4096,
+				MaxFileSize:   17592186040320,
+				MaxVolumeSize: 1152921504606846976,
+			}},
+		{"ext3", dumpe2fsExt3LimitsData, "dumpe2fs -h /dev/sdb",
+			gofsutil.FSLimits{
+				BlockSize:     4096,
+				MaxFileSize:   2194719883264,
+				MaxVolumeSize: 17592186044416,
+			}},
+		{"xfs", xfsInfoData, "xfs_info /dev/sdb",
+			gofsutil.FSLimits{
+				BlockSize:     4096,
+				MaxFileSize:   1<<63 - 1,
+				MaxVolumeSize: 1<<63 - 1,
+			}},
+	} {
+		out := tt.out
+		r := &testCommandRunner{
+			handler: func(args []string) (string, error) {
+				return out, nil
+			},
+		}
+		fs := &gofsutil.FS{RunCommand: r.run}
+
+		limits, err := fs.GetFSLimits(context.TODO(), "/dev/sdb", tt.fsType)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.fsType, err)
+		}
+		if limits != tt.exp {
+			t.Errorf("%s: invalid limits: exp=%+v, act=%+v",
+				tt.fsType, tt.exp, limits)
+		}
+		r.assertCommands(t, tt.cmd)
+	}
+}
+
+func TestGetFSLimitsNotImplemented(t *testing.T) {
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	_, err := fs.GetFSLimits(context.TODO(), "/dev/sdb", "btrfs")
+	if !errors.Is(err, gofsutil.ErrNotImplemented) {
+		t.Fatalf("expected ErrNotImplemented: %v", err)
+	}
+	r.assertCommands(t)
+}
+
+const btrfsDumpSuperData = `superblock: bytenr=65536, device=/dev/sdb
+---------------------------------------------------------
+csum_type		0 (crc32c)
+csum_size		4
+bytenr			65536
+flags			0x1
+			( WRITTEN )
+magic			_BHRfS_M [match]
+fsid			6d3e1e0a-8a8f-4a43-9bd4-3a5d0e3c2d1f
+label
+generation		7
+total_bytes		1073741824
+bytes_used		147456
+sectorsize		4096
+nodesize		16384
+leafsize (deprecated)	16384
+stripesize		4096
+`
+
+func TestGetFSBlockSize(t *testing.T) {
+	for _, tt := range []struct {
+		fsType string
+		out    string
+		cmd    string
+		exp    uint32
+	}{
+		{"ext4", dumpe2fsExt4LimitsData, "dumpe2fs -h /dev/sdb", 4096},
+		{"xfs", xfsInfoData, "xfs_info /dev/sdb", 4096},
+		{"btrfs", btrfsDumpSuperData,
+			"btrfs inspect-internal dump-super /dev/sdb", 4096},
+	} {
+		out := tt.out
+		r := &testCommandRunner{
+			handler: func(args []string) (string, error) {
+				return out, nil
+			},
+		}
+		fs := &gofsutil.FS{RunCommand: r.run}
+
+		blockSize, err := fs.GetFSBlockSize(
+			context.TODO(), "/dev/sdb", tt.fsType)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.fsType, err)
+		}
+		if blockSize != tt.exp {
+			t.Errorf("%s: invalid block size: exp=%d, act=%d",
+				tt.fsType, tt.exp, blockSize)
+		}
+		r.assertCommands(t, tt.cmd)
+	}
+}
+
+func TestGetFSBlockSizeNotImplemented(t *testing.T) {
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	_, err := fs.GetFSBlockSize(context.TODO(), "/dev/sdb", "vfat")
+	if !errors.Is(err, gofsutil.ErrNotImplemented) {
+		t.Fatalf("expected ErrNotImplemented: %v", err)
+	}
+	r.assertCommands(t)
+}
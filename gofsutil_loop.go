@@ -0,0 +1,122 @@
+package gofsutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// deletedMarker is appended by the kernel to the path of a loop device's
+// backing file if the file has been deleted.
+const deletedMarker = " (deleted)"
+
+// LoopInfo describes a loop device.
+type LoopInfo struct {
+	// Device is the path of the loop device, ex. "/dev/loop0".
+	Device string
+
+	// BackingFile is the path of the file backing the loop device.
+	BackingFile string
+
+	// Deleted is a flag indicating whether the backing file has been
+	// deleted. The loop device keeps a deleted backing file open, so
+	// its data remains available.
+	Deleted bool
+
+	// Offset is the offset in bytes of the loop device's data within
+	// the backing file.
+	Offset uint64
+
+	// SizeLimit is the size limit in bytes of the loop device. A zero
+	// value indicates the loop device extends to the end of the backing
+	// file.
+	SizeLimit uint64
+
+	// ReadOnly is a flag indicating whether the loop device is
+	// read-only.
+	ReadOnly bool
+
+	// AutoClear is a flag indicating whether the loop device is
+	// detached when it is last closed.
+	AutoClear bool
+}
+
+// trimDeletedMarker returns backingFile without the deleted marker and a
+// flag indicating whether the marker was present
+func trimDeletedMarker(backingFile string) (string, bool) {
+	if strings.HasSuffix(backingFile, deletedMarker) {
+		return strings.TrimSuffix(backingFile, deletedMarker), true
+	}
+	return backingFile, false
+}
+
+// parseLosetupJSON parses the output of 'losetup -J'. Versions of
+// losetup prior to util-linux 2.33 emit every value as a string, ex.
+// "ro": "0", so values of either form are accepted.
+func parseLosetupJSON(buf []byte) ([]LoopInfo, error) {
+	// losetup emits nothing when no loop devices are attached.
+	if len(strings.TrimSpace(string(buf))) == 0 {
+		return nil, nil
+	}
+	var out struct {
+		LoopDevices []map[string]interface{} `json:"loopdevices"`
+	}
+	if err := json.Unmarshal(buf, &out); err != nil {
+		return nil, fmt.Errorf("invalid losetup output: %v", err)
+	}
+
+	var loops []LoopInfo
+	for _, d := range out.LoopDevices {
+		var (
+			info LoopInfo
+			err  error
+		)
+		info.Device, _ = d["name"].(string)
+		if v, ok := d["back-file"].(string); ok {
+			info.BackingFile, info.Deleted = trimDeletedMarker(v)
+		}
+		if info.Offset, err = losetupUint(d["offset"]); err != nil {
+			return nil, fmt.Errorf("invalid losetup offset: %v", err)
+		}
+		if info.SizeLimit, err = losetupUint(d["sizelimit"]); err != nil {
+			return nil, fmt.Errorf("invalid losetup sizelimit: %v", err)
+		}
+		if info.ReadOnly, err = losetupBool(d["ro"]); err != nil {
+			return nil, fmt.Errorf("invalid losetup ro: %v", err)
+		}
+		if info.AutoClear, err = losetupBool(d["autoclear"]); err != nil {
+			return nil, fmt.Errorf("invalid losetup autoclear: %v", err)
+		}
+		loops = append(loops, info)
+	}
+	return loops, nil
+}
+
+// losetupUint returns the unsigned integer value v of a 'losetup -J'
+// column, which is either a number or a string
+func losetupUint(v interface{}) (uint64, error) {
+	switch t := v.(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		return uint64(t), nil
+	case string:
+		return strconv.ParseUint(t, 10, 64)
+	}
+	return 0, fmt.Errorf("unexpected value: %v", v)
+}
+
+// losetupBool returns the boolean value v of a 'losetup -J' column,
+// which is either a boolean or the string "0" or "1"
+func losetupBool(v interface{}) (bool, error) {
+	switch t := v.(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return t, nil
+	case string:
+		return strconv.ParseBool(t)
+	}
+	return false, fmt.Errorf("unexpected value: %v", v)
+}
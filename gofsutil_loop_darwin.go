@@ -0,0 +1,51 @@
+package gofsutil
+
+import "context"
+
+// mountImage attaches the filesystem image at imagePath to a loop device
+// and mounts the loop device to target
+func (fs *FS) mountImage(
+	ctx context.Context,
+	imagePath, target, fsType string,
+	opts ...string) (string, error) {
+
+	return "", ErrNotImplemented
+}
+
+// unmountImage unmounts target and detaches loopDevice
+func (fs *FS) unmountImage(
+	ctx context.Context, target, loopDevice string) error {
+
+	return ErrNotImplemented
+}
+
+// createLoopFilesystem creates a sparse file of sizeBytes at imagePath,
+// attaches it to a loop device, and formats the loop device as fsType
+func (fs *FS) createLoopFilesystem(
+	ctx context.Context,
+	imagePath string,
+	sizeBytes uint64,
+	fsType string) (string, error) {
+
+	return "", ErrNotImplemented
+}
+
+// deleteLoopFilesystem detaches loopDevice and removes its backing file,
+// which must be imagePath
+func (fs *FS) deleteLoopFilesystem(
+	ctx context.Context, loopDevice, imagePath string) error {
+
+	return ErrNotImplemented
+}
+
+// getLoopBackingFile returns the path of the file backing loopDevice
+func (fs *FS) getLoopBackingFile(
+	ctx context.Context, loopDevice string) (string, error) {
+
+	return "", ErrNotImplemented
+}
+
+// listLoopDevices returns the attached loop devices
+func (fs *FS) listLoopDevices(ctx context.Context) ([]LoopInfo, error) {
+	return nil, ErrNotImplemented
+}
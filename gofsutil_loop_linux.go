@@ -0,0 +1,231 @@
+package gofsutil
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// mountImage attaches the filesystem image at imagePath to a loop device
+// and mounts the loop device to target
+func (fs *FS) mountImage(
+	ctx context.Context,
+	imagePath, target, fsType string,
+	opts ...string) (string, error) {
+
+	// The options are checked by mount, but also before the image is
+	// attached.
+	if err := fs.checkOptions(fsType, opts); err != nil {
+		return "", err
+	}
+	readOnly := false
+	for _, o := range opts {
+		if o == "ro" {
+			readOnly = true
+		}
+	}
+
+	loopDevice, err := fs.attachLoopDevice(ctx, imagePath, readOnly)
+	if err != nil {
+		return "", err
+	}
+
+	if err := fs.mount(ctx, loopDevice, target, fsType, opts...); err != nil {
+		if err := fs.detachLoopDevice(ctx, loopDevice); err != nil {
+			log.WithFields(logFields(ctx, log.Fields{
+				"loopDevice": loopDevice,
+			})).WithError(err).Error(
+				"failed to detach loop device after mount failed")
+		}
+		return "", err
+	}
+
+	return loopDevice, nil
+}
+
+// unmountImage unmounts target and detaches loopDevice
+func (fs *FS) unmountImage(
+	ctx context.Context, target, loopDevice string) error {
+
+	if err := fs.unmount(ctx, target); err != nil {
+		return err
+	}
+	return fs.detachLoopDevice(ctx, loopDevice)
+}
+
+// createLoopFilesystem creates a sparse file of sizeBytes at imagePath,
+// attaches it to a loop device, and formats the loop device as fsType
+func (fs *FS) createLoopFilesystem(
+	ctx context.Context,
+	imagePath string,
+	sizeBytes uint64,
+	fsType string) (string, error) {
+
+	f := logFields(ctx, log.Fields{
+		"imagePath": imagePath,
+		"size":      sizeBytes,
+		"fsType":    fsType,
+	})
+	if sizeBytes == 0 {
+		return "", fmt.Errorf("invalid loop filesystem size: %d", sizeBytes)
+	}
+	log.WithFields(f).Info("creating loop filesystem")
+
+	img, err := os.OpenFile(imagePath, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return "", err
+	}
+	err = img.Truncate(int64(sizeBytes))
+	if cerr := img.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(imagePath)
+		return "", err
+	}
+
+	loopDevice, err := fs.attachLoopDevice(ctx, imagePath, false)
+	if err != nil {
+		os.Remove(imagePath)
+		return "", err
+	}
+	if err := fs.formatDevice(ctx, loopDevice, fsType, false); err != nil {
+		if err := fs.detachLoopDevice(ctx, loopDevice); err != nil {
+			log.WithFields(f).WithField("loopDevice", loopDevice).WithError(
+				err).Error("failed to detach loop device after format failed")
+		}
+		os.Remove(imagePath)
+		return "", err
+	}
+
+	f["loopDevice"] = loopDevice
+	log.WithFields(f).Info("created loop filesystem")
+	return loopDevice, nil
+}
+
+// deleteLoopFilesystem detaches loopDevice and removes its backing file,
+// which must be imagePath. Neither loopDevice nor any of its partitions
+// may be mounted.
+func (fs *FS) deleteLoopFilesystem(
+	ctx context.Context, loopDevice, imagePath string) error {
+
+	backingFile, err := fs.getLoopBackingFile(ctx, loopDevice)
+	if err != nil {
+		return err
+	}
+	if evalSymlinksOrPath(backingFile) != evalSymlinksOrPath(imagePath) {
+		return fmt.Errorf(
+			"refusing to delete %s: backed by %s, not %s",
+			loopDevice, backingFile, imagePath)
+	}
+
+	mounts, err := fs.getDiskMounts(ctx, loopDevice)
+	if err != nil {
+		return err
+	}
+	if len(mounts) > 0 {
+		return fmt.Errorf(
+			"refusing to delete %s: %s mounted at %s: %w",
+			loopDevice, mounts[0].Device, mounts[0].Path, ErrAlreadyMounted)
+	}
+
+	if err := fs.detachLoopDevice(ctx, loopDevice); err != nil {
+		return err
+	}
+	if err := os.Remove(imagePath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// attachLoopDevice attaches the file at imagePath to the first unused
+// loop device and returns the loop device's path
+func (fs *FS) attachLoopDevice(
+	ctx context.Context, imagePath string, readOnly bool) (string, error) {
+
+	args := []string{"-f", "--show"}
+	if readOnly {
+		args = append(args, "-r")
+	}
+	args = append(args, imagePath)
+
+	f := logFields(ctx, log.Fields{
+		"cmd":  "losetup",
+		"args": args,
+	})
+	log.WithFields(f).Info("attaching loop device")
+
+	buf, err := fs.exec(ctx, "losetup", args...)
+	out := strings.TrimSpace(string(buf))
+	if err != nil {
+		log.WithFields(f).WithField("output", out).WithError(
+			err).Error("losetup failed")
+		return "", fmt.Errorf(
+			"losetup failed: %v\narguments: %v\noutput: %s", err, args, out)
+	}
+	if out == "" {
+		return "", fmt.Errorf(
+			"losetup failed: no loop device\narguments: %v", args)
+	}
+	return out, nil
+}
+
+// detachLoopDevice detaches loopDevice from its backing file
+func (fs *FS) detachLoopDevice(ctx context.Context, loopDevice string) error {
+	f := logFields(ctx, log.Fields{
+		"cmd":        "losetup",
+		"loopDevice": loopDevice,
+	})
+	log.WithFields(f).Info("detaching loop device")
+
+	buf, err := fs.exec(ctx, "losetup", "-d", loopDevice)
+	if err != nil {
+		out := string(buf)
+		log.WithFields(f).WithField("output", out).WithError(
+			err).Error("losetup failed")
+		return fmt.Errorf(
+			"losetup failed: %v\nloop device: %s\noutput: %s",
+			err, loopDevice, out)
+	}
+	return nil
+}
+
+// getLoopBackingFile returns the path of the file backing loopDevice
+func (fs *FS) getLoopBackingFile(
+	ctx context.Context, loopDevice string) (string, error) {
+
+	name := path.Base(evalSymlinksOrPath(loopDevice))
+	if _, err := os.Stat(fs.sysPath("block", name)); os.IsNotExist(err) {
+		return "", fmt.Errorf("%s: %w", loopDevice, ErrDeviceNotFound)
+	}
+	buf, err := ioutil.ReadFile(
+		fs.sysPath("block", name, "loop", "backing_file"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf(
+				"%s is not an attached loop device", loopDevice)
+		}
+		return "", err
+	}
+	backingFile, _ := trimDeletedMarker(
+		strings.TrimSuffix(string(buf), "\n"))
+	return backingFile, nil
+}
+
+// listLoopDevices returns the attached loop devices
+func (fs *FS) listLoopDevices(ctx context.Context) ([]LoopInfo, error) {
+	log.WithField("cmd", "losetup").Info("listing loop devices")
+
+	buf, err := fs.exec(ctx, "losetup", "-J")
+	if err != nil {
+		out := string(buf)
+		log.WithField("output", out).WithError(err).Error("losetup failed")
+		return nil, fmt.Errorf("losetup failed: %v\noutput: %s", err, out)
+	}
+	return parseLosetupJSON(buf)
+}
@@ -0,0 +1,426 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+// newTestLoopRunner returns a command runner that attaches images to
+// /dev/loop3 and fails mount commands if failMount is true.
+func newTestLoopRunner(failMount bool) *testCommandRunner {
+	return &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			switch {
+			case args[0] == "losetup" && args[1] == "-f":
+				return "/dev/loop3\n", nil
+			case args[0] == "mount" && failMount:
+				return "wrong fs type", errors.New("exit status 32")
+			}
+			return "", nil
+		},
+	}
+}
+
+func TestMountImage(t *testing.T) {
+	r := newTestLoopRunner(false)
+	fs := &gofsutil.FS{
+		RunCommand: r.run,
+		ProcRoot:   newTestRootProcRoot(t),
+	}
+
+	loopDevice, err := fs.MountImage(
+		context.TODO(), "/data/disk.img", "/mnt", "ext4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loopDevice != "/dev/loop3" {
+		t.Errorf("invalid loop device: %s", loopDevice)
+	}
+	if err := fs.UnmountImage(context.TODO(), "/mnt", loopDevice); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t,
+		"losetup -f --show /data/disk.img",
+		"mount -t ext4 /dev/loop3 /mnt",
+		"umount /mnt",
+		"losetup -d /dev/loop3")
+}
+
+func TestMountImageReadOnly(t *testing.T) {
+	r := newTestLoopRunner(false)
+	fs := &gofsutil.FS{
+		RunCommand: r.run,
+		ProcRoot:   newTestRootProcRoot(t),
+	}
+
+	if _, err := fs.MountImage(
+		context.TODO(), "/data/disk.img", "/mnt", "ext4", "ro"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t,
+		"losetup -f --show -r /data/disk.img",
+		"mount -t ext4 -o ro /dev/loop3 /mnt")
+}
+
+func TestMountImageHooks(t *testing.T) {
+	var sources []string
+	r := newTestLoopRunner(false)
+	fs := &gofsutil.FS{
+		ProcRoot:   newTestRootProcRoot(t),
+		RunCommand: r.run,
+		PreMountHook: func(
+			ctx context.Context,
+			source, target, fsType string,
+			opts []string) error {
+
+			sources = append(sources, source)
+			return nil
+		},
+	}
+
+	if _, err := fs.MountImage(
+		context.TODO(), "/data/disk.img", "/mnt", "ext4"); err != nil {
+		t.Fatal(err)
+	}
+	if exp := []string{"/dev/loop3"}; !reflect.DeepEqual(sources, exp) {
+		t.Errorf("invalid hook sources: exp=%v, act=%v", exp, sources)
+	}
+}
+
+func TestMountImageDeniedOptions(t *testing.T) {
+	r := newTestLoopRunner(false)
+	fs := &gofsutil.FS{
+		RunCommand:    r.run,
+		DeniedOptions: map[string]struct{}{"exec": {}},
+	}
+
+	_, err := fs.MountImage(
+		context.TODO(), "/data/disk.img", "/mnt", "ext4", "exec")
+	var optErr *gofsutil.MountOptionError
+	if !errors.As(err, &optErr) || optErr.Option != "exec" {
+		t.Fatalf("expected MountOptionError: %v", err)
+	}
+	r.assertCommands(t)
+}
+
+func TestMountImageMountFailed(t *testing.T) {
+	r := newTestLoopRunner(true)
+	fs := &gofsutil.FS{
+		RunCommand: r.run,
+		ProcRoot:   newTestRootProcRoot(t),
+	}
+
+	if _, err := fs.MountImage(
+		context.TODO(), "/data/disk.img", "/mnt", "ext4"); err == nil {
+		t.Fatal("expected mount error")
+	}
+	r.assertCommands(t,
+		"losetup -f --show /data/disk.img",
+		"mount -t ext4 /dev/loop3 /mnt",
+		"losetup -d /dev/loop3")
+}
+
+// newTestLoopSysRoot creates a temporary sys filesystem root in which
+// loop0 is backed by /var/lib/images/a.img, loop1 is backed by a deleted
+// file, and loop2 is not attached.
+func newTestLoopSysRoot(t *testing.T) string {
+	sysRoot := t.TempDir()
+	backingFiles := map[string]string{
+		"loop0": "/var/lib/images/a.img\n",
+		"loop1": "/var/lib/images/b.img (deleted)\n",
+		"loop2": "",
+	}
+	for name, backingFile := range backingFiles {
+		dir := path.Join(sysRoot, "block", name)
+		if backingFile != "" {
+			dir = path.Join(dir, "loop")
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if backingFile == "" {
+			continue
+		}
+		if err := ioutil.WriteFile(path.Join(dir, "backing_file"),
+			[]byte(backingFile), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return sysRoot
+}
+
+func TestGetLoopBackingFile(t *testing.T) {
+	sysRoot := newTestLoopSysRoot(t)
+	fs := &gofsutil.FS{SysRoot: sysRoot}
+
+	tests := []struct {
+		device      string
+		backingFile string
+	}{
+		{"/dev/loop0", "/var/lib/images/a.img"},
+		{"/dev/loop1", "/var/lib/images/b.img"},
+	}
+	for _, tt := range tests {
+		backingFile, err := fs.GetLoopBackingFile(context.TODO(), tt.device)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.device, err)
+		}
+		if backingFile != tt.backingFile {
+			t.Errorf("%s: invalid backing file: exp=%s, act=%s",
+				tt.device, tt.backingFile, backingFile)
+		}
+	}
+
+	if _, err := fs.GetLoopBackingFile(
+		context.TODO(), "/dev/loop2"); err == nil {
+		t.Error("expected error for detached loop device")
+	}
+	_, err := fs.GetLoopBackingFile(context.TODO(), "/dev/loop9")
+	if !errors.Is(err, gofsutil.ErrDeviceNotFound) {
+		t.Errorf("expected ErrDeviceNotFound, got %v", err)
+	}
+}
+
+func TestListLoopDevices(t *testing.T) {
+	tests := []struct {
+		name string
+		out  string
+	}{
+		{
+			name: "typed",
+			out: `{
+   "loopdevices": [
+      {"name":"/dev/loop0", "sizelimit":0, "offset":0, "autoclear":false, "ro":false, "back-file":"/var/lib/images/a.img", "dio":false, "log-sec":512},
+      {"name":"/dev/loop1", "sizelimit":1048576, "offset":4096, "autoclear":true, "ro":true, "back-file":"/var/lib/images/b.img (deleted)", "dio":false, "log-sec":512}
+   ]
+}
+`,
+		},
+		{
+			name: "strings",
+			out: `{
+   "loopdevices": [
+      {"name":"/dev/loop0", "sizelimit":"0", "offset":"0", "autoclear":"0", "ro":"0", "back-file":"/var/lib/images/a.img"},
+      {"name":"/dev/loop1", "sizelimit":"1048576", "offset":"4096", "autoclear":"1", "ro":"1", "back-file":"/var/lib/images/b.img (deleted)"}
+   ]
+}
+`,
+		},
+	}
+	exp := []gofsutil.LoopInfo{
+		{
+			Device:      "/dev/loop0",
+			BackingFile: "/var/lib/images/a.img",
+		},
+		{
+			Device:      "/dev/loop1",
+			BackingFile: "/var/lib/images/b.img",
+			Deleted:     true,
+			Offset:      4096,
+			SizeLimit:   1048576,
+			ReadOnly:    true,
+			AutoClear:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		out := tt.out
+		r := &testCommandRunner{
+			handler: func(args []string) (string, error) {
+				return out, nil
+			},
+		}
+		fs := &gofsutil.FS{RunCommand: r.run}
+		loops, err := fs.ListLoopDevices(context.TODO())
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(loops, exp) {
+			t.Errorf("%s: invalid loop devices: exp=%+v, act=%+v",
+				tt.name, exp, loops)
+		}
+		r.assertCommands(t, "losetup -J")
+	}
+
+	// losetup emits nothing when no loop devices are attached.
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{RunCommand: r.run}
+	loops, err := fs.ListLoopDevices(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loops) != 0 {
+		t.Errorf("expected no loop devices, got %+v", loops)
+	}
+}
+
+func TestCreateLoopFilesystem(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("requires root")
+	}
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var (
+		ctx       = context.TODO()
+		imagePath = path.Join(dir, "disk.img")
+		target    = path.Join(dir, "mnt")
+	)
+	loopDevice, err := gofsutil.CreateLoopFilesystem(
+		ctx, imagePath, 64<<20, "ext4")
+	if err != nil {
+		t.Skipf("create loop filesystem: %v", err)
+	}
+	// The loop device is mounted as ext4 to verify it was formatted.
+	if err := os.Mkdir(target, 0755); err != nil {
+		gofsutil.DeleteLoopFilesystem(ctx, loopDevice, imagePath)
+		t.Fatal(err)
+	}
+	if err := gofsutil.Mount(ctx, loopDevice, target, "ext4"); err != nil {
+		gofsutil.DeleteLoopFilesystem(ctx, loopDevice, imagePath)
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(path.Join(target, "a"), []byte("data"), 0644)
+	if uerr := gofsutil.Unmount(ctx, target); uerr != nil {
+		t.Fatal(uerr)
+	}
+	if err != nil {
+		t.Error(err)
+	}
+
+	if err := gofsutil.DeleteLoopFilesystem(ctx, loopDevice, imagePath); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(imagePath); !os.IsNotExist(err) {
+		t.Errorf("image not removed: %v", err)
+	}
+	loops, err := gofsutil.ListLoopDevices(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range loops {
+		if l.BackingFile == imagePath {
+			t.Errorf("loop device not detached: %+v", l)
+		}
+	}
+}
+
+func TestDeleteLoopFilesystem(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := gofsutil.EvalSymlinks(context.TODO(), &dir); err != nil {
+		t.Fatal(err)
+	}
+
+	// The partition loop5p1 is a child of the sys filesystem directory
+	// of loop5, which is backed by imagePath.
+	var (
+		sysRoot   = path.Join(dir, "sys")
+		imagePath = path.Join(dir, "disk.img")
+		part      = path.Join(sysRoot, "class", "block", "loop5", "loop5p1")
+		loop      = path.Join(sysRoot, "block", "loop5", "loop")
+	)
+	for _, d := range []string{part, loop} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, data := range map[string]string{
+		path.Join(part, "partition"):    "1\n",
+		path.Join(loop, "backing_file"): imagePath + "\n",
+		imagePath:                       "",
+	} {
+		if err := ioutil.WriteFile(name, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		imagePath string
+		mountInfo string
+		err       error
+		cmds      []string
+	}{
+		{
+			name:      "other backing file",
+			imagePath: "/var/lib/images/a.img",
+			err:       errors.New("backed by"),
+		},
+		{
+			name:      "mounted partition",
+			imagePath: imagePath,
+			mountInfo: "72 60 259:0 / /mnt/data rw,relatime shared:28 - " +
+				"ext4 /dev/loop5p1 rw\n",
+			err: gofsutil.ErrAlreadyMounted,
+		},
+		{
+			name:      "unmounted",
+			imagePath: imagePath,
+			cmds:      []string{"losetup -d /dev/loop5"},
+		},
+	}
+
+	for _, tt := range tests {
+		procRoot := newTestProcRoot(t, tt.mountInfo, "self")
+
+		r := &testCommandRunner{}
+		fs := &gofsutil.FS{
+			ProcRoot:   procRoot,
+			SysRoot:    sysRoot,
+			RunCommand: r.run,
+		}
+		err := fs.DeleteLoopFilesystem(
+			context.TODO(), "/dev/loop5", tt.imagePath)
+		switch {
+		case tt.err == nil && err != nil:
+			t.Fatalf("%s: %v", tt.name, err)
+		case errors.Is(tt.err, gofsutil.ErrAlreadyMounted) &&
+			!errors.Is(err, tt.err):
+			t.Errorf("%s: expected ErrAlreadyMounted: %v", tt.name, err)
+		case tt.err != nil && (err == nil ||
+			!strings.Contains(err.Error(), tt.err.Error())):
+			t.Errorf("%s: expected %q: %v", tt.name, tt.err, err)
+		}
+		r.assertCommands(t, tt.cmds...)
+
+		_, err = os.Stat(imagePath)
+		if tt.err != nil && err != nil {
+			t.Errorf("%s: image removed: %v", tt.name, err)
+		}
+		if tt.err == nil && !os.IsNotExist(err) {
+			t.Errorf("%s: image not removed: %v", tt.name, err)
+		}
+	}
+}
+
+func TestCreateLoopFilesystemExists(t *testing.T) {
+	f, err := ioutil.TempFile("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{RunCommand: r.run}
+	_, err = fs.CreateLoopFilesystem(context.TODO(), f.Name(), 64<<20, "ext4")
+	if !os.IsExist(err) {
+		t.Errorf("expected exist error: %v", err)
+	}
+	r.assertCommands(t)
+}
@@ -0,0 +1,16 @@
+package gofsutil
+
+// LsblkMode selects the output format GetDiskFormat requests from lsblk.
+type LsblkMode string
+
+const (
+	// LsblkModeJSON requests JSON output with "lsblk -J", which is
+	// supported by util-linux 2.27 and later. The JSON emitted by
+	// versions prior to 2.33 has only string values, which is also
+	// accepted.
+	LsblkModeJSON LsblkMode = "json"
+
+	// LsblkModePairs requests KEY="value" pairs output with "lsblk -P",
+	// which is supported by all versions of lsblk.
+	LsblkModePairs LsblkMode = "pairs"
+)
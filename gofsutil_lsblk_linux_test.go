@@ -0,0 +1,170 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+// lsblkOutputs are the outputs captured from different versions of lsblk
+// for a formatted disk, an unformatted disk, and a partitioned disk.
+var lsblkOutputs = []struct {
+	version     string
+	json        bool
+	formatted   string
+	unformatted string
+	partitioned string
+}{
+	{
+		// util-linux 2.23.2 does not support JSON output.
+		version:     "2.23.2",
+		formatted:   "NAME=\"sdb\" FSTYPE=\"xfs\"\n",
+		unformatted: "NAME=\"sdb\" FSTYPE=\"\"\n",
+		partitioned: "NAME=\"sdb\" FSTYPE=\"\"\n" +
+			"NAME=\"sdb1\" FSTYPE=\"ext4\"\n" +
+			"NAME=\"sdb2\" FSTYPE=\"swap\"\n",
+	},
+	{
+		// util-linux 2.32.1 emits null for an empty column.
+		version: "2.32.1",
+		json:    true,
+		formatted: `{
+   "blockdevices": [
+      {"name": "sdb", "fstype": "xfs"}
+   ]
+}
+`,
+		unformatted: `{
+   "blockdevices": [
+      {"name": "sdb", "fstype": null}
+   ]
+}
+`,
+		partitioned: `{
+   "blockdevices": [
+      {"name": "sdb", "fstype": null,
+         "children": [
+            {"name": "sdb1", "fstype": "ext4"},
+            {"name": "sdb2", "fstype": "swap"}
+         ]
+      }
+   ]
+}
+`,
+	},
+	{
+		version: "2.37.2",
+		json:    true,
+		formatted: `{
+   "blockdevices": [
+      {
+         "name": "sdb",
+         "fstype": "xfs"
+      }
+   ]
+}
+`,
+		unformatted: `{
+   "blockdevices": [
+      {
+         "name": "sdb",
+         "fstype": null
+      }
+   ]
+}
+`,
+		partitioned: `{
+   "blockdevices": [
+      {
+         "name": "sdb",
+         "fstype": null,
+         "children": [
+            {
+               "name": "sdb1",
+               "fstype": "ext4"
+            },{
+               "name": "sdb2",
+               "fstype": "swap"
+            }
+         ]
+      }
+   ]
+}
+`,
+	},
+}
+
+// newTestLsblkVersionRunner returns a command runner that emits out for
+// lsblk commands. If json is false then lsblk rejects the -J option like
+// the versions of lsblk that do not support JSON output.
+func newTestLsblkVersionRunner(json bool, out string) *testCommandRunner {
+	return &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			if args[1] == "-J" && !json {
+				return "lsblk: invalid option -- 'J'\n",
+					errors.New("exit status 1")
+			}
+			return out, nil
+		},
+	}
+}
+
+func TestGetDiskFormatLsblkVersions(t *testing.T) {
+	for _, v := range lsblkOutputs {
+		for _, tt := range []struct {
+			out    string
+			fsType string
+		}{
+			{v.formatted, "xfs"},
+			{v.unformatted, ""},
+			{v.partitioned, "unknown data, probably partitions"},
+		} {
+			r := newTestLsblkVersionRunner(v.json, tt.out)
+			fs := &gofsutil.FS{RunCommand: r.run}
+			fsType, err := fs.GetDiskFormat(context.TODO(), "/dev/sdb")
+			if err != nil {
+				t.Fatalf("%s: %v", v.version, err)
+			}
+			if fsType != tt.fsType {
+				t.Errorf("%s: invalid fsType: exp=%q, act=%q",
+					v.version, tt.fsType, fsType)
+			}
+			if v.json {
+				r.assertCommands(t, "lsblk -J -o NAME,FSTYPE /dev/sdb")
+			} else {
+				r.assertCommands(t,
+					"lsblk -J -o NAME,FSTYPE /dev/sdb",
+					"lsblk -P -o NAME,FSTYPE /dev/sdb")
+			}
+		}
+	}
+}
+
+func TestGetDiskFormatLsblkMode(t *testing.T) {
+	v := lsblkOutputs[0]
+	r := newTestLsblkVersionRunner(false, v.partitioned)
+	fs := &gofsutil.FS{
+		RunCommand: r.run,
+		LsblkMode:  gofsutil.LsblkModePairs,
+	}
+	fsType, err := fs.GetDiskFormat(context.TODO(), "/dev/sdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fsType != "unknown data, probably partitions" {
+		t.Errorf("invalid fsType: %q", fsType)
+	}
+	r.assertCommands(t, "lsblk -P -o NAME,FSTYPE /dev/sdb")
+
+	// A JSON mode that is not supported is not silently replaced.
+	fs.LsblkMode = gofsutil.LsblkModeJSON
+	if _, err := fs.GetDiskFormat(context.TODO(), "/dev/sdb"); err == nil {
+		t.Error("expected error for unsupported json mode")
+	}
+	fs.LsblkMode = "columns"
+	if _, err := fs.GetDiskFormat(context.TODO(), "/dev/sdb"); err == nil {
+		t.Error("expected error for invalid lsblk mode")
+	}
+}
@@ -0,0 +1,8 @@
+package gofsutil
+
+import "context"
+
+// modprobe loads the kernel module of the filesystem type
+func (fs *FS) modprobe(ctx context.Context, fsType string) error {
+	return ErrNotImplemented
+}
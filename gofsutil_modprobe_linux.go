@@ -0,0 +1,24 @@
+package gofsutil
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// modprobe loads the kernel module of the filesystem type
+func (fs *FS) modprobe(ctx context.Context, fsType string) error {
+	if err := validatePathChars(fsType); err != nil {
+		return fmt.Errorf("invalid filesystem type: %v", err)
+	}
+	log.WithFields(logFields(ctx, log.Fields{
+		"fsType": fsType,
+	})).Info("loading filesystem module")
+	buf, err := fs.exec(ctx, "modprobe", fsType)
+	if err != nil {
+		return fmt.Errorf("modprobe %s failed: %v\noutput: %s",
+			fsType, err, string(buf))
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+// newTestUnknownFSTypeRunner returns a command runner that fails to mount
+// a filesystem until the filesystem's module is loaded with modprobe.
+func newTestUnknownFSTypeRunner(modprobeErr error) *testCommandRunner {
+	loaded := false
+	return &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			switch args[0] {
+			case "modprobe":
+				if modprobeErr != nil {
+					return "modprobe: FATAL: Module btrfs not found",
+						modprobeErr
+				}
+				loaded = true
+			case "mount":
+				if !loaded {
+					return "mount: /mnt: unknown filesystem type 'btrfs'.",
+						errors.New("exit status 32")
+				}
+			}
+			return "", nil
+		},
+	}
+}
+
+func TestMountAutoModprobe(t *testing.T) {
+	r := newTestUnknownFSTypeRunner(nil)
+	fs := &gofsutil.FS{
+		RunCommand:   r.run,
+		AutoModprobe: true,
+		ProcRoot:     newTestRootProcRoot(t),
+	}
+
+	if err := fs.Mount(
+		context.TODO(), "/dev/sdc", "/mnt", "btrfs", "ro"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t,
+		"mount -t btrfs -o ro /dev/sdc /mnt",
+		"modprobe btrfs",
+		"mount -t btrfs -o ro /dev/sdc /mnt")
+}
+
+func TestMountAutoModprobeDisabled(t *testing.T) {
+	r := newTestUnknownFSTypeRunner(nil)
+	fs := &gofsutil.FS{
+		RunCommand: r.run,
+		ProcRoot:   newTestRootProcRoot(t),
+	}
+
+	if err := fs.Mount(
+		context.TODO(), "/dev/sdc", "/mnt", "btrfs", "ro"); err == nil {
+		t.Fatal("expected unknown filesystem type error")
+	}
+	r.assertCommands(t, "mount -t btrfs -o ro /dev/sdc /mnt")
+}
+
+func TestMountAutoModprobeFailure(t *testing.T) {
+	r := newTestUnknownFSTypeRunner(errors.New("exit status 1"))
+	fs := &gofsutil.FS{
+		RunCommand:   r.run,
+		AutoModprobe: true,
+		ProcRoot:     newTestRootProcRoot(t),
+	}
+
+	err := fs.Mount(context.TODO(), "/dev/sdc", "/mnt", "btrfs")
+	if err == nil {
+		t.Fatal("expected unknown filesystem type error")
+	}
+	r.assertCommands(t,
+		"mount -t btrfs /dev/sdc /mnt",
+		"modprobe btrfs")
+}
+
+func TestMountAutoModprobeOtherError(t *testing.T) {
+	r := &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			return "mount: /mnt: wrong fs type, bad option, bad superblock",
+				errors.New("exit status 32")
+		},
+	}
+	fs := &gofsutil.FS{
+		RunCommand:   r.run,
+		AutoModprobe: true,
+		ProcRoot:     newTestRootProcRoot(t),
+	}
+
+	if err := fs.Mount(
+		context.TODO(), "/dev/sdc", "/mnt", "btrfs"); err == nil {
+		t.Fatal("expected mount error")
+	}
+	r.assertCommands(t, "mount -t btrfs /dev/sdc /mnt")
+}
@@ -8,6 +8,7 @@ import (
 	"io"
 	"path"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -15,11 +16,65 @@ import (
 // https://www.kernel.org/doc/Documentation/filesystems/proc.txt
 const ProcMountsFields = 9
 
+const (
+	// defaultProcRoot is the path to the root of the proc filesystem
+	// used when FS.ProcRoot is empty.
+	defaultProcRoot = "/proc"
+
+	// defaultSysRoot is the path to the root of the sys filesystem
+	// used when FS.SysRoot is empty.
+	defaultSysRoot = "/sys"
+
+	// defaultDevRoot is the path to the root of the dev filesystem
+	// used when FS.DevRoot is empty.
+	defaultDevRoot = "/dev"
+
+	// defaultEtcRoot is the path to the host configuration directory
+	// used when FS.EtcRoot is empty.
+	defaultEtcRoot = "/etc"
+
+	// defaultModulesRoot is the path to the kernel modules directory
+	// used when FS.ModulesRoot is empty.
+	defaultModulesRoot = "/lib/modules"
+)
+
+// diskFormatPartitions is the format of a disk that does not contain a
+// filesystem but has dependent devices, most probably partitions.
+const diskFormatPartitions = "unknown data, probably partitions"
+
+const (
+	// MountKindFilesystem is the kind of a mount of a filesystem to a
+	// directory.
+	MountKindFilesystem = "filesystem"
+
+	// MountKindBlock is the kind of a bind mount of a block device to a
+	// file, ex. a CSI block volume.
+	MountKindBlock = "block"
+
+	// MountKindBind is the kind of a bind mount of a directory or of a
+	// file other than a block device.
+	MountKindBind = "bind"
+)
+
 // Info describes a mounted filesystem.
 //
 // Please note that all fields that represent filesystem paths must
 // be absolute and not contain any symlinks.
 type Info struct {
+	// ID is the unique identifier of the mount. The ID of a mount may
+	// be reused after it is unmounted.
+	//
+	// The ID is only reported by the mount table of a process, ex.
+	// "/proc/self/mountinfo", and is zero otherwise, ex. for the
+	// entries of mtab and on Darwin hosts.
+	ID int
+
+	// ParentID is the ID of the parent of the mount, or of the mount
+	// itself if the mount is the root of the process's mount tree. The
+	// parent of a mount may not be visible to the process, ex. when the
+	// process is chrooted.
+	ParentID int
+
 	// Device is the filesystem path of the device to which the filesystem is
 	// mounted.
 	Device string
@@ -27,6 +82,20 @@ type Info struct {
 	// Path is the filesystem path to which Device is mounted.
 	Path string
 
+	// MajorMinor is the value of st_dev for files on the filesystem in
+	// the form "major:minor".
+	//
+	// Darwin hosts do not report the device ID of a mount, so MajorMinor
+	// is always empty.
+	MajorMinor string
+
+	// Root is the root of the mount within the filesystem, ex. the
+	// directory bind mounted to Path or the path of a btrfs subvolume.
+	//
+	// Darwin hosts do not report the root of a mount, so Root is always
+	// set to "/".
+	Root string
+
 	// Source may be set to one of two values:
 	//
 	//   1. If this is a bind mount created with "bindfs" then Source
@@ -53,6 +122,47 @@ type Info struct {
 	// Opts are the mount options (https://linux.die.net/man/8/mount)
 	// used to mount the filesystem.
 	Opts []string
+
+	// SuperOpts are the per super block options.
+	SuperOpts []string
+}
+
+// FormatOptions are the options used when a disk is formatted by
+// FormatAndMountWithOpts.
+type FormatOptions struct {
+	// MkfsOptions are additional arguments passed to the mkfs command
+	// before the device.
+	MkfsOptions []string
+
+	// ReservedBlocksPercent is the percentage of an ext filesystem's
+	// blocks reserved for the super-user. The value is applied with
+	// tune2fs after the disk is formatted. If nil then the mkfs
+	// default is used.
+	ReservedBlocksPercent *float64
+
+	// ProjectQuota enables project quotas on the filesystem. An xfs
+	// filesystem is mounted with the "prjquota" option, and the quota
+	// feature is enabled on an ext filesystem with tune2fs after it is
+	// formatted.
+	ProjectQuota bool
+
+	// Label is the label given to the filesystem when it is created.
+	// The label is validated with ValidateFSLabel.
+	Label string
+
+	// AutoNoDiscard prevents the mkfs command from discarding the blocks
+	// of a device that supports discard, which may take minutes on a
+	// large thin-provisioned volume. The ext family is formatted with
+	// "-E nodiscard" and xfs and btrfs with "-K". Devices that do not
+	// support discard are formatted as usual. Please see
+	// FS.SupportsDiscard. Darwin hosts ignore this field.
+	AutoNoDiscard bool
+
+	// Progress, if not nil, is invoked with each line of the output of
+	// the mkfs command as it is written, ex. so a UI may show the status
+	// of a format that takes minutes. The function is invoked from the
+	// goroutine that copies the command's output and must not block.
+	Progress func(line string)
 }
 
 // Entry is a superset of Info and maps to the fields of a mount table
@@ -70,6 +180,15 @@ type Info struct {
 //   (10) mount source:  filesystem specific information or "none"
 //   (11) super options:  per super block options
 type Entry struct {
+	// ID is the unique identifier of the mount.
+	ID int
+
+	// ParentID is the ID of the parent of the mount.
+	ParentID int
+
+	// MajorMinor is the value of st_dev for files on filesystem.
+	MajorMinor string
+
 	// Root of the mount within the filesystem.
 	Root string
 
@@ -84,6 +203,9 @@ type Entry struct {
 
 	// MountSource is filesystem specific information or "none"
 	MountSource string
+
+	// SuperOpts are per super block options.
+	SuperOpts []string
 }
 
 // EntryScanFunc defines the signature of the function that is optionally
@@ -113,11 +235,34 @@ func defaultEntryScanFunc(
 		return
 	}
 
+	return entryToInfo(entry, cache), true, nil
+}
+
+// scanAllEntries is an EntryScanFunc that converts every mount table
+// entry to an Info object.
+func scanAllEntries(
+	ctx context.Context,
+	entry Entry,
+	cache map[string]Entry) (Info, bool, error) {
+
+	return entryToInfo(entry, cache), true, nil
+}
+
+// entryToInfo converts a mount table entry to an Info object. The cache
+// holds the first entry of each mount source and is used to resolve the
+// Source field of subsequent entries with the same mount source.
+func entryToInfo(entry Entry, cache map[string]Entry) (info Info) {
 	// Copy the Entry object's fields to the Info object.
+	info.ID = entry.ID
+	info.ParentID = entry.ParentID
 	info.Device = entry.MountSource
 	info.Opts = make([]string, len(entry.MountOpts))
 	copy(info.Opts, entry.MountOpts)
+	info.SuperOpts = make([]string, len(entry.SuperOpts))
+	copy(info.SuperOpts, entry.SuperOpts)
 	info.Path = entry.MountPoint
+	info.Root = entry.Root
+	info.MajorMinor = entry.MajorMinor
 	info.Type = entry.FSType
 	info.Source = entry.MountSource
 
@@ -138,6 +283,33 @@ func defaultEntryScanFunc(
 	return
 }
 
+// ParseMountInfo parses a mount table in the format of
+// "/proc/<pid>/mountinfo", ex. one captured from a remote host. Unlike
+// ReadProcMountsFrom, every entry in the mount table is returned.
+func ParseMountInfo(r io.Reader) ([]Info, error) {
+	infos, _, err := ReadProcMountsFrom(
+		context.Background(), r, true, ProcMountsFields, scanAllEntries)
+	return infos, err
+}
+
+// ParseMountTable parses a mount table in the format of "/proc/mounts"
+// and "/etc/mtab". Every entry in the mount table is returned. Since
+// this format does not include the root of a mount, the Root field of
+// each entry is set to "/".
+func ParseMountTable(r io.Reader) ([]Info, error) {
+	var infos []Info
+	err := walkMountTableFrom(
+		context.Background(), r, scanAllEntries,
+		func(info Info) (bool, error) {
+			infos = append(infos, info)
+			return false, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return infos, nil
+}
+
 /*
 ReadProcMountsFrom parses the contents of a mount table file, typically
 "/proc/self/mountinfo".
@@ -179,64 +351,185 @@ func ReadProcMountsFrom(
 	expectedFields int,
 	scanEntry EntryScanFunc) ([]Info, uint32, error) {
 
+	var (
+		infos []Info
+		hash  = fnv.New32a()
+	)
+
+	err := walkProcMountsFrom(
+		ctx, file, expectedFields, scanEntry,
+		func(line string, info Info) (bool, error) {
+			fmt.Fprint(hash, line)
+			infos = append(infos, info)
+			return false, nil
+		})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return infos, hash.Sum32(), nil
+}
+
+// walkProcMountsFrom parses the contents of a mount table file and
+// invokes fn with each valid mount table entry and the line from
+// which it was parsed. The walk ends early if fn returns true or an
+// error, or if the context is cancelled.
+func walkProcMountsFrom(
+	ctx context.Context,
+	file io.Reader,
+	expectedFields int,
+	scanEntry EntryScanFunc,
+	fn func(line string, info Info) (bool, error)) error {
+
 	if scanEntry == nil {
 		scanEntry = defaultEntryScanFunc
 	}
 
 	var (
-		infos []Info
-		hash  = fnv.New32a()
 		fscan = bufio.NewScanner(file)
 		cache = map[string]Entry{}
 	)
 
 	for fscan.Scan() {
 
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		// Read the next line of text and attempt to parse it into
-		// distinct, space-separated fields.
+		// a mount table entry.
 		line := fscan.Text()
-		fields := strings.Fields(line)
+		e, err := parseMountInfoLine(line, expectedFields)
+		if err != nil {
+			return err
+		}
+
+		// If the ScanFunc indicates the mount table entry is invalid
+		// then do not consider it for the checksum and continue to the
+		// next mount table entry.
+		i, valid, err := scanEntry(ctx, e, cache)
+		if err != nil {
+			return err
+		}
+		if !valid {
+			continue
+		}
+
+		stop, err := fn(line, i)
+		if err != nil {
+			return err
+		}
+		if stop {
+			return nil
+		}
+	}
+
+	return fscan.Err()
+}
 
-		// Remove the optional fields that should be ignored.
-		for {
-			val := fields[6]
-			fields = append(fields[:6], fields[7:]...)
-			if val == "-" {
+// parseMountInfoLine parses a line of a mount table in the format of
+// "/proc/<pid>/mountinfo". The optional fields are ignored, and the
+// octal escape sequences in the paths are decoded.
+func parseMountInfoLine(line string, expectedFields int) (Entry, error) {
+	fields := strings.Fields(line)
+
+	// Remove the optional fields that should be ignored, including the
+	// separator that follows them.
+	if len(fields) > 6 {
+		for i := 6; i < len(fields); i++ {
+			if fields[i] == "-" {
+				fields = append(fields[:6], fields[i+1:]...)
 				break
 			}
 		}
+	}
+
+	if len(fields) != expectedFields || len(fields) < ProcMountsFields {
+		return Entry{}, fmt.Errorf(
+			"readProcMountsFrom: invalid field count: exp=%d, act=%d: %s",
+			expectedFields, len(fields), line)
+	}
+
+	id, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return Entry{}, fmt.Errorf(
+			"readProcMountsFrom: invalid mount id: %s", line)
+	}
+	parentID, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return Entry{}, fmt.Errorf(
+			"readProcMountsFrom: invalid parent id: %s", line)
+	}
 
-		if len(fields) != expectedFields {
-			return nil, 0, fmt.Errorf(
-				"readProcMountsFrom: invalid field count: exp=%d, act=%d: %s",
-				expectedFields, len(fields), line)
+	return Entry{
+		ID:          id,
+		ParentID:    parentID,
+		MajorMinor:  fields[2],
+		Root:        unescapeOctal(fields[3]),
+		MountPoint:  unescapeOctal(fields[4]),
+		MountOpts:   splitMountOpts(fields[5]),
+		FSType:      fields[6],
+		MountSource: unescapeOctal(fields[7]),
+		SuperOpts:   splitMountOpts(fields[8]),
+	}, nil
+}
+
+// walkMountTableFrom parses the contents of a mount table in the format
+// of "/proc/mounts" and "/etc/mtab" and invokes fn with each valid mount
+// table entry. The walk ends early if fn returns true or an error, or if
+// the context is cancelled.
+func walkMountTableFrom(
+	ctx context.Context,
+	file io.Reader,
+	scanEntry EntryScanFunc,
+	fn func(info Info) (bool, error)) error {
+
+	if scanEntry == nil {
+		scanEntry = defaultEntryScanFunc
+	}
+
+	var (
+		fscan = bufio.NewScanner(file)
+		cache = map[string]Entry{}
+	)
+
+	for fscan.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
 		}
 
-		// Create a new Entry object from the mount table entry.
+		// Each line has the format of an fstab entry:
+		// source target fstype options dump pass
+		fields := strings.Fields(fscan.Text())
+		if len(fields) < 4 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
 		e := Entry{
-			Root:        fields[3],
-			MountPoint:  fields[4],
-			MountOpts:   strings.Split(fields[5], ","),
-			FSType:      fields[6],
-			MountSource: fields[7],
+			Root:        "/",
+			MountPoint:  unescapeOctal(fields[1]),
+			MountOpts:   splitMountOpts(fields[3]),
+			FSType:      fields[2],
+			MountSource: unescapeOctal(fields[0]),
 		}
 
-		// If the ScanFunc indicates the mount table entry is invalid
-		// then do not consider it for the checksum and continue to the
-		// next mount table entry.
 		i, valid, err := scanEntry(ctx, e, cache)
 		if err != nil {
-			return nil, 0, err
+			return err
 		}
 		if !valid {
 			continue
 		}
 
-		fmt.Fprint(hash, line)
-		infos = append(infos, i)
+		stop, err := fn(i)
+		if err != nil {
+			return err
+		}
+		if stop {
+			return nil
+		}
 	}
 
-	return infos, hash.Sum32(), nil
+	return fscan.Err()
 }
 
 // MakeMountArgs makes the arguments to the mount(8) command.
@@ -268,3 +561,264 @@ func MakeMountArgs(
 
 	return args
 }
+
+// mountTableKey is the context key for a mount table attached to a
+// context with WithMountTable.
+type mountTableKey struct{}
+
+// WithMountTable returns a copy of ctx with the provided mount table
+// attached. The functions in this package that read the mount table of
+// the current process, ex. GetMounts, GetDevMounts, and GetMountFSType,
+// use the attached table instead of reading and parsing the mount table
+// again. This allows a caller that makes several lookups in a row to
+// scan the mount table once, ex.:
+//
+//         mounts, err := gofsutil.GetMounts(ctx)
+//         ...
+//         ctx = gofsutil.WithMountTable(ctx, mounts)
+//
+// The attached table is not updated as filesystems are mounted or
+// unmounted, so it is only used to answer queries. The checks made
+// before and after a filesystem is mounted, ex. for an overmount or by
+// VerifyMount and VerifyOptions, and the polls of WaitForUnmount always
+// read the mount table again.
+func WithMountTable(ctx context.Context, mounts []Info) context.Context {
+	table := make([]Info, len(mounts))
+	copy(table, mounts)
+	return context.WithValue(ctx, mountTableKey{}, table)
+}
+
+// mountTableFromContext returns a copy of the mount table attached to
+// ctx with WithMountTable. A false value is returned if ctx does not
+// have a mount table.
+func mountTableFromContext(ctx context.Context) ([]Info, bool) {
+	table, ok := ctx.Value(mountTableKey{}).([]Info)
+	if !ok {
+		return nil, false
+	}
+	mounts := make([]Info, len(table))
+	copy(mounts, table)
+	return mounts, true
+}
+
+// withoutMountTable returns a copy of ctx without the mount table
+// attached with WithMountTable, so the mount table is read again
+func withoutMountTable(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(mountTableKey{}).([]Info); !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, mountTableKey{}, nil)
+}
+
+// withAllEntries returns a copy of ctx without the mount table attached
+// with WithMountTable and with scanAllEntries attached as the scan
+// function, so every entry of the mount table is read again, ex. to
+// check whether anything at all is mounted at a path.
+func withAllEntries(ctx context.Context) context.Context {
+	return WithEntryScanFunc(withoutMountTable(ctx), scanAllEntries)
+}
+
+// entryScanFuncKey is the context key for an EntryScanFunc attached to a
+// context with WithEntryScanFunc.
+type entryScanFuncKey struct{}
+
+// WithEntryScanFunc returns a copy of ctx with the provided scan
+// function attached. The FS functions invoked with the returned context
+// process the entries of the mount table with scan instead of the FS's
+// ScanEntry, which allows a caller to override the scan function of a
+// shared FS for a single call without modifying the FS. Darwin hosts do
+// not use a scan function.
+func WithEntryScanFunc(ctx context.Context, scan EntryScanFunc) context.Context {
+	return context.WithValue(ctx, entryScanFuncKey{}, scan)
+}
+
+// scanEntry returns the EntryScanFunc attached to ctx with
+// WithEntryScanFunc, if any, otherwise the FS's ScanEntry.
+func (fs *FS) scanEntry(ctx context.Context) EntryScanFunc {
+	if scan, ok := ctx.Value(entryScanFuncKey{}).(EntryScanFunc); ok &&
+		scan != nil {
+		return scan
+	}
+	return fs.ScanEntry
+}
+
+// skipMount returns a flag indicating whether the FS is configured to
+// omit the mount from the mount table.
+func (fs *FS) skipMount(i Info) bool {
+	return fs.SkipAutofs && i.Type == "autofs"
+}
+
+// filterMounts removes the mounts the FS is configured to omit from the
+// mount table
+func (fs *FS) filterMounts(mounts []Info) []Info {
+	if !fs.SkipAutofs && !fs.DedupeMounts {
+		return mounts
+	}
+	var (
+		filtered = make([]Info, 0, len(mounts))
+		seen     = map[string]struct{}{}
+	)
+	for _, m := range mounts {
+		if fs.skipMount(m) {
+			continue
+		}
+		if fs.DedupeMounts {
+			key := strings.Join([]string{
+				m.Device, m.Path, m.Type, m.Root,
+				strings.Join(m.Opts, ","),
+			}, "\x00")
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+		}
+		filtered = append(filtered, m)
+	}
+	return filtered
+}
+
+// getMountsWhere returns the mounted filesystems for which pred returns
+// true. The mount table is walked so that only the matching entries are
+// accumulated in memory.
+func (fs *FS) getMountsWhere(
+	ctx context.Context, pred func(Info) bool) ([]Info, error) {
+
+	var mounts []Info
+	err := fs.walkMounts(ctx, func(m Info) (bool, error) {
+		if pred == nil || pred(m) {
+			mounts = append(mounts, m)
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mounts, nil
+}
+
+// LooksLikeBindMount returns a flag indicating whether the mount appears
+// to be a bind mount of another entry in the provided mount table.
+//
+// It is not possible to reliably differentiate a bind mount from the
+// original mount once the bind mount is created, so this function is
+// best-effort: a mount is considered a bind mount if another mount in
+// the table has the same device ID (MajorMinor) and either a root that
+// is a parent of this mount's root, or the same root and an earlier
+// position in the table. Please note that a btrfs subvolume mounted
+// beneath another mounted subvolume of the same filesystem also meets
+// this criteria.
+func (i Info) LooksLikeBindMount(all []Info) bool {
+	if i.MajorMinor == "" {
+		return false
+	}
+
+	// Find the position of this mount in the table.
+	pos := len(all)
+	for j, o := range all {
+		if o.Path == i.Path && o.Root == i.Root &&
+			o.MajorMinor == i.MajorMinor {
+			pos = j
+			break
+		}
+	}
+
+	for j, o := range all {
+		if j == pos || o.MajorMinor != i.MajorMinor {
+			continue
+		}
+		if o.Root == i.Root {
+			if j < pos {
+				return true
+			}
+			continue
+		}
+		if isPathOrSubpath(i.Root, o.Root) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPathOrSubpath returns a flag indicating whether p is equal to or
+// beneath parent.
+func isPathOrSubpath(p, parent string) bool {
+	p, parent = path.Clean(p), path.Clean(parent)
+	if p == parent || parent == "/" {
+		return true
+	}
+	return strings.HasPrefix(p, parent+"/")
+}
+
+// splitMountOptsCSV splits a comma-separated list of mount options
+// provided by a caller, trimming the space around each option. A nil
+// slice is returned for an empty list.
+func splitMountOptsCSV(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	opts := splitMountOpts(s)
+	for i := range opts {
+		opts[i] = strings.TrimSpace(opts[i])
+	}
+	return opts
+}
+
+// splitMountOpts splits a comma-separated list of mount options. Commas
+// that appear inside of a double-quoted value, such as an SELinux
+// context, do not split the option.
+func splitMountOpts(s string) []string {
+	var (
+		opts   []string
+		quoted bool
+		start  int
+	)
+	for i, c := range s {
+		switch c {
+		case '"':
+			quoted = !quoted
+		case ',':
+			if !quoted {
+				opts = append(opts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(opts, s[start:])
+}
+
+// AlreadyMountedError is returned when a mount fails because a different
+// filesystem is already mounted at the target. The error wraps
+// ErrAlreadyMounted.
+type AlreadyMountedError struct {
+	// Target is the path at which the filesystem is mounted.
+	Target string
+
+	// Source is the source of the mounted filesystem.
+	Source string
+
+	// Type is the type of the mounted filesystem.
+	Type string
+
+	// Err is the error returned by the mount, if any.
+	Err error
+}
+
+// Error returns the error message.
+func (e *AlreadyMountedError) Error() string {
+	msg := fmt.Sprintf("mount %s: %v: source=%s, fsType=%s",
+		e.Target, ErrAlreadyMounted, e.Source, e.Type)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+// Is returns a flag indicating whether target is ErrAlreadyMounted.
+func (e *AlreadyMountedError) Is(target error) bool {
+	return target == ErrAlreadyMounted
+}
+
+// Unwrap returns the error returned by the mount.
+func (e *AlreadyMountedError) Unwrap() error {
+	return e.Err
+}
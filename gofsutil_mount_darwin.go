@@ -4,45 +4,39 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/xml"
 	"fmt"
-	"os/exec"
+	"io"
+	"os"
 	"regexp"
 	"strings"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
 )
 
 var (
 	bindRemountOpts = []string{}
-	mountRX         = regexp.MustCompile(`^(.+) on (.+) \((.+)\)$`)
-)
-
-// getDiskFormat uses 'lsblk' to see if the given disk is unformated
-func (fs *FS) getDiskFormat(ctx context.Context, disk string) (string, error) {
-
-	mps, err := fs.getMounts(ctx)
-	if err != nil {
-		return "", err
-	}
-	for _, i := range mps {
-		if i.Device == disk {
-			return i.Type, nil
-		}
-	}
-	return "", fmt.Errorf("getDiskFormat: failed: %s", disk)
-}
 
-// formatAndMount uses unix utils to format and mount the given disk
-func (fs *FS) formatAndMount(
-	ctx context.Context,
-	source, target, fsType string,
-	opts ...string) error {
+	// lazyUnmountArgs is nil since Darwin does not support lazy unmounts.
+	lazyUnmountArgs []string
+	mountRX         = regexp.MustCompile(`^(.+) on (.+) \((.+)\)$`)
 
-	return ErrNotImplemented
-}
+	// fuseUnmountCmds and nfsUnmountCmds are empty since Darwin unmounts
+	// all filesystems with umount.
+	fuseUnmountCmds [][]string
+	nfsUnmountCmds  map[string][]string
+)
 
 // getMounts returns a slice of all the mounted filesystems
 func (fs *FS) getMounts(ctx context.Context) ([]Info, error) {
 
-	out, err := exec.Command("mount").CombinedOutput()
+	if mounts, ok := mountTableFromContext(ctx); ok {
+		return fs.filterMounts(mounts), nil
+	}
+
+	out, err := fs.exec(ctx, "mount")
 	if err != nil {
 		return nil, err
 	}
@@ -80,14 +74,195 @@ func (fs *FS) getMounts(ctx context.Context) ([]Info, error) {
 		mountInfos = append(mountInfos, Info{
 			Device: device,
 			Path:   path,
+			Root:   "/",
 			Source: source,
 			Type:   fsType,
 			Opts:   options,
 		})
 	}
+	return fs.filterMounts(mountInfos), nil
+}
+
+// getDevMounts returns a slice of all mounts for dev. The device may be
+// a disk identifier, ex. "disk2", or a device node, ex. "/dev/disk2" or
+// "/dev/rdisk2". The mounts of a disk include those of its slices, ex.
+// "/dev/disk2s1", and those of the APFS volumes in containers whose
+// physical store is on the disk, since an APFS container is synthesized
+// as a separate disk, ex. "/dev/disk3".
+func (fs *FS) getDevMounts(ctx context.Context, dev string) ([]Info, error) {
+
+	allMnts, err := fs.getMounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		devID      = darwinDiskID(dev)
+		mountInfos []Info
+		stores     = map[string][]string{}
+	)
+	for _, m := range allMnts {
+		// The source of a FUSE filesystem may be a path, ex. the cipher
+		// directory of gocryptfs, but is never the device.
+		if isFuseNonBlockMount(m) {
+			continue
+		}
+		id := darwinDiskID(m.Device)
+		if isDarwinDiskOrSlice(id, devID) {
+			mountInfos = append(mountInfos, m)
+			continue
+		}
+
+		// Only an APFS volume with a disk identifier has a container,
+		// unlike ex. a Time Machine snapshot whose source is
+		// "com.apple.TimeMachine.<date>.local@/dev/disk3s5".
+		if m.Type != "apfs" || !strings.HasPrefix(m.Device, "/dev/") ||
+			!darwinWholeDiskRX.MatchString(id) {
+			continue
+		}
+
+		// The physical stores are the same for all of the volumes in an
+		// APFS container, so they are only looked up once per container.
+		// A container whose stores cannot be read is skipped rather than
+		// failing the lookup of the mounts of unrelated disks.
+		container := darwinWholeDisk(id)
+		physStores, ok := stores[container]
+		if !ok {
+			physStores, err = fs.getAPFSPhysicalStores(ctx, id)
+			if err != nil {
+				log.WithFields(log.Fields{
+					"device":    m.Device,
+					"container": container,
+				}).WithError(err).Warn(
+					"failed to get apfs physical stores, skipping container")
+			}
+			stores[container] = physStores
+		}
+		for _, ps := range physStores {
+			if isDarwinDiskOrSlice(ps, devID) {
+				mountInfos = append(mountInfos, m)
+				break
+			}
+		}
+	}
+
 	return mountInfos, nil
 }
 
+// darwinWholeDiskRX matches the whole disk identifier at the start of a
+// disk or slice identifier.
+var darwinWholeDiskRX = regexp.MustCompile(`^disk\d+`)
+
+// darwinDiskID returns the disk identifier of the provided device node,
+// ex. "disk2s1" for "/dev/disk2s1" or "/dev/rdisk2s1".
+func darwinDiskID(dev string) string {
+	id := strings.TrimPrefix(dev, "/dev/")
+	if strings.HasPrefix(id, "rdisk") {
+		id = id[1:]
+	}
+	return id
+}
+
+// darwinWholeDisk returns the identifier of the whole disk that contains
+// the provided disk or slice, ex. "disk2" for "disk2s1". The identifier
+// is returned unchanged if it is not a disk identifier.
+func darwinWholeDisk(id string) string {
+	if d := darwinWholeDiskRX.FindString(id); d != "" {
+		return d
+	}
+	return id
+}
+
+// isDarwinDiskOrSlice returns a flag indicating whether the identifier
+// id is devID or a slice of devID, ex. "disk2s1" and "disk2s1s1" are
+// slices of "disk2".
+func isDarwinDiskOrSlice(id, devID string) bool {
+	return id == devID || strings.HasPrefix(id, devID+"s")
+}
+
+// getAPFSPhysicalStores returns the identifiers of the physical stores
+// of the APFS container of the provided volume.
+func (fs *FS) getAPFSPhysicalStores(
+	ctx context.Context, volume string) ([]string, error) {
+
+	args := []string{"info", "-plist", volume}
+	buf, err := fs.exec(ctx, "diskutil", args...)
+	if err != nil {
+		out := string(buf)
+		return nil, fmt.Errorf(
+			"diskutil failed: %w\narguments: %v\noutput: %s",
+			wrapCmdError(err, out, diskutilErrors), args, out)
+	}
+	return parseAPFSPhysicalStores(bytes.NewReader(buf))
+}
+
+// parseAPFSPhysicalStores returns the values of the APFSPhysicalStore
+// keys in the property list emitted by 'diskutil info -plist'. Unlike
+// the top-level keys parsed by parseDiskutilInfo, these keys are in the
+// dictionaries of the APFSPhysicalStores array.
+func parseAPFSPhysicalStores(r io.Reader) ([]string, error) {
+	var (
+		dec    = xml.NewDecoder(r)
+		stores []string
+		elem   string
+		next   bool
+	)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return stores, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid diskutil output: %v", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			elem = t.Name.Local
+		case xml.EndElement:
+			elem = ""
+		case xml.CharData:
+			switch elem {
+			case "key":
+				next = string(t) == "APFSPhysicalStore"
+			case "string":
+				if next {
+					stores = append(stores, string(t))
+					next = false
+				}
+			}
+		}
+	}
+}
+
+// walkMounts invokes fn for each mounted filesystem
+func (fs *FS) walkMounts(
+	ctx context.Context, fn func(Info) (bool, error)) error {
+
+	mounts, err := fs.getMounts(ctx)
+	if err != nil {
+		return err
+	}
+	for _, m := range mounts {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		stop, err := fn(m)
+		if err != nil {
+			return err
+		}
+		if stop {
+			return nil
+		}
+	}
+	return nil
+}
+
+// getMountsForPID returns a slice of all the filesystems mounted in the
+// mount namespace of the process with the provided PID
+func (fs *FS) getMountsForPID(ctx context.Context, pid int) ([]Info, error) {
+	return nil, ErrNotImplemented
+}
+
 // bindMount performs a bind mount
 func (fs *FS) bindMount(
 	ctx context.Context,
@@ -95,3 +270,18 @@ func (fs *FS) bindMount(
 
 	return fs.doMount(ctx, "bindfs", source, target, "", opts...)
 }
+
+// statChangeTime returns the time at which the status of the file
+// described by fi last changed
+func statChangeTime(fi os.FileInfo) time.Time {
+	if st, ok := fi.Sys().(*syscall.Stat_t); ok {
+		return time.Unix(st.Ctimespec.Sec, st.Ctimespec.Nsec)
+	}
+	return fi.ModTime()
+}
+
+// isDeviceReadOnly returns a flag indicating whether the block device is
+// write-protected
+func (fs *FS) isDeviceReadOnly(ctx context.Context, device string) (bool, error) {
+	return false, nil
+}
@@ -1,17 +1,23 @@
 package gofsutil
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
-	"os/exec"
+	"path"
+	"regexp"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 )
 
 const (
-	procMountsPath = "/proc/self/mountinfo"
 	// procMountsRetries is number of times to retry for a consistent
 	// read of procMountsPath.
 	procMountsRetries = 3
@@ -19,113 +25,533 @@ const (
 
 var (
 	bindRemountOpts = []string{"remount"}
+
+	// lazyUnmountArgs are the arguments to umount for a lazy unmount.
+	lazyUnmountArgs = []string{"-l"}
+
+	// fuseUnmountCmds are the commands tried in order to unmount a FUSE
+	// filesystem before falling back to umount.
+	fuseUnmountCmds = [][]string{
+		{"fusermount3", "-u"},
+		{"fusermount", "-u"},
+	}
+
+	// nfsUnmountCmds are the umount helpers of the NFS filesystem types.
+	nfsUnmountCmds = map[string][]string{
+		"nfs":  {"umount.nfs"},
+		"nfs4": {"umount.nfs4"},
+	}
+
+	// defaultDiskFormatTools are the tools used to determine a disk's
+	// format when FS.DiskFormatTools is empty.
+	defaultDiskFormatTools = []string{"lsblk", "blkid"}
+
+	// errLsblkModeUnsupported is returned when lsblk does not support
+	// an output mode.
+	errLsblkModeUnsupported = errors.New("lsblk mode unsupported")
+
+	// lsblkInvalidOptionRX matches the output of an lsblk command that
+	// does not support one of its options.
+	lsblkInvalidOptionRX = regexp.MustCompile(
+		`(?i)(invalid|unrecognized|unknown) option`)
+
+	// lsblkErrors maps the output of a failed lsblk command to the
+	// error that describes the failure.
+	lsblkErrors = []cmdError{
+		{regexp.MustCompile(`(?i)not a block device`), ErrDeviceNotFound},
+		{regexp.MustCompile(`(?i)no such file or directory`),
+			ErrDeviceNotFound},
+	}
 )
 
-// getDiskFormat uses 'lsblk' to see if the given disk is unformatted
+// getDiskFormat uses the first of the FS's disk format tools that is
+// installed to see if the given disk is unformatted
 func (fs *FS) getDiskFormat(ctx context.Context, disk string) (string, error) {
+	tools := fs.DiskFormatTools
+	if len(tools) == 0 {
+		tools = defaultDiskFormatTools
+	}
+
+	var err error
+	for _, tool := range tools {
+		var fsType string
+		switch tool {
+		case "lsblk":
+			fsType, err = fs.getDiskFormatLsblk(ctx, disk)
+		case "blkid":
+			fsType, err = fs.getDiskFormatBlkid(ctx, disk)
+		default:
+			return "", fmt.Errorf("invalid disk format tool: %s", tool)
+		}
+		if err == nil || !isCommandNotFound(err) {
+			// A btrfs member device that has not been probed by the
+			// tool appears unformatted, but the kernel knows better.
+			if err == nil && fsType == "" && fs.isBtrfsMemberDevice(disk) {
+				return "btrfs", nil
+			}
+			return fsType, err
+		}
+		log.WithFields(logFields(ctx, log.Fields{
+			"tool": tool,
+		})).Warn("disk format tool not found, trying next tool")
+	}
+	return "", err
+}
 
-	args := []string{"-n", "-o", "FSTYPE", disk}
+// getDiskFormatLsblk uses 'lsblk' to see if the given disk is unformatted.
+// The output is requested in the FS's lsblk mode, or as JSON and then as
+// pairs if the mode is empty and lsblk does not support JSON output.
+func (fs *FS) getDiskFormatLsblk(
+	ctx context.Context, disk string) (string, error) {
 
-	f := log.Fields{
-		"disk": disk,
+	switch fs.LsblkMode {
+	case LsblkModeJSON, LsblkModePairs:
+		return fs.getDiskFormatLsblkMode(ctx, disk, fs.LsblkMode)
+	case "":
+	default:
+		return "", fmt.Errorf("invalid lsblk mode: %q", fs.LsblkMode)
 	}
+
+	fsType, err := fs.getDiskFormatLsblkMode(ctx, disk, LsblkModeJSON)
+	if errors.Is(err, errLsblkModeUnsupported) {
+		log.WithFields(logFields(ctx, log.Fields{
+			"disk": disk,
+		})).WithError(err).Warn(
+			"lsblk json output unsupported, trying pairs output")
+		return fs.getDiskFormatLsblkMode(ctx, disk, LsblkModePairs)
+	}
+	return fsType, err
+}
+
+// getDiskFormatLsblkMode uses 'lsblk' with output in the provided mode to
+// see if the given disk is unformatted
+func (fs *FS) getDiskFormatLsblkMode(
+	ctx context.Context, disk string, mode LsblkMode) (string, error) {
+
+	var args []string
+	if mode == LsblkModeJSON {
+		args = []string{"-J", "-o", "NAME,FSTYPE", disk}
+	} else {
+		args = []string{"-P", "-o", "NAME,FSTYPE", disk}
+	}
+
+	f := logFields(ctx, log.Fields{
+		"disk":      disk,
+		"lsblkMode": mode,
+	})
 	log.WithFields(f).WithField("args", args).Info(
 		"checking if disk is formatted using lsblk")
-	buf, err := exec.Command("lsblk", args...).CombinedOutput()
+	buf, err := fs.exec(ctx, "lsblk", args...)
 	out := string(buf)
-	log.WithField("output", out).Debug("lsblk output")
+	log.WithFields(f).WithField("output", out).Debug("lsblk output")
 
 	if err != nil {
+		if mode == LsblkModeJSON && lsblkInvalidOptionRX.MatchString(out) {
+			return "", fmt.Errorf("getDiskFormat: %w: %s",
+				errLsblkModeUnsupported, strings.TrimSpace(out))
+		}
 		log.WithFields(f).WithError(err).Error(
 			"failed to determine if disk is formatted")
+
+		// The output of nsenter for a missing lsblk also matches
+		// the output of lsblk for a missing device.
+		if isCommandNotFound(err) {
+			return "", err
+		}
+		if e := wrapCmdError(err, out, lsblkErrors); e != err {
+			return "", fmt.Errorf(
+				"getDiskFormat: lsblkMode=%s: %w: %s", mode, e, disk)
+		}
 		return "", err
 	}
 
-	// Split lsblk output into lines. Unformatted devices should contain only
-	// "\n". Beware of "\n\n", that's a device with one empty partition.
-	out = strings.TrimSuffix(out, "\n") // Avoid last empty line
-	lines := strings.Split(out, "\n")
-	if lines[0] != "" {
-		// The device is formatted
-		return lines[0], nil
+	// Like an unformatted device without dependent devices, an empty
+	// output has no filesystem type.
+	if strings.TrimSpace(out) == "" {
+		return "", nil
 	}
 
-	if len(lines) == 1 {
+	var fsTypes []string
+	if mode == LsblkModeJSON {
+		if fsTypes, err = parseLsblkJSONFSTypes(buf); err != nil {
+			return "", fmt.Errorf(
+				"getDiskFormat: lsblkMode=%s: %w: %v",
+				mode, errLsblkModeUnsupported, err)
+		}
+	} else {
+		for _, dev := range parseLsblkPairs(buf) {
+			fsTypes = append(fsTypes, dev["FSTYPE"])
+		}
+	}
+
+	// The disk is listed first, followed by its dependent devices.
+	switch {
+	case len(fsTypes) == 0:
+		return "", fmt.Errorf(
+			"getDiskFormat: lsblkMode=%s: disk not listed: %s", mode, disk)
+	case fsTypes[0] != "":
+		// The device is formatted
+		return fsTypes[0], nil
+	case len(fsTypes) == 1:
 		// The device is unformatted and has no dependent devices
 		return "", nil
 	}
 
 	// The device has dependent devices, most probably partitions (LVM, LUKS
 	// and MD RAID are reported as FSTYPE and caught above).
-	return "unknown data, probably partitions", nil
+	return diskFormatPartitions, nil
 }
 
-// formatAndMount uses unix utils to format and mount the given disk
+// blkidExitNoSignature is the exit status of blkid when the device does
+// not contain a recognized signature.
+const blkidExitNoSignature = 2
+
+// getDiskFormatBlkid uses 'blkid' to see if the given disk is unformatted.
+// The result is the same as that of getDiskFormatLsblk.
+func (fs *FS) getDiskFormatBlkid(
+	ctx context.Context, disk string) (string, error) {
+
+	args := []string{"-p", "-o", "export", disk}
+
+	f := logFields(ctx, log.Fields{
+		"disk": disk,
+	})
+	log.WithFields(f).WithField("args", args).Info(
+		"checking if disk is formatted using blkid")
+	buf, err := fs.exec(ctx, "blkid", args...)
+	out := string(buf)
+	log.WithFields(f).WithField("output", out).Debug("blkid output")
+
+	if err != nil {
+		// blkid exits with status 2 when the device does not contain a
+		// filesystem or partition table. Any other failure, ex. an I/O
+		// error, says nothing about whether the device is formatted.
+		if exitCode(err) == blkidExitNoSignature {
+			return "", nil
+		}
+		log.WithFields(f).WithError(err).Error(
+			"failed to determine if disk is formatted")
+		if e := wrapCmdError(err, out, lsblkErrors); e != err {
+			return "", fmt.Errorf("getDiskFormat: %w: %s", e, disk)
+		}
+		return "", err
+	}
+
+	var ptType string
+	for _, line := range strings.Split(out, "\n") {
+		kv := strings.SplitN(strings.TrimSpace(line), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "TYPE":
+			return kv[1], nil
+		case "PTTYPE":
+			ptType = kv[1]
+		}
+	}
+	if ptType != "" {
+		return diskFormatPartitions, nil
+	}
+	return "", nil
+}
+
+// formatAndMount uses unix utils to format and mount the given disk and
+// returns a flag indicating whether the disk was formatted
 func (fs *FS) formatAndMount(
 	ctx context.Context,
 	source, target, fsType string,
-	opts ...string) error {
+	formatOpts FormatOptions,
+	opts ...string) (bool, error) {
+
+	// The options are checked by mount, but also before the device may
+	// be formatted.
+	if err := fs.checkOptions(fsType, opts); err != nil {
+		return false, err
+	}
+	if p := formatOpts.ReservedBlocksPercent; p != nil {
+		if err := validateReservedBlocksPercent(*p); err != nil {
+			return false, err
+		}
+		if !isExtFS(fsType) && len(fsType) > 0 {
+			return false, fmt.Errorf(
+				"reserved blocks percent not supported: fsType=%s", fsType)
+		}
+	}
+
+	if formatOpts.Label != "" {
+		labelFSType := fsType
+		if labelFSType == "" {
+			labelFSType = "ext4"
+		}
+		if err := ValidateFSLabel(labelFSType, formatOpts.Label); err != nil {
+			return false, err
+		}
+	}
+
+	if formatOpts.ProjectQuota {
+		if len(fsType) > 0 && fsType != "xfs" && !isExtFS(fsType) {
+			return false, fmt.Errorf(
+				"project quotas not supported: fsType=%s", fsType)
+		}
+		opts = append(opts, "prjquota")
+	}
 
 	opts = append(opts, "defaults")
-	f := log.Fields{
+	opts, readOnly, err := fs.autoReadOnly(ctx, source, opts)
+	if err != nil {
+		return false, err
+	}
+	f := logFields(ctx, log.Fields{
 		"source":  source,
 		"target":  target,
 		"fsType":  fsType,
 		"options": opts,
-	}
+	})
 
 	// Try to mount the disk
 	log.WithFields(f).Info("attempting to mount disk")
 	mountErr := fs.mount(ctx, source, target, fsType, opts...)
 	if mountErr == nil {
-		return nil
+		return false, fs.verifyMounted(ctx, target)
+	}
+
+	// A disk is never formatted when the target is in use or the mount
+	// was aborted by the PreMountHook.
+	if errors.Is(mountErr, ErrAlreadyMounted) || isMountVetoed(mountErr) {
+		return false, mountErr
+	}
+
+	// A write-protected device cannot be formatted.
+	if readOnly {
+		return false, mountErr
 	}
 
 	// Mount failed. This indicates either that the disk is unformatted or
 	// it contains an unexpected filesystem.
 	existingFormat, err := fs.getDiskFormat(ctx, source)
 	if err != nil {
-		return err
+		return false, err
 	}
 	if existingFormat == "" {
 		// Disk is unformatted so format it.
-		args := []string{source}
 		// Use 'ext4' as the default
 		if len(fsType) == 0 {
 			fsType = "ext4"
 		}
-
-		if fsType == "ext4" || fsType == "ext3" {
-			args = []string{"-F", source}
-		}
 		f["fsType"] = fsType
 		log.WithFields(f).Info(
 			"disk appears unformatted, attempting format")
 
-		mkfsCmd := fmt.Sprintf("mkfs.%s", fsType)
-		if err := exec.Command(mkfsCmd, args...).Run(); err != nil {
-			log.WithFields(f).WithError(err).Error(
-				"format of disk failed")
+		if fs.ExclusiveFormat {
+			if err := openExclusive(source); err != nil {
+				log.WithFields(f).WithError(err).Error(
+					"disk is in use, refusing to format")
+				return false, err
+			}
+		}
+
+		if formatOpts.AutoNoDiscard {
+			ok, err := fs.supportsDiscard(ctx, source)
+			if err != nil {
+				return false, err
+			}
+			if ok {
+				log.WithFields(f).Info(
+					"disk supports discard, formatting without discard")
+				formatOpts.MkfsOptions = makeNoDiscardArgs(
+					fsType, fs.MkfsDefaults[fsType], formatOpts.MkfsOptions)
+			}
+		}
+
+		if fn := fs.getFormatter(fsType); fn != nil {
+			if err := fs.runFormatter(
+				ctx, fn, fsType, source, formatOpts.MkfsOptions,
+				formatOpts.Progress, f); err != nil {
+				return false, err
+			}
+		} else if err := fs.mkfs(
+			ctx, fsType, fs.makeMkfsArgs(fsType, source, formatOpts),
+			formatOpts.Progress, f); err != nil {
+			return false, err
 		}
 
 		// the disk has been formatted successfully try to mount it again.
 		log.WithFields(f).Info(
 			"disk successfully formatted")
-		return fs.mount(ctx, source, target, fsType, opts...)
+
+		if p := formatOpts.ReservedBlocksPercent; p != nil {
+			if err := fs.setReservedBlocksPercent(ctx, source, *p); err != nil {
+				return true, err
+			}
+		}
+		if formatOpts.ProjectQuota && isExtFS(fsType) {
+			if err := fs.enableProjectQuota(ctx, source, fsType); err != nil {
+				return true, err
+			}
+		}
+
+		if err := fs.mount(ctx, source, target, fsType, opts...); err != nil {
+			return true, err
+		}
+		return true, fs.verifyMounted(ctx, target)
 	}
 
 	// Disk is already formatted and failed to mount
 	if len(fsType) == 0 || fsType == existingFormat {
 		// This is mount error
-		return mountErr
+		return false, mountErr
+	}
+
+	// Block device is formatted with a different member of the ext
+	// filesystem family. The disk is never reformatted, but the exact
+	// subtype and the reason it cannot be mounted are surfaced.
+	if isExtFS(fsType) && isExtFS(existingFormat) {
+		return false, fs.extMismatchError(
+			ctx, source, fsType, existingFormat, mountErr)
 	}
 
 	// Block device is formatted with unexpected filesystem
-	return fmt.Errorf(
+	return false, fmt.Errorf(
 		"failed to mount volume as %q; already contains %s: error: %v",
 		fsType, existingFormat, mountErr)
 }
 
+// makeMkfsArgs returns the arguments used to format source with the
+// mkfs command for fsType. The FS's defaults for fsType precede the
+// per-call options so the latter take precedence.
+func (fs *FS) makeMkfsArgs(
+	fsType, source string, formatOpts FormatOptions) []string {
+
+	var args []string
+
+	// The ext family's mkfs commands prompt for confirmation before
+	// formatting a whole disk unless forced.
+	if isExtFS(fsType) {
+		args = append(args, "-F")
+	}
+	args = append(args, fs.MkfsDefaults[fsType]...)
+	args = append(args, makeMkfsLabelArgs(fsType, formatOpts.Label)...)
+	args = append(args, formatOpts.MkfsOptions...)
+	return append(args, source)
+}
+
+// mkfs formats a disk as fsType with the provided mkfs arguments. The
+// command's output is streamed to progress if it is not nil.
+func (fs *FS) mkfs(
+	ctx context.Context,
+	fsType string,
+	args []string,
+	progress func(line string),
+	f log.Fields) error {
+
+	mkfsCmd := fmt.Sprintf("mkfs.%s", fsType)
+	buf, err := fs.execProgress(ctx, progress, mkfsCmd, args...)
+	if err != nil {
+		out := string(buf)
+		log.WithFields(f).WithField("output", out).WithError(
+			err).Error("format of disk failed")
+		return fmt.Errorf(
+			"format failed: %v\nformat command: %s\noutput: %s",
+			err, mkfsCmd, out)
+	}
+	return nil
+}
+
+// runFormatter formats a disk as fsType with the formatter registered
+// for fsType. The formatter's commands stream their output to progress if
+// it is not nil.
+func (fs *FS) runFormatter(
+	ctx context.Context,
+	fn FormatterFunc,
+	fsType, device string,
+	mkfsOpts []string,
+	progress func(line string),
+	f log.Fields) error {
+
+	var opts []string
+	opts = append(opts, fs.MkfsDefaults[fsType]...)
+	opts = append(opts, mkfsOpts...)
+
+	exec := func(
+		ctx context.Context, name string, args ...string) ([]byte, error) {
+
+		return fs.execProgress(ctx, progress, name, args...)
+	}
+	log.WithFields(f).WithField("mkfsOptions", opts).Info(
+		"formatting disk with registered formatter")
+	if err := fn(ctx, exec, device, opts); err != nil {
+		log.WithFields(f).WithError(err).Error("format of disk failed")
+		return fmt.Errorf("format failed: fsType=%s: %w", fsType, err)
+	}
+	return nil
+}
+
+// formatDevice formats the given disk without mounting it
+func (fs *FS) formatDevice(
+	ctx context.Context,
+	device, fsType string,
+	force bool,
+	mkfsOpts ...string) error {
+
+	if len(fsType) == 0 {
+		fsType = "ext4"
+	}
+	f := logFields(ctx, log.Fields{
+		"device": device,
+		"fsType": fsType,
+		"force":  force,
+	})
+
+	existingFormat, err := fs.getDiskFormat(ctx, device)
+	if err != nil {
+		return err
+	}
+	if existingFormat != "" && !force {
+		if existingFormat == fsType {
+			log.WithFields(f).Info("disk already formatted")
+			return nil
+		}
+		return fmt.Errorf(
+			"failed to format volume as %q; already contains %s",
+			fsType, existingFormat)
+	}
+
+	if fs.ExclusiveFormat {
+		if err := openExclusive(device); err != nil {
+			log.WithFields(f).WithError(err).Error(
+				"disk is in use, refusing to format")
+			return err
+		}
+	}
+
+	if fn := fs.getFormatter(fsType); fn != nil {
+		log.WithFields(f).Info("attempting format")
+		if err := fs.runFormatter(
+			ctx, fn, fsType, device, mkfsOpts, nil, f); err != nil {
+			return err
+		}
+		log.WithFields(f).Info("disk successfully formatted")
+		return nil
+	}
+
+	args := fs.makeMkfsArgs(
+		fsType, device, FormatOptions{MkfsOptions: mkfsOpts})
+
+	// Unlike the ext family, the xfs and btrfs mkfs commands refuse to
+	// overwrite an existing filesystem unless forced.
+	if existingFormat != "" && (fsType == "xfs" || fsType == "btrfs") {
+		args = append([]string{"-f"}, args...)
+	}
+
+	log.WithFields(f).Info("attempting format")
+	if err := fs.mkfs(ctx, fsType, args, nil, f); err != nil {
+		return err
+	}
+	log.WithFields(f).Info("disk successfully formatted")
+	return nil
+}
+
 // bindMount performs a bind mount
 func (fs *FS) bindMount(
 	ctx context.Context,
@@ -139,8 +565,56 @@ func (fs *FS) bindMount(
 	return fs.doMount(ctx, "mount", source, target, "", opts...)
 }
 
+// getDevMounts returns a slice of all mounts for dev. Symlinks in both
+// dev and the devices in the mount table are evaluated, so the mounts of
+// a device are found by any of its names, ex. "/dev/dm-0" matches the
+// mount of "/dev/mapper/vg-lv".
+func (fs *FS) getDevMounts(ctx context.Context, dev string) ([]Info, error) {
+
+	allMnts, err := fs.getMounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The source of a FUSE filesystem may be a path, ex. the cipher
+	// directory of gocryptfs, but is never the device.
+	realDev := fs.resolveDevice(ctx, Info{Device: dev})
+	var mountInfos []Info
+	for _, m := range allMnts {
+		if isFuseNonBlockMount(m) {
+			continue
+		}
+		if m.Device == dev || m.Device == realDev ||
+			(path.IsAbs(m.Device) && fs.resolveDevice(ctx, m) == realDev) {
+			mountInfos = append(mountInfos, m)
+		}
+	}
+
+	return mountInfos, nil
+}
+
 // getMounts returns a slice of all the mounted filesystems
 func (fs *FS) getMounts(ctx context.Context) ([]Info, error) {
+	if mounts, ok := mountTableFromContext(ctx); ok {
+		return fs.filterMounts(mounts), nil
+	}
+	return fs.getMountsFrom(ctx, fs.procPath("self", "mountinfo"))
+}
+
+// getMountsForPID returns a slice of all the filesystems mounted in the
+// mount namespace of the process with the provided PID
+func (fs *FS) getMountsForPID(ctx context.Context, pid int) ([]Info, error) {
+	if pid <= 0 {
+		return nil, fmt.Errorf("getMountsForPID: invalid pid: %d", pid)
+	}
+	return fs.getMountsFrom(
+		ctx, fs.procPath(strconv.Itoa(pid), "mountinfo"))
+}
+
+// getMountsFrom returns a slice of all the mounted filesystems read
+// from the provided mount table file
+func (fs *FS) getMountsFrom(
+	ctx context.Context, procMountsPath string) ([]Info, error) {
 
 	_, hash1, err := fs.readProcMounts(ctx, procMountsPath, false)
 	if err != nil {
@@ -154,7 +628,7 @@ func (fs *FS) getMounts(ctx context.Context) ([]Info, error) {
 		}
 		if hash1 == hash2 {
 			// Success
-			return mps, nil
+			return fs.filterMounts(mps), nil
 		}
 		hash1 = hash2
 	}
@@ -163,6 +637,37 @@ func (fs *FS) getMounts(ctx context.Context) ([]Info, error) {
 		procMountsPath, procMountsRetries)
 }
 
+// walkMounts invokes fn for each mounted filesystem
+func (fs *FS) walkMounts(
+	ctx context.Context, fn func(Info) (bool, error)) error {
+
+	if mounts, ok := mountTableFromContext(ctx); ok {
+		for _, m := range fs.filterMounts(mounts) {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if stop, err := fn(m); err != nil || stop {
+				return err
+			}
+		}
+		return nil
+	}
+
+	buf, err := readFileContext(ctx, fs.procPath("self", "mountinfo"))
+	if err != nil {
+		return err
+	}
+
+	return walkProcMountsFrom(
+		ctx, bytes.NewReader(buf), ProcMountsFields, fs.scanEntry(ctx),
+		func(line string, info Info) (bool, error) {
+			if fs.skipMount(info) {
+				return false, nil
+			}
+			return fn(info)
+		})
+}
+
 // readProcMounts reads procMountsInfo and produce a hash
 // of the contents and a list of the mounts as Info objects.
 func (fs *FS) readProcMounts(
@@ -170,11 +675,134 @@ func (fs *FS) readProcMounts(
 	path string,
 	info bool) ([]Info, uint32, error) {
 
-	file, err := os.Open(path)
+	buf, err := readFileContext(ctx, path)
 	if err != nil {
 		return nil, 0, err
 	}
-	defer file.Close()
 
-	return ReadProcMountsFrom(ctx, file, !info, ProcMountsFields, fs.ScanEntry)
+	return ReadProcMountsFrom(
+		ctx, bytes.NewReader(buf), !info, ProcMountsFields, fs.scanEntry(ctx))
+}
+
+// readFileContext reads the file at path in a goroutine so the read may
+// be abandoned when the context is cancelled. Reading the mount table
+// may block, ex. on a wedged FUSE filesystem, and a blocked read cannot
+// be interrupted, so the goroutine is left to finish in the background.
+func readFileContext(ctx context.Context, path string) ([]byte, error) {
+	type result struct {
+		buf []byte
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		buf, err := ioutil.ReadFile(path)
+		done <- result{buf, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.buf, r.err
+	case <-ctx.Done():
+		log.WithFields(logFields(ctx, log.Fields{
+			"path": path,
+		})).WithError(ctx.Err()).Error(
+			"read of mount table abandoned")
+		return nil, fmt.Errorf("read %s: %w", path, ctx.Err())
+	}
+}
+
+// procPath returns the path of the provided elements relative to the
+// root of the proc filesystem
+func (fs *FS) procPath(elem ...string) string {
+	procRoot := fs.ProcRoot
+	if procRoot == "" {
+		procRoot = defaultProcRoot
+	}
+	return path.Join(append([]string{procRoot}, elem...)...)
+}
+
+// devPath returns the path of the provided elements relative to the
+// root of the dev filesystem
+func (fs *FS) devPath(elem ...string) string {
+	devRoot := fs.DevRoot
+	if devRoot == "" {
+		devRoot = defaultDevRoot
+	}
+	return path.Join(append([]string{devRoot}, elem...)...)
+}
+
+// hostDevPath returns the path of a device the kernel reports in /dev,
+// ex. the source of a mount, relative to DevRoot
+func (fs *FS) hostDevPath(p string) string {
+	if fs.DevRoot == "" || !strings.HasPrefix(p, defaultDevRoot+"/") {
+		return p
+	}
+	return fs.devPath(strings.TrimPrefix(p, defaultDevRoot+"/"))
+}
+
+// openExclusive returns an error wrapping ErrDeviceBusy if the block
+// device cannot be opened exclusively because it is in use
+func openExclusive(device string) error {
+	f, err := os.OpenFile(device, os.O_RDONLY|syscall.O_EXCL, 0)
+	if err != nil {
+		if errors.Is(err, syscall.EBUSY) {
+			return fmt.Errorf("%s: %w", device, ErrDeviceBusy)
+		}
+		return err
+	}
+	return f.Close()
+}
+
+// isDeviceReadOnly returns a flag indicating whether the block device is
+// write-protected. A device that is not a block device, ex. the source of
+// an NFS mount, is not write-protected.
+func (fs *FS) isDeviceReadOnly(ctx context.Context, device string) (bool, error) {
+	name := path.Base(evalSymlinksOrPath(device))
+	buf, err := ioutil.ReadFile(fs.sysPath("class", "block", name, "ro"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return strings.TrimSpace(string(buf)) == "1", nil
+}
+
+// sysPath returns the path of the provided elements relative to the
+// root of the sys filesystem
+func (fs *FS) sysPath(elem ...string) string {
+	sysRoot := fs.SysRoot
+	if sysRoot == "" {
+		sysRoot = defaultSysRoot
+	}
+	return path.Join(append([]string{sysRoot}, elem...)...)
+}
+
+// etcPath returns the path of the provided elements relative to the
+// host configuration directory
+func (fs *FS) etcPath(elem ...string) string {
+	etcRoot := fs.EtcRoot
+	if etcRoot == "" {
+		etcRoot = defaultEtcRoot
+	}
+	return path.Join(append([]string{etcRoot}, elem...)...)
+}
+
+// modulesPath returns the path of the provided elements relative to the
+// kernel modules directory
+func (fs *FS) modulesPath(elem ...string) string {
+	modulesRoot := fs.ModulesRoot
+	if modulesRoot == "" {
+		modulesRoot = defaultModulesRoot
+	}
+	return path.Join(append([]string{modulesRoot}, elem...)...)
+}
+
+// statChangeTime returns the time at which the status of the file
+// described by fi last changed
+func statChangeTime(fi os.FileInfo) time.Time {
+	if st, ok := fi.Sys().(*syscall.Stat_t); ok {
+		return time.Unix(int64(st.Ctim.Sec), int64(st.Ctim.Nsec))
+	}
+	return fi.ModTime()
 }
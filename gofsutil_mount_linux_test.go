@@ -0,0 +1,1280 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+// newTestProcRoot creates a temporary proc filesystem root with a
+// mountinfo file for each of the provided PIDs.
+func newTestProcRoot(
+	t *testing.T, data string, pids ...string) string {
+
+	procRoot := t.TempDir()
+	for _, pid := range pids {
+		dir := path.Join(procRoot, pid)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(
+			path.Join(dir, "mountinfo"), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return procRoot
+}
+
+func TestGetMountsForPID(t *testing.T) {
+	procRoot := newTestProcRoot(t, procMountInfoData, "1234")
+
+	fs := &gofsutil.FS{ProcRoot: procRoot}
+	mounts, err := fs.GetMountsForPID(context.TODO(), 1234)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mounts) == 0 {
+		t.Fatal("no mounts parsed")
+	}
+	success := false
+	for _, m := range mounts {
+		if m.Path == "/home/akutz/red" && m.Device == "localhost:/home/akutz" {
+			success = true
+		}
+	}
+	if !success {
+		t.Errorf("unable to find nfs mount: %+v", mounts)
+	}
+}
+
+func TestGetMountsForPIDNotExist(t *testing.T) {
+	procRoot := newTestProcRoot(t, procMountInfoData, "1234")
+
+	fs := &gofsutil.FS{ProcRoot: procRoot}
+	_, err := fs.GetMountsForPID(context.TODO(), 4321)
+	if err == nil {
+		t.Fatal("expected error for non-existent pid")
+	}
+	if !os.IsNotExist(err) {
+		t.Errorf("expected not exist error: %v", err)
+	}
+}
+
+const btrfsMountInfoData = `60 1 253:0 / / rw,relatime shared:1 - xfs /dev/mapper/cl-root rw,seclabel,attr2,inode64,noquota
+80 60 0:45 /vol1 /mnt/vol1 rw,relatime shared:40 - btrfs /dev/sdb rw,space_cache,subvolid=256,subvol=/vol1
+81 60 0:45 /vol2 /mnt/vol2 rw,relatime shared:41 - btrfs /dev/sdb rw,space_cache,subvolid=257,subvol=/vol2
+82 60 0:45 /vol10 /mnt/vol10 rw,relatime shared:42 - btrfs /dev/sdb rw,space_cache,subvolid=258,subvol=/vol10
+83 80 0:45 /vol1/data /mnt/data rw,relatime shared:40 - btrfs /dev/sdb rw,space_cache,subvolid=256,subvol=/vol1
+`
+
+func TestGetDevMountsDevRoot(t *testing.T) {
+	devRoot := newTestDeviceAliases(t)
+
+	// The kernel reports the source in /dev on the host, while the
+	// caller provides a device in DevRoot, or in /dev.
+	ctx := gofsutil.WithMountTable(context.TODO(), []gofsutil.Info{
+		{Device: "/dev/sda1", Path: "/"},
+		{Device: "/dev/mapper/vg-lv", Path: "/mnt/data"},
+	})
+	fs := &gofsutil.FS{DevRoot: devRoot}
+	for _, dev := range []string{
+		"/dev/dm-0",
+		path.Join(devRoot, "dm-0"),
+		path.Join(devRoot, "disk", "by-id", "dm-name-vg-lv"),
+	} {
+		mounts, err := fs.GetDevMounts(ctx, dev)
+		if err != nil {
+			t.Fatalf("%s: %v", dev, err)
+		}
+		if len(mounts) != 1 || mounts[0].Path != "/mnt/data" {
+			t.Errorf("%s: invalid mounts: %+v", dev, mounts)
+		}
+	}
+}
+
+func TestGetDevMountsWithRoot(t *testing.T) {
+	procRoot := newTestProcRoot(t, btrfsMountInfoData, "self")
+
+	fs := &gofsutil.FS{ProcRoot: procRoot}
+
+	tests := []struct {
+		root  string
+		paths []string
+		roots []string
+	}{
+		{
+			root:  "/vol1",
+			paths: []string{"/mnt/vol1", "/mnt/data"},
+			roots: []string{"/vol1", "/vol1/data"},
+		},
+		{
+			root:  "/vol2",
+			paths: []string{"/mnt/vol2"},
+			roots: []string{"/vol2"},
+		},
+		{
+			root:  "/vol1/data",
+			paths: []string{"/mnt/data"},
+			roots: []string{"/vol1/data"},
+		},
+		{
+			root: "/vol3",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.root, func(st *testing.T) {
+			mounts, err := fs.GetDevMountsWithRoot(
+				context.TODO(), "/dev/sdb", tt.root)
+			if err != nil {
+				st.Fatal(err)
+			}
+			if len(mounts) != len(tt.paths) {
+				st.Fatalf("invalid mount count: exp=%d, act=%d: %+v",
+					len(tt.paths), len(mounts), mounts)
+			}
+			for i, m := range mounts {
+				if m.Path != tt.paths[i] {
+					st.Errorf("invalid path: exp=%s, act=%s",
+						tt.paths[i], m.Path)
+				}
+				if m.Root != tt.roots[i] {
+					st.Errorf("invalid root: exp=%s, act=%s",
+						tt.roots[i], m.Root)
+				}
+			}
+		})
+	}
+}
+
+// overmountMountInfoData is a mount table in which the ext4 filesystem
+// on /dev/sdb is mounted at /mnt.
+const overmountMountInfoData = `60 1 8:1 / / rw,relatime shared:1 - ext4 /dev/sda1 rw
+72 60 8:16 / /mnt rw,relatime shared:28 - ext4 /dev/sdb rw
+`
+
+func TestMountOvermount(t *testing.T) {
+	procRoot := newTestProcRoot(t, overmountMountInfoData, "self")
+	ctx := context.TODO()
+
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{ProcRoot: procRoot, RunCommand: r.run}
+	err := fs.Mount(ctx, "/dev/sdc", "/mnt", "ext4")
+	if !errors.Is(err, gofsutil.ErrAlreadyMounted) {
+		t.Fatalf("expected ErrAlreadyMounted: %v", err)
+	}
+	r.assertCommands(t)
+
+	// A remount is not an overmount.
+	if err := fs.Mount(ctx, "", "/mnt", "", "remount", "ro"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t, "mount -o remount,ro /mnt")
+}
+
+func TestMountOvermountTmpfs(t *testing.T) {
+	// The default ScanEntry skips the tmpfs entry, but the filesystem
+	// is still hidden by a mount at /mnt.
+	procRoot := newTestProcRoot(t,
+		`60 1 8:1 / / rw,relatime shared:1 - ext4 /dev/sda1 rw
+72 60 0:42 / /mnt rw,relatime shared:28 - tmpfs tmpfs rw
+`, "self")
+
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{ProcRoot: procRoot, RunCommand: r.run}
+	err := fs.Mount(context.TODO(), "/dev/sdb", "/mnt", "ext4")
+	var amErr *gofsutil.AlreadyMountedError
+	if !errors.As(err, &amErr) {
+		t.Fatalf("expected AlreadyMountedError: %v", err)
+	}
+	if amErr.Source != "tmpfs" || amErr.Type != "tmpfs" {
+		t.Errorf("invalid conflicting mount: %+v", amErr)
+	}
+	r.assertCommands(t)
+}
+
+func TestMountAlreadyMounted(t *testing.T) {
+	procRoot := newTestProcRoot(t, overmountMountInfoData, "self")
+	ctx := context.TODO()
+
+	// The mount is idempotent.
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{ProcRoot: procRoot, RunCommand: r.run}
+	if err := fs.Mount(ctx, "/dev/sdb", "/mnt", "ext4"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t)
+
+	err := fs.Mount(ctx, "/dev/sdc", "/mnt", "ext4")
+	var amErr *gofsutil.AlreadyMountedError
+	if !errors.As(err, &amErr) {
+		t.Fatalf("expected AlreadyMountedError: %v", err)
+	}
+	if !errors.Is(err, gofsutil.ErrAlreadyMounted) {
+		t.Errorf("expected ErrAlreadyMounted: %v", err)
+	}
+	if amErr.Source != "/dev/sdb" || amErr.Type != "ext4" {
+		t.Errorf("invalid conflicting mount: %+v", amErr)
+	}
+	r.assertCommands(t)
+}
+
+func TestMountAllowOvermountAlreadyMounted(t *testing.T) {
+	procRoot := newTestProcRoot(t, overmountMountInfoData, "self")
+	ctx := context.TODO()
+
+	// The kernel refuses to mount /dev/sdb at /mnt again.
+	r := &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			if args[len(args)-2] == "/dev/sdb" {
+				return "mount: /mnt: /dev/sdb already mounted " +
+					"or mount point busy.\n", errors.New("exit status 32")
+			}
+			return "", nil
+		},
+	}
+	fs := &gofsutil.FS{
+		ProcRoot:       procRoot,
+		RunCommand:     r.run,
+		AllowOvermount: true,
+	}
+
+	// The mount of the source that is already mounted at the target is
+	// idempotent rather than stacked.
+	if err := fs.Mount(ctx, "/dev/sdb", "/mnt", "ext4"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t, "mount -t ext4 /dev/sdb /mnt")
+
+	// The mount of another source is stacked.
+	if err := fs.Mount(ctx, "/dev/sdc", "/mnt", "ext4"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t,
+		"mount -t ext4 /dev/sdb /mnt",
+		"mount -t ext4 /dev/sdc /mnt")
+}
+
+func TestMountAlreadyMountedBusy(t *testing.T) {
+	procRoot := newTestProcRoot(t, overmountMountInfoData, "self")
+	ctx := context.TODO()
+
+	// The mount table is not checked before the mount when overmounts
+	// are allowed, so the mount command fails with EBUSY.
+	r := newTestErrorRunner(
+		"mount: /mnt: /dev/sdb already mounted or mount point busy.\n")
+	fs := &gofsutil.FS{
+		ProcRoot:       procRoot,
+		RunCommand:     r.run,
+		AllowOvermount: true,
+	}
+	if err := fs.Mount(ctx, "/dev/sdb", "/mnt", "ext4"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t, "mount -t ext4 /dev/sdb /mnt")
+
+	r = newTestErrorRunner("mount: /mnt: mount point busy.\n")
+	fs.RunCommand = r.run
+	err := fs.Mount(ctx, "/dev/sdc", "/mnt", "xfs")
+	var amErr *gofsutil.AlreadyMountedError
+	if !errors.As(err, &amErr) {
+		t.Fatalf("expected AlreadyMountedError: %v", err)
+	}
+	if amErr.Source != "/dev/sdb" || amErr.Err == nil {
+		t.Errorf("invalid conflicting mount: %+v", amErr)
+	}
+
+	// A busy target at which nothing is mounted is not already mounted.
+	err = fs.Mount(ctx, "/dev/sdc", "/mnt/data", "xfs")
+	if err == nil || errors.Is(err, gofsutil.ErrAlreadyMounted) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestBindMountRoot(t *testing.T) {
+	src, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(src)
+	tgt, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tgt)
+	if err := gofsutil.EvalSymlinks(context.TODO(), &src); err != nil {
+		t.Fatal(err)
+	}
+	if err := gofsutil.EvalSymlinks(context.TODO(), &tgt); err != nil {
+		t.Fatal(err)
+	}
+	sub := path.Join(src, "sub")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := gofsutil.BindMount(context.TODO(), sub, tgt); err != nil {
+		t.Fatal(err)
+	}
+	defer gofsutil.Unmount(context.TODO(), tgt)
+
+	mounts, err := gofsutil.GetMounts(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range mounts {
+		if m.Path != tgt {
+			continue
+		}
+		if m.Root == "/" || path.Base(m.Root) != "sub" {
+			t.Errorf("invalid root: %s", m.Root)
+		}
+		return
+	}
+	t.Errorf("unable to find bind mount: src=%s, tgt=%s", sub, tgt)
+}
+
+func TestWalkMountsStop(t *testing.T) {
+	procRoot := newTestProcRoot(t, procMountInfoData, "self")
+
+	fs := &gofsutil.FS{ProcRoot: procRoot}
+	var paths []string
+	err := fs.WalkMounts(context.TODO(), func(m gofsutil.Info) (bool, error) {
+		paths = append(paths, m.Path)
+		return len(paths) == 3, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 3 {
+		t.Errorf("walk did not stop: %v", paths)
+	}
+}
+
+func TestWalkMountsCancel(t *testing.T) {
+	procRoot := newTestProcRoot(t, procMountInfoData, "self")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fs := &gofsutil.FS{ProcRoot: procRoot}
+	count := 0
+	err := fs.WalkMounts(ctx, func(m gofsutil.Info) (bool, error) {
+		if count++; count == 2 {
+			cancel()
+		}
+		return false, nil
+	})
+	if err != context.Canceled {
+		t.Errorf("expected context canceled: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("walk continued after cancel: count=%d", count)
+	}
+}
+
+func TestGetMountFSType(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := gofsutil.EvalSymlinks(context.TODO(), &dir); err != nil {
+		t.Fatal(err)
+	}
+	mnt := path.Join(dir, "mnt")
+	if err := os.Mkdir(mnt, 0755); err != nil {
+		t.Fatal(err)
+	}
+	link := path.Join(dir, "link")
+	if err := os.Symlink(mnt, link); err != nil {
+		t.Fatal(err)
+	}
+
+	data := "60 1 253:0 / / rw,relatime shared:1 - xfs /dev/sda1 rw\n" +
+		"72 60 8:16 / " + mnt + " rw,relatime shared:28 - ext4 /dev/sdb rw\n" +
+		"73 72 0:45 / " + mnt + " rw,relatime shared:29 - tmpfs tmpfs rw\n" +
+		"74 60 8:32 / /mnt/data rw,relatime shared:30 - ext4 /dev/sdc rw\n"
+	procRoot := newTestProcRoot(t, data, "self")
+
+	// The default scan function ignores tmpfs mounts.
+	scanAll := func(
+		ctx context.Context,
+		entry gofsutil.Entry,
+		cache map[string]gofsutil.Entry) (gofsutil.Info, bool, error) {
+
+		return gofsutil.Info{
+			Device: entry.MountSource,
+			Path:   entry.MountPoint,
+			Type:   entry.FSType,
+		}, true, nil
+	}
+
+	tests := []struct {
+		name     string
+		fs       *gofsutil.FS
+		target   string
+		fsType   string
+		notFound bool
+	}{
+		{
+			name:   "ext4",
+			fs:     &gofsutil.FS{ProcRoot: procRoot},
+			target: "/mnt/data",
+			fsType: "ext4",
+		},
+		{
+			name:   "ext4-symlink",
+			fs:     &gofsutil.FS{ProcRoot: procRoot},
+			target: link,
+			fsType: "ext4",
+		},
+		{
+			name:   "tmpfs",
+			fs:     &gofsutil.FS{ProcRoot: procRoot, ScanEntry: scanAll},
+			target: link,
+			fsType: "tmpfs",
+		},
+		{
+			name:     "not-mounted",
+			fs:       &gofsutil.FS{ProcRoot: procRoot},
+			target:   dir,
+			notFound: true,
+		},
+	}
+
+	for _, tt := range tests {
+		fsType, err := tt.fs.GetMountFSType(context.TODO(), tt.target)
+		if tt.notFound {
+			if !errors.Is(err, gofsutil.ErrNotMounted) {
+				t.Errorf("%s: expected ErrNotMounted: %v", tt.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if fsType != tt.fsType {
+			t.Errorf("%s: invalid fsType: exp=%s, act=%s",
+				tt.name, tt.fsType, fsType)
+		}
+	}
+}
+
+const sortMountInfoData = `80 60 8:48 / /mnt/b/c rw,relatime shared:30 - ext4 /dev/sdd rw
+60 1 253:0 / / rw,relatime shared:1 - xfs /dev/sda1 rw
+72 60 8:16 / /mnt/b rw,relatime shared:28 - ext4 /dev/sdb rw
+73 60 8:32 / /mnt/a rw,relatime shared:29 - ext4 /dev/sdc rw
+74 72 8:64 / /mnt/b rw,relatime shared:31 - ext4 /dev/sde rw
+75 60 8:80 / /data rw,relatime shared:32 - ext4 /dev/sdf rw
+`
+
+func TestGetMountsSorted(t *testing.T) {
+	procRoot := newTestProcRoot(t, sortMountInfoData, "self")
+
+	fs := &gofsutil.FS{ProcRoot: procRoot}
+
+	exp := []string{
+		"/ /dev/sda1",
+		"/data /dev/sdf",
+		"/mnt/a /dev/sdc",
+		"/mnt/b /dev/sdb",
+		"/mnt/b /dev/sde",
+		"/mnt/b/c /dev/sdd",
+	}
+
+	var prev []gofsutil.Info
+	for i := 0; i < 2; i++ {
+		mounts, err := fs.GetMountsSorted(context.TODO())
+		if err != nil {
+			t.Fatal(err)
+		}
+		var act []string
+		for _, m := range mounts {
+			act = append(act, m.Path+" "+m.Device)
+		}
+		if !reflect.DeepEqual(act, exp) {
+			t.Errorf("invalid order: exp=%v, act=%v", exp, act)
+		}
+		if prev != nil && !reflect.DeepEqual(prev, mounts) {
+			t.Errorf("order not deterministic: %v != %v", prev, mounts)
+		}
+		prev = mounts
+	}
+}
+
+const autofsMountInfoData = `60 1 253:0 / / rw,relatime shared:1 - xfs /dev/sda1 rw
+61 60 0:50 / /proc/sys/fs/binfmt_misc rw,relatime shared:2 - autofs systemd-1 rw,fd=29,pgrp=1,timeout=0,minproto=5,maxproto=5,direct
+62 60 0:51 / /misc rw,relatime shared:3 - autofs /etc/auto.misc rw,fd=7,pgrp=1200,timeout=300,minproto=5,maxproto=5,indirect
+72 60 8:16 / /mnt/data rw,relatime shared:28 - ext4 /dev/sdb rw
+`
+
+func TestGetMountsSkipAutofs(t *testing.T) {
+	procRoot := newTestProcRoot(t, autofsMountInfoData, "self")
+
+	// Include every entry so the systemd automount placeholder, which
+	// the default scan function ignores, is also present.
+	scanAll := func(
+		ctx context.Context,
+		entry gofsutil.Entry,
+		cache map[string]gofsutil.Entry) (gofsutil.Info, bool, error) {
+
+		return gofsutil.Info{
+			Device: entry.MountSource,
+			Path:   entry.MountPoint,
+			Type:   entry.FSType,
+		}, true, nil
+	}
+
+	paths := func(mounts []gofsutil.Info) []string {
+		var p []string
+		for _, m := range mounts {
+			p = append(p, m.Path)
+		}
+		return p
+	}
+
+	fs := &gofsutil.FS{ProcRoot: procRoot, ScanEntry: scanAll}
+	mounts, err := fs.GetMounts(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []string{"/", "/proc/sys/fs/binfmt_misc", "/misc", "/mnt/data"}
+	if act := paths(mounts); !reflect.DeepEqual(act, exp) {
+		t.Errorf("invalid mounts: exp=%v, act=%v", exp, act)
+	}
+
+	fs.SkipAutofs = true
+	mounts, err = fs.GetMounts(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp = []string{"/", "/mnt/data"}
+	if act := paths(mounts); !reflect.DeepEqual(act, exp) {
+		t.Errorf("invalid mounts: exp=%v, act=%v", exp, act)
+	}
+
+	var walked []gofsutil.Info
+	if err := fs.WalkMounts(
+		context.TODO(), func(m gofsutil.Info) (bool, error) {
+			walked = append(walked, m)
+			return false, nil
+		}); err != nil {
+		t.Fatal(err)
+	}
+	if act := paths(walked); !reflect.DeepEqual(act, exp) {
+		t.Errorf("invalid walked mounts: exp=%v, act=%v", exp, act)
+	}
+}
+
+func TestWithMountTable(t *testing.T) {
+	procRoot := newTestProcRoot(t, sortMountInfoData, "self")
+
+	fs := &gofsutil.FS{ProcRoot: procRoot}
+
+	table := []gofsutil.Info{
+		{Device: "/dev/sdz", Path: "/mnt/cached", Type: "xfs"},
+	}
+	ctx := gofsutil.WithMountTable(context.Background(), table)
+
+	// The mount table file is removed to prove it is not read.
+	if err := os.Remove(path.Join(procRoot, "self", "mountinfo")); err != nil {
+		t.Fatal(err)
+	}
+
+	mounts, err := fs.GetMounts(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(mounts, table) {
+		t.Errorf("invalid mounts: exp=%v, act=%v", table, mounts)
+	}
+	fsType, err := fs.GetMountFSType(ctx, "/mnt/cached")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fsType != "xfs" {
+		t.Errorf("invalid fsType: exp=xfs, act=%s", fsType)
+	}
+	devMounts, err := fs.GetDevMounts(ctx, "/dev/sdz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(devMounts) != 1 {
+		t.Errorf("invalid dev mounts: %v", devMounts)
+	}
+	var walked int
+	if err := fs.WalkMounts(ctx, func(gofsutil.Info) (bool, error) {
+		walked++
+		return false, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if walked != 1 {
+		t.Errorf("invalid walk count: exp=1, act=%d", walked)
+	}
+
+	// Without a table in the context the mount table file is read.
+	if _, err := fs.GetMounts(context.Background()); !os.IsNotExist(err) {
+		t.Errorf("expected not exist error: %v", err)
+	}
+}
+
+func TestWithMountTableFallback(t *testing.T) {
+	procRoot := newTestProcRoot(t, sortMountInfoData, "self")
+
+	fs := &gofsutil.FS{ProcRoot: procRoot}
+	fsType, err := fs.GetMountFSType(context.Background(), "/data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fsType != "ext4" {
+		t.Errorf("invalid fsType: exp=ext4, act=%s", fsType)
+	}
+}
+
+func TestIsDeviceMounted(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := gofsutil.EvalSymlinks(context.TODO(), &dir); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"sdb", "sdc", "dm-0"} {
+		if err := ioutil.WriteFile(
+			path.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for link, name := range map[string]string{
+		"by-id-sdb": "sdb",
+		"mapper":    "dm-0",
+	} {
+		if err := os.Symlink(
+			path.Join(dir, name), path.Join(dir, link)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The device-mapper device is listed in the mount table by its
+	// symlink, as it is for /dev/mapper devices.
+	data := "60 1 253:0 / / rw,relatime shared:1 - xfs /dev/sda1 rw\n" +
+		"72 60 8:16 / /mnt/b rw shared:28 - ext4 " + dir + "/sdb rw\n" +
+		"73 60 253:1 / /mnt/m rw shared:29 - ext4 " + dir + "/mapper rw\n"
+	procRoot := newTestProcRoot(t, data, "self")
+
+	fs := &gofsutil.FS{ProcRoot: procRoot}
+
+	for _, tt := range []struct {
+		dev     string
+		mounted bool
+	}{
+		{path.Join(dir, "sdb"), true},
+		{path.Join(dir, "by-id-sdb"), true},
+		{path.Join(dir, "dm-0"), true},
+		{path.Join(dir, "sdc"), false},
+	} {
+		mounted, err := fs.IsDeviceMounted(context.TODO(), tt.dev)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mounted != tt.mounted {
+			t.Errorf("invalid mounted state: dev=%s, exp=%v, act=%v",
+				tt.dev, tt.mounted, mounted)
+		}
+	}
+}
+
+func TestGetMountAge(t *testing.T) {
+	src, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(src)
+	tgt, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tgt)
+	if err := gofsutil.EvalSymlinks(context.TODO(), &tgt); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.TODO()
+	if err := gofsutil.BindMount(ctx, src, tgt); err != nil {
+		t.Fatal(err)
+	}
+	defer gofsutil.Unmount(ctx, tgt)
+
+	age, err := gofsutil.GetMountAge(ctx, tgt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if age < 0 || age > time.Minute {
+		t.Errorf("invalid age: %v", age)
+	}
+
+	_, err = gofsutil.GetMountAge(ctx, src)
+	if !errors.Is(err, gofsutil.ErrNotMounted) {
+		t.Errorf("expected ErrNotMounted: %v", err)
+	}
+}
+
+func TestGetMountKind(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := gofsutil.EvalSymlinks(context.TODO(), &dir); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		ctx     = context.TODO()
+		blk     = path.Join(dir, "blk")
+		blkTgt  = path.Join(dir, "blk-tgt")
+		bindSrc = path.Join(dir, "bind-src")
+		bindTgt = path.Join(dir, "bind-tgt")
+		fsTgt   = path.Join(dir, "fs-tgt")
+	)
+
+	// A block device node for loop0, bind mounted to a file.
+	if err := syscall.Mknod(
+		blk, syscall.S_IFBLK|0600, int(7<<8|0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(blkTgt, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := gofsutil.BindMount(ctx, blk, blkTgt); err != nil {
+		t.Fatal(err)
+	}
+	defer gofsutil.Unmount(ctx, blkTgt)
+
+	for _, d := range []string{bindSrc, bindTgt, fsTgt} {
+		if err := os.Mkdir(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := gofsutil.BindMount(ctx, bindSrc, bindTgt); err != nil {
+		t.Fatal(err)
+	}
+	defer gofsutil.Unmount(ctx, bindTgt)
+
+	for _, tt := range []struct {
+		target string
+		kind   string
+	}{
+		{blkTgt, gofsutil.MountKindBlock},
+		{bindTgt, gofsutil.MountKindBind},
+	} {
+		kind, err := gofsutil.GetMountKind(ctx, tt.target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if kind != tt.kind {
+			t.Errorf("invalid kind: target=%s, exp=%s, act=%s",
+				tt.target, tt.kind, kind)
+		}
+	}
+
+	// A filesystem mounted at the root of its device is described by a
+	// mount table fixture so no device needs to be formatted.
+	data := "60 1 253:0 / / rw,relatime shared:1 - xfs /dev/sda1 rw\n" +
+		"72 60 8:16 / " + fsTgt + " rw shared:28 - ext4 /dev/sdb rw\n"
+	procRoot := newTestProcRoot(t, data, "self")
+
+	fs := &gofsutil.FS{ProcRoot: procRoot}
+	kind, err := fs.GetMountKind(ctx, fsTgt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kind != gofsutil.MountKindFilesystem {
+		t.Errorf("invalid kind: exp=%s, act=%s",
+			gofsutil.MountKindFilesystem, kind)
+	}
+	_, err = fs.GetMountKind(ctx, bindSrc)
+	if !errors.Is(err, gofsutil.ErrNotMounted) {
+		t.Errorf("expected ErrNotMounted: %v", err)
+	}
+}
+
+const waitMountInfoData = `60 1 253:0 / / rw,relatime shared:1 - xfs /dev/sda1 rw
+72 60 8:16 / /mnt/wait rw,relatime shared:28 - ext4 /dev/sdb rw
+`
+
+func TestWaitForUnmount(t *testing.T) {
+	procRoot := newTestProcRoot(t, waitMountInfoData, "self")
+
+	fs := &gofsutil.FS{ProcRoot: procRoot}
+	if ok, err := fs.IsMountPoint(context.TODO(), "/mnt/wait"); err != nil {
+		t.Fatal(err)
+	} else if !ok {
+		t.Fatal("/mnt/wait should be a mount point")
+	}
+
+	// Remove the mount from the table after a delay. The new table is
+	// renamed into place so a poll never reads a partial file.
+	errs := make(chan error, 1)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		mountInfo := path.Join(procRoot, "self", "mountinfo")
+		data := strings.SplitN(waitMountInfoData, "\n", 2)[0] + "\n"
+		if err := ioutil.WriteFile(
+			mountInfo+".tmp", []byte(data), 0644); err != nil {
+			errs <- err
+			return
+		}
+		errs <- os.Rename(mountInfo+".tmp", mountInfo)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+	if err := fs.WaitForUnmount(
+		ctx, "/mnt/wait", 10*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := fs.IsMountPoint(context.TODO(), "/mnt/wait"); err != nil {
+		t.Fatal(err)
+	} else if ok {
+		t.Fatal("/mnt/wait should not be a mount point")
+	}
+}
+
+func TestWaitForUnmountTimeout(t *testing.T) {
+	procRoot := newTestProcRoot(t, waitMountInfoData, "self")
+
+	fs := &gofsutil.FS{ProcRoot: procRoot}
+	ctx, cancel := context.WithTimeout(
+		context.TODO(), 100*time.Millisecond)
+	defer cancel()
+	err := fs.WaitForUnmount(ctx, "/mnt/wait", 10*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded: %v", err)
+	}
+}
+
+func TestGetMountsBlockedRead(t *testing.T) {
+	procRoot := newTestProcRoot(t, "", "self")
+
+	// Replace the mount table with a pipe that has no writer, so any
+	// attempt to read it blocks.
+	mountInfo := path.Join(procRoot, "self", "mountinfo")
+	if err := os.Remove(mountInfo); err != nil {
+		t.Fatal(err)
+	}
+	if err := syscall.Mkfifo(mountInfo, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Open and close the write end of the pipe once the test is done
+	// to release the blocked reads.
+	defer func() {
+		for i := 0; i < 2; i++ {
+			w, err := os.OpenFile(
+				mountInfo, os.O_WRONLY|syscall.O_NONBLOCK, 0)
+			if err != nil {
+				return
+			}
+			w.Close()
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+
+	fs := &gofsutil.FS{ProcRoot: procRoot}
+
+	ctx, cancel := context.WithTimeout(
+		context.TODO(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := fs.GetMounts(ctx); !errors.Is(
+		err, context.DeadlineExceeded) {
+		t.Errorf("GetMounts: expected deadline exceeded: %v", err)
+	}
+
+	ctx, cancel = context.WithCancel(context.TODO())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	if _, err := fs.IsMountPoint(ctx, "/mnt"); !errors.Is(
+		err, context.Canceled) {
+		t.Errorf("IsMountPoint: expected canceled: %v", err)
+	}
+}
+
+const dedupeMountInfoData = `60 1 253:0 / / rw,relatime shared:1 - xfs /dev/sda1 rw
+72 60 8:16 / /mnt/a rw,relatime shared:28 - ext4 /dev/sdb rw
+73 72 8:16 / /mnt/a rw,relatime shared:28 - ext4 /dev/sdb rw
+74 73 8:16 / /mnt/a ro,relatime shared:28 - ext4 /dev/sdb rw
+75 74 8:32 / /mnt/a rw,relatime shared:29 - ext4 /dev/sdc rw
+76 60 8:16 /sub /mnt/b rw,relatime shared:28 - ext4 /dev/sdb rw
+77 60 8:16 / /mnt/b rw,relatime shared:28 - ext4 /dev/sdb rw
+`
+
+func TestGetMountsDedupeMounts(t *testing.T) {
+	procRoot := newTestProcRoot(t, dedupeMountInfoData, "self")
+
+	fs := &gofsutil.FS{ProcRoot: procRoot}
+	mounts, err := fs.GetMounts(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mounts) != 7 {
+		t.Fatalf("invalid mount count: exp=7, act=%d", len(mounts))
+	}
+
+	fs.DedupeMounts = true
+	mounts, err = fs.GetMounts(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var act []string
+	for _, m := range mounts {
+		act = append(act, m.Path+":"+m.Device+":"+m.Root+":"+m.Opts[0])
+	}
+	exp := []string{
+		"/:/dev/sda1:/:rw",
+		"/mnt/a:/dev/sdb:/:rw",
+		"/mnt/a:/dev/sdb:/:ro",
+		"/mnt/a:/dev/sdc:/:rw",
+		"/mnt/b:/dev/sdb:/sub:rw",
+		"/mnt/b:/dev/sdb:/:rw",
+	}
+	if !reflect.DeepEqual(act, exp) {
+		t.Errorf("invalid mounts: exp=%v, act=%v", exp, act)
+	}
+}
+
+func TestGetDevMountsMulti(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Regular files stand in for the devices.
+	var devs []string
+	for _, name := range []string{"sdb", "sdc", "sdd"} {
+		dev := path.Join(dir, name)
+		if err := ioutil.WriteFile(dev, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		devs = append(devs, dev)
+	}
+	link := path.Join(dir, "by-id")
+	if err := os.Symlink(devs[1], link); err != nil {
+		t.Fatal(err)
+	}
+
+	data := strings.Join([]string{
+		"60 0 8:1 / / rw - ext4 /dev/sda1 rw",
+		"61 60 8:16 / /mnt/a rw - ext4 " + devs[0] + " rw",
+		"62 60 8:16 /sub /mnt/b rw - ext4 " + devs[0] + " rw",
+		"63 60 8:32 / /mnt/c rw - xfs " + devs[1] + " rw",
+	}, "\n") + "\n"
+	procRoot := newTestProcRoot(t, data, "self")
+
+	var scans int
+	scanEntry := gofsutil.DefaultEntryScanFunc()
+	fs := &gofsutil.FS{
+		ProcRoot: procRoot,
+		ScanEntry: func(
+			ctx context.Context,
+			entry gofsutil.Entry,
+			cache map[string]gofsutil.Entry) (gofsutil.Info, bool, error) {
+
+			scans++
+			return scanEntry(ctx, entry, cache)
+		},
+	}
+
+	mounts, err := fs.GetDevMountsMulti(
+		context.TODO(), []string{devs[0], link, devs[2]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scans != 4 {
+		t.Errorf("mount table read more than once: %d entries scanned", scans)
+	}
+	if len(mounts) != 2 {
+		t.Fatalf("invalid device count: %d: %+v", len(mounts), mounts)
+	}
+	if m := mounts[devs[0]]; len(m) != 2 ||
+		m[0].Path != "/mnt/a" || m[1].Path != "/mnt/b" {
+		t.Errorf("invalid mounts for %s: %+v", devs[0], m)
+	}
+	if m := mounts[link]; len(m) != 1 || m[0].Path != "/mnt/c" {
+		t.Errorf("invalid mounts for %s: %+v", link, m)
+	}
+	if m, ok := mounts[devs[2]]; ok {
+		t.Errorf("unexpected mounts for %s: %+v", devs[2], m)
+	}
+}
+
+func TestUnmountByFSType(t *testing.T) {
+	ctx := gofsutil.WithMountTable(context.TODO(), []gofsutil.Info{
+		{Device: "/dev/sdb", Path: "/mnt/data", Type: "ext4"},
+		{Device: "user@host:/data", Path: "/mnt/sshfs", Type: "fuse.sshfs"},
+		{Device: "host:/export", Path: "/mnt/nfs", Type: "nfs4"},
+	})
+
+	for _, tt := range []struct {
+		target string
+		cmds   []string
+	}{
+		{"/mnt/data", []string{"umount /mnt/data"}},
+		{"/mnt/sshfs", []string{"fusermount3 -u /mnt/sshfs"}},
+		{"/mnt/nfs", []string{"umount.nfs4 /mnt/nfs"}},
+		{"/mnt/none", []string{"umount /mnt/none"}},
+	} {
+		r := &testCommandRunner{}
+		fs := &gofsutil.FS{RunCommand: r.run}
+		if err := fs.Unmount(ctx, tt.target); err != nil {
+			t.Fatalf("%s: %v", tt.target, err)
+		}
+		r.assertCommands(t, tt.cmds...)
+	}
+}
+
+func TestUnmountFuseFallback(t *testing.T) {
+	ctx := gofsutil.WithMountTable(context.TODO(), []gofsutil.Info{
+		{Device: "user@host:/data", Path: "/mnt/sshfs", Type: "fuse.sshfs"},
+	})
+
+	// Neither fusermount3 nor fusermount is installed.
+	r := &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			if strings.HasPrefix(args[0], "fusermount") {
+				return "", &exec.Error{Name: args[0], Err: exec.ErrNotFound}
+			}
+			return "", nil
+		},
+	}
+	fs := &gofsutil.FS{RunCommand: r.run}
+	if err := fs.Unmount(ctx, "/mnt/sshfs"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t,
+		"fusermount3 -u /mnt/sshfs",
+		"fusermount -u /mnt/sshfs",
+		"umount /mnt/sshfs")
+}
+
+func TestUnmountFuseFailed(t *testing.T) {
+	ctx := gofsutil.WithMountTable(context.TODO(), []gofsutil.Info{
+		{Device: "user@host:/data", Path: "/mnt/sshfs", Type: "fuse.sshfs"},
+	})
+
+	// A helper that fails for a reason other than not being installed
+	// is not followed by the next helper.
+	r := &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			return "fusermount3: failed to unmount /mnt/sshfs: " +
+				"Device or resource busy", errors.New("exit status 1")
+		},
+	}
+	fs := &gofsutil.FS{RunCommand: r.run}
+	if err := fs.Unmount(ctx, "/mnt/sshfs"); err == nil {
+		t.Fatal("expected error")
+	}
+	r.assertCommands(t, "fusermount3 -u /mnt/sshfs")
+}
+
+func TestGetMountByTarget(t *testing.T) {
+	ctx := gofsutil.WithMountTable(context.TODO(), []gofsutil.Info{
+		{Device: "/dev/sdb", Path: "/mnt/data", Type: "ext4"},
+		{Device: "tmpfs", Path: "/mnt/data", Type: "tmpfs"},
+	})
+
+	m, err := gofsutil.GetMountByTarget(ctx, "/mnt/data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Type != "tmpfs" {
+		t.Errorf("invalid mount: exp=tmpfs, act=%s", m.Type)
+	}
+	_, err = gofsutil.GetMountByTarget(ctx, "/mnt/none")
+	if !errors.Is(err, gofsutil.ErrNotMounted) {
+		t.Errorf("expected ErrNotMounted: %v", err)
+	}
+}
+
+func TestGetTopMount(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := gofsutil.EvalSymlinks(context.TODO(), &dir); err != nil {
+		t.Fatal(err)
+	}
+	target, link := path.Join(dir, "mnt"), path.Join(dir, "link")
+	if err := os.Mkdir(target, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+
+	// Two tmpfs filesystems are stacked at target.
+	procRoot := newTestProcRoot(t, fmt.Sprintf(
+		`22 1 8:1 / / rw,relatime shared:1 - ext4 /dev/sda1 rw
+90 22 0:51 / %[1]s rw,relatime shared:40 - tmpfs first rw,size=1024k
+91 90 0:52 / %[1]s rw,relatime shared:41 - tmpfs second rw,size=2048k
+`, target), "self")
+
+	// The default scan function ignores tmpfs mounts.
+	scanAll := func(
+		ctx context.Context,
+		entry gofsutil.Entry,
+		cache map[string]gofsutil.Entry) (gofsutil.Info, bool, error) {
+
+		return gofsutil.Info{
+			Device: entry.MountSource,
+			Path:   entry.MountPoint,
+			Type:   entry.FSType,
+		}, true, nil
+	}
+	fs := &gofsutil.FS{ProcRoot: procRoot, ScanEntry: scanAll}
+	for _, p := range []string{target, link} {
+		m, ok, err := fs.GetTopMount(context.TODO(), p)
+		if err != nil {
+			t.Fatalf("%s: %v", p, err)
+		}
+		if !ok {
+			t.Fatalf("%s: not mounted", p)
+		}
+		if m.Device != "second" {
+			t.Errorf("%s: invalid top mount: exp=second, act=%s", p, m.Device)
+		}
+	}
+
+	m, ok, err := fs.GetTopMount(context.TODO(), path.Join(dir, "none"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Errorf("unexpected mount: %+v", m)
+	}
+}
+
+func TestMountTargetNormalization(t *testing.T) {
+	procRoot := newTestProcRoot(t,
+		`22 1 8:1 / / rw,relatime shared:1 - ext4 /dev/sda1 rw
+90 22 8:16 / /mnt/data rw,noatime shared:40 - ext4 /dev/sdb rw
+`, "self")
+
+	fs := &gofsutil.FS{ProcRoot: procRoot}
+	for _, target := range []string{
+		"/mnt/data", "/mnt/data/", "/mnt/./data", "/mnt/foo/../data",
+	} {
+		m, err := fs.GetMountByTarget(context.TODO(), target)
+		if err != nil {
+			t.Fatalf("%s: %v", target, err)
+		}
+		if m.Path != "/mnt/data" || m.Device != "/dev/sdb" {
+			t.Errorf("%s: invalid mount: %+v", target, m)
+		}
+		if _, ok, err := fs.GetTopMount(
+			context.TODO(), target); err != nil || !ok {
+			t.Errorf("%s: top mount not found: %v", target, err)
+		}
+		mounted, err := fs.IsMountPoint(context.TODO(), target)
+		if err != nil {
+			t.Fatalf("%s: %v", target, err)
+		}
+		if !mounted {
+			t.Errorf("%s: expected mount point", target)
+		}
+		if _, err := fs.GetEffectiveMountFlags(
+			context.TODO(), target); err != nil {
+			t.Errorf("%s: %v", target, err)
+		}
+	}
+}
+
+const scanWithMountInfoData = `60 1 253:0 / / rw,relatime shared:1 - xfs /dev/mapper/cl-root rw,seclabel,attr2,inode64,noquota
+72 60 8:16 / /mnt/data rw,relatime shared:28 - ext4 /dev/sdb rw,data=ordered
+73 60 0:45 / /mnt/tmp rw,relatime shared:29 - tmpfs tmpfs rw,size=1024k
+`
+
+func TestGetMountsWith(t *testing.T) {
+	procRoot := newTestProcRoot(t, scanWithMountInfoData, "self")
+
+	fs := &gofsutil.FS{
+		ProcRoot:  procRoot,
+		ScanEntry: gofsutil.DefaultEntryScanFunc(),
+	}
+
+	var scanned int
+	scanAll := func(
+		ctx context.Context,
+		entry gofsutil.Entry,
+		cache map[string]gofsutil.Entry) (gofsutil.Info, bool, error) {
+
+		scanned++
+		return gofsutil.Info{
+			Device: entry.MountSource,
+			Path:   entry.MountPoint,
+			Type:   entry.FSType,
+		}, true, nil
+	}
+	hasTmpfs := func(mounts []gofsutil.Info) bool {
+		for _, m := range mounts {
+			if m.Type == "tmpfs" {
+				return true
+			}
+		}
+		return false
+	}
+
+	ctx := context.TODO()
+	mounts, err := fs.GetMountsWith(ctx, scanAll)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mounts) != 3 || !hasTmpfs(mounts) {
+		t.Errorf("override not used: %+v", mounts)
+	}
+	if scanned == 0 {
+		t.Error("override not invoked")
+	}
+	m, err := fs.GetMountByTargetWith(ctx, "/mnt/tmp", scanAll)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Type != "tmpfs" {
+		t.Errorf("invalid mount: %+v", m)
+	}
+
+	// The FS's scan function is used once the call returns.
+	scanned = 0
+	mounts, err = fs.GetMounts(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mounts) != 2 || hasTmpfs(mounts) {
+		t.Errorf("override used after call: %+v", mounts)
+	}
+	if scanned != 0 {
+		t.Errorf("override invoked after call: %d", scanned)
+	}
+	if _, err := fs.GetMountByTarget(ctx, "/mnt/tmp"); err == nil {
+		t.Error("expected error for tmpfs target")
+	}
+}
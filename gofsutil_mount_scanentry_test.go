@@ -155,3 +155,41 @@ const procMountInfoData = `17 60 0:16 / /sys rw,nosuid,nodev,noexec,relatime sha
 121 61 0:39 / /var/lib/rexray/volumes/vol01 rw,relatime shared:69 - nfs 192.168.1.80:/ifs/vols/vol01 rw,vers=3,rsize=131072,wsize=524288,namlen=255,hard,proto=tcp,timeo=600,retrans=2,sec=sys,mountaddr=192.168.1.80,mountvers=3,mountport=300,mountproto=udp,local_lock=none,addr=192.168.1.80
 124 61 0:39 / /var/lib/rexray/csi/volumes/vol01 rw,relatime shared:69 - nfs 192.168.1.80:/ifs/vols/vol01/data rw,vers=3,rsize=131072,wsize=524288,namlen=255,hard,proto=tcp,timeo=600,retrans=2,sec=sys,mountaddr=192.168.1.80,mountvers=3,mountport=300,mountproto=udp,local_lock=none,addr=192.168.1.80
 `
+
+const bindMountInfoData = `60 1 253:0 / / rw,relatime shared:1 - xfs /dev/mapper/cl-root rw,seclabel,attr2,inode64,noquota
+72 60 8:16 / /data rw,relatime shared:28 - ext4 /dev/sdb rw
+73 60 8:16 /sub /mnt/bind rw,relatime shared:28 - ext4 /dev/sdb rw
+74 60 8:16 / /mnt/data2 rw,relatime shared:28 - ext4 /dev/sdb rw
+75 60 0:45 / /mnt/tmp rw,relatime shared:29 - tmpfs tmpfs rw
+`
+
+func TestLooksLikeBindMount(t *testing.T) {
+	mounts, _, err := gofsutil.ReadProcMountsFrom(
+		context.TODO(),
+		strings.NewReader(bindMountInfoData),
+		false,
+		gofsutil.ProcMountsFields,
+		gofsutil.DefaultEntryScanFunc())
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := map[string]bool{
+		"/":          false,
+		"/data":      false,
+		"/mnt/bind":  true,
+		"/mnt/data2": true,
+	}
+	for _, m := range mounts {
+		e, ok := exp[m.Path]
+		if !ok {
+			continue
+		}
+		if m.MajorMinor == "" {
+			t.Errorf("missing major:minor: %s", m.Path)
+		}
+		if act := m.LooksLikeBindMount(mounts); act != e {
+			t.Errorf("invalid bind mount flag: path=%s, exp=%v, act=%v",
+				m.Path, e, act)
+		}
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"io/ioutil"
 	"os"
+	"path"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/thecodeteam/gofsutil"
@@ -70,3 +73,149 @@ func TestGetMounts(t *testing.T) {
 		t.Logf("%+v", m)
 	}
 }
+
+func TestGetDeviceForPath(t *testing.T) {
+	ctx := gofsutil.WithMountTable(context.TODO(), []gofsutil.Info{
+		{Device: "/dev/sda1", Path: "/", Root: "/"},
+		{Device: "/dev/sdb", Path: "/var/lib", Root: "/"},
+		{Device: "/dev/sdc", Path: "/var/lib/data", Root: "/vol1"},
+		{Device: "/dev/sdd", Path: "/var/lib/database", Root: "/"},
+		{Device: "/dev/sde", Path: "/var/lib/data", Root: "/vol2"},
+	})
+	fs := &gofsutil.FS{}
+
+	for _, tt := range []struct {
+		path   string
+		device string
+		root   string
+	}{
+		{"/var/lib/data/a/b/file.db", "/dev/sde", "/vol2"},
+		{"/var/lib/data", "/dev/sde", "/vol2"},
+		{"/var/lib/database/file.db", "/dev/sdd", "/"},
+		{"/var/lib/other/file", "/dev/sdb", "/"},
+		{"/no/such/mount/file", "/dev/sda1", "/"},
+	} {
+		device, root, err := fs.GetDeviceForPath(ctx, tt.path)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.path, err)
+		}
+		if device != tt.device || root != tt.root {
+			t.Errorf("%s: invalid mount: exp=%s:%s, act=%s:%s",
+				tt.path, tt.device, tt.root, device, root)
+		}
+	}
+}
+
+func TestGetMountsWhere(t *testing.T) {
+	ctx := gofsutil.WithMountTable(context.TODO(), []gofsutil.Info{
+		{Device: "/dev/sda1", Path: "/", Opts: []string{"rw"}},
+		{Device: "/dev/sdb", Path: "/var/lib/a", Opts: []string{"ro"}},
+		{Device: "/dev/sdc", Path: "/var/lib/b", Opts: []string{"rw"}},
+		{Device: "/dev/sdd", Path: "/mnt", Opts: []string{"ro", "noexec"}},
+	})
+	fs := &gofsutil.FS{}
+
+	devices := func(mounts []gofsutil.Info) []string {
+		var d []string
+		for _, m := range mounts {
+			d = append(d, m.Device)
+		}
+		return d
+	}
+
+	ro, err := fs.GetMountsWhere(ctx, func(m gofsutil.Info) bool {
+		return m.Flags().ReadOnly
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if act := devices(ro); !reflect.DeepEqual(
+		act, []string{"/dev/sdb", "/dev/sdd"}) {
+		t.Errorf("invalid ro mounts: %v", act)
+	}
+
+	lib, err := fs.GetMountsWhere(ctx, func(m gofsutil.Info) bool {
+		return strings.HasPrefix(m.Path, "/var/lib/")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if act := devices(lib); !reflect.DeepEqual(
+		act, []string{"/dev/sdb", "/dev/sdc"}) {
+		t.Errorf("invalid /var/lib mounts: %v", act)
+	}
+}
+
+func TestMountAutoCreateTarget(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// A regular file stands in for a block device.
+	dev := path.Join(dir, "dev")
+	if err := ioutil.WriteFile(dev, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	target := path.Join(dir, "mnt", "fs")
+	if err := fs.Mount(
+		context.TODO(), "/dev/sdb", target, "ext4"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Fatalf("target created with AutoCreateTarget disabled: %v", err)
+	}
+
+	fs.AutoCreateTarget = true
+	if err := fs.Mount(
+		context.TODO(), "/dev/sdb", target, "ext4"); err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fi.IsDir() || fi.Mode().Perm() != 0750 {
+		t.Errorf("invalid target: dir=%v, mode=%v", fi.IsDir(), fi.Mode())
+	}
+
+	blockTarget := path.Join(dir, "mnt", "block", "vol1")
+	if err := fs.BindMount(context.TODO(), dev, blockTarget); err != nil {
+		t.Fatal(err)
+	}
+	fi, err = os.Stat(blockTarget)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fi.Mode().IsRegular() {
+		t.Errorf("invalid block target mode: %v", fi.Mode())
+	}
+
+	dirTarget := path.Join(dir, "mnt", "bind")
+	if err := fs.BindMount(context.TODO(), dir, dirTarget); err != nil {
+		t.Fatal(err)
+	}
+	if fi, err := os.Stat(dirTarget); err != nil || !fi.IsDir() {
+		t.Errorf("bind mount target of a directory is not a directory: %v", err)
+	}
+}
+
+func TestMountAllowOvermount(t *testing.T) {
+	ctx := gofsutil.WithMountTable(context.TODO(), []gofsutil.Info{
+		{Device: "/dev/sda1", Path: "/", Root: "/"},
+		{Device: "/dev/sdb", Path: "/mnt", Root: "/"},
+	})
+
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{RunCommand: r.run, AllowOvermount: true}
+	if err := fs.Mount(ctx, "/dev/sdc", "/mnt", "ext4"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t, "mount -t ext4 /dev/sdc /mnt")
+}
+
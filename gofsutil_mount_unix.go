@@ -4,10 +4,15 @@ package gofsutil
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"path"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -21,64 +26,562 @@ import (
 // The 'options' parameter is a list of options. Please see mount(8) for
 // more information. If no options are required then please invoke Mount
 // with an empty or nil argument.
+//
+// The options are checked against the FS's AllowedOptions, DeniedOptions,
+// and StrictOptions before anything is mounted, so every function that
+// mounts a filesystem enforces them, and the mount is invoked between
+// the FS's PreMountHook and PostMountHook. If the FS's VerifyOptions
+// field is true then the options of the mount are verified once it is
+// mounted.
 func (fs *FS) mount(
 	ctx context.Context,
 	source, target, fsType string,
 	opts ...string) error {
 
+	if err := fs.checkOptions(fsType, opts); err != nil {
+		return err
+	}
+	return fs.runMountHooks(
+		ctx, source, target, fsType, opts, func() error {
+			err := fs.mountUnverified(ctx, source, target, fsType, opts...)
+			if err != nil {
+				return err
+			}
+			if fs.VerifyOptions {
+				return fs.verifyMountOptions(ctx, target, opts)
+			}
+			return nil
+		})
+}
+
+// mountUnverified mounts source to target as fsType with given options
+// without verifying the options of the mount.
+func (fs *FS) mountUnverified(
+	ctx context.Context,
+	source, target, fsType string,
+	opts ...string) error {
+
+	if err := fs.checkOvermount(ctx, target, opts); err != nil {
+		return fs.checkAlreadyMounted(ctx, source, target, fsType, err)
+	}
+	bindOpts, bind := fs.isBind(ctx, opts...)
+	if fs.AutoCreateTarget {
+		if err := createMountTarget(ctx, source, target, bind); err != nil {
+			return err
+		}
+	}
+
 	// All Linux distributes should support bind mounts.
-	if opts, ok := fs.isBind(ctx, opts...); ok {
-		return fs.bindMount(ctx, source, target, opts...)
+	if bind {
+		if err := checkBindTypes(source, target); err != nil {
+			return err
+		}
+		return fs.bindMount(ctx, source, target, bindOpts...)
+	}
+	if defaults := fs.DefaultMountOpts[fsType]; len(defaults) > 0 {
+		opts = mergeDefaultMountOpts(defaults, opts)
+	}
+	opts, _, err := fs.autoReadOnly(ctx, source, opts)
+	if err != nil {
+		return err
+	}
+	err = fs.doMountRetry(ctx, source, target, fsType, opts...)
+
+	// The mount fails with EBUSY if the target is already mounted or is
+	// busy, which is only distinguished by inspecting the target.
+	if err != nil &&
+		(errors.Is(err, ErrAlreadyMounted) || errors.Is(err, errMountBusy)) {
+		return fs.checkAlreadyMounted(ctx, source, target, fsType, err)
+	}
+
+	// The kernel does not load the module of a filesystem type on demand
+	// if module autoloading is disabled or the module has no alias.
+	if err != nil && fsType != "" && fs.AutoModprobe &&
+		errors.Is(err, errUnknownFSType) {
+
+		f := logFields(ctx, log.Fields{
+			"source": source,
+			"target": target,
+			"fsType": fsType,
+		})
+		if perr := fs.modprobe(ctx, fsType); perr != nil {
+			log.WithFields(f).WithError(perr).Warn(
+				"failed to load filesystem module")
+			return err
+		}
+		log.WithFields(f).WithError(err).Warn(
+			"unknown filesystem type, retrying after modprobe")
+		err = fs.doMount(ctx, "mount", source, target, fsType, opts...)
+	}
+
+	// A clone of an xfs filesystem has the same UUID as the original and
+	// cannot be mounted alongside it unless UUID checking is disabled.
+	if err != nil && fsType == "xfs" && fs.XFSAutoNoUUID &&
+		errors.Is(err, errDuplicateUUID) {
+
+		log.WithFields(logFields(ctx, log.Fields{
+			"source": source,
+			"target": target,
+		})).WithError(err).Warn("duplicate xfs uuid, retrying with nouuid")
+		opts = append(opts[:len(opts):len(opts)], "nouuid")
+		return fs.doMount(ctx, "mount", source, target, fsType, opts...)
+	}
+	return err
+}
+
+// checkOvermount returns an *AlreadyMountedError if a filesystem is
+// already mounted at target, unless the FS allows overmounts or the
+// options describe a remount of target. Every entry of the mount table
+// is checked, including those the FS's ScanEntry skips, ex. tmpfs.
+func (fs *FS) checkOvermount(
+	ctx context.Context, target string, opts []string) error {
+
+	if fs.AllowOvermount {
+		return nil
+	}
+	for _, o := range opts {
+		if o == "remount" {
+			return nil
+		}
+	}
+	m, mounted, err := fs.lookupTopMount(withAllEntries(ctx), target)
+	if err != nil {
+		return err
+	}
+	if mounted {
+		return &AlreadyMountedError{
+			Target: target,
+			Source: m.Device,
+			Type:   m.Type,
+		}
+	}
+	return nil
+}
+
+// checkAlreadyMounted inspects the target of a mount that failed with
+// mountErr. Nil is returned if source is already mounted at target with
+// the same filesystem type, which makes the mount idempotent. Otherwise
+// an *AlreadyMountedError that describes the filesystem mounted at target
+// is returned, or mountErr if nothing is mounted at target.
+func (fs *FS) checkAlreadyMounted(
+	ctx context.Context,
+	source, target, fsType string,
+	mountErr error) error {
+
+	var amErr *AlreadyMountedError
+	if !errors.As(mountErr, &amErr) &&
+		!errors.Is(mountErr, ErrAlreadyMounted) &&
+		!errors.Is(mountErr, errMountBusy) {
+		return mountErr
+	}
+	m, mounted, err := fs.lookupTopMount(withAllEntries(ctx), target)
+	if err != nil || !mounted {
+		return mountErr
+	}
+	if source != "" &&
+		canonicalMountDevice(source) == canonicalMountDevice(m.Device) &&
+		(fsType == "" || fsType == m.Type) {
+
+		log.WithFields(logFields(ctx, log.Fields{
+			"source": source,
+			"target": target,
+			"fsType": m.Type,
+		})).Info("source is already mounted at target")
+		return nil
+	}
+	if amErr != nil {
+		return amErr
+	}
+	return &AlreadyMountedError{
+		Target: target,
+		Source: m.Device,
+		Type:   m.Type,
+		Err:    mountErr,
+	}
+}
+
+// checkBindTypes returns an error wrapping ErrBindTypeMismatch if source
+// and target exist and only one of them is a directory, since the kernel
+// does not bind mount a directory to a file or a file to a directory
+func checkBindTypes(source, target string) error {
+	sfi, err := os.Stat(source)
+	if err != nil {
+		return nil
+	}
+	tfi, err := os.Stat(target)
+	if err != nil {
+		return nil
+	}
+	if sfi.IsDir() == tfi.IsDir() {
+		return nil
+	}
+	kind := func(fi os.FileInfo) string {
+		if fi.IsDir() {
+			return "directory"
+		}
+		return "file"
+	}
+	return fmt.Errorf("bind mount %s %s to %s %s: %w",
+		kind(sfi), source, kind(tfi), target, ErrBindTypeMismatch)
+}
+
+// createBindFileTarget creates target as an empty file if it does not
+// exist. An error is returned if source is not a regular file.
+func createBindFileTarget(ctx context.Context, source, target string) error {
+	fi, err := os.Stat(source)
+	if err != nil {
+		return err
+	}
+	if !fi.Mode().IsRegular() {
+		return fmt.Errorf("bind mount source is not a regular file: %s", source)
+	}
+	return createMountTarget(ctx, source, target, true)
+}
+
+// createMountTarget creates target if it does not exist. The target of a
+// bind mount of a file, ex. a block device, is created as an empty file
+// and any other target as a directory.
+func createMountTarget(
+	ctx context.Context, source, target string, bind bool) error {
+
+	if _, err := os.Lstat(target); !os.IsNotExist(err) {
+		return err
+	}
+
+	isDir := true
+	if bind {
+		if fi, err := os.Stat(source); err == nil && !fi.IsDir() {
+			isDir = false
+		}
+	}
+	log.WithFields(logFields(ctx, log.Fields{
+		"source": source,
+		"target": target,
+		"isDir":  isDir,
+	})).Info("creating mount target")
+
+	if isDir {
+		return os.MkdirAll(target, 0750)
+	}
+	if err := os.MkdirAll(path.Dir(target), 0750); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0640)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// autoReadOnly returns opts with the "ro" option added if fs.AutoReadOnly
+// is true and source is a write-protected block device. The returned flag
+// indicates whether source is write-protected.
+func (fs *FS) autoReadOnly(
+	ctx context.Context,
+	source string,
+	opts []string) ([]string, bool, error) {
+
+	if !fs.AutoReadOnly || source == "" {
+		return opts, false, nil
+	}
+	ro, err := fs.isDeviceReadOnly(ctx, source)
+	if err != nil || !ro {
+		return opts, false, err
+	}
+	for _, o := range opts {
+		if o == "ro" {
+			return opts, true, nil
+		}
+	}
+	log.WithFields(logFields(ctx, log.Fields{
+		"source":  source,
+		"options": opts,
+	})).Warn("device is write-protected, mounting read-only")
+	return append(opts[:len(opts):len(opts)], "ro"), true, nil
+}
+
+// doMountRetry runs the mount command, retrying it as described by the
+// FS's Retry policy while the source device does not exist
+func (fs *FS) doMountRetry(
+	ctx context.Context,
+	source, target, fsType string,
+	opts ...string) error {
+
+	for attempt := 0; ; attempt++ {
+		src := source
+		if fs.Retry.Attempts > 0 && src != "" {
+			src = evalSymlinksOrPath(src)
+		}
+		err := fs.doMount(ctx, "mount", src, target, fsType, opts...)
+		if err == nil || attempt >= fs.Retry.Attempts ||
+			!isMountRetryable(err, src) {
+			return err
+		}
+		log.WithFields(logFields(ctx, log.Fields{
+			"source":  source,
+			"target":  target,
+			"attempt": attempt + 1,
+		})).WithError(err).Warn("source device does not exist, retrying mount")
+		if err := fs.Retry.wait(ctx); err != nil {
+			return err
+		}
 	}
-	return fs.doMount(ctx, "mount", source, target, fsType, opts...)
 }
 
-// doMount runs the mount command.
+// doMount mounts source to target with the FS's mount backend, or runs
+// mntCmd if the FS uses the exec backend.
 func (fs *FS) doMount(
 	ctx context.Context,
 	mntCmd, source, target, fsType string,
 	opts ...string) error {
 
+	if ok, err := fs.doMountBackend(
+		ctx, source, target, fsType, opts...); ok {
+		return err
+	}
+	return fs.doMountCmd(ctx, mntCmd, source, target, fsType, opts...)
+}
+
+// doMountCmd runs the mount command.
+func (fs *FS) doMountCmd(
+	ctx context.Context,
+	mntCmd, source, target, fsType string,
+	opts ...string) error {
+
 	mountArgs := MakeMountArgs(ctx, source, target, fsType, opts...)
 	args := strings.Join(mountArgs, " ")
 
-	f := log.Fields{
+	f := logFields(ctx, log.Fields{
 		"cmd":  mntCmd,
 		"args": args,
+	})
+
+	// The options interpreted by userspace are passed to mount(8), which
+	// consumes them rather than passing them to the kernel.
+	if _, userspaceOpts := SplitMountOptions(opts); len(userspaceOpts) > 0 {
+		f["userspaceOptions"] = userspaceOpts
 	}
 	log.WithFields(f).Info("mount command")
 
-	buf, err := exec.Command(mntCmd, mountArgs...).CombinedOutput()
+	buf, err := fs.exec(ctx, mntCmd, mountArgs...)
 	if err != nil {
 		out := string(buf)
 		log.WithFields(f).WithField("output", out).WithError(
 			err).Error("mount Failed")
 		return fmt.Errorf(
-			"mount failed: %v\nmounting arguments: %s\noutput: %s",
-			err, args, out)
+			"mount failed: %w\nmounting arguments: %s\noutput: %s",
+			wrapCmdError(err, out, mountErrors), args, out)
 	}
 	return nil
 }
 
-// unmount unmounts the target.
+var (
+	// errDuplicateUUID is returned when a filesystem cannot be mounted
+	// because a mounted filesystem has the same UUID.
+	errDuplicateUUID = errors.New("duplicate filesystem uuid")
+
+	// errUnknownFSType is returned when a filesystem cannot be mounted
+	// because its type is not registered with the kernel.
+	errUnknownFSType = errors.New("unknown filesystem type")
+
+	// mountErrors maps the output of a failed mount command to the
+	// error that describes the failure.
+	mountErrors = []cmdError{
+		{regexp.MustCompile(`(?i)duplicate uuid`), errDuplicateUUID},
+		{regexp.MustCompile(`(?i)already mounted`), ErrAlreadyMounted},
+		{regexp.MustCompile(`(?i)(?:mount point|resource|device) (?:is )?busy`),
+			errMountBusy},
+		{regexp.MustCompile(`(?i)unknown filesystem type`),
+			errUnknownFSType},
+		{regexp.MustCompile(`(?i)special device .+ does not exist`),
+			ErrDeviceNotFound},
+	}
+
+	// unmountErrors maps the output of a failed umount command to the
+	// error that describes the failure.
+	unmountErrors = []cmdError{
+		{regexp.MustCompile(`(?i)not mounted`), ErrNotMounted},
+		{regexp.MustCompile(`(?i)not currently mounted`), ErrNotMounted},
+		{regexp.MustCompile(`(?i)(?:target|device|resource) is busy`),
+			errTargetBusy},
+	}
+
+	// errTargetBusy is returned when a target cannot be unmounted
+	// because it is in use.
+	errTargetBusy = errors.New("target is busy")
+
+	// errMountBusy is returned when a mount fails because the target or
+	// source is busy.
+	errMountBusy = errors.New("mount point busy")
+)
+
+// unmount unmounts the target with the unmount helper of the type of the
+// filesystem mounted at target, if any, falling back to umount if the
+// filesystem has no helper, no helper is installed, or the mount table
+// does not include target.
 func (fs *FS) unmount(ctx context.Context, target string) error {
-	f := log.Fields{
+	m, err := fs.getTopMount(ctx, target)
+	if err != nil {
+		if !errors.Is(err, ErrNotMounted) {
+			log.WithFields(logFields(ctx, log.Fields{
+				"path": target,
+			})).WithError(err).Warn(
+				"failed to get mount, unmounting with umount")
+		}
+		return fs.doUnmount(ctx, target)
+	}
+	for _, cmd := range unmountHelpers(m) {
+		err := fs.doUnmountCmd(ctx, cmd[0], target, cmd[1:]...)
+		if err == nil || !errors.Is(err, exec.ErrNotFound) {
+			return err
+		}
+		log.WithFields(logFields(ctx, log.Fields{
+			"path": target,
+			"cmd":  cmd[0],
+		})).Debug("unmount helper not found, trying next helper")
+	}
+	return fs.doUnmount(ctx, target)
+}
+
+// unmountHelpers returns the commands tried in order to unmount m
+// before falling back to umount
+func unmountHelpers(m Info) [][]string {
+	switch {
+	case isFuseMount(m):
+		return fuseUnmountCmds
+	case nfsUnmountCmds[m.Type] != nil:
+		return [][]string{nfsUnmountCmds[m.Type]}
+	}
+	return nil
+}
+
+// unmountLazy detaches the target from the filesystem hierarchy now and
+// cleans up the references to it once it is no longer busy.
+func (fs *FS) unmountLazy(ctx context.Context, target string) error {
+	if lazyUnmountArgs == nil {
+		return ErrNotImplemented
+	}
+	return fs.doUnmount(ctx, target, lazyUnmountArgs...)
+}
+
+// doUnmount runs the umount command.
+func (fs *FS) doUnmount(
+	ctx context.Context, target string, args ...string) error {
+
+	return fs.doUnmountCmd(ctx, "umount", target, args...)
+}
+
+// doUnmountCmd runs the named unmount command.
+func (fs *FS) doUnmountCmd(
+	ctx context.Context, name, target string, args ...string) error {
+
+	args = append(args[:len(args):len(args)], target)
+	f := logFields(ctx, log.Fields{
 		"path": target,
-		"cmd":  "umount",
+		"cmd":  name,
+	})
+	if len(args) > 1 {
+		f["args"] = args
 	}
 	log.WithFields(f).Info("unmount command")
-	buf, err := exec.Command("umount", target).CombinedOutput()
+	buf, err := fs.exec(ctx, name, args...)
 	if err != nil {
 		out := string(buf)
 		f["output"] = out
 		log.WithFields(f).WithError(err).Error("unmount failed")
 		return fmt.Errorf(
-			"unmount failed: %v\nunmounting arguments: %s\nOutput: %s",
-			err, target, out)
+			"unmount failed: %w\nunmounting arguments: %s\nOutput: %s",
+			wrapCmdError(err, out, unmountErrors),
+			strings.Join(args, " "), out)
 	}
 	return nil
 }
 
+// unmountAll unmounts each of the targets, deepest first, and returns
+// the errors of the failed unmounts joined together
+func (fs *FS) unmountAll(ctx context.Context, targets []string) error {
+	sorted := append([]string(nil), targets...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return pathDepth(sorted[i]) > pathDepth(sorted[j])
+	})
+
+	var errs []error
+	for _, target := range sorted {
+		if err := fs.unmount(ctx, target); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", target, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// unmountRecursive unmounts every mount at or beneath root, deepest first,
+// and returns the errors of the failed unmounts joined together
+func (fs *FS) unmountRecursive(ctx context.Context, root string) error {
+	root = evalSymlinksOrPath(root)
+
+	mounts, err := fs.getMounts(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Mounts stacked on the same path appear in the list once for each
+	// mount, so the path is unmounted once for each mount.
+	var targets []string
+	for _, m := range mounts {
+		if isPathOrSubpath(m.Path, root) {
+			targets = append(targets, m.Path)
+		}
+	}
+	return fs.unmountTargets(ctx, targets)
+}
+
+// unmountDevice unmounts every mount of device, deepest first, and
+// returns the errors of the failed unmounts joined together
+func (fs *FS) unmountDevice(ctx context.Context, device string) error {
+	mounts, err := fs.getDevMounts(ctx, device)
+	if err != nil {
+		return err
+	}
+	var targets []string
+	for _, m := range mounts {
+		targets = append(targets, m.Path)
+	}
+	return fs.unmountTargets(ctx, targets)
+}
+
+// unmountTargets unmounts each of the targets, deepest first, falling
+// back to a lazy unmount if a target is busy, and returns the errors of
+// the failed unmounts joined together. A target that is no longer
+// mounted is ignored.
+func (fs *FS) unmountTargets(ctx context.Context, targets []string) error {
+	targets = append([]string(nil), targets...)
+	sort.SliceStable(targets, func(i, j int) bool {
+		return pathDepth(targets[i]) > pathDepth(targets[j])
+	})
+
+	var errs []error
+	for _, target := range targets {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+		err := fs.unmount(ctx, target)
+		if errors.Is(err, errTargetBusy) {
+			log.WithFields(logFields(ctx, log.Fields{
+				"path": target,
+			})).Warn(
+				"target is busy, falling back to lazy unmount")
+			err = fs.unmountLazy(ctx, target)
+		}
+
+		// A mount may have disappeared since the mount table was read.
+		if err != nil && !errors.Is(err, ErrNotMounted) {
+			errs = append(errs, fmt.Errorf("%s: %w", target, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // isBind detects whether a bind mount is being requested and determines
 // which remount options are needed. A secondary mount operation is
 // required for bind mounts as the initial operation does not apply the
@@ -105,17 +608,117 @@ func (fs *FS) isBind(ctx context.Context, opts ...string) ([]string, bool) {
 	return remountOpts, bind
 }
 
-// getDevMounts returns a slice of all mounts for dev
-func (fs *FS) getDevMounts(ctx context.Context, dev string) ([]Info, error) {
+// getDevMountsMulti returns the mounts of each of devs keyed by the
+// device as provided. The mount table is walked once.
+func (fs *FS) getDevMountsMulti(
+	ctx context.Context, devs []string) (map[string][]Info, error) {
+
+	// More than one of devs may refer to the same device, ex. a device
+	// and a symlink to it.
+	keys := map[string][]string{}
+	for _, dev := range devs {
+		real := fs.resolveDevice(ctx, Info{Device: dev})
+		keys[real] = append(keys[real], dev)
+	}
+
+	mounts := map[string][]Info{}
+	err := fs.walkMounts(ctx, func(m Info) (bool, error) {
+		if isFuseNonBlockMount(m) {
+			return false, nil
+		}
+		devKeys, ok := keys[m.Device]
+		if !ok && path.IsAbs(m.Device) {
+			devKeys = keys[fs.resolveDevice(ctx, m)]
+		}
+		for _, k := range devKeys {
+			mounts[k] = append(mounts[k], m)
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mounts, nil
+}
+
+// isDeviceMounted returns a flag indicating whether dev has any mounts.
+// The scan of the mount table ends at the first mount of dev.
+func (fs *FS) isDeviceMounted(ctx context.Context, dev string) (bool, error) {
+	dev = evalSymlinksOrPath(dev)
+
+	var mounted bool
+	err := fs.walkMounts(ctx, func(m Info) (bool, error) {
+		mounted = m.Device == dev || evalSymlinksOrPath(m.Device) == dev
+		return mounted, nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return mounted, nil
+}
+
+// isMountPoint returns a flag indicating whether a filesystem is mounted
+// at target. The scan of the mount table ends at the first mount at target.
+func (fs *FS) isMountPoint(ctx context.Context, target string) (bool, error) {
+	target = evalSymlinksOrPath(target)
+
+	var mounted bool
+	err := fs.walkMounts(ctx, func(m Info) (bool, error) {
+		mounted = m.Path == target
+		return mounted, nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return mounted, nil
+}
+
+// waitForUnmount polls the mount table until nothing is mounted at target
+// or the context is cancelled.
+func (fs *FS) waitForUnmount(
+	ctx context.Context,
+	target string,
+	pollInterval time.Duration) error {
+
+	if pollInterval <= 0 {
+		pollInterval = defaultPollInterval
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	// The mount table is read again by each poll.
+	ctx = withoutMountTable(ctx)
+	for {
+		mounted, err := fs.isMountPoint(ctx, target)
+		if err != nil {
+			return err
+		}
+		if !mounted {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("wait for unmount: %s: %w", target, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// getDevMountsWithRoot returns a slice of all mounts for dev with a root
+// that is or is beneath the provided root
+func (fs *FS) getDevMountsWithRoot(
+	ctx context.Context, dev, root string) ([]Info, error) {
 
-	allMnts, err := fs.getMounts(ctx)
+	devMnts, err := fs.getDevMounts(ctx, dev)
 	if err != nil {
 		return nil, err
 	}
 
 	var mountInfos []Info
-	for _, m := range allMnts {
-		if m.Device == dev {
+	for _, m := range devMnts {
+		if isPathOrSubpath(m.Root, root) {
 			mountInfos = append(mountInfos, m)
 		}
 	}
@@ -123,6 +726,180 @@ func (fs *FS) getDevMounts(ctx context.Context, dev string) ([]Info, error) {
 	return mountInfos, nil
 }
 
+// getTopMount returns the topmost mount at target, which is the last
+// entry in the mount table for the path. Symlinks in target are
+// evaluated first.
+func (fs *FS) getTopMount(ctx context.Context, target string) (Info, error) {
+	target = evalSymlinksOrPath(target)
+
+	mounts, err := fs.getMounts(ctx)
+	if err != nil {
+		return Info{}, err
+	}
+	for i := len(mounts) - 1; i >= 0; i-- {
+		if mounts[i].Path == target {
+			return mounts[i], nil
+		}
+	}
+	return Info{}, fmt.Errorf("%s: %w", target, ErrNotMounted)
+}
+
+// lookupTopMount returns the topmost mount at target and a flag
+// indicating whether anything is mounted at target
+func (fs *FS) lookupTopMount(
+	ctx context.Context, target string) (Info, bool, error) {
+
+	m, err := fs.getTopMount(ctx, target)
+	if err != nil {
+		if errors.Is(err, ErrNotMounted) {
+			return Info{}, false, nil
+		}
+		return Info{}, false, err
+	}
+	return m, true, nil
+}
+
+// getDeviceForPath returns the device and root of the mount that
+// contains p, which is the mount with the longest mount point that is p
+// or a parent of p. Symlinks in p are evaluated first.
+func (fs *FS) getDeviceForPath(
+	ctx context.Context, p string) (string, string, error) {
+
+	p = evalSymlinksOrPath(p)
+
+	mounts, err := fs.getMounts(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	m := findMountForPath(mounts, p)
+	if m == nil {
+		return "", "", fmt.Errorf("%s: %w", p, ErrNotMounted)
+	}
+	return m.Device, m.Root, nil
+}
+
+// findMountForPath returns the mount with the longest mount point that is
+// the path p or one of its parents, or nil if no mount contains p. Mounts
+// stacked on the same path appear later in the mount table, so the last
+// of the longest matches is the visible mount.
+func findMountForPath(mounts []Info, p string) *Info {
+	var m *Info
+	for i := range mounts {
+		if !isPathOrSubpath(p, mounts[i].Path) {
+			continue
+		}
+		if m == nil ||
+			len(path.Clean(mounts[i].Path)) >= len(path.Clean(m.Path)) {
+			m = &mounts[i]
+		}
+	}
+	return m
+}
+
+// getMountsSorted returns the mounted filesystems sorted by the depth of
+// their mount points and then by path. Mounts stacked on the same path
+// retain their order from the mount table.
+func (fs *FS) getMountsSorted(ctx context.Context) ([]Info, error) {
+	mounts, err := fs.getMounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(mounts, func(i, j int) bool {
+		di, dj := pathDepth(mounts[i].Path), pathDepth(mounts[j].Path)
+		if di != dj {
+			return di < dj
+		}
+		return mounts[i].Path < mounts[j].Path
+	})
+	return mounts, nil
+}
+
+// pathDepth returns the number of elements in p, ex. zero for "/" and
+// two for "/mnt/data".
+func pathDepth(p string) int {
+	p = path.Clean(p)
+	if p == "/" || p == "." {
+		return 0
+	}
+	return strings.Count(strings.Trim(p, "/"), "/") + 1
+}
+
+// verifyMounted returns an error if fs.VerifyMount is true and nothing
+// is mounted at target. Some kernels report success for a mount that
+// did not take, so the exit status of mount(8) is not always enough.
+func (fs *FS) verifyMounted(ctx context.Context, target string) error {
+	if !fs.VerifyMount {
+		return nil
+	}
+	if _, err := fs.getTopMount(withoutMountTable(ctx), target); err != nil {
+		log.WithFields(logFields(ctx, log.Fields{
+			"target": target,
+		})).WithError(err).Error(
+			"mount verification failed")
+		return fmt.Errorf("mount verification failed: %w", err)
+	}
+	return nil
+}
+
+// getMountAge returns the approximate amount of time the filesystem has
+// been mounted at target
+func (fs *FS) getMountAge(
+	ctx context.Context, target string) (time.Duration, error) {
+
+	m, err := fs.getTopMount(ctx, target)
+	if err != nil {
+		return 0, err
+	}
+	fi, err := os.Stat(m.Path)
+	if err != nil {
+		return 0, err
+	}
+	age := time.Since(statChangeTime(fi))
+	if age < 0 {
+		age = 0
+	}
+	return age, nil
+}
+
+// getMountKind returns the kind of the mount at target
+func (fs *FS) getMountKind(ctx context.Context, target string) (string, error) {
+	mounts, err := fs.getMounts(ctx)
+	if err != nil {
+		return "", err
+	}
+	ctx = WithMountTable(ctx, mounts)
+
+	m, err := fs.getTopMount(ctx, target)
+	if err != nil {
+		return "", err
+	}
+	fi, err := os.Stat(m.Path)
+	if err != nil {
+		return "", err
+	}
+
+	mode := fi.Mode()
+	switch {
+	case mode&os.ModeDevice != 0 && mode&os.ModeCharDevice == 0:
+		return MountKindBlock, nil
+	case !mode.IsDir(), m.Root != "" && m.Root != "/",
+		m.LooksLikeBindMount(mounts):
+		return MountKindBind, nil
+	}
+	return MountKindFilesystem, nil
+}
+
+// getMountFSType returns the type of the filesystem mounted at target
+func (fs *FS) getMountFSType(
+	ctx context.Context, target string) (string, error) {
+
+	m, err := fs.getTopMount(ctx, target)
+	if err != nil {
+		return "", err
+	}
+	return m.Type, nil
+}
+
 func (fs *FS) validateDevice(
 	ctx context.Context, source string) (string, error) {
 
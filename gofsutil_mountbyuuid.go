@@ -0,0 +1,60 @@
+package gofsutil
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// UUIDMismatchError is returned by MountByUUID when the device resolved
+// from a filesystem UUID does not contain a filesystem with that UUID,
+// ex. because the device's /dev/disk/by-uuid link is stale.
+type UUIDMismatchError struct {
+	// Device is the device resolved from the expected UUID.
+	Device string
+
+	// Expected is the expected UUID.
+	Expected string
+
+	// Actual is the UUID of the filesystem on the device.
+	Actual string
+}
+
+// Error returns the error message.
+func (e *UUIDMismatchError) Error() string {
+	return fmt.Sprintf(
+		"filesystem uuid mismatch: device=%s, expected=%s, actual=%s",
+		e.Device, e.Expected, e.Actual)
+}
+
+// resolveDeviceByUUID returns the device that contains the filesystem
+// with the expected UUID after verifying the UUID of the filesystem on
+// the device
+func (fs *FS) resolveDeviceByUUID(
+	ctx context.Context, expectedUUID string) (string, error) {
+
+	device, err := fs.getDeviceByUUID(ctx, expectedUUID)
+	if err != nil {
+		return "", err
+	}
+	actualUUID, err := fs.getFSUUID(ctx, device)
+	if err != nil {
+		return "", err
+	}
+
+	// Some filesystems, ex. vfat, have upper-case UUIDs.
+	if !strings.EqualFold(actualUUID, expectedUUID) {
+		return "", &UUIDMismatchError{
+			Device:   device,
+			Expected: expectedUUID,
+			Actual:   actualUUID,
+		}
+	}
+	log.WithFields(logFields(ctx, log.Fields{
+		"uuid":   expectedUUID,
+		"device": device,
+	})).Debug("verified filesystem uuid")
+	return device, nil
+}
@@ -0,0 +1,69 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"path"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+func TestMountByUUID(t *testing.T) {
+	const uuid = "3e6be9de-8139-11d1-9106-a43f08d823a6"
+	devRoot := newTestDevRoot(t, uuid, "data")
+
+	r := &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			if args[0] == "blkid" {
+				return uuid + "\n", nil
+			}
+			return "", nil
+		},
+	}
+	fs := &gofsutil.FS{DevRoot: devRoot, RunCommand: r.run}
+
+	if err := fs.MountByUUID(
+		context.TODO(), uuid, "/mnt/data", "ext4", "noatime"); err != nil {
+		t.Fatal(err)
+	}
+	dev := path.Join(devRoot, "sdb")
+	r.assertCommands(t,
+		"blkid -s UUID -o value "+dev,
+		"mount -t ext4 -o noatime "+dev+" /mnt/data")
+}
+
+func TestMountByUUIDMismatch(t *testing.T) {
+	const (
+		uuid  = "3e6be9de-8139-11d1-9106-a43f08d823a6"
+		other = "7d6f3b2b-0f7a-4a41-9c1e-0dcf9d6e4b8f"
+	)
+	devRoot := newTestDevRoot(t, uuid, "data")
+
+	// The by-uuid link is stale: sdb now contains another filesystem.
+	r := &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			if args[0] == "blkid" {
+				return other + "\n", nil
+			}
+			return "", nil
+		},
+	}
+	fs := &gofsutil.FS{DevRoot: devRoot, RunCommand: r.run}
+
+	err := fs.MountByUUID(context.TODO(), uuid, "/mnt/data", "ext4")
+	var mismatch *gofsutil.UUIDMismatchError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("expected UUIDMismatchError, got %v", err)
+	}
+	if mismatch.Expected != uuid || mismatch.Actual != other ||
+		mismatch.Device != path.Join(devRoot, "sdb") {
+		t.Errorf("invalid mismatch: %+v", mismatch)
+	}
+	r.assertCommands(t, "blkid -s UUID -o value "+path.Join(devRoot, "sdb"))
+
+	err = fs.MountByUUID(context.TODO(), other, "/mnt/data", "ext4")
+	if !errors.Is(err, gofsutil.ErrDeviceNotFound) {
+		t.Errorf("expected ErrDeviceNotFound, got %v", err)
+	}
+}
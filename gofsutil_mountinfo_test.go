@@ -0,0 +1,87 @@
+package gofsutil_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+const parseMountInfoData = `17 60 0:16 / /sys rw,nosuid,nodev,noexec,relatime shared:6 - sysfs sysfs rw
+25 60 0:21 / /run rw,nosuid,nodev shared:22 master:1 - tmpfs tmpfs rw,mode=755
+60 0 8:1 / / rw,relatime - ext4 /dev/sda1 rw,errors=remount-ro
+61 60 8:16 /vol\040one /mnt/my\040data rw,relatime shared:30 - xfs /dev/sdb rw,attr2
+`
+
+func TestParseMountInfo(t *testing.T) {
+	mounts, err := gofsutil.ParseMountInfo(
+		strings.NewReader(parseMountInfoData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mounts) != 4 {
+		t.Fatalf("invalid mount count: %d: %+v", len(mounts), mounts)
+	}
+
+	run := mounts[1]
+	if run.Type != "tmpfs" || run.Path != "/run" || run.Device != "tmpfs" {
+		t.Errorf("invalid mount with optional fields: %+v", run)
+	}
+	if !reflect.DeepEqual(run.SuperOpts, []string{"rw", "mode=755"}) {
+		t.Errorf("invalid super options: %v", run.SuperOpts)
+	}
+
+	data := mounts[3]
+	if data.Path != "/mnt/my data" {
+		t.Errorf("invalid unescaped path: %q", data.Path)
+	}
+	if data.Root != "/vol one" {
+		t.Errorf("invalid unescaped root: %q", data.Root)
+	}
+	if data.MajorMinor != "8:16" || data.Device != "/dev/sdb" {
+		t.Errorf("invalid mount: %+v", data)
+	}
+}
+
+func TestParseMountInfoInvalid(t *testing.T) {
+	for _, data := range []string{
+		"60 0 8:1 / / rw,relatime\n",
+		"60 0 8:1 / / rw,relatime shared:1 ext4 /dev/sda1 rw\n",
+	} {
+		if _, err := gofsutil.ParseMountInfo(
+			strings.NewReader(data)); err == nil {
+			t.Errorf("expected error: %q", data)
+		}
+	}
+}
+
+func TestParseMountTable(t *testing.T) {
+	mounts, err := gofsutil.ParseMountTable(strings.NewReader(
+		`# comment
+/dev/sda1 / ext4 rw,relatime 0 0
+tmpfs /run tmpfs rw,nosuid,nodev,mode=755 0 0
+/dev/sdb /mnt/my\040data xfs rw,attr2 0 0
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []gofsutil.Info{
+		{Device: "/dev/sda1", Path: "/", Type: "ext4",
+			Opts: []string{"rw", "relatime"}},
+		{Device: "tmpfs", Path: "/run", Type: "tmpfs",
+			Opts: []string{"rw", "nosuid", "nodev", "mode=755"}},
+		{Device: "/dev/sdb", Path: "/mnt/my data", Type: "xfs",
+			Opts: []string{"rw", "attr2"}},
+	}
+	if len(mounts) != len(exp) {
+		t.Fatalf("invalid mount count: %d: %+v", len(mounts), mounts)
+	}
+	for i, m := range mounts {
+		if m.Device != exp[i].Device || m.Path != exp[i].Path ||
+			m.Type != exp[i].Type || m.Root != "/" ||
+			!reflect.DeepEqual(m.Opts, exp[i].Opts) {
+			t.Errorf("invalid mount %d: exp=%+v, act=%+v", i, exp[i], m)
+		}
+	}
+}
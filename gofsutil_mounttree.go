@@ -0,0 +1,56 @@
+package gofsutil
+
+import "context"
+
+// MountNode is a node of the tree of mounts returned by GetMountTree.
+type MountNode struct {
+	// Info describes the mount. The Info of the node at the top of the
+	// tree is empty since that node does not describe a mount.
+	Info Info
+
+	// Children are the mounts whose parent is the mount, in the order
+	// in which they appear in the mount table.
+	Children []*MountNode
+}
+
+// getMountTree returns the tree of the mounted filesystems
+func (fs *FS) getMountTree(ctx context.Context) (*MountNode, error) {
+	mounts, err := fs.getMounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return buildMountTree(mounts), nil
+}
+
+// buildMountTree builds a tree of mounts from the ID and ParentID of
+// each mount. A mount whose parent is not in mounts, whose parent is
+// itself, or whose ID is unknown is a child of the node at the top of
+// the tree.
+func buildMountTree(mounts []Info) *MountNode {
+	var (
+		top   = &MountNode{}
+		nodes = make([]*MountNode, len(mounts))
+		byID  = map[int]*MountNode{}
+	)
+	for i, m := range mounts {
+		nodes[i] = &MountNode{Info: m}
+		if m.ID == 0 {
+			continue
+		}
+		// The kernel reuses the ID of an unmounted filesystem, so the
+		// first mount with an ID is its owner.
+		if _, ok := byID[m.ID]; !ok {
+			byID[m.ID] = nodes[i]
+		}
+	}
+	for _, n := range nodes {
+		parent := top
+		if n.Info.ID != 0 && n.Info.ParentID != n.Info.ID {
+			if p, ok := byID[n.Info.ParentID]; ok {
+				parent = p
+			}
+		}
+		parent.Children = append(parent.Children, n)
+	}
+	return top
+}
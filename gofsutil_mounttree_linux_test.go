@@ -0,0 +1,107 @@
+package gofsutil_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+const procMountTreeData = `60 1 8:1 / / rw,relatime shared:1 - ext4 /dev/sda1 rw
+17 60 0:16 / /sys rw,nosuid,nodev,noexec,relatime shared:6 - sysfs sysfs rw
+24 17 0:19 / /sys/fs/cgroup ro,nosuid,nodev,noexec shared:8 - tmpfs tmpfs ro,mode=755
+19 60 0:5 / /dev rw,nosuid shared:2 - devtmpfs devtmpfs rw,mode=755
+21 19 0:17 / /dev/shm rw,nosuid,nodev shared:3 - tmpfs tmpfs rw
+90 88 8:16 / /mnt/orphan rw,relatime - ext4 /dev/sdb rw
+91 90 8:17 / /mnt/orphan/data rw,relatime - xfs /dev/sdc rw
+`
+
+// formatMountTree returns the paths of the mounts in the tree rooted at n
+// with each child indented below its parent.
+func formatMountTree(n *gofsutil.MountNode) string {
+	var b strings.Builder
+	var walk func(*gofsutil.MountNode, int)
+	walk = func(n *gofsutil.MountNode, depth int) {
+		for _, c := range n.Children {
+			fmt.Fprintf(&b, "%s%s (%d)\n",
+				strings.Repeat("  ", depth), c.Info.Path, c.Info.ID)
+			walk(c, depth+1)
+		}
+	}
+	walk(n, 0)
+	return b.String()
+}
+
+func TestGetMountTree(t *testing.T) {
+	procRoot := newTestProcRoot(t, procMountTreeData, "self")
+
+	scanAll := func(
+		ctx context.Context,
+		entry gofsutil.Entry,
+		cache map[string]gofsutil.Entry) (gofsutil.Info, bool, error) {
+
+		return gofsutil.Info{
+			ID:       entry.ID,
+			ParentID: entry.ParentID,
+			Device:   entry.MountSource,
+			Path:     entry.MountPoint,
+			Type:     entry.FSType,
+		}, true, nil
+	}
+	fs := &gofsutil.FS{ProcRoot: procRoot, ScanEntry: scanAll}
+
+	tree, err := fs.GetMountTree(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree.Info.Path != "" {
+		t.Errorf("top node describes a mount: %+v", tree.Info)
+	}
+	exp := `/ (60)
+  /sys (17)
+    /sys/fs/cgroup (24)
+  /dev (19)
+    /dev/shm (21)
+/mnt/orphan (90)
+  /mnt/orphan/data (91)
+`
+	if act := formatMountTree(tree); act != exp {
+		t.Errorf("tree:\n%s\nexp:\n%s", act, exp)
+	}
+}
+
+func TestGetMountTreeScanEntry(t *testing.T) {
+	procRoot := newTestProcRoot(t, procMountTreeData, "self")
+
+	// The default ScanEntry omits the sysfs and tmpfs mounts, so the
+	// children of the omitted mounts become roots.
+	fs := &gofsutil.FS{ProcRoot: procRoot}
+	tree, err := fs.GetMountTree(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := `/ (60)
+  /dev (19)
+/mnt/orphan (90)
+  /mnt/orphan/data (91)
+`
+	if act := formatMountTree(tree); act != exp {
+		t.Errorf("tree:\n%s\nexp:\n%s", act, exp)
+	}
+}
+
+func TestParseMountInfoIDs(t *testing.T) {
+	mounts, err := gofsutil.ParseMountInfo(strings.NewReader(procMountTreeData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := mounts[2]; m.ID != 24 || m.ParentID != 17 {
+		t.Errorf("ID=%d, ParentID=%d, exp=24, 17", m.ID, m.ParentID)
+	}
+	if _, err := gofsutil.ParseMountInfo(strings.NewReader(
+		"x 1 8:1 / / rw - ext4 /dev/sda1 rw\n")); err == nil {
+		t.Error("expected error for invalid mount id")
+	}
+}
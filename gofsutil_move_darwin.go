@@ -0,0 +1,8 @@
+package gofsutil
+
+import "context"
+
+// moveMount moves the mount at source to target
+func (fs *FS) moveMount(ctx context.Context, source, target string) error {
+	return ErrNotImplemented
+}
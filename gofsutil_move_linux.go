@@ -0,0 +1,38 @@
+package gofsutil
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// moveMount moves the mount at source to target with "mount --move"
+func (fs *FS) moveMount(ctx context.Context, source, target string) error {
+	m, err := fs.getTopMount(ctx, source)
+	if err != nil {
+		return fmt.Errorf("move mount: %w", err)
+	}
+	if _, err := os.Stat(target); err != nil {
+		return fmt.Errorf("move mount: %w", err)
+	}
+
+	f := logFields(ctx, log.Fields{
+		"source": m.Path,
+		"target": target,
+		"fsType": m.Type,
+	})
+	log.WithFields(f).Info("move mount command")
+
+	buf, err := fs.exec(ctx, "mount", "--move", m.Path, target)
+	if err != nil {
+		out := string(buf)
+		log.WithFields(f).WithField("output", out).WithError(
+			err).Error("move mount failed")
+		return fmt.Errorf(
+			"move mount failed: %w\nsource: %s\ntarget: %s\noutput: %s",
+			wrapCmdError(err, out, mountErrors), m.Path, target, out)
+	}
+	return nil
+}
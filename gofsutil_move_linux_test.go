@@ -0,0 +1,118 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+func TestMoveMount(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("requires root")
+	}
+	root, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	if err := gofsutil.EvalSymlinks(context.TODO(), &root); err != nil {
+		t.Fatal(err)
+	}
+
+	// A mount cannot be moved from beneath a shared mount, so the
+	// mounts are created beneath a private tmpfs.
+	if err := unix.Mount("tmpfs", root, "tmpfs", 0, "size=1m"); err != nil {
+		t.Skipf("mount tmpfs: %v", err)
+	}
+	defer unix.Unmount(root, unix.MNT_DETACH)
+	if err := unix.Mount("", root, "", unix.MS_PRIVATE, ""); err != nil {
+		t.Skipf("make private: %v", err)
+	}
+
+	src, dst := path.Join(root, "src"), path.Join(root, "dst")
+	for _, dir := range []string{src, dst} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := unix.Mount("tmpfs", src, "tmpfs", 0, "size=1m"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path.Join(src, "a"), nil, 0644); err != nil {
+		unix.Unmount(src, 0)
+		t.Fatal(err)
+	}
+
+	// The default scan function ignores tmpfs mounts.
+	scanAll := func(
+		ctx context.Context,
+		entry gofsutil.Entry,
+		cache map[string]gofsutil.Entry) (gofsutil.Info, bool, error) {
+
+		return gofsutil.Info{
+			Device: entry.MountSource,
+			Path:   entry.MountPoint,
+			Type:   entry.FSType,
+		}, true, nil
+	}
+	fs := &gofsutil.FS{ScanEntry: scanAll}
+
+	if err := fs.MoveMount(context.TODO(), src, dst); err != nil {
+		unix.Unmount(src, 0)
+		t.Fatal(err)
+	}
+	defer unix.Unmount(dst, 0)
+
+	mounts, err := fs.GetMounts(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, m := range mounts {
+		switch m.Path {
+		case src:
+			t.Errorf("mount still at source: %+v", m)
+		case dst:
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("mount not at target: %s", dst)
+	}
+	if _, err := os.Stat(path.Join(dst, "a")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMoveMountNotMounted(t *testing.T) {
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{RunCommand: r.run}
+	ctx := gofsutil.WithMountTable(context.TODO(), nil)
+
+	err := fs.MoveMount(ctx, "/mnt/src", "/mnt/dst")
+	if !errors.Is(err, gofsutil.ErrNotMounted) {
+		t.Errorf("expected ErrNotMounted: %v", err)
+	}
+	r.assertCommands(t)
+}
+
+func TestMoveMountTargetNotExist(t *testing.T) {
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{RunCommand: r.run}
+	ctx := gofsutil.WithMountTable(context.TODO(), []gofsutil.Info{
+		{Device: "tmpfs", Path: "/mnt/src", Type: "tmpfs"},
+	})
+
+	err := fs.MoveMount(ctx, "/mnt/src", "/does/not/exist")
+	if !os.IsNotExist(errors.Unwrap(err)) {
+		t.Errorf("expected not exist error: %v", err)
+	}
+	r.assertCommands(t)
+}
@@ -0,0 +1,16 @@
+package gofsutil
+
+import "context"
+
+// getMtabMounts returns the mounts recorded in the mtab file
+func (fs *FS) getMtabMounts(ctx context.Context) ([]Info, error) {
+	return nil, ErrNotImplemented
+}
+
+// compareMountSources returns the mounts recorded in the mtab file but
+// not the mount table of the kernel and vice versa
+func (fs *FS) compareMountSources(
+	ctx context.Context) (onlyInMtab, onlyInProc []Info, err error) {
+
+	return nil, nil, ErrNotImplemented
+}
@@ -0,0 +1,62 @@
+package gofsutil
+
+import (
+	"bytes"
+	"context"
+	"strings"
+)
+
+// getMtabMounts returns the mounts recorded in the mtab file
+func (fs *FS) getMtabMounts(ctx context.Context) ([]Info, error) {
+	buf, err := readFileContext(ctx, fs.etcPath("mtab"))
+	if err != nil {
+		return nil, err
+	}
+
+	var mounts []Info
+	err = walkMountTableFrom(
+		ctx, bytes.NewReader(buf), fs.scanEntry(ctx),
+		func(info Info) (bool, error) {
+			mounts = append(mounts, info)
+			return false, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return fs.filterMounts(mounts), nil
+}
+
+// compareMountSources returns the mounts recorded in the mtab file but
+// not the mount table of the kernel and vice versa
+func (fs *FS) compareMountSources(
+	ctx context.Context) (onlyInMtab, onlyInProc []Info, err error) {
+
+	mtab, err := fs.getMtabMounts(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	proc, err := fs.getMounts(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return diffMounts(mtab, proc), diffMounts(proc, mtab), nil
+}
+
+// diffMounts returns the mounts in a that have no mount in b with the
+// same device, path, and type
+func diffMounts(a, b []Info) []Info {
+	key := func(m Info) string {
+		return strings.Join([]string{m.Device, m.Path, m.Type}, "\x00")
+	}
+	set := make(map[string]struct{}, len(b))
+	for _, m := range b {
+		set[key(m)] = struct{}{}
+	}
+	var diff []Info
+	for _, m := range a {
+		if _, ok := set[key(m)]; !ok {
+			diff = append(diff, m)
+		}
+	}
+	return diff
+}
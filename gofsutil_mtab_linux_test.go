@@ -0,0 +1,61 @@
+package gofsutil_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+const mtabProcMountInfoData = `60 1 253:0 / / rw,relatime shared:1 - xfs /dev/sda1 rw
+72 60 8:16 / /mnt/a rw,relatime shared:28 - ext4 /dev/sdb rw
+73 60 8:32 / /mnt/c rw,relatime shared:29 - xfs /dev/sdd rw
+`
+
+const mtabData = `/dev/sda1 / xfs rw,relatime 0 0
+/dev/sdb /mnt/a ext4 rw,relatime 0 0
+# a stale entry for a filesystem that has since been unmounted
+/dev/sdc /mnt/b ext4 rw,noatime 0 0
+`
+
+func TestCompareMountSources(t *testing.T) {
+	procRoot := newTestProcRoot(t, mtabProcMountInfoData, "self")
+
+	etcRoot, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(etcRoot)
+	if err := ioutil.WriteFile(
+		path.Join(etcRoot, "mtab"), []byte(mtabData), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fs := &gofsutil.FS{ProcRoot: procRoot, EtcRoot: etcRoot}
+
+	mtab, err := fs.GetMtabMounts(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mtab) != 3 {
+		t.Fatalf("invalid mtab mount count: exp=3, act=%d", len(mtab))
+	}
+	if m := mtab[2]; m.Device != "/dev/sdc" || m.Path != "/mnt/b" ||
+		m.Type != "ext4" || m.Opts[1] != "noatime" {
+		t.Errorf("invalid mtab mount: %+v", m)
+	}
+
+	onlyInMtab, onlyInProc, err := fs.CompareMountSources(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(onlyInMtab) != 1 || onlyInMtab[0].Path != "/mnt/b" {
+		t.Errorf("invalid mounts only in mtab: %+v", onlyInMtab)
+	}
+	if len(onlyInProc) != 1 || onlyInProc[0].Path != "/mnt/c" {
+		t.Errorf("invalid mounts only in proc: %+v", onlyInProc)
+	}
+}
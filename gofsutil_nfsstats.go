@@ -0,0 +1,323 @@
+package gofsutil
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NFSStats are the statistics of an NFS mount as reported by
+// "/proc/<pid>/mountstats".
+type NFSStats struct {
+	// Device is the source of the mount, ex. "host:/export".
+	Device string
+
+	// Path is the path at which the filesystem is mounted.
+	Path string
+
+	// Type is the filesystem type, ex. "nfs" or "nfs4".
+	Type string
+
+	// Age is the time elapsed since the filesystem was mounted.
+	Age time.Duration
+
+	// Bytes are the byte counters of the mount.
+	Bytes NFSByteStats
+
+	// Transport are the statistics of the mount's RPC transport.
+	Transport NFSTransportStats
+
+	// Operations are the statistics of each RPC operation keyed by the
+	// name of the operation, ex. "READ" or "GETATTR".
+	Operations map[string]NFSOperationStats
+
+	// Retransmissions is the number of times an RPC request of any
+	// operation was retransmitted, ex. after a timeout.
+	Retransmissions uint64
+}
+
+// NFSByteStats are the byte counters of an NFS mount.
+type NFSByteStats struct {
+	// NormalRead is the number of bytes read by applications with
+	// read(2).
+	NormalRead uint64
+
+	// NormalWrite is the number of bytes written by applications with
+	// write(2).
+	NormalWrite uint64
+
+	// DirectRead is the number of bytes read from files opened with
+	// O_DIRECT.
+	DirectRead uint64
+
+	// DirectWrite is the number of bytes written to files opened with
+	// O_DIRECT.
+	DirectWrite uint64
+
+	// ServerRead is the number of bytes read from the server.
+	ServerRead uint64
+
+	// ServerWrite is the number of bytes written to the server.
+	ServerWrite uint64
+
+	// ReadPages is the number of pages read.
+	ReadPages uint64
+
+	// WritePages is the number of pages written.
+	WritePages uint64
+}
+
+// NFSTransportStats are the statistics of the RPC transport of an NFS
+// mount.
+type NFSTransportStats struct {
+	// Protocol is the transport protocol, ex. "tcp" or "udp".
+	Protocol string
+
+	// Sends is the number of RPC requests sent.
+	Sends uint64
+
+	// Receives is the number of RPC replies received.
+	Receives uint64
+
+	// BadXIDs is the number of replies received that did not match a
+	// request.
+	BadXIDs uint64
+}
+
+// NFSOperationStats are the statistics of an RPC operation of an NFS
+// mount.
+type NFSOperationStats struct {
+	// Requests is the number of requests of the operation.
+	Requests uint64
+
+	// Transmissions is the number of times a request was transmitted,
+	// including retransmissions.
+	Transmissions uint64
+
+	// MajorTimeouts is the number of requests that timed out.
+	MajorTimeouts uint64
+
+	// BytesSent is the number of bytes sent, including RPC headers.
+	BytesSent uint64
+
+	// BytesReceived is the number of bytes received, including RPC
+	// headers.
+	BytesReceived uint64
+
+	// QueueTime is the cumulative time requests waited to be sent.
+	QueueTime time.Duration
+
+	// ResponseTime is the cumulative round trip time of requests.
+	ResponseTime time.Duration
+
+	// TotalTime is the cumulative time from the creation of requests
+	// to their completion.
+	TotalTime time.Duration
+
+	// Errors is the number of requests that completed with an error.
+	// Kernels older than 5.3 do not report errors.
+	Errors uint64
+}
+
+// NotNFSMountError is returned by GetNFSMountStats when the filesystem
+// mounted at a target is not an NFS filesystem.
+type NotNFSMountError struct {
+	// Path is the path at which the filesystem is mounted.
+	Path string
+
+	// Type is the filesystem type.
+	Type string
+}
+
+// Error returns the error message.
+func (e *NotNFSMountError) Error() string {
+	return fmt.Sprintf("not an nfs mount: path=%s, fsType=%s", e.Path, e.Type)
+}
+
+// isNFSType returns a flag indicating whether fsType is an NFS
+// filesystem type
+func isNFSType(fsType string) bool {
+	return fsType == "nfs" || fsType == "nfs4"
+}
+
+// parseNFSMountStats returns the statistics of the filesystem mounted at
+// target from the contents of "/proc/<pid>/mountstats". Each mount is
+// described by a line of the form:
+//
+//	device host:/export mounted on /mnt/nfs with fstype nfs4 statvers=1.1
+//
+// followed by the indented statistics of NFS mounts. The statistics of
+// the last of the mounts stacked on target are returned.
+func parseNFSMountStats(r io.Reader, target string) (NFSStats, error) {
+	var (
+		stats   NFSStats
+		found   bool
+		current bool
+		perOp   bool
+	)
+	scan := bufio.NewScanner(r)
+	for scan.Scan() {
+		line := scan.Text()
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "device" {
+			current = len(fields) >= 8 && fields[2] == "mounted" &&
+				fields[3] == "on" && fields[5] == "with" &&
+				path.Clean(unescapeOctal(fields[4])) == target
+			if current {
+				found, perOp = true, false
+				stats = NFSStats{
+					Device: unescapeOctal(fields[1]),
+					Path:   unescapeOctal(fields[4]),
+					Type:   fields[7],
+				}
+			}
+			continue
+		}
+		if !current || !isNFSType(stats.Type) {
+			continue
+		}
+		if err := parseNFSMountStatsLine(&stats, fields, &perOp); err != nil {
+			return NFSStats{}, fmt.Errorf("%s: %v: %q", target, err, line)
+		}
+	}
+	if err := scan.Err(); err != nil {
+		return NFSStats{}, err
+	}
+	if !found {
+		return NFSStats{}, fmt.Errorf("%s: %w", target, ErrNotMounted)
+	}
+	if !isNFSType(stats.Type) {
+		return NFSStats{}, &NotNFSMountError{Path: stats.Path, Type: stats.Type}
+	}
+	for _, op := range stats.Operations {
+		if op.Transmissions > op.Requests {
+			stats.Retransmissions += op.Transmissions - op.Requests
+		}
+	}
+	return stats, nil
+}
+
+// parseNFSMountStatsLine parses a line of the statistics of an NFS mount.
+// The lines that follow "per-op statistics" are the statistics of each
+// RPC operation.
+func parseNFSMountStatsLine(
+	stats *NFSStats, fields []string, perOp *bool) error {
+
+	switch fields[0] {
+	case "age:":
+		if len(fields) < 2 {
+			return fmt.Errorf("invalid age")
+		}
+		age, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return err
+		}
+		stats.Age = time.Duration(age) * time.Second
+		return nil
+	case "bytes:":
+		v, err := parseUints(fields[1:], 8)
+		if err != nil {
+			return err
+		}
+		stats.Bytes = NFSByteStats{
+			NormalRead:  v[0],
+			NormalWrite: v[1],
+			DirectRead:  v[2],
+			DirectWrite: v[3],
+			ServerRead:  v[4],
+			ServerWrite: v[5],
+			ReadPages:   v[6],
+			WritePages:  v[7],
+		}
+		return nil
+	case "xprt:":
+		return parseNFSTransportStats(&stats.Transport, fields[1:])
+	case "per-op":
+		*perOp = true
+		return nil
+	}
+	if !*perOp || !strings.HasSuffix(fields[0], ":") {
+		return nil
+	}
+
+	// The ninth counter, the number of errors, was added in 5.3.
+	v, err := parseUints(fields[1:], 8)
+	if err != nil {
+		return err
+	}
+	op := NFSOperationStats{
+		Requests:      v[0],
+		Transmissions: v[1],
+		MajorTimeouts: v[2],
+		BytesSent:     v[3],
+		BytesReceived: v[4],
+		QueueTime:     time.Duration(v[5]) * time.Millisecond,
+		ResponseTime:  time.Duration(v[6]) * time.Millisecond,
+		TotalTime:     time.Duration(v[7]) * time.Millisecond,
+	}
+	if len(v) > 8 {
+		op.Errors = v[8]
+	}
+	if stats.Operations == nil {
+		stats.Operations = map[string]NFSOperationStats{}
+	}
+	stats.Operations[strings.TrimSuffix(fields[0], ":")] = op
+	return nil
+}
+
+// parseNFSTransportStats parses the counters of the "xprt:" line of the
+// statistics of an NFS mount. The position of the counters depends on
+// the protocol:
+//
+//	tcp port bind_count connect_count connect_time idle_time sends recvs bad_xids ...
+//	udp port bind_count sends recvs bad_xids ...
+//
+// The counters of other protocols, ex. rdma, are not parsed.
+func parseNFSTransportStats(xprt *NFSTransportStats, fields []string) error {
+	if len(fields) == 0 {
+		return fmt.Errorf("invalid xprt")
+	}
+	xprt.Protocol = fields[0]
+	var offset int
+	switch xprt.Protocol {
+	case "tcp":
+		offset = 5
+	case "udp":
+		offset = 2
+	default:
+		return nil
+	}
+	v, err := parseUints(fields[1:], offset+3)
+	if err != nil {
+		return err
+	}
+	xprt.Sends = v[offset]
+	xprt.Receives = v[offset+1]
+	xprt.BadXIDs = v[offset+2]
+	return nil
+}
+
+// parseUints parses the provided fields as unsigned integers. An error is
+// returned if there are fewer than min fields.
+func parseUints(fields []string, min int) ([]uint64, error) {
+	if len(fields) < min {
+		return nil, fmt.Errorf(
+			"invalid field count: exp>=%d, act=%d", min, len(fields))
+	}
+	v := make([]uint64, len(fields))
+	for i, f := range fields {
+		n, err := strconv.ParseUint(f, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		v[i] = n
+	}
+	return v, nil
+}
@@ -0,0 +1,10 @@
+package gofsutil
+
+import "context"
+
+// getNFSMountStats returns the statistics of the NFS mount at target
+func (fs *FS) getNFSMountStats(
+	ctx context.Context, target string) (NFSStats, error) {
+
+	return NFSStats{}, ErrNotImplemented
+}
@@ -0,0 +1,17 @@
+package gofsutil
+
+import (
+	"bytes"
+	"context"
+)
+
+// getNFSMountStats returns the statistics of the NFS mount at target
+func (fs *FS) getNFSMountStats(
+	ctx context.Context, target string) (NFSStats, error) {
+
+	buf, err := readFileContext(ctx, fs.procPath("self", "mountstats"))
+	if err != nil {
+		return NFSStats{}, err
+	}
+	return parseNFSMountStats(bytes.NewReader(buf), evalSymlinksOrPath(target))
+}
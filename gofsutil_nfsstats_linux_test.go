@@ -0,0 +1,148 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+const procMountStatsData = `device sysfs mounted on /sys with fstype sysfs
+device proc mounted on /proc with fstype proc
+device /dev/sda1 mounted on / with fstype ext4
+device nfs.example.com:/export/data mounted on /mnt/nfs with fstype nfs4 statvers=1.1
+	opts:	rw,vers=4.2,rsize=1048576,wsize=1048576,namlen=255,acregmin=3,acregmax=60,acdirmin=30,acdirmax=60,hard,proto=tcp,timeo=600,retrans=2,sec=sys,clientaddr=10.0.0.5,local_lock=none
+	age:	86400
+	impl_id:	name='',domain='',date='0,0'
+	caps:	caps=0x3fffff,wtmult=512,dtsize=1048576,bsize=0,namlen=255
+	nfsv4:	bm0=0xfdffbfff,bm1=0xf9be3e,bm2=0x68800,acl=0x3,sessions,pnfs=not configured,lease_time=90,lease_expired=0
+	sec:	flavor=1,pseudoflavor=1
+	events:	52 1038 0 12 31 22 1512 4096 0 3 8192 2048 0 30 0 0 0 0 0 0 0 0 0 0 0 0 0
+	bytes:	104857600 52428800 0 4096 104861696 52432896 25601 12801
+	RPC iostats version: 1.1  p/v: 100003/4 (nfs)
+	xprt:	tcp 785 1 2 0 12 31337 31330 0 31345 0 2 0 0
+	per-op statistics
+	        NULL: 1 1 0 44 24 0 0 0 0
+	        READ: 100 103 3 14400 104873696 10 2500 2600 0
+	       WRITE: 50 51 1 52440000 8000 5 1800 1900 1
+	      COMMIT: 5 5 0 920 520 0 40 41 0
+	     GETATTR: 1000 1000 0 180000 240000 2 300 320 0
+device 10.0.0.9:/legacy mounted on /mnt/legacy with fstype nfs statvers=1.1
+	age:	60
+	bytes:	1 2 3 4 5 6 7 8
+	xprt:	udp 0 0 10 10 0 10 0 0 0
+	per-op statistics
+	        READ: 10 12 2 1000 2000 1 10 12
+`
+
+func newTestMountStatsProcRoot(t *testing.T) string {
+	procRoot := newTestProcRoot(t, "", "self")
+	if err := ioutil.WriteFile(
+		path.Join(procRoot, "self", "mountstats"),
+		[]byte(procMountStatsData), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return procRoot
+}
+
+func TestGetNFSMountStats(t *testing.T) {
+	procRoot := newTestMountStatsProcRoot(t)
+	fs := &gofsutil.FS{ProcRoot: procRoot}
+
+	stats, err := fs.GetNFSMountStats(context.TODO(), "/mnt/nfs/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Device != "nfs.example.com:/export/data" ||
+		stats.Path != "/mnt/nfs" || stats.Type != "nfs4" {
+		t.Errorf("invalid mount: %+v", stats)
+	}
+	if stats.Age != 24*time.Hour {
+		t.Errorf("age=%v, exp=24h", stats.Age)
+	}
+	expBytes := gofsutil.NFSByteStats{
+		NormalRead:  104857600,
+		NormalWrite: 52428800,
+		DirectWrite: 4096,
+		ServerRead:  104861696,
+		ServerWrite: 52432896,
+		ReadPages:   25601,
+		WritePages:  12801,
+	}
+	if stats.Bytes != expBytes {
+		t.Errorf("bytes=%+v, exp=%+v", stats.Bytes, expBytes)
+	}
+	expXprt := gofsutil.NFSTransportStats{
+		Protocol: "tcp", Sends: 31337, Receives: 31330,
+	}
+	if stats.Transport != expXprt {
+		t.Errorf("xprt=%+v, exp=%+v", stats.Transport, expXprt)
+	}
+	if stats.Retransmissions != 4 {
+		t.Errorf("retransmissions=%d, exp=4", stats.Retransmissions)
+	}
+	expRead := gofsutil.NFSOperationStats{
+		Requests:      100,
+		Transmissions: 103,
+		MajorTimeouts: 3,
+		BytesSent:     14400,
+		BytesReceived: 104873696,
+		QueueTime:     10 * time.Millisecond,
+		ResponseTime:  2500 * time.Millisecond,
+		TotalTime:     2600 * time.Millisecond,
+	}
+	if op := stats.Operations["READ"]; op != expRead {
+		t.Errorf("READ=%+v, exp=%+v", op, expRead)
+	}
+	if op := stats.Operations["WRITE"]; op.Errors != 1 {
+		t.Errorf("WRITE errors=%d, exp=1", op.Errors)
+	}
+	if n := len(stats.Operations); n != 5 {
+		t.Errorf("len(operations)=%d, exp=5", n)
+	}
+}
+
+func TestGetNFSMountStatsUDP(t *testing.T) {
+	procRoot := newTestMountStatsProcRoot(t)
+	fs := &gofsutil.FS{ProcRoot: procRoot}
+
+	stats, err := fs.GetNFSMountStats(context.TODO(), "/mnt/legacy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expXprt := gofsutil.NFSTransportStats{
+		Protocol: "udp", Sends: 10, Receives: 10,
+	}
+	if stats.Transport != expXprt {
+		t.Errorf("xprt=%+v, exp=%+v", stats.Transport, expXprt)
+	}
+	if stats.Retransmissions != 2 {
+		t.Errorf("retransmissions=%d, exp=2", stats.Retransmissions)
+	}
+	if stats.Bytes.WritePages != 8 {
+		t.Errorf("write pages=%d, exp=8", stats.Bytes.WritePages)
+	}
+}
+
+func TestGetNFSMountStatsErrors(t *testing.T) {
+	procRoot := newTestMountStatsProcRoot(t)
+	fs := &gofsutil.FS{ProcRoot: procRoot}
+
+	_, err := fs.GetNFSMountStats(context.TODO(), "/sys")
+	var notNFS *gofsutil.NotNFSMountError
+	if !errors.As(err, &notNFS) {
+		t.Fatalf("expected NotNFSMountError: %v", err)
+	}
+	if notNFS.Path != "/sys" || notNFS.Type != "sysfs" {
+		t.Errorf("invalid error: %+v", notNFS)
+	}
+
+	_, err = fs.GetNFSMountStats(context.TODO(), "/mnt/none")
+	if !errors.Is(err, gofsutil.ErrNotMounted) {
+		t.Errorf("expected ErrNotMounted: %v", err)
+	}
+}
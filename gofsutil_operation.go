@@ -0,0 +1,43 @@
+package gofsutil
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// operationIDField is the name of the log field that holds the operation
+// ID attached to a context with WithOperationID.
+const operationIDField = "operationID"
+
+// operationIDKey is the context key for an operation ID attached to a
+// context with WithOperationID.
+type operationIDKey struct{}
+
+// WithOperationID returns a copy of ctx with the provided operation ID,
+// ex. a request or trace ID, attached. The log entries emitted for an
+// operation invoked with ctx include the ID in the "operationID" field,
+// so a logrus hook may correlate the entries of a single operation, ex.
+// the lsblk, mkfs, and mount commands executed by FormatAndMount. The
+// PreMountHook and PostMountHook of an FS may read the ID from their
+// context with OperationID.
+func WithOperationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, operationIDKey{}, id)
+}
+
+// OperationID returns the operation ID attached to ctx with
+// WithOperationID. A false value is returned if ctx does not have an
+// operation ID.
+func OperationID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(operationIDKey{}).(string)
+	return id, ok
+}
+
+// logFields returns f with the operation ID attached to ctx, if any,
+// added to it
+func logFields(ctx context.Context, f log.Fields) log.Fields {
+	if id, ok := OperationID(ctx); ok {
+		f[operationIDField] = id
+	}
+	return f
+}
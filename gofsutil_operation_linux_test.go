@@ -0,0 +1,135 @@
+package gofsutil_test
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+// testLogHook records the log entries emitted while it is installed.
+type testLogHook struct {
+	sync.Mutex
+	entries []*log.Entry
+}
+
+func (h *testLogHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (h *testLogHook) Fire(e *log.Entry) error {
+	h.Lock()
+	h.entries = append(h.entries, e)
+	h.Unlock()
+	return nil
+}
+
+// installTestLogHook installs a hook that records the log entries of
+// all levels and returns a function that removes it.
+func installTestLogHook() (*testLogHook, func()) {
+	var (
+		h      = &testLogHook{}
+		logger = log.StandardLogger()
+		hooks  = logger.Hooks
+		level  = logger.Level
+	)
+	logger.Hooks = log.LevelHooks{}
+	logger.Hooks.Add(h)
+	log.SetLevel(log.DebugLevel)
+	return h, func() {
+		logger.Hooks = hooks
+		log.SetLevel(level)
+	}
+}
+
+func TestWithOperationID(t *testing.T) {
+	h, uninstall := installTestLogHook()
+	defer uninstall()
+
+	r := newTestFormatRunner("")
+	fs := &gofsutil.FS{
+		RunCommand: r.run,
+		ProcRoot:   newTestRootProcRoot(t),
+	}
+	ctx := gofsutil.WithOperationID(context.TODO(), "op-1")
+
+	if id, ok := gofsutil.OperationID(ctx); !ok || id != "op-1" {
+		t.Fatalf("invalid operation ID: %q", id)
+	}
+	if err := fs.FormatAndMount(ctx, "/dev/sdb", "/mnt", "ext4"); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		cmds     []string
+		messages = map[string]bool{}
+	)
+	for _, e := range h.entries {
+		id, ok := e.Data["operationID"]
+		if !ok {
+			continue
+		}
+		if id != "op-1" {
+			t.Errorf("invalid operation ID: %s: %v", e.Message, id)
+		}
+		if e.Message == "executing command" {
+			cmds = append(cmds, e.Data["cmd"].(string))
+		}
+		messages[e.Message] = true
+	}
+
+	// Each of the commands executed by FormatAndMount is tied to the
+	// operation.
+	exp := []string{"mount", "lsblk", "mkfs.ext4", "mount"}
+	if !reflect.DeepEqual(cmds, exp) {
+		t.Errorf("invalid commands: exp=%v, act=%v", exp, cmds)
+	}
+	for _, msg := range []string{
+		"attempting to mount disk",
+		"checking if disk is formatted using lsblk",
+		"disk appears unformatted, attempting format",
+		"mount command",
+	} {
+		if !messages[msg] {
+			t.Errorf("missing operation ID: %s", msg)
+		}
+	}
+
+	// The entries of an operation without an ID do not have the field.
+	h.entries = nil
+	if err := fs.FormatAndMount(
+		context.TODO(), "/dev/sdc", "/mnt", "ext4"); err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range h.entries {
+		if id, ok := e.Data["operationID"]; ok {
+			t.Errorf("unexpected operation ID: %s: %v", e.Message, id)
+		}
+	}
+
+	// The retry of a mount is tied to the operation.
+	h.entries = nil
+	r = newTestDuplicateUUIDRunner()
+	fs.RunCommand = r.run
+	fs.XFSAutoNoUUID = true
+	if err := fs.Mount(ctx, "/dev/sdc", "/mnt", "xfs"); err != nil {
+		t.Fatal(err)
+	}
+	var retried bool
+	for _, e := range h.entries {
+		if e.Message != "duplicate xfs uuid, retrying with nouuid" {
+			continue
+		}
+		retried = true
+		if id := e.Data["operationID"]; id != "op-1" {
+			t.Errorf("invalid operation ID: %s: %v", e.Message, id)
+		}
+	}
+	if !retried {
+		t.Error("mount not retried with nouuid")
+	}
+}
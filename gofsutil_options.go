@@ -0,0 +1,428 @@
+package gofsutil
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// KernelDefaultMountOptions are the mount options the kernel or mount(8)
+// may add to, or omit from, a mount's options regardless of the options
+// that were requested. CompareMountOptions ignores these options, and
+// callers may modify the list to suit the filesystems they manage.
+var KernelDefaultMountOptions = []string{
+	"defaults",
+	"rw",
+	"relatime",
+	"seclabel",
+	"attr2",
+	"inode64",
+	"logbufs=8",
+	"logbsize=32k",
+	"noquota",
+	"data=ordered",
+	"errors=continue",
+	"space_cache",
+	"space_cache=v2",
+}
+
+// CompareMountOptions compares the desired mount options with the
+// actual options of a mount and returns the desired options missing
+// from the actual options and the actual options that were not desired.
+// Options in KernelDefaultMountOptions are ignored.
+func CompareMountOptions(desired, actual []string) (missing, extra []string) {
+	ignore := toStringSet(KernelDefaultMountOptions)
+	desiredSet := toStringSet(desired)
+	actualSet := toStringSet(actual)
+
+	diff := func(a []string, b map[string]struct{}) []string {
+		var (
+			d    []string
+			seen = map[string]struct{}{}
+		)
+		for _, o := range a {
+			if _, ok := ignore[o]; ok || o == "" {
+				continue
+			}
+			if _, ok := b[o]; ok {
+				continue
+			}
+			if _, ok := seen[o]; ok {
+				continue
+			}
+			seen[o] = struct{}{}
+			d = append(d, o)
+		}
+		return d
+	}
+
+	return diff(desired, actualSet), diff(actual, desiredSet)
+}
+
+// toStringSet returns a set of the provided strings.
+func toStringSet(a []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(a))
+	for _, s := range a {
+		set[s] = struct{}{}
+	}
+	return set
+}
+
+// cloneMountOptions returns the options of the mount at target that
+// differ from the kernel defaults
+func (fs *FS) cloneMountOptions(
+	ctx context.Context, target string) ([]string, error) {
+
+	m, err := fs.getTopMount(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	return cloneMountInfoOptions(m), nil
+}
+
+// cloneMountInfoOptions returns the per super block options of m
+// followed by its per-mount options, which take precedence, resolved
+// with ResolveMountOptions and without the options in
+// KernelDefaultMountOptions.
+func cloneMountInfoOptions(m Info) []string {
+	ignore := toStringSet(KernelDefaultMountOptions)
+	opts := ResolveMountOptions(append(
+		append([]string(nil), m.SuperOpts...), m.Opts...))
+	cloned := opts[:0]
+	for _, o := range opts {
+		if _, ok := ignore[o]; !ok {
+			cloned = append(cloned, o)
+		}
+	}
+	return cloned
+}
+
+// userspaceMountOptions are the mount options that are interpreted by
+// mount(8), fstab, or systemd rather than the kernel.
+var userspaceMountOptions = map[string]struct{}{
+	"_netdev": {},
+	"nofail":  {},
+	"auto":    {},
+	"noauto":  {},
+}
+
+// IsUserspaceMountOption returns a flag indicating whether opt is a mount
+// option that is interpreted by userspace rather than the kernel, ex.
+// "_netdev", "nofail", a "comment=" option, or an "x-" option such as
+// "x-systemd.requires=iscsid.service".
+func IsUserspaceMountOption(opt string) bool {
+	if strings.HasPrefix(opt, "x-") || strings.HasPrefix(opt, "comment=") {
+		return true
+	}
+	_, ok := userspaceMountOptions[opt]
+	return ok
+}
+
+// SplitMountOptions separates the options the kernel interprets from the
+// options interpreted by userspace, ex. "_netdev" or "x-systemd.*". The
+// kernel rejects the latter, so they must not be passed to mount(2), but
+// they should be preserved when the options are recorded, ex. in fstab.
+// The order of the options is retained.
+func SplitMountOptions(opts []string) (kernel, userspace []string) {
+	for _, o := range opts {
+		if IsUserspaceMountOption(o) {
+			userspace = append(userspace, o)
+		} else {
+			kernel = append(kernel, o)
+		}
+	}
+	return kernel, userspace
+}
+
+// ResolveMountOptions returns the minimal list of options equivalent to
+// opts, which are applied left to right so that a later option overrides
+// an earlier one that it conflicts with, ex. "ro,rw" resolves to "rw" and
+// "noatime,atime" to "atime". Options conflict if they are the same
+// option, possibly with different values, ex. "mode=0755,mode=0700", if
+// one is the "no" form of the other, ex. "nodev,dev", or if they are
+// mutually exclusive, ex. "relatime,strictatime". Empty options and
+// exact duplicates are removed. The options interpreted by userspace, ex.
+// "x-systemd.requires=", may be repeated and so only their exact
+// duplicates are removed. The remaining options retain their order.
+func ResolveMountOptions(opts []string) []string {
+	keep := make([]bool, len(opts))
+	for i := len(opts) - 1; i >= 0; i-- {
+		o := opts[i]
+		if o == "" {
+			continue
+		}
+		keep[i] = true
+		for j := i + 1; j < len(opts); j++ {
+			if !keep[j] {
+				continue
+			}
+			if o == opts[j] || (!IsUserspaceMountOption(o) &&
+				!IsUserspaceMountOption(opts[j]) &&
+				mountOptsConflict(o, opts[j])) {
+
+				keep[i] = false
+				break
+			}
+		}
+	}
+	var resolved []string
+	for i, o := range opts {
+		if keep[i] {
+			resolved = append(resolved, o)
+		}
+	}
+	return resolved
+}
+
+// MountFlags are the common mount flags of a mount.
+type MountFlags struct {
+	// ReadOnly is true if the mount is read-only ("ro").
+	ReadOnly bool
+
+	// NoDev is true if device files are not interpreted ("nodev").
+	NoDev bool
+
+	// NoSuid is true if set-user-ID and set-group-ID bits are ignored
+	// ("nosuid").
+	NoSuid bool
+
+	// NoExec is true if binaries may not be executed ("noexec").
+	NoExec bool
+
+	// NoAtime is true if access times are not updated ("noatime").
+	NoAtime bool
+
+	// RelAtime is true if access times are updated relative to the
+	// modify or change times ("relatime").
+	RelAtime bool
+
+	// NoDirAtime is true if the access times of directories are not
+	// updated ("nodiratime").
+	NoDirAtime bool
+}
+
+// Flags returns the common mount flags derived from the mount's options.
+// The options are applied in order, so a later option overrides an
+// earlier one, ex. "ro,rw" is read-write, and "defaults" resets the
+// flags it implies: rw, suid, dev, and exec.
+func (i Info) Flags() MountFlags {
+	var f MountFlags
+	for _, o := range i.Opts {
+		switch o {
+		case "defaults":
+			f.ReadOnly, f.NoDev, f.NoSuid, f.NoExec = false, false, false, false
+		case "ro", "rw":
+			f.ReadOnly = o == "ro"
+		case "nodev", "dev":
+			f.NoDev = o == "nodev"
+		case "nosuid", "suid":
+			f.NoSuid = o == "nosuid"
+		case "noexec", "exec":
+			f.NoExec = o == "noexec"
+		case "noatime":
+			f.NoAtime, f.RelAtime = true, false
+		case "relatime":
+			f.NoAtime, f.RelAtime = false, true
+		case "strictatime", "norelatime":
+			f.NoAtime, f.RelAtime = false, false
+		case "nodiratime", "diratime":
+			f.NoDirAtime = o == "nodiratime"
+		}
+	}
+	return f
+}
+
+// getEffectiveMountFlags returns the effective flags of the mount at
+// target, which are the mount's own options merged with the options of
+// the super block of the filesystem from which the mount originates.
+// Every mount of a filesystem, including a bind mount, reports the
+// options of the same super block, so the originating mount need not
+// be found.
+func (fs *FS) getEffectiveMountFlags(
+	ctx context.Context, target string) ([]string, error) {
+
+	target = filepath.Clean(target)
+	mounts, err := fs.getMounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The last entry for a path is the mount visible at that path.
+	var m *Info
+	for i := len(mounts) - 1; i >= 0; i-- {
+		if mounts[i].Path == target {
+			m = &mounts[i]
+			break
+		}
+	}
+	if m == nil {
+		return nil, fmt.Errorf(
+			"getEffectiveMountFlags: %s: %w", target, ErrNotMounted)
+	}
+
+	return mergeMountFlags(m.Opts, m.SuperOpts), nil
+}
+
+// mergeMountFlags merges a mount's options with its super block options.
+// A filesystem is read-only if either the mount or the super block is
+// read-only, so "ro" takes precedence over "rw".
+func mergeMountFlags(mountOpts, superOpts []string) []string {
+	var (
+		flags []string
+		ro    bool
+		seen  = map[string]struct{}{}
+	)
+	for _, opts := range [][]string{mountOpts, superOpts} {
+		for _, o := range opts {
+			if o == "ro" {
+				ro = true
+			}
+		}
+	}
+	for _, opts := range [][]string{mountOpts, superOpts} {
+		for _, o := range opts {
+			if o == "" || (ro && o == "rw") {
+				continue
+			}
+			if _, ok := seen[o]; ok {
+				continue
+			}
+			seen[o] = struct{}{}
+			flags = append(flags, o)
+		}
+	}
+	return flags
+}
+
+// MountOptionError is returned when a mount option is rejected by the
+// FS's AllowedOptions or DeniedOptions.
+type MountOptionError struct {
+	// Option is the rejected option.
+	Option string
+
+	// Denied is a flag indicating the option was rejected because it is
+	// in DeniedOptions rather than because it is absent from
+	// AllowedOptions.
+	Denied bool
+}
+
+// Error returns the error message.
+func (e *MountOptionError) Error() string {
+	if e.Denied {
+		return fmt.Sprintf("mount option denied: %s", e.Option)
+	}
+	return fmt.Sprintf("mount option not allowed: %s", e.Option)
+}
+
+// addedMountOptions are the options the package adds to the options of
+// a mount itself, ex. "bind" for BindMount or "defaults" for
+// FormatAndMount, which are not checked against the AllowedOptions and
+// DeniedOptions.
+var addedMountOptions = toStringSet([]string{
+	"defaults", "ro", "bind", "remount",
+})
+
+// checkOptions returns an error if any of the options of a mount of
+// fsType is rejected by checkMountOptions or checkFSTypeOptions
+func (fs *FS) checkOptions(fsType string, opts []string) error {
+	if err := fs.checkMountOptions(opts); err != nil {
+		return err
+	}
+	return fs.checkFSTypeOptions(fsType, opts)
+}
+
+// checkMountOptions returns a *MountOptionError for the first of the
+// provided options that is denied or not allowed. An option with a
+// value, ex. "uid=1000", matches both its full text and its name.
+func (fs *FS) checkMountOptions(opts []string) error {
+	if fs.AllowedOptions == nil && fs.DeniedOptions == nil {
+		return nil
+	}
+	in := func(set map[string]struct{}, o string) bool {
+		if _, ok := set[o]; ok {
+			return true
+		}
+		if i := strings.IndexByte(o, '='); i > 0 {
+			_, ok := set[o[:i]]
+			return ok
+		}
+		return false
+	}
+	for _, o := range opts {
+		if _, ok := addedMountOptions[o]; ok || o == "" {
+			continue
+		}
+		if in(fs.DeniedOptions, o) {
+			return &MountOptionError{Option: o, Denied: true}
+		}
+		if fs.AllowedOptions != nil && !in(fs.AllowedOptions, o) {
+			return &MountOptionError{Option: o}
+		}
+	}
+	return nil
+}
+
+// mountOptionGroups are sets of mutually exclusive mount options that
+// are not simply an option and its "no" form.
+var mountOptionGroups = [][]string{
+	{"ro", "rw"},
+	{"atime", "noatime", "relatime", "norelatime", "strictatime"},
+}
+
+// mergeDefaultMountOpts returns opts followed by each of the defaults
+// that does not conflict with one of opts
+func mergeDefaultMountOpts(defaults, opts []string) []string {
+	merged := append([]string(nil), opts...)
+	for _, d := range defaults {
+		conflict := false
+		for _, o := range opts {
+			if mountOptsConflict(d, o) {
+				conflict = true
+				break
+			}
+		}
+		if !conflict {
+			merged = append(merged, d)
+		}
+	}
+	return merged
+}
+
+// mountOptsConflict returns a flag indicating whether the two options
+// conflict: they are the same option, possibly with different values,
+// one is the "no" form of the other, or they are in the same group of
+// mutually exclusive options.
+func mountOptsConflict(a, b string) bool {
+	name := func(o string) string {
+		if i := strings.IndexByte(o, '='); i >= 0 {
+			return o[:i]
+		}
+		return o
+	}
+	a, b = name(a), name(b)
+	if a == b || "no"+a == b || a == "no"+b {
+		return true
+	}
+	for _, g := range mountOptionGroups {
+		var hasA, hasB bool
+		for _, o := range g {
+			hasA = hasA || o == a
+			hasB = hasB || o == b
+		}
+		if hasA && hasB {
+			return true
+		}
+	}
+	return false
+}
+
+// hasMountOpt returns a flag indicating whether opts includes opt
+func hasMountOpt(opts []string, opt string) bool {
+	for _, o := range opts {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,199 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"reflect"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+const effectiveFlagsMountInfoData = `60 1 253:0 / / rw,relatime shared:1 - xfs /dev/mapper/cl-root rw,seclabel,attr2,inode64,noquota
+72 60 8:16 / /mnt/data rw,relatime shared:28 - ext4 /dev/sdb rw,data=ordered
+73 60 8:16 /sub /mnt/bind ro,relatime shared:28 - ext4 /dev/sdb rw,data=ordered
+74 60 8:32 / /mnt/rofs rw,relatime shared:29 - ext4 /dev/sdc ro,data=ordered
+`
+
+func TestGetEffectiveMountFlags(t *testing.T) {
+	procRoot := newTestProcRoot(t, effectiveFlagsMountInfoData, "self")
+
+	fs := &gofsutil.FS{ProcRoot: procRoot}
+
+	tests := []struct {
+		target string
+		flags  []string
+	}{
+		{
+			target: "/mnt/data",
+			flags:  []string{"rw", "relatime", "data=ordered"},
+		},
+		{
+			target: "/mnt/bind",
+			flags:  []string{"ro", "relatime", "data=ordered"},
+		},
+		{
+			target: "/mnt/rofs",
+			flags:  []string{"relatime", "ro", "data=ordered"},
+		},
+	}
+
+	for _, tt := range tests {
+		flags, err := fs.GetEffectiveMountFlags(context.TODO(), tt.target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(flags, tt.flags) {
+			t.Errorf("invalid flags: target=%s, exp=%v, act=%v",
+				tt.target, tt.flags, flags)
+		}
+	}
+
+	_, err := fs.GetEffectiveMountFlags(context.TODO(), "/mnt/none")
+	if !errors.Is(err, gofsutil.ErrNotMounted) {
+		t.Errorf("expected ErrNotMounted: %v", err)
+	}
+}
+
+func TestGetEffectiveMountFlagsReadOnlyBind(t *testing.T) {
+	src, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(src)
+	tgt, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tgt)
+	if err := gofsutil.EvalSymlinks(context.TODO(), &src); err != nil {
+		t.Fatal(err)
+	}
+	if err := gofsutil.EvalSymlinks(context.TODO(), &tgt); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.TODO()
+	if err := gofsutil.BindMount(ctx, src, tgt); err != nil {
+		t.Fatal(err)
+	}
+	defer gofsutil.Unmount(ctx, tgt)
+	if out, err := exec.Command(
+		"mount", "-o", "remount,bind,ro", tgt).CombinedOutput(); err != nil {
+		t.Fatalf("remount failed: %v: %s", err, out)
+	}
+
+	flags, err := gofsutil.GetEffectiveMountFlags(ctx, tgt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ro := false
+	for _, f := range flags {
+		if f == "rw" {
+			t.Errorf("read-only bind reported rw: %v", flags)
+		}
+		if f == "ro" {
+			ro = true
+		}
+	}
+	if !ro {
+		t.Errorf("read-only bind not reported ro: %v", flags)
+	}
+}
+
+func TestMountCSV(t *testing.T) {
+	tests := []struct {
+		name string
+		csv  string
+		cmd  string
+	}{
+		{
+			name: "plain",
+			csv:  "rw,nodev,noexec",
+			cmd:  "mount -t ext4 -o rw,nodev,noexec /dev/sdb /mnt",
+		},
+		{
+			name: "empty",
+			csv:  "",
+			cmd:  "mount -t ext4 /dev/sdb /mnt",
+		},
+		{
+			name: "quoted",
+			csv:  `ro, context="system_u:object_r:container_file_t:s0:c1,c2"`,
+			cmd: `mount -t ext4 -o ro,` +
+				`context="system_u:object_r:container_file_t:s0:c1,c2" ` +
+				`/dev/sdb /mnt`,
+		},
+	}
+
+	for _, tt := range tests {
+		r := &testCommandRunner{}
+		fs := &gofsutil.FS{
+			RunCommand: r.run,
+			ProcRoot:   newTestRootProcRoot(t),
+		}
+		if err := fs.MountCSV(
+			context.TODO(), "/dev/sdb", "/mnt", "ext4", tt.csv); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		r.assertCommands(t, tt.cmd)
+	}
+}
+
+func TestBindMountCSV(t *testing.T) {
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{
+		RunCommand: r.run,
+		ProcRoot:   newTestRootProcRoot(t),
+	}
+	if err := fs.BindMountCSV(
+		context.TODO(), "/src", "/mnt", "ro,nosuid"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t,
+		"mount -o bind /src /mnt",
+		"mount -o remount,ro,nosuid /src /mnt")
+}
+
+func TestMountDefaultMountOpts(t *testing.T) {
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{
+		ProcRoot:   newTestRootProcRoot(t),
+		RunCommand: r.run,
+		DefaultMountOpts: map[string][]string{
+			"xfs":  {"noatime"},
+			"ext4": {"noatime", "nodiratime", "commit=30"},
+		},
+	}
+
+	ctx := context.TODO()
+	for _, tt := range []struct {
+		fsType string
+		opts   []string
+	}{
+		{"xfs", nil},
+		{"xfs", []string{"ro"}},
+		{"xfs", []string{"relatime"}},
+		{"ext4", []string{"diratime", "commit=5"}},
+		{"btrfs", []string{"ro"}},
+	} {
+		if err := fs.Mount(
+			ctx, "/dev/sdb", "/mnt", tt.fsType, tt.opts...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := fs.BindMount(ctx, "/src", "/mnt"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t,
+		"mount -t xfs -o noatime /dev/sdb /mnt",
+		"mount -t xfs -o ro,noatime /dev/sdb /mnt",
+		"mount -t xfs -o relatime /dev/sdb /mnt",
+		"mount -t ext4 -o diratime,commit=5,noatime /dev/sdb /mnt",
+		"mount -t btrfs -o ro /dev/sdb /mnt",
+		"mount -o bind /src /mnt",
+		"mount -o remount /src /mnt")
+}
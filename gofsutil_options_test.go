@@ -0,0 +1,266 @@
+package gofsutil_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+func TestCompareMountOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		desired []string
+		actual  []string
+		missing []string
+		extra   []string
+	}{
+		{
+			name:    "kernel-default",
+			desired: []string{"nodev", "noexec"},
+			actual:  []string{"rw", "nodev", "noexec", "relatime"},
+		},
+		{
+			name:    "missing",
+			desired: []string{"nodev", "noexec"},
+			actual:  []string{"rw", "noexec", "relatime"},
+			missing: []string{"nodev"},
+		},
+		{
+			name:    "extra",
+			desired: []string{"nodev"},
+			actual:  []string{"rw", "nodev", "nosuid", "relatime"},
+			extra:   []string{"nosuid"},
+		},
+		{
+			name:    "ro",
+			desired: []string{"ro", "defaults"},
+			actual:  []string{"rw", "relatime"},
+			missing: []string{"ro"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(st *testing.T) {
+			st.Parallel()
+			missing, extra := gofsutil.CompareMountOptions(
+				tt.desired, tt.actual)
+			if !reflect.DeepEqual(missing, tt.missing) {
+				st.Errorf("invalid missing: exp=%v, act=%v",
+					tt.missing, missing)
+			}
+			if !reflect.DeepEqual(extra, tt.extra) {
+				st.Errorf("invalid extra: exp=%v, act=%v", tt.extra, extra)
+			}
+		})
+	}
+}
+
+func TestMountDeniedOptions(t *testing.T) {
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{
+		ProcRoot:   newTestRootProcRoot(t),
+		RunCommand: r.run,
+		DeniedOptions: map[string]struct{}{
+			"dev":  {},
+			"suid": {},
+			"exec": {},
+		},
+	}
+
+	err := fs.Mount(
+		context.TODO(), "/dev/sdb", "/mnt", "ext4", "rw", "suid")
+	optErr, ok := err.(*gofsutil.MountOptionError)
+	if !ok {
+		t.Fatalf("expected MountOptionError: %v", err)
+	}
+	if optErr.Option != "suid" || !optErr.Denied {
+		t.Errorf("invalid error: %+v", optErr)
+	}
+
+	err = fs.BindMount(context.TODO(), "/src", "/mnt", "exec")
+	if _, ok := err.(*gofsutil.MountOptionError); !ok {
+		t.Errorf("expected MountOptionError: %v", err)
+	}
+
+	if err := fs.Mount(
+		context.TODO(), "/dev/sdb", "/mnt", "ext4", "nosuid"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t, "mount -t ext4 -o nosuid /dev/sdb /mnt")
+}
+
+func TestMountAllowedOptions(t *testing.T) {
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{
+		ProcRoot:   newTestRootProcRoot(t),
+		RunCommand: r.run,
+		AllowedOptions: map[string]struct{}{
+			"ro":     {},
+			"nosuid": {},
+			"nodev":  {},
+			"suid":   {},
+			"uid":    {},
+		},
+		DeniedOptions: map[string]struct{}{
+			"suid": {},
+		},
+	}
+
+	err := fs.Mount(
+		context.TODO(), "/dev/sdb", "/mnt", "ext4", "ro", "noatime")
+	optErr, ok := err.(*gofsutil.MountOptionError)
+	if !ok {
+		t.Fatalf("expected MountOptionError: %v", err)
+	}
+	if optErr.Option != "noatime" || optErr.Denied {
+		t.Errorf("invalid error: %+v", optErr)
+	}
+
+	// The deny list takes precedence over the allow list.
+	err = fs.Mount(context.TODO(), "/dev/sdb", "/mnt", "ext4", "suid")
+	if optErr, ok := err.(*gofsutil.MountOptionError); !ok || !optErr.Denied {
+		t.Errorf("expected denied MountOptionError: %v", err)
+	}
+
+	if err := fs.Mount(
+		context.TODO(), "/dev/sdb", "/mnt", "ext4",
+		"ro", "nosuid", "nodev", "uid=1000"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t, "mount -t ext4 -o ro,nosuid,nodev,uid=1000 /dev/sdb /mnt")
+}
+
+func TestInfoFlags(t *testing.T) {
+	tests := []struct {
+		name  string
+		opts  []string
+		flags gofsutil.MountFlags
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "defaults",
+			opts: []string{"nodev", "ro", "defaults"},
+		},
+		{
+			name:  "rw",
+			opts:  []string{"ro", "rw", "relatime"},
+			flags: gofsutil.MountFlags{RelAtime: true},
+		},
+		{
+			name:  "relatime-nodev",
+			opts:  []string{"rw", "nodev", "relatime"},
+			flags: gofsutil.MountFlags{NoDev: true, RelAtime: true},
+		},
+		{
+			name: "hardened",
+			opts: []string{
+				"ro", "nosuid", "nodev", "noexec", "relatime", "noatime",
+				"nodiratime",
+			},
+			flags: gofsutil.MountFlags{
+				ReadOnly:   true,
+				NoSuid:     true,
+				NoDev:      true,
+				NoExec:     true,
+				NoAtime:    true,
+				NoDirAtime: true,
+			},
+		},
+		{
+			name:  "defaults-then-ro",
+			opts:  []string{"defaults", "ro", "strictatime", "uid=1000"},
+			flags: gofsutil.MountFlags{ReadOnly: true},
+		},
+	}
+
+	for _, tt := range tests {
+		flags := gofsutil.Info{Opts: tt.opts}.Flags()
+		if flags != tt.flags {
+			t.Errorf("%s: invalid flags: exp=%+v, act=%+v",
+				tt.name, tt.flags, flags)
+		}
+	}
+}
+
+func TestSplitMountOptions(t *testing.T) {
+	opts := []string{
+		"rw", "_netdev", "x-systemd.requires=iscsid.service", "noatime",
+		"nofail", "comment=csi", "x-mount.mkdir", "uid=1000",
+	}
+	kernel, userspace := gofsutil.SplitMountOptions(opts)
+	if exp := []string{"rw", "noatime", "uid=1000"}; !reflect.DeepEqual(
+		kernel, exp) {
+		t.Errorf("invalid kernel options: exp=%v, act=%v", exp, kernel)
+	}
+	if exp := []string{
+		"_netdev", "x-systemd.requires=iscsid.service", "nofail",
+		"comment=csi", "x-mount.mkdir",
+	}; !reflect.DeepEqual(userspace, exp) {
+		t.Errorf("invalid userspace options: exp=%v, act=%v", exp, userspace)
+	}
+}
+
+func TestResolveMountOptions(t *testing.T) {
+	tests := []struct {
+		opts []string
+		exp  []string
+	}{
+		{[]string{"ro", "rw"}, []string{"rw"}},
+		{[]string{"noatime", "atime"}, []string{"atime"}},
+		{[]string{"nodev", "nodev"}, []string{"nodev"}},
+		{[]string{"rw", "nodev", "ro"}, []string{"nodev", "ro"}},
+		{[]string{"relatime", "nosuid", "strictatime"},
+			[]string{"nosuid", "strictatime"}},
+		{[]string{"dev", "", "nodev", "exec"}, []string{"nodev", "exec"}},
+		{[]string{"mode=0755", "uid=1000", "mode=0700"},
+			[]string{"uid=1000", "mode=0700"}},
+		{[]string{
+			"x-systemd.requires=a.service", "_netdev",
+			"x-systemd.requires=b.service", "_netdev",
+		}, []string{
+			"x-systemd.requires=a.service",
+			"x-systemd.requires=b.service", "_netdev",
+		}},
+		{nil, nil},
+	}
+	for _, tt := range tests {
+		act := gofsutil.ResolveMountOptions(tt.opts)
+		if !reflect.DeepEqual(act, tt.exp) {
+			t.Errorf("%v: invalid options: exp=%v, act=%v",
+				tt.opts, tt.exp, act)
+		}
+	}
+}
+
+func TestCloneMountOptions(t *testing.T) {
+	ctx := gofsutil.WithMountTable(context.TODO(), []gofsutil.Info{
+		{
+			Device: "/dev/sdb",
+			Path:   "/mnt/data",
+			Type:   "xfs",
+			Opts:   []string{"ro", "nosuid", "nodev", "relatime"},
+			SuperOpts: []string{
+				"rw", "seclabel", "attr2", "inode64", "logbufs=8",
+				"logbsize=32k", "discard", "noquota",
+			},
+		},
+	})
+	opts, err := (&gofsutil.FS{}).CloneMountOptions(ctx, "/mnt/data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []string{"discard", "ro", "nosuid", "nodev"}
+	if !reflect.DeepEqual(opts, exp) {
+		t.Errorf("invalid options: exp=%v, act=%v", exp, opts)
+	}
+
+	if _, err := (&gofsutil.FS{}).CloneMountOptions(
+		ctx, "/mnt/other"); err == nil {
+		t.Error("expected error for unmounted target")
+	}
+}
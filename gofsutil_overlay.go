@@ -0,0 +1,75 @@
+package gofsutil
+
+import "strings"
+
+// OverlayDirs returns the lower, upper, and work directories of an overlay
+// mount. The lower directories are returned in the order in which they
+// are stacked, topmost first. The upper and work directories are empty
+// for a read-only overlay. A false value is returned if the mount is not
+// an overlay mount or does not specify any lower directories.
+func (i Info) OverlayDirs() (lower []string, upper, work string, ok bool) {
+	if i.Type != "overlay" {
+		return nil, "", "", false
+	}
+	for _, opts := range [][]string{i.SuperOpts, i.Opts} {
+		for _, o := range opts {
+			kv := strings.SplitN(o, "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			v := unescapeOctal(kv[1])
+			switch kv[0] {
+			case "lowerdir":
+				if lower == nil {
+					for _, d := range splitOverlayLowerDirs(v) {
+						lower = append(lower, unescapeOverlayPath(d))
+					}
+				}
+			case "upperdir":
+				if upper == "" {
+					upper = unescapeOverlayPath(v)
+				}
+			case "workdir":
+				if work == "" {
+					work = unescapeOverlayPath(v)
+				}
+			}
+		}
+	}
+	return lower, upper, work, len(lower) > 0
+}
+
+// splitOverlayLowerDirs splits a lowerdir value on the colons that are
+// not escaped with a backslash.
+func splitOverlayLowerDirs(s string) []string {
+	var (
+		dirs  []string
+		start int
+	)
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case ':':
+			dirs = append(dirs, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(dirs, s[start:])
+}
+
+// unescapeOverlayPath removes the backslashes used to escape colons and
+// commas in overlay directory paths.
+func unescapeOverlayPath(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,84 @@
+package gofsutil_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+const overlayMountInfoData = `62 25 0:52 / /var/lib/containers/merged rw,relatime - overlay overlay rw,lowerdir=/var/lib/l/a\134:b:/var/lib/l/c\054d:/var/lib/l/e,upperdir=/var/lib/u\134:1,workdir=/var/lib/w
+63 25 0:53 / /var/lib/containers/ro ro,relatime - overlay overlay ro,lowerdir=/var/lib/l/a:/var/lib/l/e
+64 25 8:1 / /mnt rw,relatime - xfs /dev/sda1 rw,attr2,inode64,noquota
+`
+
+func TestOverlayDirs(t *testing.T) {
+	// The default scan function ignores overlay mounts since their
+	// source is not a path.
+	scanAll := func(
+		ctx context.Context,
+		entry gofsutil.Entry,
+		cache map[string]gofsutil.Entry) (gofsutil.Info, bool, error) {
+
+		return gofsutil.Info{
+			Device:    entry.MountSource,
+			Path:      entry.MountPoint,
+			Type:      entry.FSType,
+			Opts:      entry.MountOpts,
+			SuperOpts: entry.SuperOpts,
+		}, true, nil
+	}
+
+	mounts, _, err := gofsutil.ReadProcMountsFrom(
+		context.TODO(),
+		strings.NewReader(overlayMountInfoData),
+		false,
+		gofsutil.ProcMountsFields,
+		scanAll)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mounts) != 3 {
+		t.Fatalf("invalid mount count: exp=3, act=%d", len(mounts))
+	}
+
+	tests := []struct {
+		lower []string
+		upper string
+		work  string
+		ok    bool
+	}{
+		{
+			lower: []string{"/var/lib/l/a:b", "/var/lib/l/c,d", "/var/lib/l/e"},
+			upper: "/var/lib/u:1",
+			work:  "/var/lib/w",
+			ok:    true,
+		},
+		{
+			lower: []string{"/var/lib/l/a", "/var/lib/l/e"},
+			ok:    true,
+		},
+		{},
+	}
+
+	for i, tt := range tests {
+		lower, upper, work, ok := mounts[i].OverlayDirs()
+		if ok != tt.ok {
+			t.Errorf("%s: invalid ok: exp=%v, act=%v", mounts[i].Path, tt.ok, ok)
+		}
+		if !reflect.DeepEqual(lower, tt.lower) {
+			t.Errorf("%s: invalid lowerdir: exp=%q, act=%q",
+				mounts[i].Path, tt.lower, lower)
+		}
+		if upper != tt.upper {
+			t.Errorf("%s: invalid upperdir: exp=%q, act=%q",
+				mounts[i].Path, tt.upper, upper)
+		}
+		if work != tt.work {
+			t.Errorf("%s: invalid workdir: exp=%q, act=%q",
+				mounts[i].Path, tt.work, work)
+		}
+	}
+}
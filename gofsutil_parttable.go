@@ -0,0 +1,10 @@
+package gofsutil
+
+const (
+	// PartitionTableGPT is the type of a GUID partition table.
+	PartitionTableGPT = "gpt"
+
+	// PartitionTableDOS is the type of an MBR partition table, also
+	// known as a DOS or msdos partition table.
+	PartitionTableDOS = "dos"
+)
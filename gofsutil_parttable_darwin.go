@@ -0,0 +1,11 @@
+package gofsutil
+
+import "context"
+
+// getPartitionTableType returns the type of the partition table of the
+// device
+func (fs *FS) getPartitionTableType(
+	ctx context.Context, device string) (string, error) {
+
+	return "", ErrNotImplemented
+}
@@ -0,0 +1,160 @@
+package gofsutil
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// partitionTableTools are the tools used, in order of preference, to
+// determine the type of the partition table of a device.
+var partitionTableTools = []string{"blkid", "sgdisk", "parted"}
+
+// getPartitionTableType returns the type of the partition table of the
+// device using the first of the partition table tools that is installed
+func (fs *FS) getPartitionTableType(
+	ctx context.Context, device string) (string, error) {
+
+	var err error
+	for _, tool := range partitionTableTools {
+		var ptType string
+		switch tool {
+		case "blkid":
+			ptType, err = fs.getPartitionTableTypeBlkid(ctx, device)
+		case "sgdisk":
+			ptType, err = fs.getPartitionTableTypeSgdisk(ctx, device)
+		case "parted":
+			ptType, err = fs.getPartitionTableTypeParted(ctx, device)
+		}
+		if err == nil || !isCommandNotFound(err) {
+			return ptType, err
+		}
+		log.WithFields(logFields(ctx, log.Fields{
+			"tool": tool,
+		})).Warn("partition table tool not found, trying next tool")
+	}
+	return "", err
+}
+
+// getPartitionTableTypeBlkid uses 'blkid' to determine the type of the
+// partition table of the device
+func (fs *FS) getPartitionTableTypeBlkid(
+	ctx context.Context, device string) (string, error) {
+
+	buf, err := fs.exec(
+		ctx, "blkid", "-p", "-o", "value", "-s", "PTTYPE", device)
+	out := strings.TrimSpace(string(buf))
+	if err != nil {
+		// blkid exits with a non-zero status and no output when the
+		// device does not contain a filesystem or partition table.
+		if !isCommandNotFound(err) && out == "" {
+			return "", nil
+		}
+		return "", fs.partitionTableError(ctx, "blkid", device, out, err)
+	}
+	return normalizePartitionTableType(out), nil
+}
+
+// getPartitionTableTypeSgdisk uses 'sgdisk' to determine the type of the
+// partition table of the device from its partition table scan, ex.
+//
+//	Partition table scan:
+//	  MBR: protective
+//	  BSD: not present
+//	  APM: not present
+//	  GPT: present
+func (fs *FS) getPartitionTableTypeSgdisk(
+	ctx context.Context, device string) (string, error) {
+
+	buf, err := fs.exec(ctx, "sgdisk", "-p", device)
+	out := string(buf)
+	var mbr, gpt string
+	for _, line := range strings.Split(out, "\n") {
+		kv := strings.SplitN(strings.TrimSpace(line), ":", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "MBR":
+			mbr = strings.TrimSpace(kv[1])
+		case "GPT":
+			gpt = strings.TrimSpace(kv[1])
+		}
+	}
+	// sgdisk exits with a non-zero status when it prints the partition
+	// table of a blank device, but it has scanned the device.
+	if err != nil && (mbr == "" || gpt == "") {
+		return "", fs.partitionTableError(ctx, "sgdisk", device, out, err)
+	}
+	switch {
+	case gpt == "present" || gpt == "damaged":
+		return PartitionTableGPT, nil
+	case mbr == "MBR only" || mbr == "hybrid":
+		return PartitionTableDOS, nil
+	}
+	return "", nil
+}
+
+// getPartitionTableTypeParted uses 'parted' to determine the type of the
+// partition table of the device from the sixth field of the device line
+// of its machine-readable output, ex. "/dev/sdb:10.7GB:scsi:512:512:gpt:"
+func (fs *FS) getPartitionTableTypeParted(
+	ctx context.Context, device string) (string, error) {
+
+	buf, err := fs.exec(ctx, "parted", "-s", "-m", device, "print")
+	out := string(buf)
+	if err != nil {
+		// parted fails to print a device without a partition table.
+		if strings.Contains(out, "unrecognised disk label") ||
+			strings.Contains(out, "unrecognized disk label") {
+			return "", nil
+		}
+		return "", fs.partitionTableError(ctx, "parted", device, out, err)
+	}
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), ":")
+		if len(fields) < 6 || fields[0] != device {
+			continue
+		}
+		switch fields[5] {
+		case "loop", "unknown":
+			// A "loop" label is a filesystem on the whole device.
+			return "", nil
+		}
+		return normalizePartitionTableType(fields[5]), nil
+	}
+	return "", fmt.Errorf("parted: device not listed: %s", device)
+}
+
+// partitionTableError logs and returns the error of a failed partition
+// table tool
+func (fs *FS) partitionTableError(
+	ctx context.Context, tool, device, out string, err error) error {
+
+	log.WithFields(logFields(ctx, log.Fields{
+		"tool":   tool,
+		"device": device,
+		"output": out,
+	})).WithError(err).Error("failed to determine partition table type")
+	if isCommandNotFound(err) {
+		return err
+	}
+	if e := wrapCmdError(err, out, lsblkErrors); e != err {
+		return fmt.Errorf("getPartitionTableType: %w: %s", e, device)
+	}
+	return fmt.Errorf("%s failed: %v\ndevice: %s\noutput: %s",
+		tool, err, device, out)
+}
+
+// normalizePartitionTableType returns the type of a partition table as
+// reported by one of the partition table tools, ex. parted's "msdos", as
+// one of the PartitionTable constants
+func normalizePartitionTableType(ptType string) string {
+	switch ptType = strings.ToLower(ptType); ptType {
+	case "msdos", "mbr":
+		return PartitionTableDOS
+	}
+	return ptType
+}
@@ -0,0 +1,171 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+const sgdiskGPTOutput = `Disk /dev/sdb: 20971520 sectors, 10.0 GiB
+Partition table scan:
+  MBR: protective
+  BSD: not present
+  APM: not present
+  GPT: present
+
+Found valid GPT with protective MBR; using GPT.
+`
+
+const sgdiskMBROutput = `Partition table scan:
+  MBR: MBR only
+  BSD: not present
+  APM: not present
+  GPT: not present
+`
+
+const sgdiskBlankOutput = `Creating new GPT entries in memory.
+Partition table scan:
+  MBR: not present
+  BSD: not present
+  APM: not present
+  GPT: not present
+`
+
+func TestGetPartitionTableTypeBlkid(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		err    error
+		exp    string
+	}{
+		{"gpt", "gpt\n", nil, gofsutil.PartitionTableGPT},
+		{"mbr", "dos\n", nil, gofsutil.PartitionTableDOS},
+		{"filesystem", "", nil, ""},
+		{"blank", "", errors.New("exit status 2"), ""},
+	}
+	for _, tt := range tests {
+		r := &testCommandRunner{
+			handler: func(args []string) (string, error) {
+				return tt.output, tt.err
+			},
+		}
+		fs := &gofsutil.FS{RunCommand: r.run}
+		ptType, err := fs.GetPartitionTableType(context.TODO(), "/dev/sdb")
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if ptType != tt.exp {
+			t.Errorf("%s: ptType=%q, exp=%q", tt.name, ptType, tt.exp)
+		}
+		r.assertCommands(t, "blkid -p -o value -s PTTYPE /dev/sdb")
+	}
+}
+
+func TestGetPartitionTableTypeBlkidError(t *testing.T) {
+	r := &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			return "blkid: error: /dev/sdx: No such file or directory",
+				errors.New("exit status 2")
+		},
+	}
+	fs := &gofsutil.FS{RunCommand: r.run}
+	_, err := fs.GetPartitionTableType(context.TODO(), "/dev/sdx")
+	if !errors.Is(err, gofsutil.ErrDeviceNotFound) {
+		t.Errorf("expected ErrDeviceNotFound, got %v", err)
+	}
+	if _, err := fs.GetPartitionTableType(
+		context.TODO(), "sdb"); err == nil {
+		t.Error("expected error for relative device")
+	}
+}
+
+func TestGetPartitionTableTypeSgdisk(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		err    error
+		exp    string
+	}{
+		{"gpt", sgdiskGPTOutput, nil, gofsutil.PartitionTableGPT},
+		{"mbr", sgdiskMBROutput, nil, gofsutil.PartitionTableDOS},
+		{"blank", sgdiskBlankOutput, errors.New("exit status 2"), ""},
+	}
+	for _, tt := range tests {
+		r := &testCommandRunner{
+			handler: func(args []string) (string, error) {
+				if args[0] == "blkid" {
+					return "", &exec.Error{Name: "blkid", Err: exec.ErrNotFound}
+				}
+				return tt.output, tt.err
+			},
+		}
+		fs := &gofsutil.FS{RunCommand: r.run}
+		ptType, err := fs.GetPartitionTableType(context.TODO(), "/dev/sdb")
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if ptType != tt.exp {
+			t.Errorf("%s: ptType=%q, exp=%q", tt.name, ptType, tt.exp)
+		}
+		r.assertCommands(t,
+			"blkid -p -o value -s PTTYPE /dev/sdb",
+			"sgdisk -p /dev/sdb")
+	}
+}
+
+func TestGetPartitionTableTypeParted(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		err    error
+		exp    string
+	}{
+		{
+			"gpt",
+			"BYT;\n/dev/sdb:10.7GB:scsi:512:512:gpt:QEMU HARDDISK:;\n",
+			nil, gofsutil.PartitionTableGPT,
+		},
+		{
+			"mbr",
+			"BYT;\n/dev/sdb:10.7GB:scsi:512:512:msdos:QEMU HARDDISK:;\n" +
+				"1:1049kB:10.7GB:10.7GB:ext4::;\n",
+			nil, gofsutil.PartitionTableDOS,
+		},
+		{
+			"filesystem",
+			"BYT;\n/dev/sdb:10.7GB:scsi:512:512:loop:QEMU HARDDISK:;\n",
+			nil, "",
+		},
+		{
+			"blank",
+			"Error: /dev/sdb: unrecognised disk label\n" +
+				"BYT;\n/dev/sdb:10.7GB:scsi:512:512:unknown:QEMU HARDDISK:;\n",
+			errors.New("exit status 1"), "",
+		},
+	}
+	for _, tt := range tests {
+		r := &testCommandRunner{
+			handler: func(args []string) (string, error) {
+				if args[0] != "parted" {
+					return "", &exec.Error{Name: args[0], Err: exec.ErrNotFound}
+				}
+				return tt.output, tt.err
+			},
+		}
+		fs := &gofsutil.FS{RunCommand: r.run}
+		ptType, err := fs.GetPartitionTableType(context.TODO(), "/dev/sdb")
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if ptType != tt.exp {
+			t.Errorf("%s: ptType=%q, exp=%q", tt.name, ptType, tt.exp)
+		}
+		r.assertCommands(t,
+			"blkid -p -o value -s PTTYPE /dev/sdb",
+			"sgdisk -p /dev/sdb",
+			"parted -s -m /dev/sdb print")
+	}
+}
@@ -0,0 +1,83 @@
+package gofsutil
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// fileID identifies a file by its device and inode numbers.
+type fileID struct {
+	dev, ino uint64
+}
+
+// getPathUsage returns the disk usage of the subtree rooted at root. The
+// walk does not descend into the filesystems mounted beneath root.
+func (fs *FS) getPathUsage(ctx context.Context, root string) (uint64, error) {
+	fi, err := os.Lstat(root)
+	if err != nil {
+		return 0, err
+	}
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, fmt.Errorf("stat: %s: unsupported file info", root)
+	}
+	rootDev := uint64(st.Dev)
+
+	var (
+		usage uint64
+		seen  = map[fileID]struct{}{}
+	)
+	err = filepath.Walk(root, func(p string, fi os.FileInfo, err error) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		st, ok := fi.Sys().(*syscall.Stat_t)
+		if !ok {
+			return fmt.Errorf("stat: %s: unsupported file info", p)
+		}
+
+		// A directory on another device is a mount point of another
+		// filesystem, and is neither counted nor walked.
+		if uint64(st.Dev) != rootDev {
+			if fi.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		// The blocks of a file with several hard links are counted once.
+		if !fi.IsDir() && st.Nlink > 1 {
+			id := fileID{dev: uint64(st.Dev), ino: uint64(st.Ino)}
+			if _, ok := seen[id]; ok {
+				return nil
+			}
+			seen[id] = struct{}{}
+		}
+
+		if fs.ApparentPathUsage {
+			usage += uint64(fi.Size())
+		} else {
+			// The number of blocks is always in units of 512 bytes.
+			usage += uint64(st.Blocks) * 512
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	log.WithFields(logFields(ctx, log.Fields{
+		"path":     root,
+		"usage":    usage,
+		"apparent": fs.ApparentPathUsage,
+	})).Debug("got path usage")
+	return usage, nil
+}
@@ -0,0 +1,112 @@
+package gofsutil_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+// newTestUsageTree mounts a tmpfs at a temporary directory and creates
+// a tree with the following files, where "nested" is the mount point of
+// another tmpfs. The tree is removed when the test finishes.
+//
+//	a         1000 bytes
+//	b         hard link to a
+//	dir/c     5000 bytes
+//	nested/d  100000 bytes
+func newTestUsageTree(t *testing.T) string {
+	if os.Getuid() != 0 {
+		t.Skip("requires root")
+	}
+	root := t.TempDir()
+	if err := unix.Mount("tmpfs", root, "tmpfs", 0, "size=4m"); err != nil {
+		t.Skipf("mount tmpfs: %v", err)
+	}
+	nested := path.Join(root, "nested")
+	t.Cleanup(func() {
+		unix.Unmount(nested, 0)
+		unix.Unmount(root, 0)
+	})
+	for _, dir := range []string{"dir", "nested"} {
+		if err := os.Mkdir(path.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := unix.Mount("tmpfs", nested, "tmpfs", 0, "size=1m"); err != nil {
+		t.Skipf("mount tmpfs: %v", err)
+	}
+	files := map[string]int{
+		"a":        1000,
+		"dir/c":    5000,
+		"nested/d": 100000,
+	}
+	for name, size := range files {
+		if err := ioutil.WriteFile(
+			path.Join(root, name), make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Link(path.Join(root, "a"), path.Join(root, "b")); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+// roundUpToPage returns size rounded up to a multiple of the page size,
+// which is the unit in which tmpfs allocates space to files.
+func roundUpToPage(size uint64) uint64 {
+	page := uint64(os.Getpagesize())
+	return (size + page - 1) / page * page
+}
+
+func TestGetPathUsage(t *testing.T) {
+	root := newTestUsageTree(t)
+
+	// tmpfs does not allocate space to directories, and neither the
+	// hard link nor the files beneath the nested mount are counted.
+	usage, err := gofsutil.GetPathUsage(context.TODO(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := roundUpToPage(1000) + roundUpToPage(5000); usage != exp {
+		t.Errorf("invalid usage: exp=%d, act=%d", exp, usage)
+	}
+}
+
+func TestGetPathUsageApparent(t *testing.T) {
+	root := newTestUsageTree(t)
+
+	exp := uint64(1000 + 5000)
+	for _, dir := range []string{"", "dir"} {
+		fi, err := os.Lstat(path.Join(root, dir))
+		if err != nil {
+			t.Fatal(err)
+		}
+		exp += uint64(fi.Size())
+	}
+
+	fs := &gofsutil.FS{ApparentPathUsage: true}
+	usage, err := fs.GetPathUsage(context.TODO(), root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage != exp {
+		t.Errorf("invalid usage: exp=%d, act=%d", exp, usage)
+	}
+}
+
+func TestGetPathUsageCanceled(t *testing.T) {
+	root := newTestUsageTree(t)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	if _, err := gofsutil.GetPathUsage(ctx, root); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
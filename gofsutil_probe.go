@@ -0,0 +1,107 @@
+package gofsutil
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ProbeFileName is the name of the file ProbeVolume writes to and reads
+// from the root of the filesystem it probes.
+const ProbeFileName = ".gofsutil-probe"
+
+// probeVolume mounts device at a temporary directory, writes a file,
+// reads it back, and unmounts device
+func (fs *FS) probeVolume(
+	ctx context.Context, device, fsType string) (err error) {
+
+	dir, err := ioutil.TempDir("", "gofsutil-probe-")
+	if err != nil {
+		return err
+	}
+	f := logFields(ctx, log.Fields{
+		"device": device,
+		"fsType": fsType,
+		"target": dir,
+	})
+	log.WithFields(f).Info("probing volume")
+
+	// The probe is cleaned up even if the context is cancelled, and
+	// everything mounted beneath the directory is unmounted.
+	defer func() {
+		cctx := context.Background()
+		if uerr := fs.unmountRecursive(cctx, dir); uerr != nil {
+			log.WithFields(f).WithError(uerr).Error(
+				"failed to unmount probe target")
+			if err == nil {
+				err = uerr
+			}
+			return
+		}
+		if rerr := os.Remove(dir); rerr != nil {
+			log.WithFields(f).WithError(rerr).Error(
+				"failed to remove probe target")
+			if err == nil {
+				err = rerr
+			}
+		}
+	}()
+
+	if err := fs.mount(ctx, device, dir, fsType); err != nil {
+		return fmt.Errorf("probe %s: %w", device, err)
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("probe %s: %w", device, err)
+	}
+
+	var (
+		probePath = path.Join(dir, ProbeFileName)
+		data      = []byte(fmt.Sprintf(
+			"gofsutil probe %d\n", time.Now().UnixNano()))
+	)
+	if err := writeFileSync(probePath, data); err != nil {
+		return fmt.Errorf("probe %s: %w", device, err)
+	}
+	defer os.Remove(probePath)
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("probe %s: %w", device, err)
+	}
+
+	buf, err := ioutil.ReadFile(probePath)
+	if err != nil {
+		return fmt.Errorf("probe %s: %w", device, err)
+	}
+	if !bytes.Equal(buf, data) {
+		return fmt.Errorf(
+			"probe %s: read back %q, expected %q", device, buf, data)
+	}
+	log.WithFields(f).Info("probed volume")
+	return nil
+}
+
+// writeFileSync writes data to the file at name and flushes the file to
+// the underlying storage. A file that does not support synchronization,
+// ex. a special file, is not flushed.
+func writeFileSync(name string, data []byte) error {
+	w, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return err
+	}
+	if err := w.Sync(); err != nil && !errors.Is(err, syscall.EINVAL) {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
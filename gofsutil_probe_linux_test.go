@@ -0,0 +1,109 @@
+package gofsutil_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+// newTestProbeVolume creates an ext4 loop filesystem, which is deleted
+// when the test finishes.
+func newTestProbeVolume(t *testing.T) string {
+	if os.Getuid() != 0 {
+		t.Skip("requires root")
+	}
+	dir := t.TempDir()
+	imagePath := path.Join(dir, "disk.img")
+	loopDevice, err := gofsutil.CreateLoopFilesystem(
+		context.TODO(), imagePath, 64<<20, "ext4")
+	if err != nil {
+		t.Skipf("create loop filesystem: %v", err)
+	}
+	t.Cleanup(func() {
+		gofsutil.DeleteLoopFilesystem(context.TODO(), loopDevice, imagePath)
+	})
+	return loopDevice
+}
+
+// assertProbeCleanedUp asserts nothing is mounted at target and that
+// target was removed.
+func assertProbeCleanedUp(t *testing.T, loopDevice, target string) {
+	mounts, err := gofsutil.GetDevMounts(context.TODO(), loopDevice)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mounts) != 0 {
+		t.Errorf("device still mounted: %+v", mounts)
+	}
+	if target == "" {
+		t.Fatal("probe target not mounted")
+	}
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Errorf("probe target not removed: %v", err)
+	}
+}
+
+func TestProbeVolume(t *testing.T) {
+	loopDevice := newTestProbeVolume(t)
+
+	var target string
+	fs := &gofsutil.FS{
+		ScanEntry: gofsutil.DefaultEntryScanFunc(),
+		PostMountHook: func(
+			ctx context.Context,
+			source, tgt, fsType string,
+			opts []string,
+			err error) {
+
+			target = tgt
+		},
+	}
+	if err := fs.ProbeVolume(context.TODO(), loopDevice, "ext4"); err != nil {
+		t.Fatal(err)
+	}
+	assertProbeCleanedUp(t, loopDevice, target)
+}
+
+func TestProbeVolumeVerifyFailed(t *testing.T) {
+	loopDevice := newTestProbeVolume(t)
+
+	// The probe file is replaced by a bind mount of /dev/null, which
+	// discards the data written to it, so the read back data does not
+	// match.
+	var target string
+	fs := &gofsutil.FS{
+		ScanEntry: gofsutil.DefaultEntryScanFunc(),
+		PostMountHook: func(
+			ctx context.Context,
+			source, tgt, fsType string,
+			opts []string,
+			err error) {
+
+			target = tgt
+			if err != nil {
+				return
+			}
+			probePath := path.Join(tgt, gofsutil.ProbeFileName)
+			if err := ioutil.WriteFile(probePath, nil, 0600); err != nil {
+				t.Error(err)
+				return
+			}
+			if err := unix.Mount(
+				"/dev/null", probePath, "", unix.MS_BIND, ""); err != nil {
+				t.Error(err)
+			}
+		},
+	}
+	err := fs.ProbeVolume(context.TODO(), loopDevice, "ext4")
+	if err == nil || !strings.Contains(err.Error(), "read back") {
+		t.Fatalf("expected verification error: %v", err)
+	}
+	assertProbeCleanedUp(t, loopDevice, target)
+}
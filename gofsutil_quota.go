@@ -0,0 +1,57 @@
+package gofsutil
+
+import (
+	"context"
+	"fmt"
+)
+
+// xfsProjectQuotaOpts are the mount options that enable project quotas
+// on an xfs filesystem.
+var xfsProjectQuotaOpts = []string{"prjquota", "pquota", "pqnoenforce"}
+
+// enableProjectQuota enables project quotas on the filesystem on device
+func (fs *FS) enableProjectQuota(
+	ctx context.Context, device, fsType string) error {
+
+	switch {
+	case fsType == "xfs":
+		return fs.checkXFSProjectQuota(ctx, device)
+	case isExtFS(fsType):
+		// The quota feature stores quota information in hidden inodes
+		// that are kept consistent by the filesystem, so there is no
+		// need to run quotacheck afterwards.
+		return fs.tune2fs(
+			ctx, device, "-O", "project,quota", "-Q", "prjquota")
+	}
+	return fmt.Errorf("project quotas not supported: fsType=%s", fsType)
+}
+
+// checkXFSProjectQuota returns an error if the xfs filesystem on device
+// is not mounted with project quotas enabled. Quotas cannot be enabled
+// on an xfs filesystem after it is mounted.
+func (fs *FS) checkXFSProjectQuota(ctx context.Context, device string) error {
+	mounts, err := fs.getDevMounts(ctx, device)
+	if err != nil {
+		return err
+	}
+	if len(mounts) == 0 {
+		return fmt.Errorf(
+			"xfs project quotas must be enabled at mount time "+
+				"with the prjquota option: %s: %w", device, ErrNotMounted)
+	}
+	for _, m := range mounts {
+		for _, opts := range [][]string{m.Opts, m.SuperOpts} {
+			for _, o := range opts {
+				for _, q := range xfsProjectQuotaOpts {
+					if o == q {
+						return nil
+					}
+				}
+			}
+		}
+	}
+	return fmt.Errorf(
+		"xfs project quotas must be enabled at mount time "+
+			"with the prjquota option: %s is mounted at %s without it",
+		device, mounts[0].Path)
+}
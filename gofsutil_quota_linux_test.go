@@ -0,0 +1,69 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+const quotaMountInfoData = `60 1 253:0 / / rw,relatime shared:1 - xfs /dev/mapper/cl-root rw,seclabel,attr2,inode64,noquota
+72 60 8:16 / /mnt/quota rw,relatime shared:28 - xfs /dev/sdb rw,attr2,inode64,prjquota
+73 60 8:32 / /mnt/noquota rw,relatime shared:29 - xfs /dev/sdc rw,attr2,inode64,noquota
+`
+
+func TestEnableProjectQuotaXFS(t *testing.T) {
+	procRoot := newTestProcRoot(t, quotaMountInfoData, "self")
+
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{ProcRoot: procRoot, RunCommand: r.run}
+
+	if err := fs.EnableProjectQuota(
+		context.TODO(), "/dev/sdb", "xfs"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.EnableProjectQuota(
+		context.TODO(), "/dev/sdc", "xfs"); err == nil {
+		t.Error("expected error for xfs mounted without prjquota")
+	}
+	err := fs.EnableProjectQuota(context.TODO(), "/dev/sdd", "xfs")
+	if !errors.Is(err, gofsutil.ErrNotMounted) {
+		t.Errorf("expected ErrNotMounted: %v", err)
+	}
+	r.assertCommands(t)
+}
+
+func TestEnableProjectQuotaExt4(t *testing.T) {
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	if err := fs.EnableProjectQuota(
+		context.TODO(), "/dev/sdb", "ext4"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.EnableProjectQuota(
+		context.TODO(), "/dev/sdb", "vfat"); err == nil {
+		t.Error("expected error for vfat")
+	}
+	r.assertCommands(t, "tune2fs -O project,quota -Q prjquota /dev/sdb")
+}
+
+func TestFormatAndMountWithOptsProjectQuota(t *testing.T) {
+	r := newTestFormatRunner("")
+	fs := &gofsutil.FS{
+		RunCommand: r.run,
+		ProcRoot:   newTestRootProcRoot(t),
+	}
+
+	if err := fs.FormatAndMountWithOpts(
+		context.TODO(), "/dev/sdb", "/mnt", "xfs",
+		gofsutil.FormatOptions{ProjectQuota: true}); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t,
+		"mount -t xfs -o prjquota,defaults /dev/sdb /mnt",
+		"lsblk -J -o NAME,FSTYPE /dev/sdb",
+		"mkfs.xfs /dev/sdb",
+		"mount -t xfs -o prjquota,defaults /dev/sdb /mnt")
+}
@@ -0,0 +1,9 @@
+package gofsutil
+
+// mountRaw mounts source to target with the mount(2) system call using
+// the provided flags and data
+func (fs *FS) mountRaw(
+	source, target, fsType string, flags uintptr, data string) error {
+
+	return ErrNotImplemented
+}
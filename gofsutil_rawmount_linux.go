@@ -0,0 +1,43 @@
+package gofsutil
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+)
+
+// mountRaw mounts source to target with the mount(2) system call using
+// the provided flags and data
+func (fs *FS) mountRaw(
+	source, target, fsType string, flags uintptr, data string) error {
+
+	f := log.Fields{
+		"source": source,
+		"target": target,
+		"fsType": fsType,
+		"flags":  fmt.Sprintf("%#x", flags),
+		"data":   data,
+	}
+
+	// The kernel rejects the options interpreted by userspace.
+	if data != "" {
+		kernelOpts, userspaceOpts := SplitMountOptions(splitMountOpts(data))
+		if len(userspaceOpts) > 0 {
+			data = strings.Join(kernelOpts, ",")
+			f["data"] = data
+			f["userspaceOptions"] = userspaceOpts
+		}
+	}
+	log.WithFields(f).Info("mount system call")
+
+	if err := unix.Mount(source, target, fsType, flags, data); err != nil {
+		log.WithFields(f).WithError(err).Error("mount system call failed")
+		return fmt.Errorf(
+			"mount failed: %v\nsource: %s\ntarget: %s\nfsType: %s\n"+
+				"flags: %#x\ndata: %s",
+			err, source, target, fsType, flags, data)
+	}
+	return nil
+}
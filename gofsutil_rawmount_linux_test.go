@@ -0,0 +1,97 @@
+package gofsutil_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+func TestMountRaw(t *testing.T) {
+	target, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(target)
+	if err := gofsutil.EvalSymlinks(context.TODO(), &target); err != nil {
+		t.Fatal(err)
+	}
+
+	// The default scan function ignores tmpfs mounts.
+	scanAll := func(
+		ctx context.Context,
+		entry gofsutil.Entry,
+		cache map[string]gofsutil.Entry) (gofsutil.Info, bool, error) {
+
+		return gofsutil.Info{
+			Device: entry.MountSource,
+			Path:   entry.MountPoint,
+			Type:   entry.FSType,
+			Opts:   entry.MountOpts,
+		}, true, nil
+	}
+	fs := &gofsutil.FS{ScanEntry: scanAll}
+
+	if err := fs.MountRaw(
+		"gofsutil", target, "tmpfs",
+		unix.MS_NOEXEC|unix.MS_NOSUID, "size=1m,mode=0700"); err != nil {
+		t.Fatal(err)
+	}
+	defer unix.Unmount(target, 0)
+
+	mounts, err := fs.GetMounts(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var info *gofsutil.Info
+	for i := range mounts {
+		if mounts[i].Path == target {
+			info = &mounts[i]
+		}
+	}
+	if info == nil {
+		t.Fatalf("%s not mounted", target)
+	}
+	if info.Type != "tmpfs" {
+		t.Errorf("invalid fsType: exp=tmpfs, act=%s", info.Type)
+	}
+	for _, opt := range []string{"noexec", "nosuid"} {
+		if !hasOpt(info.Opts, opt) {
+			t.Errorf("missing option %s: %v", opt, info.Opts)
+		}
+	}
+	if hasOpt(info.Opts, "nodev") {
+		t.Errorf("unexpected option nodev: %v", info.Opts)
+	}
+}
+
+func hasOpt(opts []string, opt string) bool {
+	for _, o := range opts {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
+
+func TestMountRawUserspaceOptions(t *testing.T) {
+	target, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(target)
+
+	// tmpfs rejects unknown options, so the mount fails unless the
+	// options interpreted by userspace are removed.
+	fs := &gofsutil.FS{}
+	if err := fs.MountRaw(
+		"gofsutil", target, "tmpfs", 0,
+		"size=1m,_netdev,x-systemd.automount,nofail"); err != nil {
+		t.Fatal(err)
+	}
+	unix.Unmount(target, 0)
+}
@@ -0,0 +1,15 @@
+package gofsutil
+
+// makeRemountOpts returns the options that remount a mount with the
+// provided options so that it has the option opt. The options of the
+// mount that conflict with opt, ex. "ro" for "rw", are replaced by opt
+// and the other options are preserved.
+func makeRemountOpts(opts []string, opt string) []string {
+	remountOpts := []string{"remount"}
+	for _, o := range opts {
+		if !mountOptsConflict(o, opt) {
+			remountOpts = append(remountOpts, o)
+		}
+	}
+	return append(remountOpts, opt)
+}
@@ -0,0 +1,11 @@
+package gofsutil
+
+import "context"
+
+// remountWithOption remounts the mount at target with the option opt in
+// place of the options that conflict with it
+func (fs *FS) remountWithOption(
+	ctx context.Context, target, opt string) error {
+
+	return ErrNotImplemented
+}
@@ -0,0 +1,25 @@
+package gofsutil
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// remountWithOption remounts the mount at target with the option opt in
+// place of the options that conflict with it
+func (fs *FS) remountWithOption(
+	ctx context.Context, target, opt string) error {
+
+	m, err := fs.getTopMount(ctx, target)
+	if err != nil {
+		return err
+	}
+	opts := makeRemountOpts(m.Opts, opt)
+	log.WithFields(logFields(ctx, log.Fields{
+		"target":  m.Path,
+		"option":  opt,
+		"options": opts,
+	})).Info("remounting with option")
+	return fs.mount(ctx, "", m.Path, "", opts...)
+}
@@ -0,0 +1,82 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+func TestPromoteToReadWrite(t *testing.T) {
+	ctx := gofsutil.WithMountTable(context.TODO(), []gofsutil.Info{
+		{
+			Device: "/dev/sdb",
+			Path:   "/mnt/data",
+			Type:   "ext4",
+			Opts:   []string{"ro", "nosuid", "nodev", "noatime"},
+		},
+		{
+			Device: "/dev/sdc",
+			Path:   "/mnt/logs",
+			Type:   "xfs",
+			Opts:   []string{"rw", "relatime"},
+		},
+	})
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	if err := fs.PromoteToReadWrite(ctx, "/mnt/data"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.PromoteToReadWrite(ctx, "/mnt/logs/"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t,
+		"mount -o remount,nosuid,nodev,noatime,rw /mnt/data",
+		"mount -o remount,relatime,rw /mnt/logs")
+}
+
+func TestDemoteToReadOnly(t *testing.T) {
+	ctx := gofsutil.WithMountTable(context.TODO(), []gofsutil.Info{
+		{
+			Device: "/dev/sdb",
+			Path:   "/mnt/data",
+			Type:   "ext4",
+			Opts:   []string{"rw", "nosuid", "nodev", "noatime"},
+		},
+	})
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	if err := fs.DemoteToReadOnly(ctx, "/mnt/data"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t, "mount -o remount,nosuid,nodev,noatime,ro /mnt/data")
+}
+
+func TestPromoteDemoteErrors(t *testing.T) {
+	ctx := gofsutil.WithMountTable(context.TODO(), []gofsutil.Info{
+		{
+			Device: "/dev/sdb",
+			Path:   "/mnt/data",
+			Type:   "ext4",
+			Opts:   []string{"ro"},
+		},
+	})
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	if err := fs.PromoteToReadWrite(
+		ctx, "/mnt/other"); !errors.Is(err, gofsutil.ErrNotMounted) {
+		t.Errorf("expected ErrNotMounted, got %v", err)
+	}
+	if err := fs.DemoteToReadOnly(
+		ctx, "/mnt/other"); !errors.Is(err, gofsutil.ErrNotMounted) {
+		t.Errorf("expected ErrNotMounted, got %v", err)
+	}
+	if err := fs.PromoteToReadWrite(ctx, "mnt/data"); err == nil {
+		t.Error("expected error for relative target")
+	}
+	r.assertCommands(t)
+}
@@ -0,0 +1,15 @@
+package gofsutil
+
+import "context"
+
+// resolveSource returns the canonical path of the block device that is
+// the source of the mount
+func (fs *FS) resolveSource(ctx context.Context, i Info) (string, error) {
+	return "", ErrNotImplemented
+}
+
+// resolveDevice returns the source of the mount with its symlinks
+// evaluated
+func (fs *FS) resolveDevice(ctx context.Context, i Info) string {
+	return evalSymlinksOrPath(i.Device)
+}
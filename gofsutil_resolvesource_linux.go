@@ -0,0 +1,65 @@
+package gofsutil
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// resolveSource returns the canonical path of the block device that is
+// the source of the mount
+func (fs *FS) resolveSource(ctx context.Context, i Info) (string, error) {
+	src := i.Device
+	if !strings.HasPrefix(src, "/") || isFuseNonBlockMount(i) {
+		return src, nil
+	}
+
+	// The kernel reports the source in /dev, which is DevRoot on the
+	// host, so the source and its fallback below are both in DevRoot.
+	resolved, err := filepath.EvalSymlinks(fs.hostDevPath(src))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+
+		// The kernel may report a source that does not exist, ex.
+		// "/dev/root", in which case the device is found by its number.
+		if i.MajorMinor == "" {
+			return "", fmt.Errorf("%s: %w", src, ErrDeviceNotFound)
+		}
+		link, err := os.Readlink(fs.sysPath("dev", "block", i.MajorMinor))
+		if err != nil {
+			if os.IsNotExist(err) {
+				return "", fmt.Errorf("%s: %w", src, ErrDeviceNotFound)
+			}
+			return "", err
+		}
+		resolved = fs.devPath(path.Base(link))
+	}
+
+	// The source of a bind mount of a file or directory is untouched.
+	st, err := os.Stat(resolved)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("%s: %w", src, ErrDeviceNotFound)
+		}
+		return "", err
+	}
+	if st.Mode()&os.ModeDevice == 0 || st.Mode()&os.ModeCharDevice != 0 {
+		return src, nil
+	}
+	return resolved, nil
+}
+
+// resolveDevice returns the source of the mount resolved by
+// resolveSource, or the source with its symlinks evaluated if it cannot
+// be resolved or is not a block device
+func (fs *FS) resolveDevice(ctx context.Context, i Info) string {
+	if src, err := fs.resolveSource(ctx, i); err == nil && src != i.Device {
+		return src
+	}
+	return evalSymlinksOrPath(fs.hostDevPath(i.Device))
+}
@@ -0,0 +1,108 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"syscall"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+func TestResolveSource(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("requires root")
+	}
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := gofsutil.EvalSymlinks(context.TODO(), &dir); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		devRoot = path.Join(dir, "dev")
+		sysRoot = path.Join(dir, "sys")
+		dm0     = path.Join(devRoot, "dm-0")
+		loop0   = path.Join(devRoot, "loop0")
+		mapper  = path.Join(devRoot, "mapper", "vg-data")
+		byID    = path.Join(devRoot, "disk", "by-id", "dm-name-vg-data")
+	)
+	for _, d := range []string{
+		path.Dir(mapper), path.Dir(byID), path.Join(sysRoot, "dev", "block"),
+	} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for blk, dev := range map[string]int{dm0: 253<<8 | 0, loop0: 7<<8 | 0} {
+		if err := syscall.Mknod(blk, syscall.S_IFBLK|0600, dev); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for link, tgt := range map[string]string{
+		mapper: "../dm-0",
+		byID:   "../../dm-0",
+		path.Join(sysRoot, "dev", "block", "7:0"): "../../devices/virtual/block/loop0",
+	} {
+		if err := os.Symlink(tgt, link); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fs := &gofsutil.FS{DevRoot: devRoot, SysRoot: sysRoot}
+	ctx := context.TODO()
+
+	// The kernel reports the mapper name of a device-mapper device while
+	// the caller provides a udev symlink to the same device.
+	kernel, err := fs.ResolveSource(ctx, gofsutil.Info{Device: mapper})
+	if err != nil {
+		t.Fatal(err)
+	}
+	caller, err := fs.ResolveSource(ctx, gofsutil.Info{Device: byID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kernel != dm0 || caller != dm0 {
+		t.Errorf("invalid sources: exp=%s, kernel=%s, caller=%s",
+			dm0, kernel, caller)
+	}
+
+	for _, tt := range []struct {
+		info gofsutil.Info
+		exp  string
+	}{
+		{gofsutil.Info{
+			Device:     path.Join(devRoot, "root"),
+			MajorMinor: "7:0",
+		}, loop0},
+		{gofsutil.Info{Device: "/dev/mapper/vg-data"}, dm0},
+		{gofsutil.Info{
+			Device:     "/dev/root",
+			MajorMinor: "7:0",
+		}, loop0},
+		{gofsutil.Info{Device: "host:/export", Type: "nfs4"}, "host:/export"},
+		{gofsutil.Info{Device: "tmpfs", Type: "tmpfs"}, "tmpfs"},
+		{gofsutil.Info{Device: dir}, dir},
+	} {
+		act, err := fs.ResolveSource(ctx, tt.info)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.info.Device, err)
+		}
+		if act != tt.exp {
+			t.Errorf("%s: invalid source: exp=%s, act=%s",
+				tt.info.Device, tt.exp, act)
+		}
+	}
+
+	_, err = fs.ResolveSource(
+		ctx, gofsutil.Info{Device: path.Join(devRoot, "sdz")})
+	if !errors.Is(err, gofsutil.ErrDeviceNotFound) {
+		t.Errorf("expected ErrDeviceNotFound: %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package gofsutil
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"time"
+)
+
+// defaultRetryInterval is the interval between the attempts of an
+// operation when RetryPolicy.Interval is not positive.
+const defaultRetryInterval = 500 * time.Millisecond
+
+// RetryPolicy describes how an operation that fails with a transient
+// error is retried.
+type RetryPolicy struct {
+	// Attempts is the maximum number of times an operation is retried
+	// after it first fails. An operation is not retried if Attempts is
+	// not positive.
+	Attempts int
+
+	// Interval is the time to wait before each retry. If not positive
+	// then 500ms is used.
+	Interval time.Duration
+}
+
+// wait blocks for the policy's interval or until the context is
+// cancelled, in which case the context's error is returned.
+func (p RetryPolicy) wait(ctx context.Context) error {
+	interval := p.Interval
+	if interval <= 0 {
+		interval = defaultRetryInterval
+	}
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// isMountRetryable returns a flag indicating whether the mount of source
+// failed because the source device does not exist yet, ex. when udev has
+// not yet created the device node of a recently attached volume.
+func isMountRetryable(err error, source string) bool {
+	if errors.Is(err, ErrDeviceNotFound) {
+		return true
+	}
+	if !strings.HasPrefix(source, "/") {
+		return false
+	}
+	_, statErr := os.Stat(source)
+	return os.IsNotExist(statErr)
+}
@@ -0,0 +1,121 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+// newTestUdevLagRunner returns a command runner that fails the first
+// mount commands, up to the provided number of failures, with a report
+// that the special device does not exist. After each failure the symlink
+// link is pointed to the next of the devices, as if udev were still
+// settling.
+func newTestUdevLagRunner(
+	t *testing.T, fails int, link string, devs ...string) *testCommandRunner {
+
+	var mounts int
+	return &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			if args[0] != "mount" {
+				return "", nil
+			}
+			mounts++
+			if mounts > fails {
+				return "", nil
+			}
+			if err := os.Remove(link); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Symlink(devs[mounts], link); err != nil {
+				t.Fatal(err)
+			}
+			return "mount: /mnt: special device " + args[len(args)-2] +
+				" does not exist.\n", errors.New("exit status 32")
+		},
+	}
+}
+
+func TestMountRetry(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if dir, err = filepath.EvalSymlinks(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	var devs []string
+	for _, name := range []string{"sdb", "sdc", "sdd"} {
+		dev := path.Join(dir, name)
+		if err := ioutil.WriteFile(dev, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		devs = append(devs, dev)
+	}
+	link := path.Join(dir, "by-id")
+	if err := os.Symlink(devs[0], link); err != nil {
+		t.Fatal(err)
+	}
+
+	r := newTestUdevLagRunner(t, 2, link, devs...)
+	fs := &gofsutil.FS{
+		ProcRoot:   newTestRootProcRoot(t),
+		RunCommand: r.run,
+		Retry: gofsutil.RetryPolicy{
+			Attempts: 3,
+			Interval: time.Millisecond,
+		},
+	}
+	if err := fs.Mount(context.TODO(), link, "/mnt", "ext4"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t,
+		"mount -t ext4 "+devs[0]+" /mnt",
+		"mount -t ext4 "+devs[1]+" /mnt",
+		"mount -t ext4 "+devs[2]+" /mnt")
+}
+
+func TestMountRetryExhausted(t *testing.T) {
+	r := newTestErrorRunner(
+		"mount: /mnt: special device /dev/sdb does not exist.\n")
+	fs := &gofsutil.FS{
+		ProcRoot:   newTestRootProcRoot(t),
+		RunCommand: r.run,
+		Retry: gofsutil.RetryPolicy{
+			Attempts: 2,
+			Interval: time.Millisecond,
+		},
+	}
+	err := fs.Mount(context.TODO(), "/dev/sdb", "/mnt", "ext4")
+	if !errors.Is(err, gofsutil.ErrDeviceNotFound) {
+		t.Fatalf("expected ErrDeviceNotFound: %v", err)
+	}
+	r.assertCommands(t,
+		"mount -t ext4 /dev/sdb /mnt",
+		"mount -t ext4 /dev/sdb /mnt",
+		"mount -t ext4 /dev/sdb /mnt")
+}
+
+func TestMountNoRetry(t *testing.T) {
+	r := newTestErrorRunner(
+		"mount: /mnt: special device /dev/sdb does not exist.\n")
+	fs := &gofsutil.FS{
+		RunCommand: r.run,
+		ProcRoot:   newTestRootProcRoot(t),
+	}
+
+	if err := fs.Mount(
+		context.TODO(), "/dev/sdb", "/mnt", "ext4"); err == nil {
+		t.Fatal("expected error")
+	}
+	r.assertCommands(t, "mount -t ext4 /dev/sdb /mnt")
+}
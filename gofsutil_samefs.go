@@ -0,0 +1,80 @@
+package gofsutil
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+)
+
+// sameFilesystem returns a flag indicating whether the two paths are on
+// the same filesystem
+func (fs *FS) sameFilesystem(
+	ctx context.Context, pathA, pathB string) (bool, error) {
+
+	a, devA, err := statDevice(pathA)
+	if err != nil {
+		return false, err
+	}
+	b, devB, err := statDevice(pathB)
+	if err != nil {
+		return false, err
+	}
+	if devA != devB {
+		return false, nil
+	}
+
+	// Bind mounts of different directories of a filesystem share its
+	// device but have different roots.
+	mounts, err := fs.getMounts(ctx)
+	if err != nil {
+		return false, err
+	}
+	majorMinor := fmt.Sprintf("%d:%d", unix.Major(devA), unix.Minor(devA))
+	var devMounts []Info
+	for _, m := range mounts {
+		if m.MajorMinor == majorMinor {
+			devMounts = append(devMounts, m)
+		}
+	}
+	mA, mB := findMountForPath(devMounts, a), findMountForPath(devMounts, b)
+	if mA == nil || mB == nil {
+		return true, nil
+	}
+	if mA.Root != mB.Root {
+		log.WithFields(logFields(ctx, log.Fields{
+			"pathA": a,
+			"rootA": mA.Root,
+			"pathB": b,
+			"rootB": mB.Root,
+		})).Debug("paths share a device but not a mount root")
+		return false, nil
+	}
+	return true, nil
+}
+
+// statDevice evaluates the symlinks in p and returns the resulting path
+// and the ID of the device that contains it
+func statDevice(p string) (string, uint64, error) {
+	p, err := filepath.EvalSymlinks(p)
+	if err != nil {
+		return "", 0, err
+	}
+	p, err = filepath.Abs(p)
+	if err != nil {
+		return "", 0, err
+	}
+	fi, err := os.Stat(p)
+	if err != nil {
+		return "", 0, err
+	}
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return "", 0, fmt.Errorf("%s: stat not supported", p)
+	}
+	return p, uint64(st.Dev), nil
+}
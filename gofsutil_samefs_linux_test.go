@@ -0,0 +1,119 @@
+package gofsutil_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+// newTestSameFSTree mounts a tmpfs at a temporary directory and creates
+// the following tree, where "other" is the mount point of another tmpfs
+// and "bind1" and "bind2" are bind mounts of "src/d1" and "src/d2". The
+// tree is removed when the test finishes.
+//
+//	src/d1/a
+//	src/d2/b
+//	other/c
+//	link -> other/c
+//	bind1
+//	bind2
+func newTestSameFSTree(t *testing.T) string {
+	if os.Getuid() != 0 {
+		t.Skip("requires root")
+	}
+	root := t.TempDir()
+	if err := unix.Mount("tmpfs", root, "tmpfs", 0, "size=1m"); err != nil {
+		t.Skipf("mount tmpfs: %v", err)
+	}
+	t.Cleanup(func() {
+		for _, dir := range []string{"bind2", "bind1", "other"} {
+			unix.Unmount(path.Join(root, dir), 0)
+		}
+		unix.Unmount(root, 0)
+	})
+	for _, dir := range []string{
+		"src/d1", "src/d2", "other", "bind1", "bind2"} {
+
+		if err := os.MkdirAll(path.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := unix.Mount(
+		"tmpfs", path.Join(root, "other"), "tmpfs", 0, "size=1m"); err != nil {
+		t.Skipf("mount tmpfs: %v", err)
+	}
+	for _, name := range []string{"src/d1/a", "src/d2/b", "other/c"} {
+		if err := ioutil.WriteFile(
+			path.Join(root, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(
+		path.Join(root, "other", "c"), path.Join(root, "link")); err != nil {
+		t.Fatal(err)
+	}
+	for src, dst := range map[string]string{
+		"src/d1": "bind1", "src/d2": "bind2"} {
+
+		if err := unix.Mount(
+			path.Join(root, src), path.Join(root, dst),
+			"", unix.MS_BIND, ""); err != nil {
+			t.Skipf("bind mount: %v", err)
+		}
+	}
+	return root
+}
+
+func TestSameFilesystem(t *testing.T) {
+	root := newTestSameFSTree(t)
+
+	// The default ScanEntry omits tmpfs mounts.
+	scanAll := func(
+		ctx context.Context,
+		entry gofsutil.Entry,
+		cache map[string]gofsutil.Entry) (gofsutil.Info, bool, error) {
+
+		return gofsutil.Info{
+			Device:     entry.MountSource,
+			Path:       entry.MountPoint,
+			MajorMinor: entry.MajorMinor,
+			Root:       entry.Root,
+			Type:       entry.FSType,
+		}, true, nil
+	}
+	fs := &gofsutil.FS{ScanEntry: scanAll}
+
+	tests := []struct {
+		a, b string
+		same bool
+	}{
+		{"src/d1/a", "src/d2/b", true},
+		{"src/d1", "src", true},
+		{"bind1/a", "bind1", true},
+		{"src/d1/a", "other/c", false},
+		{"link", "other", true},
+		{"link", "src", false},
+		{"bind1/a", "bind2/b", false},
+	}
+	for _, tt := range tests {
+		same, err := fs.SameFilesystem(
+			context.TODO(), path.Join(root, tt.a), path.Join(root, tt.b))
+		if err != nil {
+			t.Fatalf("%s, %s: %v", tt.a, tt.b, err)
+		}
+		if same != tt.same {
+			t.Errorf("%s, %s: same=%v, exp=%v", tt.a, tt.b, same, tt.same)
+		}
+	}
+
+	if _, err := fs.SameFilesystem(
+		context.TODO(), path.Join(root, "missing"), root); err == nil {
+		t.Error("expected error for missing path")
+	}
+}
@@ -0,0 +1,88 @@
+package gofsutil
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// MakeSELinuxContextOption returns the "context" mount option for the
+// provided SELinux context. The value is always double-quoted so that
+// contexts containing commas, such as those with multiple categories,
+// are not split into separate mount options.
+func MakeSELinuxContextOption(seLinuxContext string) (string, error) {
+	seLinuxContext = strings.TrimSpace(seLinuxContext)
+	if len(seLinuxContext) > 1 &&
+		strings.HasPrefix(seLinuxContext, `"`) &&
+		strings.HasSuffix(seLinuxContext, `"`) {
+		seLinuxContext = seLinuxContext[1 : len(seLinuxContext)-1]
+	}
+	if seLinuxContext == "" {
+		return "", fmt.Errorf("invalid selinux context: empty")
+	}
+	if strings.ContainsAny(seLinuxContext, "\"\n\x00") {
+		return "", fmt.Errorf("invalid selinux context: %q", seLinuxContext)
+	}
+	return fmt.Sprintf(`context="%s"`, seLinuxContext), nil
+}
+
+// SELinuxContext returns the SELinux context with which the filesystem
+// is mounted. The per-mount options are searched before the super block
+// options. A false value is returned if the mount does not have an
+// SELinux context.
+func (i Info) SELinuxContext() (string, bool) {
+	for _, opts := range [][]string{i.Opts, i.SuperOpts} {
+		for _, o := range opts {
+			if !strings.HasPrefix(o, "context=") {
+				continue
+			}
+			v := strings.TrimPrefix(o, "context=")
+			if len(v) > 1 && strings.HasPrefix(v, `"`) &&
+				strings.HasSuffix(v, `"`) {
+				v = v[1 : len(v)-1]
+			}
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// getSELinuxMountContext returns the SELinux context of the filesystem
+// mounted at target. An empty string is returned if the filesystem is
+// mounted without a context, even if it is labeled ("seclabel").
+func (fs *FS) getSELinuxMountContext(
+	ctx context.Context, target string) (string, error) {
+
+	target = filepath.Clean(target)
+	mounts, err := fs.getMounts(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	// The last entry for a path is the mount visible at that path.
+	for i := len(mounts) - 1; i >= 0; i-- {
+		if mounts[i].Path != target {
+			continue
+		}
+		seLinuxContext, _ := mounts[i].SELinuxContext()
+		return seLinuxContext, nil
+	}
+
+	return "", fmt.Errorf("getSELinuxMountContext: not mounted: %s", target)
+}
+
+// mountWithContext mounts source to target with the provided SELinux
+// context injected into the mount options.
+func (fs *FS) mountWithContext(
+	ctx context.Context,
+	source, target, fsType, seLinuxContext string,
+	opts ...string) error {
+
+	ctxOpt, err := MakeSELinuxContextOption(seLinuxContext)
+	if err != nil {
+		return err
+	}
+	return fs.mount(
+		ctx, source, target, fsType, append(opts, ctxOpt)...)
+}
@@ -0,0 +1,103 @@
+package gofsutil_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+const seLinuxTestContext = "system_u:object_r:container_file_t:s0:c1,c2"
+
+func TestMakeSELinuxContextOption(t *testing.T) {
+	tests := []struct {
+		ctx    string
+		result string
+		err    bool
+	}{
+		{
+			ctx:    seLinuxTestContext,
+			result: `context="` + seLinuxTestContext + `"`,
+		},
+		{
+			ctx:    `"` + seLinuxTestContext + `"`,
+			result: `context="` + seLinuxTestContext + `"`,
+		},
+		{
+			ctx: "",
+			err: true,
+		},
+		{
+			ctx: `system_u:object_r:"bad"`,
+			err: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run("", func(st *testing.T) {
+			st.Parallel()
+			opt, err := gofsutil.MakeSELinuxContextOption(tt.ctx)
+			if tt.err {
+				if err == nil {
+					st.Errorf("expected error for context %q", tt.ctx)
+				}
+				return
+			}
+			if err != nil {
+				st.Fatal(err)
+			}
+			if opt != tt.result {
+				st.Errorf("invalid context option: exp=%s, act=%s",
+					tt.result, opt)
+			}
+		})
+	}
+}
+
+func TestSELinuxContextMountArgs(t *testing.T) {
+	opt, err := gofsutil.MakeSELinuxContextOption(seLinuxTestContext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := gofsutil.MakeMountArgs(
+		context.TODO(), "/dev/sdc", "/mnt", "xfs", "rw", opt)
+	exp := fmt.Sprintf("-t xfs -o rw,%s /dev/sdc /mnt", opt)
+	if act := strings.Join(args, " "); act != exp {
+		t.Errorf("invalid mount args: exp=%s, act=%s", exp, act)
+	}
+}
+
+func TestSELinuxContextRoundTrip(t *testing.T) {
+	opt, err := gofsutil.MakeSELinuxContextOption(seLinuxTestContext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := fmt.Sprintf(
+		"72 60 8:1 / /mnt rw,relatime shared:28 - xfs /dev/sda1 "+
+			"rw,seclabel,%s,attr2,inode64,noquota\n", opt)
+
+	mounts, _, err := gofsutil.ReadProcMountsFrom(
+		context.TODO(),
+		strings.NewReader(data),
+		false,
+		gofsutil.ProcMountsFields,
+		nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mounts) != 1 {
+		t.Fatalf("invalid mount count: exp=1, act=%d", len(mounts))
+	}
+
+	seLinuxContext, ok := mounts[0].SELinuxContext()
+	if !ok {
+		t.Fatalf("selinux context not found: %+v", mounts[0])
+	}
+	if seLinuxContext != seLinuxTestContext {
+		t.Errorf("invalid selinux context: exp=%s, act=%s",
+			seLinuxTestContext, seLinuxContext)
+	}
+}
@@ -0,0 +1,13 @@
+package gofsutil
+
+import "context"
+
+// shrinkFS shrinks the filesystem of type fsType on device to
+// newSizeBytes
+func (fs *FS) shrinkFS(
+	ctx context.Context,
+	device, fsType string,
+	newSizeBytes uint64) error {
+
+	return ErrNotImplemented
+}
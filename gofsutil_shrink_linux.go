@@ -0,0 +1,101 @@
+package gofsutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// shrinkFS shrinks the filesystem of type fsType on device to
+// newSizeBytes
+func (fs *FS) shrinkFS(
+	ctx context.Context,
+	device, fsType string,
+	newSizeBytes uint64) error {
+
+	if !isExtFS(fsType) {
+		return fmt.Errorf("%w: fsType=%s", ErrShrinkUnsupported, fsType)
+	}
+
+	// resize2fs shrinks only unmounted filesystems.
+	mounted, err := fs.isDeviceMounted(ctx, device)
+	if err != nil {
+		return err
+	}
+	if mounted {
+		return fmt.Errorf("shrink %s: %w", device, ErrAlreadyMounted)
+	}
+
+	buf, err := fs.execFsckCmd(ctx, "dumpe2fs", "-h", device)
+	if err != nil {
+		return err
+	}
+	blockCount, err := parseDumpe2fsUint(buf, "Block count")
+	if err != nil {
+		return fmt.Errorf("%s: %v", device, err)
+	}
+	blockSize, err := parseDumpe2fsUint(buf, "Block size")
+	if err != nil {
+		return fmt.Errorf("%s: %v", device, err)
+	}
+	if blockSize == 0 {
+		return fmt.Errorf("%s: invalid block size: 0", device)
+	}
+	if size := blockCount * blockSize; newSizeBytes >= size {
+		return fmt.Errorf(
+			"invalid shrink size: %d: must be less than the current size: %d",
+			newSizeBytes, size)
+	}
+	if newSizeBytes%blockSize != 0 {
+		return fmt.Errorf(
+			"invalid shrink size: %d: must be a multiple of the block size: %d",
+			newSizeBytes, blockSize)
+	}
+
+	f := log.Fields{
+		"device":       device,
+		"fsType":       fsType,
+		"newSizeBytes": newSizeBytes,
+	}
+	log.WithFields(f).Info("shrinking filesystem")
+
+	// resize2fs refuses to shrink a filesystem that has not been
+	// checked since it was last mounted. An exit status of 1 indicates
+	// e2fsck corrected errors.
+	if _, err := fs.execFsckCmd(ctx, "e2fsck", "-f", "-p", device); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+			return err
+		}
+	}
+
+	newBlockCount := strconv.FormatUint(newSizeBytes/blockSize, 10)
+	if buf, err := fs.exec(
+		ctx, "resize2fs", device, newBlockCount); err != nil {
+
+		out := string(buf)
+		log.WithFields(f).WithField("output", out).WithError(
+			err).Error("resize2fs failed")
+		return fmt.Errorf(
+			"resize2fs failed: %v\ndevice: %s\noutput: %s", err, device, out)
+	}
+	return nil
+}
+
+// parseDumpe2fsUint returns the value of the named numeric field of the
+// output of 'dumpe2fs -h'.
+func parseDumpe2fsUint(buf []byte, name string) (uint64, error) {
+	v, err := parseDumpe2fsField(buf, name)
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.ParseUint(v, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %q", name, v)
+	}
+	return n, nil
+}
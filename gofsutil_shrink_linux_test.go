@@ -0,0 +1,76 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+const dumpe2fsSizeData = `dumpe2fs 1.46.5 (30-Dec-2021)
+Filesystem volume name:   <none>
+Filesystem state:         clean
+Block count:              262144
+Block size:               4096
+`
+
+func TestShrinkFS(t *testing.T) {
+	procRoot := newTestProcRoot(t, procMountInfoData, "self")
+
+	r := &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			if args[0] == "dumpe2fs" {
+				return dumpe2fsSizeData, nil
+			}
+			return "", nil
+		},
+	}
+	fs := &gofsutil.FS{ProcRoot: procRoot, RunCommand: r.run}
+
+	if err := fs.ShrinkFS(
+		context.TODO(), "/dev/sdz", "ext4", 512*1024*1024); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t,
+		"dumpe2fs -h /dev/sdz",
+		"e2fsck -f -p /dev/sdz",
+		"resize2fs /dev/sdz 131072")
+}
+
+func TestShrinkFSInvalidSize(t *testing.T) {
+	procRoot := newTestProcRoot(t, procMountInfoData, "self")
+
+	r := &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			return dumpe2fsSizeData, nil
+		},
+	}
+	fs := &gofsutil.FS{ProcRoot: procRoot, RunCommand: r.run}
+
+	for _, size := range []uint64{1024 * 1024 * 1024, 2 << 30, 4097} {
+		if err := fs.ShrinkFS(
+			context.TODO(), "/dev/sdz", "ext4", size); err == nil {
+			t.Errorf("expected error for size %d", size)
+		}
+	}
+
+	// The filesystem is never checked or resized.
+	r.assertCommands(t,
+		"dumpe2fs -h /dev/sdz",
+		"dumpe2fs -h /dev/sdz",
+		"dumpe2fs -h /dev/sdz")
+}
+
+func TestShrinkFSUnsupported(t *testing.T) {
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	for _, fsType := range []string{"xfs", "btrfs"} {
+		err := fs.ShrinkFS(context.TODO(), "/dev/sdz", fsType, 1<<20)
+		if !errors.Is(err, gofsutil.ErrShrinkUnsupported) {
+			t.Errorf("%s: expected ErrShrinkUnsupported: %v", fsType, err)
+		}
+	}
+	r.assertCommands(t)
+}
@@ -0,0 +1,63 @@
+package gofsutil
+
+import (
+	"context"
+	"time"
+)
+
+// MountSnapshot is the mount table at a point in time as returned by
+// SnapshotMounts.
+type MountSnapshot struct {
+	// Time is the time at which the snapshot was taken.
+	Time time.Time
+
+	// Mounts are the mounts returned by GetMounts at Time.
+	Mounts []Info
+}
+
+// mountIdentity is the identity of a mount that is stable across
+// snapshots. The mount ID is not used since the kernel reuses the ID of
+// an unmounted filesystem.
+type mountIdentity struct {
+	device, path, root string
+}
+
+// snapshotMounts returns a snapshot of the mount table
+func (fs *FS) snapshotMounts(ctx context.Context) (MountSnapshot, error) {
+	now := time.Now()
+	mounts, err := fs.getMounts(ctx)
+	if err != nil {
+		return MountSnapshot{}, err
+	}
+	return MountSnapshot{Time: now, Mounts: mounts}, nil
+}
+
+// DiffMounts returns the mounts in after that are not in before and the
+// mounts in before that are not in after. Mounts are matched by their
+// Device, Path, and Root, so a mount whose options changed between the
+// snapshots, ex. after a remount, is neither added nor removed. Each
+// occurrence of a mount stacked on the same path is matched separately.
+func DiffMounts(before, after MountSnapshot) (added, removed []Info) {
+	return diffMountInfos(after.Mounts, before.Mounts),
+		diffMountInfos(before.Mounts, after.Mounts)
+}
+
+// diffMountInfos returns the mounts in a that are not in b. If a mount
+// occurs more often in a than in b then its last occurrences are
+// returned.
+func diffMountInfos(a, b []Info) []Info {
+	counts := map[mountIdentity]int{}
+	for _, m := range b {
+		counts[mountIdentity{m.Device, m.Path, m.Root}]++
+	}
+	var diff []Info
+	for _, m := range a {
+		id := mountIdentity{m.Device, m.Path, m.Root}
+		if counts[id] > 0 {
+			counts[id]--
+			continue
+		}
+		diff = append(diff, m)
+	}
+	return diff
+}
@@ -0,0 +1,91 @@
+package gofsutil_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+func TestDiffMounts(t *testing.T) {
+	root := gofsutil.Info{
+		ID: 1, Device: "/dev/sda1", Path: "/", Root: "/",
+		Opts: []string{"rw"},
+	}
+	data := gofsutil.Info{
+		ID: 20, Device: "/dev/sdb", Path: "/mnt/data", Root: "/",
+		Opts: []string{"rw", "relatime"},
+	}
+	logs := gofsutil.Info{
+		ID: 21, Device: "/dev/sdc", Path: "/mnt/logs", Root: "/",
+		Opts: []string{"rw"},
+	}
+	bind := gofsutil.Info{
+		ID: 22, Device: "/dev/sdb", Path: "/mnt/bind", Root: "/dir",
+		Opts: []string{"rw"},
+	}
+
+	// The data mount is remounted read-only and its ID changes, which is
+	// neither an addition nor a removal. The ID of the logs mount is
+	// reused by the new bind mount of another root.
+	dataRO := data
+	dataRO.ID = 30
+	dataRO.Opts = []string{"ro", "relatime"}
+	bindOther := bind
+	bindOther.ID = 21
+	bindOther.Root = "/other"
+
+	before := gofsutil.MountSnapshot{
+		Mounts: []gofsutil.Info{root, data, logs, bind},
+	}
+	after := gofsutil.MountSnapshot{
+		Mounts: []gofsutil.Info{root, dataRO, bindOther},
+	}
+	added, removed := gofsutil.DiffMounts(before, after)
+	if exp := []gofsutil.Info{bindOther}; !reflect.DeepEqual(added, exp) {
+		t.Errorf("added=%+v, exp=%+v", added, exp)
+	}
+	if exp := []gofsutil.Info{logs, bind}; !reflect.DeepEqual(removed, exp) {
+		t.Errorf("removed=%+v, exp=%+v", removed, exp)
+	}
+
+	added, removed = gofsutil.DiffMounts(before, before)
+	if len(added) != 0 || len(removed) != 0 {
+		t.Errorf("added=%+v, removed=%+v, exp none", added, removed)
+	}
+}
+
+func TestDiffMountsStacked(t *testing.T) {
+	data := gofsutil.Info{Device: "/dev/sdb", Path: "/mnt/data", Root: "/"}
+	before := gofsutil.MountSnapshot{Mounts: []gofsutil.Info{data}}
+	after := gofsutil.MountSnapshot{Mounts: []gofsutil.Info{data, data}}
+
+	added, removed := gofsutil.DiffMounts(before, after)
+	if len(added) != 1 || len(removed) != 0 {
+		t.Errorf("added=%+v, removed=%+v, exp one added", added, removed)
+	}
+	added, removed = gofsutil.DiffMounts(after, before)
+	if len(added) != 0 || len(removed) != 1 {
+		t.Errorf("added=%+v, removed=%+v, exp one removed", added, removed)
+	}
+}
+
+func TestSnapshotMounts(t *testing.T) {
+	mounts := []gofsutil.Info{
+		{Device: "/dev/sdb", Path: "/mnt/data", Root: "/", Type: "ext4"},
+	}
+	ctx := gofsutil.WithMountTable(context.TODO(), mounts)
+	fs := &gofsutil.FS{}
+
+	snapshot, err := fs.SnapshotMounts(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.Time.IsZero() {
+		t.Error("snapshot time is zero")
+	}
+	if !reflect.DeepEqual(snapshot.Mounts, mounts) {
+		t.Errorf("mounts=%+v, exp=%+v", snapshot.Mounts, mounts)
+	}
+}
@@ -0,0 +1,59 @@
+package gofsutil
+
+import "strings"
+
+const (
+	// SourceSchemeNFS is the scheme of a mount source of the form
+	// "host:/export".
+	SourceSchemeNFS = "nfs"
+
+	// SourceSchemeCIFS is the scheme of a mount source of the form
+	// "//host/share".
+	SourceSchemeCIFS = "cifs"
+
+	// SourceSchemeDevice is the scheme of a mount source that is a
+	// device, ex. "/dev/sdb".
+	SourceSchemeDevice = "device"
+
+	// SourceSchemePath is the scheme of a mount source that is a path
+	// other than a device, ex. the source of a bind mount.
+	SourceSchemePath = "path"
+)
+
+// ParseSource decomposes the mount's Source, or its Device if Source is
+// empty, into a scheme, a host, and a path. A source of the form
+// "host:/export" or "[fe80::1]:/export" is an NFS source, and the
+// brackets are removed from an IPv6 host. A source of the form
+// "//host/share" is a CIFS source with the path "/share". A path beneath
+// "/dev" is a device and any other absolute path, ex. the source of a
+// bind mount, is a path; neither has a host. The scheme is empty for any
+// other source, ex. "tmpfs", in which case the source is returned as the
+// path.
+func (i Info) ParseSource() (scheme, host, path string) {
+	src := i.Source
+	if src == "" {
+		src = i.Device
+	}
+
+	switch {
+	case strings.HasPrefix(src, "//"):
+		rest := src[2:]
+		if n := strings.Index(rest, "/"); n >= 0 {
+			return SourceSchemeCIFS, rest[:n], rest[n:]
+		}
+		return SourceSchemeCIFS, rest, ""
+	case strings.HasPrefix(src, "/dev/"):
+		return SourceSchemeDevice, "", src
+	case strings.HasPrefix(src, "/"):
+		return SourceSchemePath, "", src
+	case strings.HasPrefix(src, "["):
+		if n := strings.Index(src, "]:"); n > 1 {
+			return SourceSchemeNFS, src[1:n], src[n+2:]
+		}
+	default:
+		if n := strings.Index(src, ":"); n > 0 {
+			return SourceSchemeNFS, src[:n], src[n+1:]
+		}
+	}
+	return "", "", src
+}
@@ -0,0 +1,41 @@
+package gofsutil_test
+
+import (
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+func TestParseSource(t *testing.T) {
+	for _, tt := range []struct {
+		info   gofsutil.Info
+		scheme string
+		host   string
+		path   string
+	}{
+		{gofsutil.Info{Source: "nfs.local:/export/vol1"},
+			gofsutil.SourceSchemeNFS, "nfs.local", "/export/vol1"},
+		{gofsutil.Info{Source: "10.0.0.5:/export"},
+			gofsutil.SourceSchemeNFS, "10.0.0.5", "/export"},
+		{gofsutil.Info{Source: "[fd00::5]:/export/vol1"},
+			gofsutil.SourceSchemeNFS, "fd00::5", "/export/vol1"},
+		{gofsutil.Info{Source: "//fileserver/share/dir"},
+			gofsutil.SourceSchemeCIFS, "fileserver", "/share/dir"},
+		{gofsutil.Info{Source: "//fileserver"},
+			gofsutil.SourceSchemeCIFS, "fileserver", ""},
+		{gofsutil.Info{Source: "/dev/sdb"},
+			gofsutil.SourceSchemeDevice, "", "/dev/sdb"},
+		{gofsutil.Info{Device: "/dev/mapper/vg-lv"},
+			gofsutil.SourceSchemeDevice, "", "/dev/mapper/vg-lv"},
+		{gofsutil.Info{Device: "/dev/sdb", Source: "/mnt/data/sub"},
+			gofsutil.SourceSchemePath, "", "/mnt/data/sub"},
+		{gofsutil.Info{Source: "tmpfs"}, "", "", "tmpfs"},
+		{gofsutil.Info{Source: "[fd00::5"}, "", "", "[fd00::5"},
+	} {
+		scheme, host, path := tt.info.ParseSource()
+		if scheme != tt.scheme || host != tt.host || path != tt.path {
+			t.Errorf("%+v: exp=%q,%q,%q, act=%q,%q,%q", tt.info,
+				tt.scheme, tt.host, tt.path, scheme, host, path)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package gofsutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// defaultStaleCheckTimeout is the amount of time a stat of a mount
+// target may take before the mount is considered stale.
+const defaultStaleCheckTimeout = 5 * time.Second
+
+// StatFunc defines the signature of the function used to stat a path
+// when checking whether the filesystem mounted at that path is stale.
+type StatFunc func(path string) error
+
+// DefaultStatFunc returns the default stat function.
+func DefaultStatFunc() StatFunc {
+	return defaultStatFunc
+}
+
+func defaultStatFunc(path string) error {
+	var buf syscall.Statfs_t
+	return syscall.Statfs(path, &buf)
+}
+
+// isStaleNFSMount stats target in a separate goroutine so that a stat
+// hung on an unresponsive server is abandoned rather than blocking the
+// caller. The mount is stale if the stat does not complete within the
+// FS's StatTimeout. The end of the context is not a sign of staleness,
+// so the context's error is returned instead.
+func (fs *FS) isStaleNFSMount(
+	ctx context.Context, target string) (bool, error) {
+
+	stat := fs.Stat
+	if stat == nil {
+		stat = defaultStatFunc
+	}
+	timeout := fs.StatTimeout
+	if timeout <= 0 {
+		timeout = defaultStaleCheckTimeout
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	// The channel is buffered so the goroutine does not leak if the
+	// stat returns after the timeout has elapsed.
+	errCh := make(chan error, 1)
+	go func() { errCh <- stat(target) }()
+
+	f := logFields(ctx, log.Fields{"target": target})
+	select {
+	case err := <-errCh:
+		if err == nil {
+			return false, nil
+		}
+		if isStaleErr(err) {
+			log.WithFields(f).WithError(err).Warn("stale mount")
+			return true, nil
+		}
+		return false, err
+	case <-timer.C:
+		log.WithFields(f).WithField("timeout", timeout).Warn(
+			"stat timed out")
+		return true, nil
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+// recoverStaleMount unmounts the stale filesystem mounted at target and
+// mounts it again with its source, type, and options
+func (fs *FS) recoverStaleMount(ctx context.Context, target string) error {
+	stale, err := fs.isStaleNFSMount(ctx, target)
+	if err != nil || !stale {
+		return err
+	}
+
+	// The mount is captured before it is unmounted. The per-superblock
+	// options of an NFS mount include the options of the mount request,
+	// ex. "vers=4.2", and precede the per-mount options, ex. "ro", which
+	// take precedence.
+	m, err := fs.getTopMount(ctx, target)
+	if err != nil {
+		return err
+	}
+	opts := ResolveMountOptions(append(
+		append([]string(nil), m.SuperOpts...), m.Opts...))
+	f := logFields(ctx, log.Fields{
+		"target":  m.Path,
+		"source":  m.Device,
+		"fsType":  m.Type,
+		"options": opts,
+	})
+	log.WithFields(f).Warn("recovering stale mount")
+
+	err = fs.unmount(ctx, m.Path)
+	if errors.Is(err, errTargetBusy) {
+		log.WithFields(f).Warn("target is busy, falling back to lazy unmount")
+		err = fs.unmountLazy(ctx, m.Path)
+	}
+	if err != nil && !errors.Is(err, ErrNotMounted) {
+		return fmt.Errorf("recover stale mount: %s: %w", m.Path, err)
+	}
+
+	if err := fs.mount(ctx, m.Device, m.Path, m.Type, opts...); err != nil {
+		log.WithFields(f).WithError(err).Error("failed to recover stale mount")
+		return fmt.Errorf("recover stale mount: %s: %w", m.Path, err)
+	}
+	log.WithFields(f).Info("recovered stale mount")
+	return nil
+}
+
+// isStaleErr returns a flag indicating whether err is ESTALE.
+func isStaleErr(err error) bool {
+	return errors.Is(err, syscall.ESTALE)
+}
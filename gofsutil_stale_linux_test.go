@@ -0,0 +1,91 @@
+package gofsutil_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+func TestRecoverStaleMount(t *testing.T) {
+	ctx := gofsutil.WithMountTable(context.TODO(), []gofsutil.Info{
+		{
+			Device:    "host:/export",
+			Path:      "/mnt/nfs",
+			Type:      "nfs4",
+			Opts:      []string{"ro", "relatime"},
+			SuperOpts: []string{"rw", "vers=4.2", "hard", "addr=10.0.0.1"},
+		},
+	})
+	r := &testCommandRunner{handler: func(args []string) (string, error) {
+		if args[0] == "umount.nfs4" {
+			return "umount.nfs4: /mnt/nfs: device is busy",
+				fmt.Errorf("exit status 16")
+		}
+		return "", nil
+	}}
+
+	// The mount table attached to the context is not updated when the
+	// stale mount is unmounted, so the remount would otherwise be
+	// rejected as an overmount.
+	fs := &gofsutil.FS{
+		RunCommand:     r.run,
+		AllowOvermount: true,
+		Stat: func(path string) error {
+			return fmt.Errorf("statfs %s: %w", path, syscall.ESTALE)
+		},
+	}
+	if err := fs.RecoverStaleMount(ctx, "/mnt/nfs"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t,
+		"umount.nfs4 /mnt/nfs",
+		"umount -l /mnt/nfs",
+		"mount -t nfs4 -o vers=4.2,hard,addr=10.0.0.1,ro,relatime "+
+			"host:/export /mnt/nfs")
+}
+
+func TestRecoverStaleMountNotStale(t *testing.T) {
+	ctx := gofsutil.WithMountTable(context.TODO(), []gofsutil.Info{
+		{Device: "host:/export", Path: "/mnt/nfs", Type: "nfs4"},
+	})
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{
+		RunCommand: r.run,
+		Stat:       func(string) error { return nil },
+	}
+	if err := fs.RecoverStaleMount(ctx, "/mnt/nfs"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t)
+}
+
+func TestRecoverStaleMountRemountFailed(t *testing.T) {
+	ctx := gofsutil.WithMountTable(context.TODO(), []gofsutil.Info{
+		{Device: "host:/export", Path: "/mnt/nfs", Type: "nfs4"},
+	})
+	r := &testCommandRunner{handler: func(args []string) (string, error) {
+		if args[0] == "mount" {
+			return "mount.nfs4: Connection timed out",
+				fmt.Errorf("exit status 32")
+		}
+		return "", nil
+	}}
+	fs := &gofsutil.FS{
+		RunCommand:     r.run,
+		AllowOvermount: true,
+		Stat: func(path string) error {
+			return fmt.Errorf("statfs %s: %w", path, syscall.ESTALE)
+		},
+	}
+	err := fs.RecoverStaleMount(ctx, "/mnt/nfs")
+	if err == nil || !strings.Contains(err.Error(), "recover stale mount") {
+		t.Fatalf("expected recover error: %v", err)
+	}
+	if cmds := r.commands(); len(cmds) == 0 || cmds[0] != "umount.nfs4 /mnt/nfs" {
+		t.Errorf("stale mount not unmounted: %q", cmds)
+	}
+}
@@ -0,0 +1,92 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+func TestIsStaleNFSMountResponsive(t *testing.T) {
+	fs := &gofsutil.FS{Stat: func(string) error { return nil }}
+	stale, err := fs.IsStaleNFSMount(context.TODO(), "/mnt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stale {
+		t.Error("responsive mount reported as stale")
+	}
+}
+
+func TestIsStaleNFSMountTimeout(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+
+	fs := &gofsutil.FS{
+		Stat: func(string) error {
+			<-done
+			return nil
+		},
+		StatTimeout: 50 * time.Millisecond,
+	}
+
+	stale, err := fs.IsStaleNFSMount(context.TODO(), "/mnt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stale {
+		t.Error("hung mount not reported as stale")
+	}
+}
+
+func TestIsStaleNFSMountContextDeadline(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+
+	fs := &gofsutil.FS{Stat: func(string) error {
+		<-done
+		return nil
+	}}
+
+	// The end of the caller's context is not a stale mount.
+	ctx, cancel := context.WithTimeout(
+		context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	stale, err := fs.IsStaleNFSMount(ctx, "/mnt")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded: %v", err)
+	}
+	if stale {
+		t.Error("mount reported as stale when the context ended")
+	}
+}
+
+func TestIsStaleNFSMountESTALE(t *testing.T) {
+	fs := &gofsutil.FS{Stat: func(path string) error {
+		return fmt.Errorf("statfs %s: %w", path, syscall.ESTALE)
+	}}
+	stale, err := fs.IsStaleNFSMount(context.TODO(), "/mnt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stale {
+		t.Error("ESTALE not reported as stale")
+	}
+}
+
+func TestIsStaleNFSMountError(t *testing.T) {
+	fs := &gofsutil.FS{}
+	stale, err := fs.IsStaleNFSMount(context.TODO(), "/does/not/exist")
+	if !os.IsNotExist(err) {
+		t.Errorf("expected not exist error: %v", err)
+	}
+	if stale {
+		t.Error("missing path reported as stale")
+	}
+}
@@ -0,0 +1,102 @@
+package gofsutil
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// SwapInfo describes an active swap area.
+type SwapInfo struct {
+	// Filename is the path of the swap device or file.
+	Filename string
+
+	// Type is the type of the swap area, ex. "partition" or "file".
+	Type string
+
+	// Size is the size of the swap area in kibibytes.
+	Size uint64
+
+	// Used is the amount of the swap area in use in kibibytes.
+	Used uint64
+
+	// Priority is the priority of the swap area.
+	Priority int
+}
+
+// readProcSwapsFrom parses the contents of a swap table file, typically
+// "/proc/swaps".
+func readProcSwapsFrom(r io.Reader) ([]SwapInfo, error) {
+	var (
+		swaps []SwapInfo
+		scan  = bufio.NewScanner(r)
+	)
+	for scan.Scan() {
+		line := scan.Text()
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[0] == "Filename" {
+			continue
+		}
+		if len(fields) != 5 {
+			return nil, fmt.Errorf(
+				"readProcSwapsFrom: invalid field count: exp=5, act=%d: %s",
+				len(fields), line)
+		}
+		size, err := strconv.ParseUint(fields[2], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"readProcSwapsFrom: invalid size: %v: %s", err, line)
+		}
+		used, err := strconv.ParseUint(fields[3], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"readProcSwapsFrom: invalid used: %v: %s", err, line)
+		}
+		prio, err := strconv.Atoi(fields[4])
+		if err != nil {
+			return nil, fmt.Errorf(
+				"readProcSwapsFrom: invalid priority: %v: %s", err, line)
+		}
+		swaps = append(swaps, SwapInfo{
+			Filename: unescapeOctal(fields[0]),
+			Type:     fields[1],
+			Size:     size,
+			Used:     used,
+			Priority: prio,
+		})
+	}
+	return swaps, scan.Err()
+}
+
+// unescapeOctal replaces the octal escape sequences the kernel uses for
+// whitespace and backslashes in paths, ex. "\040", with the characters
+// they represent.
+func unescapeOctal(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) && isOctal(s[i+1:i+4]) {
+			v, _ := strconv.ParseUint(s[i+1:i+4], 8, 8)
+			b.WriteByte(byte(v))
+			i += 3
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// isOctal returns a flag indicating whether s consists solely of
+// octal digits.
+func isOctal(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '7' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,25 @@
+package gofsutil
+
+import "context"
+
+// getSwaps returns the active swap areas
+func (fs *FS) getSwaps(ctx context.Context) ([]SwapInfo, error) {
+	return nil, ErrNotImplemented
+}
+
+// swapOn enables swapping on device
+func (fs *FS) swapOn(
+	ctx context.Context, device string, opts ...string) error {
+
+	return ErrNotImplemented
+}
+
+// swapOff disables swapping on device
+func (fs *FS) swapOff(ctx context.Context, device string) error {
+	return ErrNotImplemented
+}
+
+// makeSwap sets up a swap area on device
+func (fs *FS) makeSwap(ctx context.Context, device string) error {
+	return ErrNotImplemented
+}
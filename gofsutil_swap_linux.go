@@ -0,0 +1,88 @@
+package gofsutil
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// getSwaps returns the active swap areas
+func (fs *FS) getSwaps(ctx context.Context) ([]SwapInfo, error) {
+	file, err := os.Open(fs.procPath("swaps"))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return readProcSwapsFrom(file)
+}
+
+// isSwapOn returns a flag indicating whether device is an active
+// swap area
+func (fs *FS) isSwapOn(ctx context.Context, device string) (bool, error) {
+	swaps, err := fs.getSwaps(ctx)
+	if err != nil {
+		return false, err
+	}
+	device = evalSymlinksOrPath(device)
+	for _, s := range swaps {
+		if evalSymlinksOrPath(s.Filename) == device {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// swapOn enables swapping on device
+func (fs *FS) swapOn(
+	ctx context.Context, device string, opts ...string) error {
+
+	on, err := fs.isSwapOn(ctx, device)
+	if err != nil {
+		return err
+	}
+	if on {
+		return nil
+	}
+	return fs.execSwapCmd(ctx, "swapon", append(opts, device)...)
+}
+
+// swapOff disables swapping on device
+func (fs *FS) swapOff(ctx context.Context, device string) error {
+	on, err := fs.isSwapOn(ctx, device)
+	if err != nil {
+		return err
+	}
+	if !on {
+		return nil
+	}
+	return fs.execSwapCmd(ctx, "swapoff", device)
+}
+
+// makeSwap sets up a swap area on device
+func (fs *FS) makeSwap(ctx context.Context, device string) error {
+	return fs.execSwapCmd(ctx, "mkswap", device)
+}
+
+// execSwapCmd runs one of the swap commands
+func (fs *FS) execSwapCmd(
+	ctx context.Context, swapCmd string, args ...string) error {
+
+	f := log.Fields{
+		"cmd":  swapCmd,
+		"args": args,
+	}
+	log.WithFields(f).Info("swap command")
+
+	buf, err := fs.exec(ctx, swapCmd, args...)
+	if err != nil {
+		out := string(buf)
+		log.WithFields(f).WithField("output", out).WithError(
+			err).Error("swap command failed")
+		return fmt.Errorf(
+			"%s failed: %v\narguments: %v\noutput: %s",
+			swapCmd, err, args, out)
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package gofsutil_test
+
+import (
+	"context"
+	"io/ioutil"
+	"path"
+	"reflect"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+const procSwapsData = `Filename				Type		Size		Used		Priority
+/dev/sda2                               partition	8388604		1024		-2
+/swap\040file                           file		2097148		0		-3
+/dev/zram0                              partition	4194300		0		100
+`
+
+func newTestSwapsProcRoot(t *testing.T) string {
+	procRoot := t.TempDir()
+	if err := ioutil.WriteFile(
+		path.Join(procRoot, "swaps"), []byte(procSwapsData), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return procRoot
+}
+
+func TestGetSwaps(t *testing.T) {
+	procRoot := newTestSwapsProcRoot(t)
+
+	fs := &gofsutil.FS{ProcRoot: procRoot}
+	swaps, err := fs.GetSwaps(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []gofsutil.SwapInfo{
+		{
+			Filename: "/dev/sda2",
+			Type:     "partition",
+			Size:     8388604,
+			Used:     1024,
+			Priority: -2,
+		},
+		{
+			Filename: "/swap file",
+			Type:     "file",
+			Size:     2097148,
+			Priority: -3,
+		},
+		{
+			Filename: "/dev/zram0",
+			Type:     "partition",
+			Size:     4194300,
+			Priority: 100,
+		},
+	}
+	if !reflect.DeepEqual(swaps, exp) {
+		t.Errorf("invalid swaps: exp=%+v, act=%+v", exp, swaps)
+	}
+}
+
+func TestSwapOn(t *testing.T) {
+	procRoot := newTestSwapsProcRoot(t)
+
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{ProcRoot: procRoot, RunCommand: r.run}
+
+	// The device is already an active swap area.
+	if err := fs.SwapOn(context.TODO(), "/dev/sda2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.SwapOn(context.TODO(), "/dev/sdb", "-p", "10"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t, "swapon -p 10 /dev/sdb")
+}
+
+func TestSwapOff(t *testing.T) {
+	procRoot := newTestSwapsProcRoot(t)
+
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{ProcRoot: procRoot, RunCommand: r.run}
+
+	if err := fs.SwapOff(context.TODO(), "/dev/sda2"); err != nil {
+		t.Fatal(err)
+	}
+	// The device is not an active swap area.
+	if err := fs.SwapOff(context.TODO(), "/dev/sdb"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.MakeSwap(context.TODO(), "/dev/sdb"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t, "swapoff /dev/sda2", "mkswap /dev/sdb")
+}
@@ -0,0 +1,47 @@
+package gofsutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// targetParentMode is the mode used to create the missing parent
+// directories of a target file.
+const targetParentMode = 0750
+
+// ensureTargetPath creates target as a directory, or as an empty file if
+// isBlock is true, unless target already exists as the expected type
+func (fs *FS) ensureTargetPath(
+	target string, isBlock bool, mode os.FileMode) error {
+
+	st, err := os.Stat(target)
+	if err == nil {
+		if isBlock && st.IsDir() {
+			return fmt.Errorf(
+				"ensureTargetPath: target is a directory: %s", target)
+		}
+		if !isBlock && !st.IsDir() {
+			return fmt.Errorf(
+				"ensureTargetPath: target is not a directory: %s", target)
+		}
+		return nil
+	}
+	if !os.IsNotExist(err) {
+		return err
+	}
+
+	if !isBlock {
+		return os.MkdirAll(target, mode)
+	}
+
+	if err := os.MkdirAll(
+		filepath.Dir(target), targetParentMode); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, mode)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
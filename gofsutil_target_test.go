@@ -0,0 +1,87 @@
+package gofsutil_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+func TestEnsureTargetPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	existingDir := path.Join(dir, "existing-dir")
+	if err := os.Mkdir(existingDir, 0750); err != nil {
+		t.Fatal(err)
+	}
+	existingFile := path.Join(dir, "existing-file")
+	if err := ioutil.WriteFile(existingFile, nil, 0640); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		target  string
+		isBlock bool
+		err     bool
+	}{
+		{
+			name:   "create-dir",
+			target: path.Join(dir, "new", "dir"),
+		},
+		{
+			name:    "create-file",
+			target:  path.Join(dir, "new", "file"),
+			isBlock: true,
+		},
+		{
+			name:   "existing-dir",
+			target: existingDir,
+		},
+		{
+			name:    "existing-file",
+			target:  existingFile,
+			isBlock: true,
+		},
+		{
+			name:    "dir-mismatch",
+			target:  existingDir,
+			isBlock: true,
+			err:     true,
+		},
+		{
+			name:   "file-mismatch",
+			target: existingFile,
+			err:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(st *testing.T) {
+			err := gofsutil.EnsureTargetPath(tt.target, tt.isBlock, 0750)
+			if tt.err {
+				if err == nil {
+					st.Errorf("expected error: %s", tt.target)
+				}
+				return
+			}
+			if err != nil {
+				st.Fatal(err)
+			}
+			fi, err := os.Stat(tt.target)
+			if err != nil {
+				st.Fatal(err)
+			}
+			if fi.IsDir() == tt.isBlock {
+				st.Errorf("invalid target type: isDir=%v", fi.IsDir())
+			}
+		})
+	}
+}
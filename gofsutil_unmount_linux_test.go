@@ -0,0 +1,124 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+func TestUnmountRecursive(t *testing.T) {
+	ctx := gofsutil.WithMountTable(context.TODO(), []gofsutil.Info{
+		{Device: "/dev/sda1", Path: "/"},
+		{Device: "/dev/sdb", Path: "/mnt/root"},
+		{Device: "/dev/sdc", Path: "/mnt/root/a"},
+		{Device: "/dev/sdd", Path: "/mnt/root/a/b"},
+		{Device: "/dev/sde", Path: "/mnt/root/c"},
+		{Device: "/dev/sdf", Path: "/mnt/rootfs"},
+	})
+	r := &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			if args[1] == "/mnt/root/c" {
+				return "umount: /mnt/root/c: target is busy.",
+					errors.New("exit status 32")
+			}
+			if args[len(args)-1] == "/mnt/root/a" {
+				return "umount: /mnt/root/a: permission denied",
+					errors.New("exit status 32")
+			}
+			return "", nil
+		},
+	}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	err := fs.UnmountRecursive(ctx, "/mnt/root")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if !strings.Contains(err.Error(), "/mnt/root/a: ") {
+		t.Errorf("error does not name /mnt/root/a: %v", err)
+	}
+	r.assertCommands(t,
+		"umount /mnt/root/a/b",
+		"umount /mnt/root/a",
+		"umount /mnt/root/c",
+		"umount -l /mnt/root/c",
+		"umount /mnt/root")
+}
+
+func TestUnmountDevice(t *testing.T) {
+	ctx := gofsutil.WithMountTable(context.TODO(), []gofsutil.Info{
+		{Device: "/dev/sda1", Path: "/"},
+		{Device: "/dev/sdb", Path: "/mnt/data"},
+		{Device: "/dev/sdb", Path: "/var/lib/pods/a/volumes/data"},
+		{Device: "/dev/sdc", Path: "/mnt/data/logs"},
+		{Device: "/dev/sdb", Path: "/var/lib/pods/b/data"},
+	})
+	r := &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			switch args[len(args)-1] {
+			case "/var/lib/pods/b/data":
+				if args[1] != "-l" {
+					return "umount: /var/lib/pods/b/data: target is busy.",
+						errors.New("exit status 32")
+				}
+			case "/mnt/data", "/var/lib/pods/a/volumes/data":
+				return "umount: permission denied",
+					errors.New("exit status 32")
+			}
+			return "", nil
+		},
+	}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	err := fs.UnmountDevice(ctx, "/dev/sdb")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for _, target := range []string{
+		"/mnt/data", "/var/lib/pods/a/volumes/data"} {
+
+		if !strings.Contains(err.Error(), target+": ") {
+			t.Errorf("error does not name %s: %v", target, err)
+		}
+	}
+	r.assertCommands(t,
+		"umount /var/lib/pods/a/volumes/data",
+		"umount /var/lib/pods/b/data",
+		"umount -l /var/lib/pods/b/data",
+		"umount /mnt/data")
+
+	r = &testCommandRunner{}
+	fs.RunCommand = r.run
+	if err := fs.UnmountDevice(ctx, "/dev/sdd"); err != nil {
+		t.Errorf("unmount of device that is not mounted: %v", err)
+	}
+	r.assertCommands(t)
+}
+
+func TestUnmountDeviceAlias(t *testing.T) {
+	devRoot := newTestDeviceAliases(t)
+
+	// The kernel reports the mapper name of a dm device, while callers
+	// may know it by its kernel name or a by-id link.
+	ctx := gofsutil.WithMountTable(context.TODO(), []gofsutil.Info{
+		{Device: "/dev/sda1", Path: "/"},
+		{Device: path.Join(devRoot, "mapper", "vg-lv"), Path: "/mnt/data"},
+		{Device: path.Join(devRoot, "dm-0"), Path: "/mnt/data/bind"},
+	})
+	for _, dev := range []string{
+		path.Join(devRoot, "dm-0"),
+		path.Join(devRoot, "mapper", "vg-lv"),
+		path.Join(devRoot, "disk", "by-id", "dm-name-vg-lv"),
+	} {
+		r := &testCommandRunner{}
+		fs := &gofsutil.FS{RunCommand: r.run}
+		if err := fs.UnmountDevice(ctx, dev); err != nil {
+			t.Fatalf("%s: %v", dev, err)
+		}
+		r.assertCommands(t, "umount /mnt/data/bind", "umount /mnt/data")
+	}
+}
@@ -0,0 +1,86 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+func TestUnmountAll(t *testing.T) {
+	r := &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			switch args[1] {
+			case "/mnt/b":
+				return "umount: /mnt/b: target is busy.",
+					errors.New("exit status 32")
+			case "/mnt/c":
+				return "umount: /mnt/c: not mounted.",
+					errors.New("exit status 32")
+			}
+			return "", nil
+		},
+	}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	err := fs.UnmountAll(context.TODO(), []string{
+		"/mnt/a",
+		"/mnt/b",
+		"/mnt/a/nested",
+		"/mnt/c",
+	})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	for _, target := range []string{"/mnt/b", "/mnt/c"} {
+		if !strings.Contains(err.Error(), target+": ") {
+			t.Errorf("error does not name %s: %v", target, err)
+		}
+	}
+	if strings.Contains(err.Error(), "/mnt/a") {
+		t.Errorf("error names successful target: %v", err)
+	}
+	if !errors.Is(err, gofsutil.ErrNotMounted) {
+		t.Errorf("expected joined ErrNotMounted: %v", err)
+	}
+	r.assertCommands(t,
+		"umount /mnt/a/nested",
+		"umount /mnt/a",
+		"umount /mnt/b",
+		"umount /mnt/c")
+}
+
+func TestUnmountAllSuccess(t *testing.T) {
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	if err := fs.UnmountAll(
+		context.TODO(), []string{"/mnt/a", "/mnt/b"}); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t, "umount /mnt/a", "umount /mnt/b")
+}
+
+func TestUnmountRecursiveCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+	ctx = gofsutil.WithMountTable(ctx, []gofsutil.Info{
+		{Device: "/dev/sdb", Path: "/mnt/root"},
+		{Device: "/dev/sdc", Path: "/mnt/root/a"},
+	})
+	r := &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			cancel()
+			return "", nil
+		},
+	}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	err := fs.UnmountRecursive(ctx, "/mnt/root")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected canceled: %v", err)
+	}
+	r.assertCommands(t, "umount /mnt/root/a")
+}
@@ -0,0 +1,10 @@
+package gofsutil
+
+// ProcessInfo describes a process.
+type ProcessInfo struct {
+	// PID is the ID of the process.
+	PID int
+
+	// Command is the name of the process's command, ex. "nginx".
+	Command string
+}
@@ -0,0 +1,12 @@
+package gofsutil
+
+import "context"
+
+// getMountUsers returns the processes that have a file beneath target
+// open or use a directory beneath target as their working or root
+// directory
+func (fs *FS) getMountUsers(
+	ctx context.Context, target string) ([]ProcessInfo, error) {
+
+	return nil, ErrNotImplemented
+}
@@ -0,0 +1,76 @@
+package gofsutil
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// getMountUsers returns the processes that have a file beneath target
+// open or use a directory beneath target as their working or root
+// directory
+func (fs *FS) getMountUsers(
+	ctx context.Context, target string) ([]ProcessInfo, error) {
+
+	target = evalSymlinksOrPath(target)
+
+	dirs, err := ioutil.ReadDir(fs.procPath())
+	if err != nil {
+		return nil, err
+	}
+
+	var users []ProcessInfo
+	for _, d := range dirs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		pid, err := strconv.Atoi(d.Name())
+		if err != nil || !d.IsDir() {
+			continue
+		}
+		if !fs.isMountUser(d.Name(), target) {
+			continue
+		}
+		// A process may exit while the proc filesystem is scanned, in
+		// which case its command is unknown.
+		comm, _ := ioutil.ReadFile(fs.procPath(d.Name(), "comm"))
+		users = append(users, ProcessInfo{
+			PID:     pid,
+			Command: strings.TrimSpace(string(comm)),
+		})
+	}
+	return users, nil
+}
+
+// isMountUser returns a flag indicating whether the process with the
+// provided PID has a file beneath target open or uses a directory
+// beneath target as its working or root directory. The links of the
+// process that cannot be read, ex. because the process exited or the
+// caller lacks permission, are ignored.
+func (fs *FS) isMountUser(pid, target string) bool {
+	links := []string{
+		fs.procPath(pid, "cwd"),
+		fs.procPath(pid, "root"),
+		fs.procPath(pid, "exe"),
+	}
+	if fds, err := ioutil.ReadDir(fs.procPath(pid, "fd")); err == nil {
+		for _, fd := range fds {
+			links = append(links, fs.procPath(pid, "fd", fd.Name()))
+		}
+	}
+	for _, l := range links {
+		p, err := os.Readlink(l)
+		if err != nil || !path.IsAbs(p) {
+			continue
+		}
+		// The path of a file that was deleted has a " (deleted)" suffix.
+		p = strings.TrimSuffix(p, " (deleted)")
+		if isPathOrSubpath(p, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package gofsutil_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+// newTestUsersProcRoot returns a proc tree in which process 100 has a
+// file beneath /mnt/data open, process 200 has its working directory
+// beneath /mnt/data, and process 300 uses neither.
+func newTestUsersProcRoot(t *testing.T) string {
+	procRoot := t.TempDir()
+	for _, p := range []struct {
+		pid   string
+		comm  string
+		links map[string]string
+	}{
+		{"100", "nginx", map[string]string{
+			"cwd":  "/",
+			"fd/0": "/dev/null",
+			"fd/3": "/mnt/data/access.log",
+		}},
+		{"200", "bash", map[string]string{
+			"cwd":  "/mnt/data/sub",
+			"fd/0": "/dev/pts/0",
+		}},
+		{"300", "sshd", map[string]string{
+			"cwd":  "/",
+			"fd/3": "/mnt/database/file",
+			"fd/4": "socket:[12345]",
+		}},
+	} {
+		dir := path.Join(procRoot, p.pid)
+		if err := os.MkdirAll(path.Join(dir, "fd"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(
+			path.Join(dir, "comm"), []byte(p.comm+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		for name, target := range p.links {
+			if err := os.Symlink(target, path.Join(dir, name)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := os.Symlink("100", path.Join(procRoot, "self")); err != nil {
+		t.Fatal(err)
+	}
+	return procRoot
+}
+
+func TestGetMountUsers(t *testing.T) {
+	procRoot := newTestUsersProcRoot(t)
+
+	fs := &gofsutil.FS{ProcRoot: procRoot}
+	users, err := fs.GetMountUsers(context.TODO(), "/mnt/data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []gofsutil.ProcessInfo{
+		{PID: 100, Command: "nginx"},
+		{PID: 200, Command: "bash"},
+	}
+	if !reflect.DeepEqual(users, exp) {
+		t.Errorf("invalid users: exp=%+v, act=%+v", exp, users)
+	}
+}
+
+func TestGetMountUsersNone(t *testing.T) {
+	procRoot := newTestUsersProcRoot(t)
+
+	fs := &gofsutil.FS{ProcRoot: procRoot}
+	users, err := fs.GetMountUsers(context.TODO(), "/mnt/other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 0 {
+		t.Errorf("unexpected users: %+v", users)
+	}
+}
@@ -0,0 +1,85 @@
+package gofsutil
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// pathMetaChars are the characters that are rejected in device paths and
+// mount targets because a shell or a command line parser may interpret
+// them. Spaces are permitted since they are valid in mount targets.
+const pathMetaChars = ";&|$`<>(){}'\"*?!"
+
+// ValidateDevicePath returns an error if device is not an absolute path
+// or contains shell metacharacters, control characters, or NUL bytes.
+// The device is not required to exist.
+func ValidateDevicePath(device string) error {
+	if err := validatePath(device); err != nil {
+		return fmt.Errorf("invalid device path: %v", err)
+	}
+	return nil
+}
+
+// ValidateMountTarget returns an error if target is not an absolute path
+// or contains shell metacharacters, control characters, or NUL bytes.
+// The target is not required to exist.
+func ValidateMountTarget(target string) error {
+	if err := validatePath(target); err != nil {
+		return fmt.Errorf("invalid mount target: %v", err)
+	}
+	return nil
+}
+
+func validatePath(path string) error {
+	if path == "" {
+		return fmt.Errorf("empty path")
+	}
+	if err := validatePathChars(path); err != nil {
+		return err
+	}
+	if !filepath.IsAbs(path) {
+		return fmt.Errorf("path is not absolute: %s", path)
+	}
+	return nil
+}
+
+// validatePathChars returns an error if s contains shell metacharacters,
+// control characters, or NUL bytes.
+func validatePathChars(s string) error {
+	for _, r := range s {
+		if r < 0x20 || r == 0x7f {
+			return fmt.Errorf("path contains control character %q", r)
+		}
+	}
+	if i := strings.IndexAny(s, pathMetaChars); i >= 0 {
+		return fmt.Errorf("path contains metacharacter %q: %s", s[i], s)
+	}
+	return nil
+}
+
+// validateMountSource validates the source of a mount. Only sources that
+// are absolute paths must be valid device paths; other sources, ex. the
+// "host:/export" of an NFS mount or the "tmpfs" of a tmpfs mount, are only
+// checked for unsafe characters. An empty source is permitted.
+func validateMountSource(source string) error {
+	if source == "" {
+		return nil
+	}
+	if strings.HasPrefix(source, "/") {
+		return ValidateDevicePath(source)
+	}
+	if err := validatePathChars(source); err != nil {
+		return fmt.Errorf("invalid mount source: %v", err)
+	}
+	return nil
+}
+
+// validateFormatAndMount validates the source and target of a format and
+// mount operation. The source must always be a device path.
+func validateFormatAndMount(source, target string) error {
+	if err := ValidateDevicePath(source); err != nil {
+		return err
+	}
+	return ValidateMountTarget(target)
+}
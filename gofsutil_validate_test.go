@@ -0,0 +1,87 @@
+package gofsutil_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+func TestValidateDevicePath(t *testing.T) {
+	tests := []struct {
+		path  string
+		valid bool
+	}{
+		{"/dev/sdb", true},
+		{"/dev/disk/by-path/pci-0000:00:1f.2-ata-1", true},
+		{`/dev/disk/by-label/my\x20disk`, true},
+		{"", false},
+		{"dev/sdb", false},
+		{"sdb", false},
+		{"/dev/sdb\n/dev/sdc", false},
+		{"/dev/sdb\x00", false},
+		{"/dev/sdb; rm -rf /", false},
+		{"/dev/$(reboot)", false},
+		{"/dev/`reboot`", false},
+		{"/dev/sd*", false},
+	}
+	for _, tt := range tests {
+		err := gofsutil.ValidateDevicePath(tt.path)
+		if tt.valid && err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.path, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("%q: expected error", tt.path)
+		}
+	}
+}
+
+func TestValidateMountTarget(t *testing.T) {
+	tests := []struct {
+		path  string
+		valid bool
+	}{
+		{"/mnt", true},
+		{"/var/lib/kubelet/pods/a b/volumes", true},
+		{"", false},
+		{"mnt", false},
+		{"./mnt", false},
+		{"/mnt\nfoo", false},
+		{"/mnt\x00/foo", false},
+		{"/mnt|foo", false},
+		{"/mnt&", false},
+	}
+	for _, tt := range tests {
+		err := gofsutil.ValidateMountTarget(tt.path)
+		if tt.valid && err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.path, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("%q: expected error", tt.path)
+		}
+	}
+}
+
+func TestMountRejectsInvalidPaths(t *testing.T) {
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{RunCommand: r.run}
+	ctx := context.TODO()
+
+	if err := fs.Mount(ctx, "/dev/sdb\n", "/mnt", "ext4"); err == nil {
+		t.Error("expected error for source with newline")
+	}
+	if err := fs.Mount(ctx, "/dev/sdb", "mnt", "ext4"); err == nil {
+		t.Error("expected error for relative target")
+	}
+	if err := fs.Mount(ctx, "host:/export;reboot", "/mnt", "nfs"); err == nil {
+		t.Error("expected error for source with metacharacter")
+	}
+	if err := fs.FormatAndMount(
+		ctx, "sdb", "/mnt", "ext4"); err == nil {
+		t.Error("expected error for relative device")
+	}
+	if _, err := fs.GetParentDevice(ctx, "/dev/sdb\x00"); err == nil {
+		t.Error("expected error for device with NUL")
+	}
+	r.assertCommands(t)
+}
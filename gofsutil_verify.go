@@ -0,0 +1,126 @@
+package gofsutil
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// MountOptionsDroppedError is returned when the FS's VerifyOptions field
+// is true and requested options are absent from the options of the mount.
+type MountOptionsDroppedError struct {
+	// Target is the path at which the filesystem is mounted.
+	Target string
+
+	// Options are the requested options absent from the mount.
+	Options []string
+}
+
+// Error returns the error message.
+func (e *MountOptionsDroppedError) Error() string {
+	return fmt.Sprintf("mount %s: options dropped by the kernel: %s",
+		e.Target, strings.Join(e.Options, ","))
+}
+
+// nonEchoedMountOptions are the mount options that the kernel accepts
+// but does not report in the options of a mount, either because they
+// are not options of the mount, ex. "bind", or because they are the
+// defaults, ex. "suid". The kernel always reports "rw" or "ro".
+var nonEchoedMountOptions = map[string]struct{}{
+	"defaults":    {},
+	"bind":        {},
+	"rbind":       {},
+	"remount":     {},
+	"move":        {},
+	"loop":        {},
+	"async":       {},
+	"atime":       {},
+	"diratime":    {},
+	"dev":         {},
+	"suid":        {},
+	"exec":        {},
+	"strictatime": {},
+	"norelatime":  {},
+	"nomand":      {},
+	"silent":      {},
+	"loud":        {},
+	"user":        {},
+	"nouser":      {},
+	"users":       {},
+	"owner":       {},
+	"group":       {},
+	"private":     {},
+	"rprivate":    {},
+	"shared":      {},
+	"rshared":     {},
+	"slave":       {},
+	"rslave":      {},
+	"unbindable":  {},
+	"runbindable": {},
+}
+
+// verifyMountOptions returns a *MountOptionsDroppedError if any of opts
+// are absent from the options of the mount at target
+func (fs *FS) verifyMountOptions(
+	ctx context.Context, target string, opts []string) error {
+
+	m, err := fs.getTopMount(withoutMountTable(ctx), target)
+	if err != nil {
+		return err
+	}
+	dropped := droppedMountOptions(opts, m)
+	if len(dropped) == 0 {
+		return nil
+	}
+	log.WithFields(logFields(ctx, log.Fields{
+		"target":    target,
+		"requested": opts,
+		"actual":    m.Opts,
+		"dropped":   dropped,
+	})).Warn("mount options dropped by the kernel")
+	return &MountOptionsDroppedError{Target: target, Options: dropped}
+}
+
+// droppedMountOptions returns the options that are absent from the
+// per-mount and per super block options of m. The options the kernel
+// does not report and the options interpreted by userspace are ignored.
+// An option with a value, ex. "size=1m", is present if m has an option
+// with the same name.
+func droppedMountOptions(opts []string, m Info) []string {
+	name := func(o string) string {
+		if i := strings.IndexByte(o, '='); i >= 0 {
+			return o[:i]
+		}
+		return o
+	}
+	actual := map[string]struct{}{}
+	for _, mopts := range [][]string{m.Opts, m.SuperOpts} {
+		for _, o := range mopts {
+			actual[name(o)] = struct{}{}
+		}
+	}
+
+	var dropped []string
+	kernelOpts, _ := SplitMountOptions(opts)
+	for _, o := range kernelOpts {
+		if _, ok := nonEchoedMountOptions[o]; ok || o == "" {
+			continue
+		}
+
+		// A filesystem may be read-only even though the mount is not,
+		// ex. after an ext4 error with "errors=remount-ro".
+		if o == "rw" {
+			if _, ok := actual["ro"]; ok {
+				dropped = append(dropped, o)
+			}
+			continue
+		}
+		if _, ok := actual[name(o)]; ok {
+			continue
+		}
+		dropped = append(dropped, o)
+	}
+	return dropped
+}
@@ -0,0 +1,125 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+func TestMountVerifyOptions(t *testing.T) {
+	// The mount table describes the filesystem once it is mounted, so
+	// overmounts are allowed.
+	procRoot := newTestProcRoot(t,
+		"80 60 8:16 / /mnt rw,nosuid,noatime shared:40 - "+
+			"ext4 /dev/sdb rw,commit=5,data=ordered\n", "self")
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{
+		ProcRoot:       procRoot,
+		RunCommand:     r.run,
+		AllowOvermount: true,
+		VerifyOptions:  true,
+	}
+	ctx := context.TODO()
+
+	// Options the kernel does not report and options interpreted by
+	// userspace are not verified, and options with values are verified
+	// by name.
+	err := fs.Mount(ctx, "/dev/sdb", "/mnt", "ext4",
+		"defaults", "suid", "nosuid", "noatime", "commit=30", "_netdev")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = fs.Mount(ctx, "/dev/sdb", "/mnt", "ext4",
+		"nosuid", "discard", "commit=30", "nodev")
+	var dErr *gofsutil.MountOptionsDroppedError
+	if !errors.As(err, &dErr) {
+		t.Fatalf("expected MountOptionsDroppedError: %v", err)
+	}
+	if exp := []string{"discard", "nodev"}; !reflect.DeepEqual(
+		dErr.Options, exp) {
+		t.Errorf("invalid dropped options: exp=%v, act=%v",
+			exp, dErr.Options)
+	}
+
+	// A read-write mount of a read-only filesystem drops "rw".
+	roProcRoot := newTestProcRoot(t,
+		"81 60 8:16 / /mnt rw,relatime shared:41 - "+
+			"ext4 /dev/sdb ro,errors=remount-ro\n", "self")
+	fs.ProcRoot = roProcRoot
+	err = fs.Mount(ctx, "/dev/sdb", "/mnt", "ext4", "rw")
+	if !errors.As(err, &dErr) {
+		t.Fatalf("expected MountOptionsDroppedError: %v", err)
+	}
+	if exp := []string{"rw"}; !reflect.DeepEqual(dErr.Options, exp) {
+		t.Errorf("invalid dropped options: exp=%v, act=%v",
+			exp, dErr.Options)
+	}
+
+	// The options are not verified by default.
+	fs.ProcRoot = procRoot
+	fs.VerifyOptions = false
+	if err := fs.Mount(ctx, "/dev/sdb", "/mnt", "ext4", "discard"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMountVerifyWithMountTable(t *testing.T) {
+	procRoot := newTestProcRoot(t,
+		"80 60 8:16 / /mnt/data rw,nosuid shared:40 - "+
+			"ext4 /dev/sdb rw,data=ordered\n", "self")
+
+	// The table attached to the context was read before anything was
+	// mounted, and must not be used to check or verify the mounts.
+	stale := gofsutil.WithMountTable(context.TODO(), []gofsutil.Info{
+		{Device: "/dev/sdc", Path: "/mnt/other", Type: "ext4"},
+	})
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{
+		ProcRoot:      procRoot,
+		RunCommand:    r.run,
+		VerifyMount:   true,
+		VerifyOptions: true,
+	}
+
+	// The overmount is detected in the mount table.
+	err := fs.Mount(stale, "/dev/sdc", "/mnt/data", "ext4")
+	var amErr *gofsutil.AlreadyMountedError
+	if !errors.As(err, &amErr) {
+		t.Errorf("expected AlreadyMountedError: %v", err)
+	}
+	r.assertCommands(t)
+
+	// The options are verified using the mount table.
+	fs.AllowOvermount = true
+	err = fs.Mount(stale, "/dev/sdb", "/mnt/data", "ext4", "nosuid", "nodev")
+	var dErr *gofsutil.MountOptionsDroppedError
+	if !errors.As(err, &dErr) {
+		t.Errorf("expected MountOptionsDroppedError: %v", err)
+	}
+
+	// The mount at /mnt/other is only in the attached table.
+	fs.VerifyOptions = false
+	fs.RunCommand = newTestFormatRunner("ext4").run
+	err = fs.FormatAndMount(stale, "/dev/sdc", "/mnt/other", "ext4")
+	if !errors.Is(err, gofsutil.ErrNotMounted) {
+		t.Errorf("expected ErrNotMounted: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(stale, 5*time.Second)
+	defer cancel()
+	if err := fs.WaitForUnmount(
+		ctx, "/mnt/other", 10*time.Millisecond); err != nil {
+		t.Error(err)
+	}
+
+	// The attached table is still used by queries.
+	if ok, err := fs.IsMountPoint(stale, "/mnt/other"); err != nil {
+		t.Fatal(err)
+	} else if !ok {
+		t.Error("/mnt/other should be a mount point in the attached table")
+	}
+}
@@ -0,0 +1,37 @@
+package gofsutil
+
+// VirtualFSTypes are the types of the pseudo-filesystems that are
+// provided by the kernel rather than backed by storage. Callers may add
+// types to the map to extend the classification of Info.IsVirtualFS.
+var VirtualFSTypes = map[string]bool{
+	"autofs":      true,
+	"binfmt_misc": true,
+	"bpf":         true,
+	"cgroup":      true,
+	"cgroup2":     true,
+	"configfs":    true,
+	"debugfs":     true,
+	"devfs":       true,
+	"devpts":      true,
+	"devtmpfs":    true,
+	"efivarfs":    true,
+	"fusectl":     true,
+	"hugetlbfs":   true,
+	"mqueue":      true,
+	"nsfs":        true,
+	"proc":        true,
+	"pstore":      true,
+	"rpc_pipefs":  true,
+	"securityfs":  true,
+	"selinuxfs":   true,
+	"sysfs":       true,
+	"tmpfs":       true,
+	"tracefs":     true,
+}
+
+// IsVirtualFS returns a flag indicating whether the mount is of a
+// pseudo-filesystem provided by the kernel, ex. proc or cgroup2, rather
+// than a filesystem backed by storage. Please see VirtualFSTypes.
+func (i Info) IsVirtualFS() bool {
+	return VirtualFSTypes[i.Type]
+}
@@ -0,0 +1,48 @@
+package gofsutil_test
+
+import (
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+func TestIsVirtualFS(t *testing.T) {
+	for _, tt := range []struct {
+		info    gofsutil.Info
+		virtual bool
+	}{
+		{gofsutil.Info{Device: "proc", Path: "/proc", Type: "proc"}, true},
+		{gofsutil.Info{Device: "sysfs", Path: "/sys", Type: "sysfs"}, true},
+		{gofsutil.Info{Device: "cgroup2", Path: "/sys/fs/cgroup",
+			Type: "cgroup2"}, true},
+		{gofsutil.Info{Device: "mqueue", Path: "/dev/mqueue",
+			Type: "mqueue"}, true},
+		{gofsutil.Info{Device: "bpf", Path: "/sys/fs/bpf", Type: "bpf"}, true},
+		{gofsutil.Info{Device: "tracefs", Path: "/sys/kernel/tracing",
+			Type: "tracefs"}, true},
+		{gofsutil.Info{Device: "tmpfs", Path: "/run", Type: "tmpfs"}, true},
+		{gofsutil.Info{Device: "/dev/sda1", Path: "/", Type: "ext4"}, false},
+		{gofsutil.Info{Device: "/dev/sdb", Path: "/mnt", Type: "xfs"}, false},
+		{gofsutil.Info{Device: "host:/export", Path: "/nfs",
+			Type: "nfs4"}, false},
+		{gofsutil.Info{Device: "overlay", Path: "/merged",
+			Type: "overlay"}, false},
+	} {
+		if act := tt.info.IsVirtualFS(); act != tt.virtual {
+			t.Errorf("%s: invalid classification: exp=%v, act=%v",
+				tt.info.Type, tt.virtual, act)
+		}
+	}
+}
+
+func TestIsVirtualFSExtended(t *testing.T) {
+	info := gofsutil.Info{Device: "myfs", Path: "/myfs", Type: "myfs"}
+	if info.IsVirtualFS() {
+		t.Fatal("unexpected virtual fs")
+	}
+	gofsutil.VirtualFSTypes["myfs"] = true
+	defer delete(gofsutil.VirtualFSTypes, "myfs")
+	if !info.IsVirtualFS() {
+		t.Error("expected virtual fs")
+	}
+}
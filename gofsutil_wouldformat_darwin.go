@@ -0,0 +1,11 @@
+package gofsutil
+
+import "context"
+
+// wouldFormat returns a flag indicating whether FormatAndMount would
+// format source as fsType and the reason for the decision
+func (fs *FS) wouldFormat(
+	ctx context.Context, source, fsType string) (bool, string, error) {
+
+	return false, "", ErrNotImplemented
+}
@@ -0,0 +1,42 @@
+package gofsutil
+
+import "context"
+
+// wouldFormat returns a flag indicating whether FormatAndMount would
+// format source as fsType and the reason for the decision
+func (fs *FS) wouldFormat(
+	ctx context.Context, source, fsType string) (bool, string, error) {
+
+	if len(fsType) == 0 {
+		fsType = "ext4"
+	}
+	existingFormat, err := fs.getDiskFormat(ctx, source)
+	if err != nil {
+		return false, "", err
+	}
+	switch existingFormat {
+	case "":
+	case fsType:
+		return false, "already " + existingFormat, nil
+	case diskFormatPartitions:
+		return false, "device has partitions", nil
+	default:
+		// FormatAndMount fails rather than reformatting a device that
+		// contains another filesystem, so nothing would be formatted,
+		// and the reason says why.
+		return false, "fstype mismatch: device contains " +
+			existingFormat, nil
+	}
+
+	// A write-protected device cannot be formatted.
+	if fs.AutoReadOnly {
+		ro, err := fs.isDeviceReadOnly(ctx, source)
+		if err != nil {
+			return false, "", err
+		}
+		if ro {
+			return false, "blank device is write-protected", nil
+		}
+	}
+	return true, "blank device", nil
+}
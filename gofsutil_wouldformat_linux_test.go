@@ -0,0 +1,59 @@
+package gofsutil_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+func TestWouldFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		fsType   string
+		format   bool
+		reason   string
+	}{
+		{"blank", "", "xfs", true, "blank device"},
+		{"blank-default", "", "", true, "blank device"},
+		{"matching", "ext4", "ext4", false, "already ext4"},
+		{"matching-default", "ext4", "", false, "already ext4"},
+		{"mismatch", "xfs", "ext4", false,
+			"fstype mismatch: device contains xfs"},
+	}
+	for _, tt := range tests {
+		r := newTestFormatRunner(tt.existing)
+		fs := &gofsutil.FS{RunCommand: r.run}
+		format, reason, err := fs.WouldFormat(
+			context.TODO(), "/dev/sdb", tt.fsType)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if format != tt.format || reason != tt.reason {
+			t.Errorf("%s: exp=%v %q, act=%v %q",
+				tt.name, tt.format, tt.reason, format, reason)
+		}
+
+		// Only the disk's format is read.
+		r.assertCommands(t, "lsblk -J -o NAME,FSTYPE /dev/sdb")
+	}
+}
+
+func TestWouldFormatReadOnly(t *testing.T) {
+	sysRoot := newTestSysRoot(t, "1")
+
+	r := newTestFormatRunner("")
+	fs := &gofsutil.FS{
+		SysRoot:      sysRoot,
+		RunCommand:   r.run,
+		AutoReadOnly: true,
+	}
+	format, reason, err := fs.WouldFormat(context.TODO(), "/dev/sdb", "ext4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format || reason != "blank device is write-protected" {
+		t.Errorf("invalid result: %v %q", format, reason)
+	}
+}
@@ -0,0 +1,57 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+// newTestDuplicateUUIDRunner returns a command runner that fails to
+// mount an xfs filesystem unless the "nouuid" option is provided.
+func newTestDuplicateUUIDRunner() *testCommandRunner {
+	return &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			if args[0] == "mount" &&
+				!strings.Contains(strings.Join(args, " "), "nouuid") {
+				return "XFS (sdc): Filesystem has duplicate UUID " +
+						"3e6be9de-8139-11d1-9106-a43f08d823a6 - can't mount",
+					errors.New("exit status 32")
+			}
+			return "", nil
+		},
+	}
+}
+
+func TestMountXFSAutoNoUUID(t *testing.T) {
+	r := newTestDuplicateUUIDRunner()
+	fs := &gofsutil.FS{
+		RunCommand:    r.run,
+		XFSAutoNoUUID: true,
+		ProcRoot:      newTestRootProcRoot(t),
+	}
+
+	if err := fs.Mount(
+		context.TODO(), "/dev/sdc", "/mnt", "xfs", "ro"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t,
+		"mount -t xfs -o ro /dev/sdc /mnt",
+		"mount -t xfs -o ro,nouuid /dev/sdc /mnt")
+}
+
+func TestMountXFSAutoNoUUIDDisabled(t *testing.T) {
+	r := newTestDuplicateUUIDRunner()
+	fs := &gofsutil.FS{
+		RunCommand: r.run,
+		ProcRoot:   newTestRootProcRoot(t),
+	}
+
+	if err := fs.Mount(
+		context.TODO(), "/dev/sdc", "/mnt", "xfs", "ro"); err == nil {
+		t.Fatal("expected duplicate uuid error")
+	}
+	r.assertCommands(t, "mount -t xfs -o ro /dev/sdc /mnt")
+}
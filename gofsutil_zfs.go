@@ -0,0 +1,80 @@
+package gofsutil
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// mountZFS mounts the ZFS dataset to target. ErrNotImplemented is
+// returned if the host has no ZFS support, either because the mount
+// command is not installed or because the zfs filesystem type is not
+// registered with the kernel.
+func (fs *FS) mountZFS(
+	ctx context.Context,
+	dataset, target string,
+	opts ...string) error {
+
+	err := fs.mount(ctx, dataset, target, "zfs", opts...)
+	if errors.Is(err, exec.ErrNotFound) || errors.Is(err, errUnknownFSType) {
+		log.WithFields(log.Fields{
+			"dataset": dataset,
+			"target":  target,
+		}).WithError(err).Error("zfs is not supported")
+		return ErrNotImplemented
+	}
+	return err
+}
+
+// getZFSMounts returns a slice of the mounted ZFS datasets
+func (fs *FS) getZFSMounts(ctx context.Context) ([]Info, error) {
+
+	args := []string{"list", "-H", "-o", "name,mountpoint,mounted"}
+	buf, err := fs.exec(ctx, "zfs", args...)
+	if err != nil {
+		if isCommandNotFound(err) {
+			return nil, ErrNotImplemented
+		}
+		out := string(buf)
+		log.WithField("output", out).WithError(err).Error("zfs list failed")
+		return nil, fmt.Errorf(
+			"zfs list failed: %v\noutput: %s", err, out)
+	}
+	return parseZFSList(buf)
+}
+
+// parseZFSList parses the output of "zfs list -H -o name,mountpoint,mounted"
+// and returns the mounted datasets.
+func parseZFSList(buf []byte) ([]Info, error) {
+	var mountInfos []Info
+	scan := bufio.NewScanner(bytes.NewReader(buf))
+	for scan.Scan() {
+		line := scan.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf(
+				"parseZFSList: invalid field count: exp=3, act=%d: %s",
+				len(fields), line)
+		}
+		name, mountPoint, mounted := fields[0], fields[1], fields[2]
+		if mounted != "yes" || !strings.HasPrefix(mountPoint, "/") {
+			continue
+		}
+		mountInfos = append(mountInfos, Info{
+			Device: name,
+			Path:   mountPoint,
+			Source: name,
+			Type:   "zfs",
+		})
+	}
+	return mountInfos, scan.Err()
+}
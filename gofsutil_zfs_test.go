@@ -0,0 +1,107 @@
+package gofsutil_test
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"testing"
+
+	"github.com/thecodeteam/gofsutil"
+)
+
+const zfsListData = "tank\t/tank\tyes\n" +
+	"tank/vol1\t/var/lib/volumes/vol1\tyes\n" +
+	"tank/vol2\t/var/lib/volumes/vol2\tno\n" +
+	"tank/legacy\tlegacy\tno\n" +
+	"tank/vol3\tnone\tno\n"
+
+func TestGetZFSMounts(t *testing.T) {
+	r := &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			return zfsListData, nil
+		},
+	}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	mounts, err := fs.GetZFSMounts(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t, "zfs list -H -o name,mountpoint,mounted")
+
+	exp := []gofsutil.Info{
+		{Device: "tank", Path: "/tank", Source: "tank", Type: "zfs"},
+		{
+			Device: "tank/vol1",
+			Path:   "/var/lib/volumes/vol1",
+			Source: "tank/vol1",
+			Type:   "zfs",
+		},
+	}
+	if len(mounts) != len(exp) {
+		t.Fatalf("invalid mount count: exp=%d, act=%d: %+v",
+			len(exp), len(mounts), mounts)
+	}
+	for i := range exp {
+		if mounts[i].Device != exp[i].Device ||
+			mounts[i].Path != exp[i].Path ||
+			mounts[i].Source != exp[i].Source ||
+			mounts[i].Type != exp[i].Type {
+			t.Errorf("invalid mount: exp=%+v, act=%+v", exp[i], mounts[i])
+		}
+	}
+}
+
+func TestGetZFSMountsNotFound(t *testing.T) {
+	r := &testCommandRunner{
+		handler: func(args []string) (string, error) {
+			return "", exec.ErrNotFound
+		},
+	}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	if _, err := fs.GetZFSMounts(context.TODO()); err != gofsutil.ErrNotImplemented {
+		t.Errorf("expected ErrNotImplemented: %v", err)
+	}
+}
+
+func TestMountZFS(t *testing.T) {
+	r := &testCommandRunner{}
+	fs := &gofsutil.FS{RunCommand: r.run}
+
+	if err := fs.MountZFS(
+		context.TODO(), "tank/vol1", "/mnt/vol1", "ro"); err != nil {
+		t.Fatal(err)
+	}
+	r.assertCommands(t, "mount -t zfs -o ro tank/vol1 /mnt/vol1")
+}
+
+func TestMountZFSNotSupported(t *testing.T) {
+	tests := []struct {
+		name string
+		out  string
+		err  error
+	}{
+		{"mount not found", "", exec.ErrNotFound},
+		{"unknown fstype",
+			"mount: /mnt/vol1: unknown filesystem type 'zfs'.",
+			errors.New("exit status 32")},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(st *testing.T) {
+			r := &testCommandRunner{
+				handler: func(args []string) (string, error) {
+					return tt.out, tt.err
+				},
+			}
+			fs := &gofsutil.FS{RunCommand: r.run}
+
+			err := fs.MountZFS(context.TODO(), "tank/vol1", "/mnt/vol1")
+			if err != gofsutil.ErrNotImplemented {
+				st.Errorf("expected ErrNotImplemented: %v", err)
+			}
+		})
+	}
+}